@@ -0,0 +1,857 @@
+package a2apb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The From functions convert the SDK's JSON types to their messages. They
+// fail only when a free-form field, such as input or metadata, holds a
+// value that cannot be encoded as JSON. The To functions convert back and
+// accept nil messages, returning the zero value.
+
+// FromTaskParams converts task params to their message
+func FromTaskParams(p a2a.TaskParams) (*TaskParams, error) {
+	input, err := toStruct(p.Input)
+	if err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	metadata, err := toStruct(p.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	msg, err := fromMessagePtr(p.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskParams{
+		TaskId:              p.TaskID,
+		AgentId:             p.AgentID,
+		Action:              p.Action,
+		Sender:              p.Sender,
+		Input:               input,
+		Message:             msg,
+		Priority:            int32(p.Priority),
+		CorrelationId:       p.CorrelationID,
+		IdempotencyKey:      p.IdempotencyKey,
+		InputMode:           p.InputMode,
+		AcceptedOutputModes: p.AcceptedOutputModes,
+		Metadata:            metadata,
+		Resume:              p.Resume,
+		ParentTaskId:        p.ParentTaskID,
+		RootTaskId:          p.RootTaskID,
+		Hop:                 int32(p.Hop),
+	}, nil
+}
+
+// ToTaskParams converts a message to task params
+func ToTaskParams(p *TaskParams) a2a.TaskParams {
+	return a2a.TaskParams{
+		TaskID:              p.GetTaskId(),
+		AgentID:             p.GetAgentId(),
+		Action:              p.GetAction(),
+		Sender:              p.GetSender(),
+		Input:               fromStruct(p.GetInput()),
+		Message:             toMessagePtr(p.GetMessage()),
+		Priority:            int(p.GetPriority()),
+		CorrelationID:       p.GetCorrelationId(),
+		IdempotencyKey:      p.GetIdempotencyKey(),
+		InputMode:           p.GetInputMode(),
+		AcceptedOutputModes: p.GetAcceptedOutputModes(),
+		Metadata:            fromStruct(p.GetMetadata()),
+		Resume:              p.GetResume(),
+		ParentTaskID:        p.GetParentTaskId(),
+		RootTaskID:          p.GetRootTaskId(),
+		Hop:                 int(p.GetHop()),
+	}
+}
+
+// FromTaskResult converts a task result to its message
+func FromTaskResult(r *a2a.TaskResult) (*TaskResult, error) {
+	if r == nil {
+		return nil, nil
+	}
+	output, err := toStruct(r.Output)
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	metadata, err := toStruct(r.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	msg, err := fromMessagePtr(r.StatusMessage)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := fromArtifacts(r.Artifacts)
+	if err != nil {
+		return nil, err
+	}
+	rpcErr, err := FromRPCError(r.Error)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskResult{
+		TaskId:        r.TaskID,
+		Status:        FromTaskState(r.Status),
+		StatusMessage: msg,
+		UpdatedAt:     toTimestamp(r.UpdatedAt),
+		Output:        output,
+		Artifacts:     artifacts,
+		Attempts:      int32(r.Attempts),
+		Error:         rpcErr,
+		CorrelationId: r.CorrelationID,
+		Metadata:      metadata,
+	}, nil
+}
+
+// ToTaskResult converts a message to a task result
+func ToTaskResult(r *TaskResult) *a2a.TaskResult {
+	if r == nil {
+		return nil
+	}
+	return &a2a.TaskResult{
+		TaskID:        r.GetTaskId(),
+		Status:        ToTaskState(r.GetStatus()),
+		StatusMessage: toMessagePtr(r.GetStatusMessage()),
+		UpdatedAt:     fromTimestamp(r.GetUpdatedAt()),
+		Output:        fromStruct(r.GetOutput()),
+		Artifacts:     toArtifacts(r.GetArtifacts()),
+		Attempts:      int(r.GetAttempts()),
+		Error:         ToRPCError(r.GetError()),
+		CorrelationID: r.GetCorrelationId(),
+		Metadata:      fromStruct(r.GetMetadata()),
+	}
+}
+
+// FromRPCError converts a JSON-RPC error to its message
+func FromRPCError(e *a2a.JSONRPCError) (*RPCError, error) {
+	if e == nil {
+		return nil, nil
+	}
+	data, err := toValue(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error data: %w", err)
+	}
+	return &RPCError{Code: int32(e.Code), Message: e.Message, Data: data}, nil
+}
+
+// ToRPCError converts a message to a JSON-RPC error
+func ToRPCError(e *RPCError) *a2a.JSONRPCError {
+	if e == nil {
+		return nil
+	}
+	return &a2a.JSONRPCError{Code: int(e.GetCode()), Message: e.GetMessage(), Data: fromValue(e.GetData())}
+}
+
+// FromTask converts a task to its message
+func FromTask(t a2a.Task) (*Task, error) {
+	status, err := FromTaskStatus(t.Status)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := fromArtifacts(t.Artifacts)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]*Message, 0, len(t.History))
+	for _, m := range t.History {
+		msg, err := FromMessage(m)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, msg)
+	}
+	metadata, err := toStruct(t.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	return &Task{
+		Id:        t.ID,
+		ContextId: t.ContextID,
+		Status:    status,
+		Artifacts: artifacts,
+		History:   history,
+		Metadata:  metadata,
+	}, nil
+}
+
+// ToTask converts a message to a task
+func ToTask(t *Task) a2a.Task {
+	var history []a2a.Message
+	for _, m := range t.GetHistory() {
+		history = append(history, ToMessage(m))
+	}
+	return a2a.Task{
+		ID:        t.GetId(),
+		ContextID: t.GetContextId(),
+		Status:    ToTaskStatus(t.GetStatus()),
+		Artifacts: toArtifacts(t.GetArtifacts()),
+		History:   history,
+		Metadata:  fromStruct(t.GetMetadata()),
+		Kind:      "task",
+	}
+}
+
+// FromTaskStatus converts a task status to its message
+func FromTaskStatus(s a2a.TaskStatus) (*TaskStatus, error) {
+	msg, err := fromMessagePtr(s.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskStatus{State: FromTaskState(s.State), Message: msg, Timestamp: toTimestamp(s.Timestamp)}, nil
+}
+
+// ToTaskStatus converts a message to a task status
+func ToTaskStatus(s *TaskStatus) a2a.TaskStatus {
+	return a2a.TaskStatus{
+		State:     ToTaskState(s.GetState()),
+		Message:   toMessagePtr(s.GetMessage()),
+		Timestamp: fromTimestamp(s.GetTimestamp()),
+	}
+}
+
+// FromTaskState returns the enum value for a task state, TASK_STATE_UNKNOWN
+// for states the schema doesn't know and TASK_STATE_UNSPECIFIED for none
+func FromTaskState(s a2a.TaskState) TaskState {
+	if s == "" {
+		return TaskState_TASK_STATE_UNSPECIFIED
+	}
+	if v, ok := TaskState_value[enumName("TASK_STATE_", string(s))]; ok {
+		return TaskState(v)
+	}
+	return TaskState_TASK_STATE_UNKNOWN
+}
+
+// ToTaskState returns the task state for an enum value
+func ToTaskState(s TaskState) a2a.TaskState {
+	if s == TaskState_TASK_STATE_UNSPECIFIED {
+		return ""
+	}
+	return a2a.ParseTaskState(jsonName("TASK_STATE_", s.String()))
+}
+
+// FromMessage converts a message to its protobuf message
+func FromMessage(m a2a.Message) (*Message, error) {
+	parts, err := fromParts(m.Parts)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := toStruct(m.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("message metadata: %w", err)
+	}
+	role := Role_ROLE_UNSPECIFIED
+	if v, ok := Role_value[enumName("ROLE_", m.Role)]; ok && m.Role != "" {
+		role = Role(v)
+	}
+	return &Message{
+		Role:      role,
+		Parts:     parts,
+		MessageId: m.MessageID,
+		TaskId:    m.TaskID,
+		ContextId: m.ContextID,
+		Metadata:  metadata,
+	}, nil
+}
+
+// ToMessage converts a protobuf message to a message
+func ToMessage(m *Message) a2a.Message {
+	role := ""
+	if m.GetRole() != Role_ROLE_UNSPECIFIED {
+		role = jsonName("ROLE_", m.GetRole().String())
+	}
+	return a2a.Message{
+		Role:      role,
+		Parts:     toParts(m.GetParts()),
+		MessageID: m.GetMessageId(),
+		TaskID:    m.GetTaskId(),
+		ContextID: m.GetContextId(),
+		Kind:      "message",
+		Metadata:  fromStruct(m.GetMetadata()),
+	}
+}
+
+func fromMessagePtr(m *a2a.Message) (*Message, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return FromMessage(*m)
+}
+
+func toMessagePtr(m *Message) *a2a.Message {
+	if m == nil {
+		return nil
+	}
+	msg := ToMessage(m)
+	return &msg
+}
+
+// FromPart converts a TextPart, FilePart or DataPart to its message
+func FromPart(p a2a.Part) (*Part, error) {
+	switch p := p.(type) {
+	case a2a.TextPart:
+		metadata, err := toStruct(p.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("part metadata: %w", err)
+		}
+		return &Part{Part: &Part_Text{Text: p.Text}, Metadata: metadata}, nil
+	case a2a.FilePart:
+		metadata, err := toStruct(p.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("part metadata: %w", err)
+		}
+		file := &File{Name: p.File.Name, MimeType: p.File.MimeType, Bytes: p.File.Bytes, Uri: p.File.URI}
+		return &Part{Part: &Part_File{File: file}, Metadata: metadata}, nil
+	case a2a.DataPart:
+		data, err := toStruct(p.Data)
+		if err != nil {
+			return nil, fmt.Errorf("part data: %w", err)
+		}
+		metadata, err := toStruct(p.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("part metadata: %w", err)
+		}
+		return &Part{Part: &Part_Data{Data: data}, Metadata: metadata}, nil
+	case *a2a.TextPart:
+		return FromPart(*p)
+	case *a2a.FilePart:
+		return FromPart(*p)
+	case *a2a.DataPart:
+		return FromPart(*p)
+	}
+	return nil, fmt.Errorf("unknown part kind %q", p.PartKind())
+}
+
+// ToPart converts a message to a TextPart, FilePart or DataPart. It returns
+// nil for a part with no content.
+func ToPart(p *Part) a2a.Part {
+	metadata := fromStruct(p.GetMetadata())
+	switch c := p.GetPart().(type) {
+	case *Part_Text:
+		return a2a.TextPart{Text: c.Text, Metadata: metadata}
+	case *Part_File:
+		file := a2a.File{Name: c.File.GetName(), MimeType: c.File.GetMimeType(), Bytes: c.File.GetBytes(), URI: c.File.GetUri()}
+		return a2a.FilePart{File: file, Metadata: metadata}
+	case *Part_Data:
+		return a2a.DataPart{Data: fromStruct(c.Data), Metadata: metadata}
+	}
+	return nil
+}
+
+func fromParts(parts []a2a.Part) ([]*Part, error) {
+	out := make([]*Part, 0, len(parts))
+	for _, p := range parts {
+		part, err := FromPart(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, part)
+	}
+	return out, nil
+}
+
+func toParts(parts []*Part) []a2a.Part {
+	var out []a2a.Part
+	for _, p := range parts {
+		if part := ToPart(p); part != nil {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// FromArtifact converts an artifact to its message
+func FromArtifact(a a2a.Artifact) (*Artifact, error) {
+	parts, err := fromParts(a.Parts)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := toStruct(a.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("artifact metadata: %w", err)
+	}
+	return &Artifact{
+		ArtifactId:  a.ArtifactID,
+		Name:        a.Name,
+		Description: a.Description,
+		Parts:       parts,
+		Index:       int32(a.Index),
+		Append:      a.Append,
+		LastChunk:   a.LastChunk,
+		Metadata:    metadata,
+	}, nil
+}
+
+// ToArtifact converts a message to an artifact
+func ToArtifact(a *Artifact) a2a.Artifact {
+	return a2a.Artifact{
+		ArtifactID:  a.GetArtifactId(),
+		Name:        a.GetName(),
+		Description: a.GetDescription(),
+		Parts:       toParts(a.GetParts()),
+		Index:       int(a.GetIndex()),
+		Append:      a.GetAppend(),
+		LastChunk:   a.GetLastChunk(),
+		Metadata:    fromStruct(a.GetMetadata()),
+	}
+}
+
+func fromArtifacts(artifacts []a2a.Artifact) ([]*Artifact, error) {
+	var out []*Artifact
+	for _, a := range artifacts {
+		artifact, err := FromArtifact(a)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, artifact)
+	}
+	return out, nil
+}
+
+func toArtifacts(artifacts []*Artifact) []a2a.Artifact {
+	var out []a2a.Artifact
+	for _, a := range artifacts {
+		out = append(out, ToArtifact(a))
+	}
+	return out
+}
+
+// FromMessageSendParams converts message/send params to their message
+func FromMessageSendParams(p a2a.MessageSendParams) (*MessageSendParams, error) {
+	msg, err := FromMessage(p.Message)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := toStruct(p.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	out := &MessageSendParams{Message: msg, Metadata: metadata}
+	if c := p.Configuration; c != nil {
+		out.Configuration = &MessageSendConfiguration{
+			AcceptedOutputModes:    c.AcceptedOutputModes,
+			Blocking:               c.Blocking,
+			PushNotificationConfig: FromPushNotificationConfig(c.PushNotificationConfig),
+		}
+	}
+	return out, nil
+}
+
+// ToMessageSendParams converts a message to message/send params
+func ToMessageSendParams(p *MessageSendParams) a2a.MessageSendParams {
+	out := a2a.MessageSendParams{Message: ToMessage(p.GetMessage()), Metadata: fromStruct(p.GetMetadata())}
+	if c := p.GetConfiguration(); c != nil {
+		out.Configuration = &a2a.MessageSendConfiguration{
+			AcceptedOutputModes:    c.GetAcceptedOutputModes(),
+			Blocking:               c.Blocking,
+			PushNotificationConfig: ToPushNotificationConfig(c.GetPushNotificationConfig()),
+		}
+	}
+	return out
+}
+
+// FromStreamEvent converts a message/stream event to its message
+func FromStreamEvent(e a2a.StreamEvent) (*StreamResponse, error) {
+	switch {
+	case e.Task != nil:
+		task, err := FromTask(*e.Task)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResponse{Event: &StreamResponse_Task{Task: task}}, nil
+	case e.Message != nil:
+		msg, err := FromMessage(*e.Message)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResponse{Event: &StreamResponse_Message{Message: msg}}, nil
+	case e.StatusUpdate != nil:
+		status, err := FromTaskStatus(e.StatusUpdate.Status)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResponse{Event: &StreamResponse_StatusUpdate{StatusUpdate: &TaskStatusUpdateEvent{
+			TaskId:    e.StatusUpdate.TaskID,
+			ContextId: e.StatusUpdate.ContextID,
+			Status:    status,
+			Final:     e.StatusUpdate.Final,
+		}}}, nil
+	case e.ArtifactUpdate != nil:
+		artifact, err := FromArtifact(e.ArtifactUpdate.Artifact)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResponse{Event: &StreamResponse_ArtifactUpdate{ArtifactUpdate: &TaskArtifactUpdateEvent{
+			TaskId:    e.ArtifactUpdate.TaskID,
+			ContextId: e.ArtifactUpdate.ContextID,
+			Artifact:  artifact,
+			Append:    e.ArtifactUpdate.Append,
+			LastChunk: e.ArtifactUpdate.LastChunk,
+		}}}, nil
+	}
+	return &StreamResponse{}, nil
+}
+
+// ToStreamEvent converts a message to a message/stream event
+func ToStreamEvent(r *StreamResponse) a2a.StreamEvent {
+	switch e := r.GetEvent().(type) {
+	case *StreamResponse_Task:
+		task := ToTask(e.Task)
+		return a2a.StreamEvent{Task: &task}
+	case *StreamResponse_Message:
+		return a2a.StreamEvent{Message: toMessagePtr(e.Message)}
+	case *StreamResponse_StatusUpdate:
+		return a2a.StreamEvent{StatusUpdate: &a2a.TaskStatusUpdateEvent{
+			TaskID:    e.StatusUpdate.GetTaskId(),
+			ContextID: e.StatusUpdate.GetContextId(),
+			Kind:      "status-update",
+			Status:    ToTaskStatus(e.StatusUpdate.GetStatus()),
+			Final:     e.StatusUpdate.GetFinal(),
+		}}
+	case *StreamResponse_ArtifactUpdate:
+		return a2a.StreamEvent{ArtifactUpdate: &a2a.TaskArtifactUpdateEvent{
+			TaskID:    e.ArtifactUpdate.GetTaskId(),
+			ContextID: e.ArtifactUpdate.GetContextId(),
+			Kind:      "artifact-update",
+			Artifact:  ToArtifact(e.ArtifactUpdate.GetArtifact()),
+			Append:    e.ArtifactUpdate.GetAppend(),
+			LastChunk: e.ArtifactUpdate.GetLastChunk(),
+		}}
+	}
+	return a2a.StreamEvent{}
+}
+
+// FromPushNotificationConfig converts a push notification config to its
+// message
+func FromPushNotificationConfig(c *a2a.PushNotificationConfig) *PushNotificationConfig {
+	if c == nil {
+		return nil
+	}
+	out := &PushNotificationConfig{Id: c.ID, Url: c.URL, Token: c.Token}
+	if auth := c.Authentication; auth != nil {
+		out.Authentication = &PushNotificationAuthenticationInfo{Schemes: auth.Schemes, Credentials: auth.Credentials}
+	}
+	return out
+}
+
+// ToPushNotificationConfig converts a message to a push notification config
+func ToPushNotificationConfig(c *PushNotificationConfig) *a2a.PushNotificationConfig {
+	if c == nil {
+		return nil
+	}
+	out := &a2a.PushNotificationConfig{ID: c.GetId(), URL: c.GetUrl(), Token: c.GetToken()}
+	if auth := c.GetAuthentication(); auth != nil {
+		out.Authentication = &a2a.PushNotificationAuthenticationInfo{Schemes: auth.GetSchemes(), Credentials: auth.GetCredentials()}
+	}
+	return out
+}
+
+// FromAgentCard converts an Agent Card to its message
+func FromAgentCard(c a2a.AgentCard) *AgentCard {
+	out := &AgentCard{
+		Name:             c.Name,
+		Description:      c.Description,
+		Url:              c.URL,
+		ProtocolVersion:  c.ProtocolVersion,
+		Version:          c.Version,
+		DocumentationUrl: c.DocumentationURL,
+		Capabilities: &AgentCapabilities{
+			Streaming:              c.Capabilities.Streaming,
+			PushNotifications:      c.Capabilities.PushNotifications,
+			StateTransitionHistory: c.Capabilities.StateTransitionHistory,
+		},
+		DefaultInputModes:  c.DefaultInputModes,
+		DefaultOutputModes: c.DefaultOutputModes,
+	}
+	if c.Provider != nil {
+		out.Provider = &AgentProvider{Organization: c.Provider.Organization, Url: c.Provider.URL}
+	}
+	if c.Authentication != nil {
+		out.Authentication = &AgentAuthentication{Schemes: c.Authentication.Schemes}
+	}
+	if len(c.SecuritySchemes) > 0 {
+		out.SecuritySchemes = make(map[string]*SecurityScheme, len(c.SecuritySchemes))
+		for name, s := range c.SecuritySchemes {
+			out.SecuritySchemes[name] = &SecurityScheme{
+				Type:             s.Type,
+				Description:      s.Description,
+				Name:             s.Name,
+				In:               s.In,
+				Scheme:           s.Scheme,
+				BearerFormat:     s.BearerFormat,
+				Flows:            fromOAuthFlows(s.Flows),
+				OpenIdConnectUrl: s.OpenIDConnectURL,
+			}
+		}
+	}
+	for _, req := range c.Security {
+		schemes := make(map[string]*Scopes, len(req))
+		for name, scopes := range req {
+			schemes[name] = &Scopes{Scopes: scopes}
+		}
+		out.Security = append(out.Security, &SecurityRequirement{Schemes: schemes})
+	}
+	for _, s := range c.Skills {
+		out.Skills = append(out.Skills, &AgentSkill{
+			Id:          s.ID,
+			Name:        s.Name,
+			Description: s.Description,
+			Tags:        s.Tags,
+			Examples:    s.Examples,
+			InputModes:  s.InputModes,
+			OutputModes: s.OutputModes,
+		})
+	}
+	return out
+}
+
+// ToAgentCard converts a message to an Agent Card
+func ToAgentCard(c *AgentCard) a2a.AgentCard {
+	out := a2a.AgentCard{
+		Name:             c.GetName(),
+		Description:      c.GetDescription(),
+		URL:              c.GetUrl(),
+		ProtocolVersion:  c.GetProtocolVersion(),
+		Version:          c.GetVersion(),
+		DocumentationURL: c.GetDocumentationUrl(),
+		Capabilities: a2a.AgentCapabilities{
+			Streaming:              c.GetCapabilities().GetStreaming(),
+			PushNotifications:      c.GetCapabilities().GetPushNotifications(),
+			StateTransitionHistory: c.GetCapabilities().GetStateTransitionHistory(),
+		},
+		DefaultInputModes:  c.GetDefaultInputModes(),
+		DefaultOutputModes: c.GetDefaultOutputModes(),
+		Skills:             []a2a.AgentSkill{},
+	}
+	if p := c.GetProvider(); p != nil {
+		out.Provider = &a2a.AgentProvider{Organization: p.GetOrganization(), URL: p.GetUrl()}
+	}
+	if auth := c.GetAuthentication(); auth != nil {
+		out.Authentication = &a2a.AgentAuthentication{Schemes: auth.GetSchemes()}
+	}
+	if len(c.GetSecuritySchemes()) > 0 {
+		out.SecuritySchemes = make(map[string]a2a.SecurityScheme, len(c.GetSecuritySchemes()))
+		for name, s := range c.GetSecuritySchemes() {
+			out.SecuritySchemes[name] = a2a.SecurityScheme{
+				Type:             s.GetType(),
+				Description:      s.GetDescription(),
+				Name:             s.GetName(),
+				In:               s.GetIn(),
+				Scheme:           s.GetScheme(),
+				BearerFormat:     s.GetBearerFormat(),
+				Flows:            toOAuthFlows(s.GetFlows()),
+				OpenIDConnectURL: s.GetOpenIdConnectUrl(),
+			}
+		}
+	}
+	for _, req := range c.GetSecurity() {
+		schemes := make(map[string][]string, len(req.GetSchemes()))
+		for name, scopes := range req.GetSchemes() {
+			schemes[name] = scopes.GetScopes()
+		}
+		out.Security = append(out.Security, schemes)
+	}
+	for _, s := range c.GetSkills() {
+		out.Skills = append(out.Skills, a2a.AgentSkill{
+			ID:          s.GetId(),
+			Name:        s.GetName(),
+			Description: s.GetDescription(),
+			Tags:        s.GetTags(),
+			Examples:    s.GetExamples(),
+			InputModes:  s.GetInputModes(),
+			OutputModes: s.GetOutputModes(),
+		})
+	}
+	return out
+}
+
+func fromOAuthFlows(f *a2a.OAuthFlows) *OAuthFlows {
+	if f == nil {
+		return nil
+	}
+	return &OAuthFlows{
+		AuthorizationCode: fromOAuthFlow(f.AuthorizationCode),
+		ClientCredentials: fromOAuthFlow(f.ClientCredentials),
+		DeviceCode:        fromOAuthFlow(f.DeviceCode),
+	}
+}
+
+func fromOAuthFlow(f *a2a.OAuthFlow) *OAuthFlow {
+	if f == nil {
+		return nil
+	}
+	return &OAuthFlow{AuthorizationUrl: f.AuthorizationURL, TokenUrl: f.TokenURL, RefreshUrl: f.RefreshURL, Scopes: f.Scopes}
+}
+
+func toOAuthFlows(f *OAuthFlows) *a2a.OAuthFlows {
+	if f == nil {
+		return nil
+	}
+	return &a2a.OAuthFlows{
+		AuthorizationCode: toOAuthFlow(f.GetAuthorizationCode()),
+		ClientCredentials: toOAuthFlow(f.GetClientCredentials()),
+		DeviceCode:        toOAuthFlow(f.GetDeviceCode()),
+	}
+}
+
+func toOAuthFlow(f *OAuthFlow) *a2a.OAuthFlow {
+	if f == nil {
+		return nil
+	}
+	return &a2a.OAuthFlow{AuthorizationURL: f.GetAuthorizationUrl(), TokenURL: f.GetTokenUrl(), RefreshURL: f.GetRefreshUrl(), Scopes: f.GetScopes()}
+}
+
+// FromAgentInfo converts a directory entry to its message
+func FromAgentInfo(a a2a.AgentInfo) (*AgentInfo, error) {
+	out := &AgentInfo{
+		AgentId:      a.AgentID,
+		Name:         a.Name,
+		Capabilities: a.Capabilities,
+		Endpoint:     a.Endpoint,
+		Description:  a.Description,
+		Tags:         a.Tags,
+		Metadata:     a.Metadata,
+		Region:       a.Region,
+		Zone:         a.Zone,
+		PublicKey:    a.PublicKey,
+		RegisteredAt: toTimestamp(a.RegisteredAt),
+		LastSeen:     toTimestamp(a.LastSeen),
+	}
+	if a.Status != "" {
+		out.Status = AgentStatus(AgentStatus_value[enumName("AGENT_STATUS_", string(a.Status))])
+	}
+	if a.Load != nil {
+		out.Load = &Load{InFlight: int32(a.Load.InFlight), QueueDepth: int32(a.Load.QueueDepth)}
+	}
+	if r := a.Reputation; r != nil {
+		out.Reputation = &Reputation{Score: r.Score, Successes: r.Successes, Failures: r.Failures, AvgLatencyMs: r.AvgLatencyMs}
+	}
+	if len(a.Schemas) > 0 {
+		out.Schemas = make(map[string]*CapabilitySchema, len(a.Schemas))
+		for capability, s := range a.Schemas {
+			schema := &CapabilitySchema{}
+			var err error
+			if schema.Input, err = toValue(s.Input); err != nil {
+				return nil, fmt.Errorf("%s input schema: %w", capability, err)
+			}
+			if schema.Output, err = toValue(s.Output); err != nil {
+				return nil, fmt.Errorf("%s output schema: %w", capability, err)
+			}
+			out.Schemas[capability] = schema
+		}
+	}
+	return out, nil
+}
+
+// ToAgentInfo converts a message to a directory entry
+func ToAgentInfo(a *AgentInfo) a2a.AgentInfo {
+	out := a2a.AgentInfo{
+		AgentID:      a.GetAgentId(),
+		Name:         a.GetName(),
+		Capabilities: a.GetCapabilities(),
+		Endpoint:     a.GetEndpoint(),
+		Description:  a.GetDescription(),
+		Tags:         a.GetTags(),
+		Metadata:     a.GetMetadata(),
+		Region:       a.GetRegion(),
+		Zone:         a.GetZone(),
+		PublicKey:    a.GetPublicKey(),
+		RegisteredAt: fromTimestamp(a.GetRegisteredAt()),
+		LastSeen:     fromTimestamp(a.GetLastSeen()),
+	}
+	if a.GetStatus() != AgentStatus_AGENT_STATUS_UNSPECIFIED {
+		out.Status = a2a.AgentStatus(jsonName("AGENT_STATUS_", a.GetStatus().String()))
+	}
+	if l := a.GetLoad(); l != nil {
+		out.Load = &a2a.Load{InFlight: int(l.GetInFlight()), QueueDepth: int(l.GetQueueDepth())}
+	}
+	if r := a.GetReputation(); r != nil {
+		out.Reputation = &a2a.Reputation{Score: r.GetScore(), Successes: r.GetSuccesses(), Failures: r.GetFailures(), AvgLatencyMs: r.GetAvgLatencyMs()}
+	}
+	if len(a.GetSchemas()) > 0 {
+		out.Schemas = make(map[string]a2a.CapabilitySchema, len(a.GetSchemas()))
+		for capability, s := range a.GetSchemas() {
+			out.Schemas[capability] = a2a.CapabilitySchema{Input: fromValue(s.GetInput()), Output: fromValue(s.GetOutput())}
+		}
+	}
+	return out
+}
+
+// enumName returns the enum value name for a JSON string, e.g.
+// TASK_STATE_INPUT_REQUIRED for "input-required"
+func enumName(prefix, s string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+}
+
+// jsonName reverses enumName
+func jsonName(prefix, name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, prefix)), "_", "-")
+}
+
+// toStruct encodes a JSON object, whatever Go types it holds, as a Struct
+func toStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func fromStruct(s *structpb.Struct) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
+
+// toValue encodes raw JSON, such as a JSON Schema, as a Value
+func toValue(raw json.RawMessage) (*structpb.Value, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	v := &structpb.Value{}
+	if err := protojson.Unmarshal(raw, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func fromValue(v *structpb.Value) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := protojson.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
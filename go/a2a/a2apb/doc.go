@@ -0,0 +1,12 @@
+// Package a2apb holds the Go types and gRPC stubs generated from
+// proto/a2a/v1/a2a.proto, the protobuf schema of the A2A protocol's
+// messages and service, and converts them to and from the JSON types of
+// package a2a. It is for transports, such as gRPC, that carry protobuf,
+// and for sharing one schema with SDKs in other languages.
+//
+// The schema follows the JSON types field for field; when a field is added
+// to one of them, add it to a2a.proto, regenerate and extend the
+// conversion here.
+package a2apb
+
+//go:generate protoc -I ../../../proto --go_out=. --go_opt=module=github.com/mentessaas/a2a-protocol/go/a2a/a2apb --go-grpc_out=. --go-grpc_opt=module=github.com/mentessaas/a2a-protocol/go/a2a/a2apb a2a/v1/a2a.proto
@@ -0,0 +1,55 @@
+// Package a2achi mounts an A2A server into a chi router.
+//
+//	r := chi.NewRouter()
+//	a2achi.Mount(r, "/a2a", server)
+package a2achi
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Mount serves the A2A JSON-RPC endpoint, Agent Card, health check and
+// hosted agents of server under prefix. Set the server's endpoint (see
+// a2a.WithEndpoint) to the public URL of prefix so the Agent Card and
+// directory registrations point at it.
+func Mount(r chi.Router, prefix string, server *a2a.A2AServer) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	handler := server.Handler()
+	if prefix == "" {
+		r.Handle("/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			servePath(handler, w, r, chi.URLParam(r, "*"))
+		}))
+		return
+	}
+	// Mount leaves what follows prefix in the route context rather than
+	// in the wildcard
+	r.Mount(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		servePath(handler, w, r, chi.RouteContext(r.Context()).RoutePath)
+	}))
+}
+
+// MountAgentCard serves the server's Agent Card at the site root
+// (/.well-known/agent.json) for clients that look for it there
+func MountAgentCard(r chi.Router, server *a2a.A2AServer) {
+	r.Get(a2a.AgentCardPath, server.Handler().ServeHTTP)
+}
+
+// servePath serves a copy of r with path, what followed the prefix in its
+// URL, as the URL path, as http.StripPrefix does. Taking it from the route
+// rather than stripping prefix again works under groups too. The prefix
+// itself is served as the server's root rather than with an empty path,
+// which the server would redirect to /.
+func servePath(handler http.Handler, w http.ResponseWriter, r *http.Request, path string) {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + strings.TrimPrefix(path, "/")
+	r2.URL.RawPath = ""
+	handler.ServeHTTP(w, r2)
+}
@@ -0,0 +1,30 @@
+package a2achi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/internal/adaptertest"
+)
+
+func TestMount(t *testing.T) {
+	adaptertest.Test(t, func(prefix string, server *a2a.A2AServer) http.Handler {
+		r := chi.NewRouter()
+		Mount(r, prefix, server)
+		MountAgentCard(r, server)
+		return r
+	}, "", "/a2a", "/v1/a2a/")
+}
+
+func TestMountSubrouter(t *testing.T) {
+	adaptertest.Test(t, func(prefix string, server *a2a.A2AServer) http.Handler {
+		r := chi.NewRouter()
+		r.Route("/api", func(r chi.Router) {
+			Mount(r, "/a2a", server)
+		})
+		MountAgentCard(r, server)
+		return r
+	}, "/api/a2a")
+}
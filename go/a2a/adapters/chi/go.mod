@@ -0,0 +1,10 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/adapters/chi
+
+go 1.24
+
+require (
+	github.com/go-chi/chi/v5 v5.3.2
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
@@ -0,0 +1,58 @@
+// Package a2aecho mounts an A2A server into an echo instance.
+//
+//	e := echo.New()
+//	a2aecho.Mount(e, "/a2a", server)
+package a2aecho
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Router is the part of *echo.Echo and *echo.Group used to mount routes
+type Router interface {
+	Any(path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) []*echo.Route
+	GET(path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+}
+
+// Mount serves the A2A JSON-RPC endpoint, Agent Card, health check and
+// hosted agents of server under prefix. Set the server's endpoint (see
+// a2a.WithEndpoint) to the public URL of prefix so the Agent Card and
+// directory registrations point at it.
+func Mount(r Router, prefix string, server *a2a.A2AServer) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	serverHandler := server.Handler()
+	handler := func(c echo.Context) error {
+		servePath(serverHandler, c.Response(), c.Request(), c.Param("*"))
+		return nil
+	}
+	if prefix != "" {
+		r.Any(prefix, handler)
+	}
+	r.Any(prefix+"/*", handler)
+}
+
+// MountAgentCard serves the server's Agent Card at the site root
+// (/.well-known/agent.json) for clients that look for it there
+func MountAgentCard(r Router, server *a2a.A2AServer) {
+	r.GET(a2a.AgentCardPath, echo.WrapHandler(server.Handler()))
+}
+
+// servePath serves a copy of r with path, what followed the prefix in its
+// URL, as the URL path, as http.StripPrefix does. Taking it from the route
+// rather than stripping prefix again works under groups too. The prefix
+// itself is served as the server's root rather than with an empty path,
+// which the server would redirect to /.
+func servePath(handler http.Handler, w http.ResponseWriter, r *http.Request, path string) {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + strings.TrimPrefix(path, "/")
+	r2.URL.RawPath = ""
+	handler.ServeHTTP(w, r2)
+}
@@ -0,0 +1,28 @@
+package a2aecho
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/internal/adaptertest"
+)
+
+func TestMount(t *testing.T) {
+	adaptertest.Test(t, func(prefix string, server *a2a.A2AServer) http.Handler {
+		e := echo.New()
+		Mount(e, prefix, server)
+		MountAgentCard(e, server)
+		return e
+	}, "", "/a2a", "/v1/a2a/")
+}
+
+func TestMountGroup(t *testing.T) {
+	adaptertest.Test(t, func(prefix string, server *a2a.A2AServer) http.Handler {
+		e := echo.New()
+		Mount(e.Group("/api"), "/a2a", server)
+		MountAgentCard(e, server)
+		return e
+	}, "/api/a2a")
+}
@@ -0,0 +1,50 @@
+// Package a2agin mounts an A2A server into a gin engine.
+//
+//	r := gin.Default()
+//	a2agin.Mount(r, "/a2a", server)
+package a2agin
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Mount serves the A2A JSON-RPC endpoint, Agent Card, health check and
+// hosted agents of server under prefix, which must not be empty because
+// gin does not allow a root catch-all next to other routes. Set the
+// server's endpoint (see a2a.WithEndpoint) to the public URL of prefix so
+// the Agent Card and directory registrations point at it.
+func Mount(r gin.IRouter, prefix string, server *a2a.A2AServer) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	serverHandler := server.Handler()
+	handler := func(c *gin.Context) {
+		servePath(serverHandler, c.Writer, c.Request, c.Param("path"))
+	}
+	r.Any(prefix, handler)
+	r.Any(prefix+"/*path", handler)
+}
+
+// MountAgentCard serves the server's Agent Card at the site root
+// (/.well-known/agent.json) for clients that look for it there
+func MountAgentCard(r gin.IRouter, server *a2a.A2AServer) {
+	r.GET(a2a.AgentCardPath, gin.WrapH(server.Handler()))
+}
+
+// servePath serves a copy of r with path, what followed the prefix in its
+// URL, as the URL path, as http.StripPrefix does. Taking it from the route
+// rather than stripping prefix again works under groups too. The prefix
+// itself is served as the server's root rather than with an empty path,
+// which the server would redirect to /.
+func servePath(handler http.Handler, w http.ResponseWriter, r *http.Request, path string) {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + strings.TrimPrefix(path, "/")
+	r2.URL.RawPath = ""
+	handler.ServeHTTP(w, r2)
+}
@@ -0,0 +1,28 @@
+package a2agin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/internal/adaptertest"
+)
+
+func TestMount(t *testing.T) {
+	adaptertest.Test(t, func(prefix string, server *a2a.A2AServer) http.Handler {
+		r := gin.New()
+		Mount(r, prefix, server)
+		MountAgentCard(r, server)
+		return r
+	}, "/a2a", "/v1/a2a/")
+}
+
+func TestMountGroup(t *testing.T) {
+	adaptertest.Test(t, func(prefix string, server *a2a.A2AServer) http.Handler {
+		r := gin.New()
+		Mount(r.Group("/api"), "/a2a", server)
+		MountAgentCard(r, server)
+		return r
+	}, "/api/a2a")
+}
@@ -0,0 +1,411 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AgentCardPath is where servers publish their Agent Card
+const AgentCardPath = "/.well-known/agent.json"
+
+// agentCardPathV1 is where newer versions of the specification publish it.
+// FetchAgentCard falls back to it.
+const agentCardPathV1 = "/.well-known/agent-card.json"
+
+// ErrInvalidAgentCard is returned by AgentCard.Validate and FetchAgentCard
+// for cards missing required fields or contradicting themselves
+var ErrInvalidAgentCard = errors.New("invalid agent card")
+
+// ProtocolVersion is the version of the A2A specification Agent Cards
+// declare
+const ProtocolVersion = "0.2.5"
+
+// AgentCard is the self-description an agent publishes so clients can
+// discover its skills without a directory
+type AgentCard struct {
+	Name               string                    `json:"name"`
+	Description        string                    `json:"description,omitempty"`
+	URL                string                    `json:"url"`
+	ProtocolVersion    string                    `json:"protocolVersion,omitempty"`
+	Version            string                    `json:"version,omitempty"`
+	Provider           *AgentProvider            `json:"provider,omitempty"`
+	DocumentationURL   string                    `json:"documentationUrl,omitempty"`
+	Capabilities       AgentCapabilities         `json:"capabilities"`
+	Authentication     *AgentAuthentication      `json:"authentication,omitempty"` // superseded by SecuritySchemes in newer specs
+	SecuritySchemes    map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	Security           []map[string][]string     `json:"security,omitempty"` // alternatives, each naming schemes with their required scopes
+	DefaultInputModes  []string                  `json:"defaultInputModes,omitempty"`
+	DefaultOutputModes []string                  `json:"defaultOutputModes,omitempty"`
+	Skills             []AgentSkill              `json:"skills"`
+	// JWKS holds the public keys the agent signs requests with, for
+	// servers verifying their JWS, and those task input is encrypted to
+	JWKS *JWKSet `json:"jwks,omitempty"`
+	// Signatures vouch for the rest of the card, see CardSigner
+	Signatures []AgentCardSignature `json:"signatures,omitempty"`
+}
+
+// AgentProvider is the organization behind an agent
+type AgentProvider struct {
+	Organization string `json:"organization"`
+	URL          string `json:"url,omitempty"`
+}
+
+// SecurityScheme describes how to authenticate to an agent, in the shape
+// of an OpenAPI security scheme
+type SecurityScheme struct {
+	Type             string      `json:"type"` // apiKey, http, oauth2, openIdConnect or mutualTLS
+	Description      string      `json:"description,omitempty"`
+	Name             string      `json:"name,omitempty"`   // apiKey: header, query or cookie name
+	In               string      `json:"in,omitempty"`     // apiKey: "header", "query" or "cookie"
+	Scheme           string      `json:"scheme,omitempty"` // http: e.g. "bearer" or "basic"
+	BearerFormat     string      `json:"bearerFormat,omitempty"`
+	Flows            *OAuthFlows `json:"flows,omitempty"` // oauth2
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
+}
+
+// OAuthFlows lists the OAuth 2.0 flows an oauth2 scheme supports
+type OAuthFlows struct {
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	DeviceCode        *OAuthFlow `json:"deviceCode,omitempty"`
+}
+
+// OAuthFlow is one OAuth 2.0 flow
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// BearerScheme is an http bearer token SecurityScheme
+func BearerScheme(bearerFormat string) SecurityScheme {
+	return SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: bearerFormat}
+}
+
+// APIKeyScheme is a SecurityScheme for an API key sent in the named
+// header
+func APIKeyScheme(header string) SecurityScheme {
+	return SecurityScheme{Type: "apiKey", In: "header", Name: header}
+}
+
+// AgentCapabilities lists the optional protocol features an agent supports
+type AgentCapabilities struct {
+	Streaming              bool `json:"streaming"`
+	PushNotifications      bool `json:"pushNotifications"`
+	StateTransitionHistory bool `json:"stateTransitionHistory"`
+}
+
+// AgentAuthentication lists the authentication schemes an agent accepts
+type AgentAuthentication struct {
+	Schemes []string `json:"schemes"`
+}
+
+// AgentSkill describes one thing an agent can do
+type AgentSkill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Examples    []string `json:"examples,omitempty"` // sample requests, e.g. "Summarize this article in 3 bullets"
+	// InputModes and OutputModes are the media types, such as "text/plain"
+	// or "image/*", the skill takes and produces. Tasks are checked against
+	// those declared; empty means the card's defaults, unchecked.
+	InputModes  []string `json:"inputModes,omitempty"`
+	OutputModes []string `json:"outputModes,omitempty"`
+}
+
+// cardConfig holds what servers publish in their Agent Card beyond their
+// identity
+type cardConfig struct {
+	version          string
+	provider         *AgentProvider
+	documentationURL string
+	securitySchemes  map[string]SecurityScheme
+	security         []map[string][]string
+	skills           map[string]AgentSkill // by ID
+	jwks             []JWK
+	signers          []CardSigner
+}
+
+// WithDescription sets the description published in the Agent Card
+func WithDescription(description string) ServerOption {
+	return func(s *A2AServer) {
+		s.Description = description
+	}
+}
+
+// WithAuthentication declares the authentication schemes (e.g. "bearer")
+// the server requires, as published in the Agent Card. Enforcement is up
+// to middleware.
+func WithAuthentication(schemes ...string) ServerOption {
+	return func(s *A2AServer) {
+		s.authSchemes = schemes
+	}
+}
+
+// WithVersion sets the agent version published in the Agent Card
+func WithVersion(version string) ServerOption {
+	return func(s *A2AServer) {
+		s.card.version = version
+	}
+}
+
+// WithProvider names the organization behind the agent in the Agent Card
+func WithProvider(organization, url string) ServerOption {
+	return func(s *A2AServer) {
+		s.card.provider = &AgentProvider{Organization: organization, URL: url}
+	}
+}
+
+// WithDocumentationURL links the agent's documentation from the Agent Card
+func WithDocumentationURL(url string) ServerOption {
+	return func(s *A2AServer) {
+		s.card.documentationURL = url
+	}
+}
+
+// WithSecurityScheme declares a way to authenticate in the Agent Card,
+// required with scopes. Schemes declared separately are alternatives.
+// Enforcement is up to middleware.
+func WithSecurityScheme(name string, scheme SecurityScheme, scopes ...string) ServerOption {
+	return func(s *A2AServer) {
+		if s.card.securitySchemes == nil {
+			s.card.securitySchemes = make(map[string]SecurityScheme)
+		}
+		s.card.securitySchemes[name] = scheme
+		if scopes == nil {
+			scopes = []string{}
+		}
+		s.card.security = append(s.card.security, map[string][]string{name: scopes})
+	}
+}
+
+// WithSkill describes a capability in the Agent Card, matched by skill.ID.
+// Capabilities without one are published with only their name. Tasks for
+// the capability are refused with CodeContentTypeNotSupported if their
+// input or accepted output modes don't match the skill's InputModes or
+// OutputModes.
+func WithSkill(skill AgentSkill) ServerOption {
+	return func(s *A2AServer) {
+		if s.card.skills == nil {
+			s.card.skills = make(map[string]AgentSkill)
+		}
+		s.card.skills[skill.ID] = skill
+	}
+}
+
+// AgentCard builds the Agent Card for the server's own identity
+func (s *A2AServer) AgentCard() AgentCard {
+	return s.agentCard(s.info(), s.Description)
+}
+
+func (s *A2AServer) agentCard(info AgentInfo, description string) AgentCard {
+	card := AgentCard{
+		Name:               info.Name,
+		Description:        description,
+		URL:                info.Endpoint,
+		ProtocolVersion:    ProtocolVersion,
+		Version:            s.card.version,
+		Provider:           s.card.provider,
+		DocumentationURL:   s.card.documentationURL,
+		SecuritySchemes:    maps.Clone(s.card.securitySchemes),
+		Security:           s.card.security,
+		DefaultInputModes:  []string{"application/json"},
+		DefaultOutputModes: []string{"application/json"},
+		Skills:             make([]AgentSkill, 0, len(info.Capabilities)),
+	}
+	if len(s.card.jwks) > 0 {
+		card.JWKS = &JWKSet{Keys: s.card.jwks}
+	}
+	card.Capabilities.Streaming = true
+	card.Capabilities.PushNotifications = s.push.config != nil
+	if len(s.authSchemes) > 0 {
+		card.Authentication = &AgentAuthentication{Schemes: s.authSchemes}
+	}
+	for _, capability := range info.Capabilities {
+		skill, ok := s.card.skills[capability]
+		if !ok {
+			skill = AgentSkill{ID: capability}
+		}
+		if skill.Name == "" {
+			skill.Name = capability
+		}
+		card.Skills = append(card.Skills, skill)
+	}
+	for _, signer := range s.card.signers {
+		if err := signer.sign(&card); err != nil {
+			s.logger.Warn("could not sign agent card", "agent", info.AgentID, "error", err)
+		}
+	}
+	return card
+}
+
+// handleAgentCard serves the Agent Card of the server or of a hosted agent
+// at <agent path>/.well-known/agent.json
+func (s *A2AServer) handleAgentCard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	card := s.AgentCard()
+	if strings.HasPrefix(r.URL.Path, agentPathPrefix) {
+		agentID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, agentPathPrefix), AgentCardPath)
+		s.mu.RLock()
+		hosted, ok := s.hosted[agentID]
+		s.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		card = s.agentCard(hosted.Info(), hosted.Description)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(card)
+}
+
+// Skill returns the skill with the given ID
+func (c *AgentCard) Skill(id string) (AgentSkill, bool) {
+	for _, skill := range c.Skills {
+		if skill.ID == id {
+			return skill, true
+		}
+	}
+	return AgentSkill{}, false
+}
+
+// RequiresAuthentication reports whether the card declares any way callers
+// must authenticate
+func (c *AgentCard) RequiresAuthentication() bool {
+	return len(c.Security) > 0 || (c.Authentication != nil && len(c.Authentication.Schemes) > 0)
+}
+
+// Validate checks the card has a name, an http(s) URL and uniquely
+// identified skills, that its security requirements name declared schemes
+// and that each scheme has the fields its type needs
+func (c *AgentCard) Validate() error {
+	var problems []error
+	invalid := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidAgentCard}, args...)...))
+	}
+
+	if c.Name == "" {
+		invalid("missing name")
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("url %q is not an http or https URL", c.URL)
+	}
+	seen := make(map[string]bool, len(c.Skills))
+	for i, skill := range c.Skills {
+		switch {
+		case skill.ID == "":
+			invalid("skill %d has no id", i)
+		case seen[skill.ID]:
+			invalid("duplicate skill %q", skill.ID)
+		}
+		seen[skill.ID] = true
+	}
+	for name, scheme := range c.SecuritySchemes {
+		switch scheme.Type {
+		case "apiKey":
+			if scheme.Name == "" || (scheme.In != "header" && scheme.In != "query" && scheme.In != "cookie") {
+				invalid("apiKey scheme %q needs a name and in", name)
+			}
+		case "http":
+			if scheme.Scheme == "" {
+				invalid("http scheme %q needs a scheme", name)
+			}
+		case "oauth2":
+			if scheme.Flows == nil {
+				invalid("oauth2 scheme %q needs flows", name)
+			}
+		case "openIdConnect":
+			if scheme.OpenIDConnectURL == "" {
+				invalid("openIdConnect scheme %q needs an openIdConnectUrl", name)
+			}
+		case "mutualTLS":
+		default:
+			invalid("scheme %q has unknown type %q", name, scheme.Type)
+		}
+	}
+	if c.JWKS != nil {
+		for i, key := range c.JWKS.Keys {
+			if _, err := key.PublicKey(); err != nil {
+				invalid("jwks key %d: %v", i, err)
+			}
+		}
+	}
+	for _, requirement := range c.Security {
+		for name := range requirement {
+			if _, ok := c.SecuritySchemes[name]; !ok {
+				invalid("security requires undeclared scheme %q", name)
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// FetchAgentCard retrieves and validates the Agent Card published at
+// baseURL, so callers can check an agent's skills and how to authenticate
+// before sending it tasks. baseURL may also be the card's own URL.
+func (a *A2AAgent) FetchAgentCard(ctx context.Context, baseURL string) (*AgentCard, error) {
+	cardURL := baseURL
+	if !strings.HasSuffix(baseURL, AgentCardPath) && !strings.HasSuffix(baseURL, agentCardPathV1) {
+		cardURL = strings.TrimSuffix(baseURL, "/") + AgentCardPath
+	}
+
+	card, status, err := a.getAgentCard(ctx, cardURL)
+	if status == http.StatusNotFound && cardURL != baseURL {
+		card, _, err = a.getAgentCard(ctx, strings.TrimSuffix(baseURL, "/")+agentCardPathV1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// maxAgentCardBytes bounds the Agent Cards FetchAgentCard reads
+const maxAgentCardBytes = 1 << 20
+
+func (a *A2AAgent) getAgentCard(ctx context.Context, cardURL string) (*AgentCard, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.httpClient().Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("failed to fetch agent card: HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAgentCardBytes))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+	var card AgentCard
+	if err := json.Unmarshal(body, &card); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("%w: %v", ErrInvalidAgentCard, err)
+	}
+	if a.CardVerification != nil {
+		if err := a.CardVerification.verify(body, card.Signatures); err != nil {
+			return nil, resp.StatusCode, err
+		}
+	}
+	return &card, resp.StatusCode, nil
+}
@@ -0,0 +1,136 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// errNoTask is returned by AddArtifact outside of a task handler
+var errNoTask = errors.New("not running a task")
+
+// Artifact is an output of a task, in the A2A specification's shape. Large
+// or incrementally produced outputs are sent as chunks: the first creates
+// the artifact and later ones with Append set add their parts to it.
+type Artifact struct {
+	ArtifactID  string                 `json:"artifactId"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Parts       []Part                 `json:"parts"`
+	Index       int                    `json:"index"`
+	Append      bool                   `json:"append,omitempty"`    // adds Parts to the artifact with the same ID, or Index if it has none
+	LastChunk   bool                   `json:"lastChunk,omitempty"` // no more chunks follow
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// NewArtifact returns an artifact with a fresh ID
+func NewArtifact(name string, parts ...Part) Artifact {
+	return Artifact{ArtifactID: generateID(), Name: name, Parts: parts}
+}
+
+// UnmarshalJSON decodes each part into the type named by its "kind"
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	type plain Artifact
+	var decoded struct {
+		plain
+		Parts []json.RawMessage `json:"parts"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*a = Artifact(decoded.plain)
+	var err error
+	a.Parts, err = unmarshalParts(decoded.Parts)
+	return err
+}
+
+// AddArtifact adds an output to the task a handler is running. Call it as
+// results become available: asynchronous tasks show the artifacts added so
+// far to a2a/task/get while still working. An artifact with Append set is
+// merged into the one added earlier with the same ArtifactID or Index.
+func AddArtifact(ctx context.Context, artifact Artifact) error {
+	artifacts, ok := ctx.Value(artifactsKey{}).(*artifactSet)
+	if !ok {
+		return errNoTask
+	}
+	artifacts.add(artifact)
+	return nil
+}
+
+type artifactsKey struct{}
+
+// artifactSet collects the artifacts of a running task
+type artifactSet struct {
+	mu        sync.Mutex
+	artifacts []Artifact
+	// onChange, if set, is called after each change with a snapshot and
+	// the chunk added, its ID and Index filled in
+	onChange func(artifacts []Artifact, chunk Artifact)
+}
+
+// withArtifacts returns ctx with the task's artifact set, creating one
+// unless the caller already attached it
+func withArtifacts(ctx context.Context) (context.Context, *artifactSet) {
+	if artifacts, ok := ctx.Value(artifactsKey{}).(*artifactSet); ok {
+		return ctx, artifacts
+	}
+	artifacts := &artifactSet{}
+	return context.WithValue(ctx, artifactsKey{}, artifacts), artifacts
+}
+
+func (s *artifactSet) add(artifact Artifact) {
+	s.mu.Lock()
+	i := -1
+	if artifact.Append {
+		i = slices.IndexFunc(s.artifacts, func(existing Artifact) bool {
+			if artifact.ArtifactID != "" {
+				return existing.ArtifactID == artifact.ArtifactID
+			}
+			return existing.Index == artifact.Index
+		})
+	}
+	chunk := artifact
+	if i >= 0 {
+		existing := &s.artifacts[i]
+		chunk.ArtifactID, chunk.Index = existing.ArtifactID, existing.Index
+		existing.Parts = append(slices.Clip(existing.Parts), artifact.Parts...)
+		existing.LastChunk = artifact.LastChunk
+		if artifact.Metadata != nil {
+			existing.Metadata = artifact.Metadata
+		}
+	} else {
+		if artifact.ArtifactID == "" {
+			artifact.ArtifactID = generateID()
+		}
+		if !artifact.Append && artifact.Index == 0 {
+			artifact.Index = len(s.artifacts)
+		}
+		chunk.ArtifactID, chunk.Index, chunk.Append = artifact.ArtifactID, artifact.Index, false
+		artifact.Append = false
+		s.artifacts = append(s.artifacts, artifact)
+	}
+	snapshot := s.snapshot()
+	s.mu.Unlock()
+
+	if s.onChange != nil {
+		s.onChange(snapshot, chunk)
+	}
+}
+
+// list returns the artifacts added so far
+func (s *artifactSet) list() []Artifact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+func (s *artifactSet) snapshot() []Artifact {
+	if len(s.artifacts) == 0 {
+		return nil
+	}
+	// add never appends to a slice a snapshot shares, so a shallow copy is
+	// enough
+	return slices.Clone(s.artifacts)
+}
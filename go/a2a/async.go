@@ -0,0 +1,230 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// errNoHandler is reported when a task arrives before a handler is registered
+var errNoHandler = errors.New("no handler registered")
+
+// TaskQuery identifies a task whose state is requested with a2a/task/get
+type TaskQuery struct {
+	TaskID string `json:"taskId"`
+}
+
+// submitTask queues a task for asynchronous execution and returns immediately
+func (s *A2AServer) submitTask(ctx context.Context, agentID string, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	taskParams, rpcErr := s.decodeTask(agentID, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	result, rpcErr := s.enqueueTask(ctx, taskParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return s.marshalResult(result)
+}
+
+// enqueueTask queues a task, returning its submitted state, or the cached
+// result of an earlier one with the same idempotency key
+func (s *A2AServer) enqueueTask(ctx context.Context, taskParams TaskParams) (*TaskResult, *JSONRPCError) {
+	if taskParams.TaskID == "" {
+		taskParams.TaskID = generateID()
+	}
+	ctx, resumed, rpcErr := s.admit(ctx, &taskParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	cached, release, rpcErr := s.claimResult(ctx, taskParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if cached != nil {
+		return cached, nil
+	}
+	defer release()
+
+	s.startWorkers.Do(func() {
+		workers := s.limits.Workers
+		if workers <= 0 {
+			workers = defaultWorkers
+		}
+		s.workers.Add(workers)
+		for i := 0; i < workers; i++ {
+			go s.worker()
+		}
+	})
+
+	if rpcErr := s.checkModes(taskParams); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if rpcErr := s.checkHops(taskParams); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if taskParams.ReplyTo != nil {
+		if rpcErr := s.checkPushConfig(*taskParams.ReplyTo); rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+	if s.limits.MaxQueuedTasks > 0 && s.queue.len() >= s.limits.MaxQueuedTasks {
+		return nil, &JSONRPCError{Code: CodeQueueFull, Message: "Task queue full"}
+	}
+	if !s.acquireTask() {
+		return nil, &JSONRPCError{Code: CodeServerUnavailable, Message: "Server draining"}
+	}
+
+	result := &TaskResult{TaskID: taskParams.TaskID, Status: TaskStateSubmitted, CorrelationID: taskParams.CorrelationID, Metadata: taskParams.Metadata, Owner: owner(ctx, taskParams)}
+	if resumed {
+		// A task with new input goes back to work, never back to submitted
+		result.Status = TaskStateWorking
+	}
+	if err := s.saveTask(result); err != nil {
+		s.releaseTask()
+		var transition *InvalidTransitionError
+		if errors.As(err, &transition) {
+			return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "Task ID already in use", Data: rpcData(TaskErrorData{TaskID: taskParams.TaskID})}
+		}
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: "Internal error"}
+	}
+	if taskParams.pushConfig != nil {
+		s.setPushConfig(taskParams.TaskID, *taskParams.pushConfig)
+	}
+	if taskParams.ReplyTo != nil {
+		s.setPushConfig(taskParams.TaskID, *taskParams.ReplyTo)
+	}
+	s.rememberResult(taskParams, result)
+	if !s.queue.push(&queuedTask{params: taskParams}) {
+		s.releaseTask()
+		return nil, &JSONRPCError{Code: CodeServerUnavailable, Message: "Server shutting down"}
+	}
+	s.taskReceived(ctx, taskParams)
+
+	return result, nil
+}
+
+// getTask returns the current state of an asynchronous task
+func (s *A2AServer) getTask(ctx context.Context, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	query, rpcErr := decodeParams[TaskQuery](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	result, rpcErr := s.ownedTask(ctx, query.TaskID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return s.marshalResult(result)
+}
+
+// getSpecTask handles tasks/get, the spec's a2a/task/get
+func (s *A2AServer) getSpecTask(ctx context.Context, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	query, rpcErr := decodeParams[TaskIDParams](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	result, rpcErr := s.ownedTask(ctx, query.ID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return s.marshalResult(result.Task(nil))
+}
+
+// lookupTask returns the stored state of a task
+func (s *A2AServer) lookupTask(taskID string) (*TaskResult, *JSONRPCError) {
+	result, err := s.store.Get(taskID)
+	if errors.Is(err, ErrTaskNotFound) {
+		return nil, taskNotFound(taskID)
+	}
+	if err != nil {
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: "Internal error"}
+	}
+	return result, nil
+}
+
+// ownedTask returns the stored state of a task for the request ctx serves,
+// refusing requests not from its owner
+func (s *A2AServer) ownedTask(ctx context.Context, taskID string) (*TaskResult, *JSONRPCError) {
+	result, rpcErr := s.lookupTask(taskID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if rpcErr := s.checkOwner(ctx, result); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result, nil
+}
+
+func (s *A2AServer) worker() {
+	defer s.workers.Done()
+	for {
+		task, ok := s.queue.pop()
+		if !ok {
+			return
+		}
+		s.runQueued(task)
+	}
+}
+
+// runQueued executes one attempt of a queued task and either records the
+// outcome or schedules a retry
+func (s *A2AServer) runQueued(task *queuedTask) {
+	task.attempt++
+	params := task.params
+
+	ctx := ContextWithCorrelationID(context.Background(), params.CorrelationID)
+	working := &TaskResult{TaskID: params.TaskID, Status: TaskStateWorking, Attempts: task.attempt, CorrelationID: params.CorrelationID, Metadata: params.Metadata}
+	if err := s.saveTask(working); err != nil {
+		if s.isCanceled(params.TaskID) {
+			// Canceled while queued or waiting for a retry
+			s.finishQueued(ctx, params, nil)
+			return
+		}
+		s.logger.Error("saving task state", "taskId", params.TaskID, "correlationId", params.CorrelationID, "error", err)
+	}
+
+	// Publish artifacts as the handler adds them, so pollers can consume
+	// partial results
+	ctx, artifacts := withArtifacts(ctx)
+	artifacts.onChange = func(added []Artifact, _ Artifact) {
+		progress := *working
+		progress.Artifacts = added
+		if err := s.saveTask(&progress); err != nil && !s.isCanceled(params.TaskID) {
+			s.logger.Error("saving task state", "taskId", params.TaskID, "correlationId", params.CorrelationID, "error", err)
+		}
+	}
+	result, err := s.execute(ctx, params)
+	result.Attempts = task.attempt
+
+	policy := s.retryPolicy(params.Action)
+	if err != nil && IsRetryable(err) && task.attempt < policy.MaxAttempts && !s.isCanceled(params.TaskID) {
+		time.AfterFunc(policy.backoff(task.attempt), func() {
+			if !s.queue.push(task) {
+				// Shut down before the retry, so this attempt was the last
+				s.finishQueued(ctx, params, result)
+			}
+		})
+		return
+	}
+	s.finishQueued(ctx, params, result)
+}
+
+// finishQueued records the final result of a queued task and calls the
+// completed or failed hooks, or, if the task was canceled meanwhile, drops
+// result, which may then be nil, and calls the canceled hooks
+func (s *A2AServer) finishQueued(ctx context.Context, params TaskParams, result *TaskResult) {
+	defer s.releaseTask()
+	if canceled, ok := s.canceledTask(params.TaskID); ok {
+		s.taskCanceled(ctx, params, canceled)
+		return
+	}
+
+	if err := s.saveTask(result); err != nil {
+		s.logger.Error("saving task state", "taskId", params.TaskID, "correlationId", params.CorrelationID, "error", err)
+	}
+	if result.Status == TaskStateCompleted {
+		s.rememberResult(params, result)
+	}
+	s.taskFinished(ctx, params, result)
+}
@@ -0,0 +1,258 @@
+package a2a
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultAuditQueueSize is how many records a WebhookAuditSink queues
+// unless told otherwise
+const defaultAuditQueueSize = 1000
+
+// AuditRecord describes one accepted task for compliance review
+type AuditRecord struct {
+	TaskID     string        `json:"taskId"`
+	AgentID    string        `json:"agentId,omitempty"`
+	Sender     string        `json:"sender"`
+	Action     string        `json:"action"`
+	InputHash  string        `json:"inputHash"` // hex SHA-256 of the input as canonical JSON
+	Status     TaskState     `json:"status"`
+	ReceivedAt time.Time     `json:"receivedAt"`
+	Duration   time.Duration `json:"durationNs"`
+	Attempts   int           `json:"attempts,omitempty"`
+	// ParentTaskID, RootTaskID and Hop place the task in its tree of
+	// sub-tasks, as on TaskParams
+	ParentTaskID string `json:"parentTaskId,omitempty"`
+	RootTaskID   string `json:"rootTaskId,omitempty"`
+	Hop          int    `json:"hop,omitempty"`
+}
+
+// AuditSink stores audit records
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+}
+
+// WithAuditSink records every accepted task to sink once it completes,
+// fails or is canceled. Sink errors are logged and do not affect the task.
+// A sink that is an io.Closer is closed once the server has shut down.
+func WithAuditSink(sink AuditSink) ServerOption {
+	return func(s *A2AServer) {
+		var received sync.Map // task ID -> time.Time
+
+		s.OnTaskReceived(func(ctx context.Context, task TaskParams, _ *TaskResult) {
+			// A resumed task is received again, but was first received
+			// when it was sent
+			received.LoadOrStore(task.TaskID, time.Now())
+		})
+
+		record := func(ctx context.Context, task TaskParams, result *TaskResult) {
+			rec := AuditRecord{
+				TaskID:       task.TaskID,
+				AgentID:      task.AgentID,
+				Sender:       task.Sender,
+				Action:       task.Action,
+				InputHash:    hashInput(task.Input),
+				Status:       result.Status,
+				Attempts:     result.Attempts,
+				ParentTaskID: task.ParentTaskID,
+				RootTaskID:   task.RootTaskID,
+				Hop:          task.Hop,
+			}
+			if at, ok := received.LoadAndDelete(task.TaskID); ok {
+				rec.ReceivedAt = at.(time.Time)
+				rec.Duration = time.Since(rec.ReceivedAt)
+			}
+			if err := sink.Record(ctx, rec); err != nil {
+				s.logger.Error("recording audit entry", "taskId", task.TaskID, "correlationId", task.CorrelationID, "error", err)
+			}
+		}
+		s.OnTaskCompleted(record)
+		s.OnTaskFailed(record)
+		s.OnTaskCanceled(record)
+		if closer, ok := sink.(io.Closer); ok {
+			s.OnShutdown(func() {
+				if err := closer.Close(); err != nil {
+					s.logger.Error("closing audit sink", "error", err)
+				}
+			})
+		}
+	}
+}
+
+func hashInput(input map[string]interface{}) string {
+	data, _ := CanonicalJSON{}.Marshal(input)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// JSONAuditSink writes audit records as JSON lines, e.g. to a log file
+type JSONAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONAuditSink creates a sink appending one JSON object per line to w
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{w: w}
+}
+
+// Record writes record as a JSON line
+func (j *JSONAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return json.NewEncoder(j.w).Encode(record)
+}
+
+// WebhookAuditSink POSTs each audit record as JSON to a URL. Records are
+// queued and posted one at a time in the background, so a slow webhook
+// does not hold up the tasks being recorded.
+type WebhookAuditSink struct {
+	URL    string
+	Client *http.Client // nil means a client with a 10 second timeout
+	// QueueSize is how many records may wait to be posted, beyond which
+	// Record drops them; 0 means 1000
+	QueueSize int
+	// Logger receives posting errors; nil means slog.Default()
+	Logger *slog.Logger
+
+	start   sync.Once
+	mu      sync.RWMutex // held to write to records, and exclusively to close it
+	closed  bool
+	records chan AuditRecord
+	done    chan struct{} // closed once the queued records have been posted
+}
+
+// NewWebhookAuditSink creates a sink posting records to url
+func NewWebhookAuditSink(url string) *WebhookAuditSink {
+	return &WebhookAuditSink{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Record queues record to be posted to the webhook URL, failing if the
+// queue is full or the sink closed
+func (wh *WebhookAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	wh.start.Do(wh.run)
+	wh.mu.RLock()
+	defer wh.mu.RUnlock()
+	if wh.closed {
+		return errors.New("audit webhook: sink closed")
+	}
+	select {
+	case wh.records <- record:
+		return nil
+	default:
+		return errors.New("audit webhook: queue full, record dropped")
+	}
+}
+
+// Close stops accepting records and waits for those queued to be posted
+func (wh *WebhookAuditSink) Close() error {
+	wh.start.Do(wh.run)
+	wh.mu.Lock()
+	if !wh.closed {
+		wh.closed = true
+		close(wh.records)
+	}
+	wh.mu.Unlock()
+	<-wh.done
+	return nil
+}
+
+// run starts posting queued records
+func (wh *WebhookAuditSink) run() {
+	wh.records = make(chan AuditRecord, cmp.Or(wh.QueueSize, defaultAuditQueueSize))
+	wh.done = make(chan struct{})
+	go func() {
+		defer close(wh.done)
+		for record := range wh.records {
+			// Records outlive the requests of the tasks they describe
+			if err := wh.post(context.Background(), record); err != nil {
+				logger := wh.Logger
+				if logger == nil {
+					logger = slog.Default()
+				}
+				logger.Error("posting audit record", "taskId", record.TaskID, "url", wh.URL, "error", err)
+			}
+		}
+	}()
+}
+
+// post sends record to the webhook URL
+func (wh *WebhookAuditSink) post(ctx context.Context, record AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := wh.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SQLAuditSink inserts audit records into a database table with columns
+// task_id, agent_id, sender, action, input_hash, status, received_at,
+// duration_ms and attempts
+type SQLAuditSink struct {
+	db     *sql.DB
+	insert string
+}
+
+// NewSQLAuditSink creates a sink inserting into table. Placeholders use the
+// "?" style; pass a query built for your driver to NewSQLAuditSinkWithQuery
+// if it needs another style.
+func NewSQLAuditSink(db *sql.DB, table string) *SQLAuditSink {
+	return NewSQLAuditSinkWithQuery(db, fmt.Sprintf(
+		"INSERT INTO %s (task_id, agent_id, sender, action, input_hash, status, received_at, duration_ms, attempts) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		table,
+	))
+}
+
+// NewSQLAuditSinkWithQuery creates a sink executing insert with the nine
+// record columns as arguments, in the order listed on SQLAuditSink
+func NewSQLAuditSinkWithQuery(db *sql.DB, insert string) *SQLAuditSink {
+	return &SQLAuditSink{db: db, insert: insert}
+}
+
+// Record inserts record into the database
+func (q *SQLAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	_, err := q.db.ExecContext(ctx, q.insert,
+		record.TaskID,
+		record.AgentID,
+		record.Sender,
+		record.Action,
+		record.InputHash,
+		record.Status,
+		record.ReceivedAt,
+		record.Duration.Milliseconds(),
+		record.Attempts,
+	)
+	return err
+}
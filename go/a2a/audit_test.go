@@ -0,0 +1,141 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink keeps the records it is sent
+type recordingSink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (r *recordingSink) Record(ctx context.Context, record AuditRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *recordingSink) recorded() []AuditRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]AuditRecord(nil), r.records...)
+}
+
+// waitFor polls until ok reports true, failing the test after a few seconds
+func waitFor(t *testing.T, what string, ok func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !ok(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAuditCanceledTasks(t *testing.T) {
+	sink := &recordingSink{}
+	server, ts := serveTest(t, WithAuditSink(sink))
+	running := make(chan struct{}, 1)
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		if action == "ask" {
+			return nil, RequireInput("What is the answer?")
+		}
+		running <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+	cancel := func(taskID string) {
+		t.Helper()
+		if _, err := agent.CancelTask("server", taskID, ""); err != nil {
+			t.Fatalf("canceling %s: %v", taskID, err)
+		}
+	}
+
+	// Canceled while running
+	submitted, err := agent.SubmitTask("server", "wait", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-running
+	cancel(submitted.TaskID)
+	waitFor(t, "the running task's record", func() bool { return len(sink.recorded()) == 1 })
+
+	// Canceled while waiting for input, sent asynchronously and synchronously
+	submitted, err = agent.SubmitTask("server", "ask", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the task to pause", func() bool { return server.isPaused(submitted.TaskID) })
+	cancel(submitted.TaskID)
+	paused, err := agent.SendTask("server", "ask", nil, "")
+	if err != nil || paused.Status != TaskStateInputRequired {
+		t.Fatalf("task: %v, %v; want it waiting for input", paused, err)
+	}
+	cancel(paused.TaskID)
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	records := sink.recorded()
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want one per task: %+v", len(records), records)
+	}
+	for _, record := range records {
+		if record.Status != TaskStateCanceled || record.ReceivedAt.IsZero() {
+			t.Errorf("record %+v, want a canceled task with when it was received", record)
+		}
+	}
+}
+
+func TestWebhookAuditSinkQueues(t *testing.T) {
+	received := make(chan AuditRecord)
+	release := make(chan struct{})
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record AuditRecord
+		json.NewDecoder(r.Body).Decode(&record)
+		received <- record
+		<-release
+	}))
+	defer webhook.Close()
+	sink := NewWebhookAuditSink(webhook.URL)
+	sink.QueueSize = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := sink.Record(ctx, AuditRecord{TaskID: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if record := <-received; record.TaskID != "first" {
+		t.Fatalf("webhook received %+v", record)
+	}
+	// The webhook is still busy with the first, and the task's request over
+	cancel()
+	if err := sink.Record(ctx, AuditRecord{TaskID: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Record(ctx, AuditRecord{TaskID: "third"}); err == nil {
+		t.Error("recorded beyond the queue size")
+	}
+
+	close(release)
+	go func() {
+		if record := <-received; record.TaskID != "second" {
+			t.Errorf("webhook received %+v, want the second record", record)
+		}
+	}()
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Record(context.Background(), AuditRecord{TaskID: "fourth"}); err == nil {
+		t.Error("recorded after closing")
+	}
+}
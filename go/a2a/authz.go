@@ -0,0 +1,195 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"path"
+)
+
+// A server created WithAuthorizer asks it whether each task may run before
+// running or queueing it, with the task's sender as the principal. Policy
+// is an Authorizer allowing principals the actions listed for them and
+// refusing all others. Senders are authenticated only on servers created
+// WithJWSVerification or WithJWTVerification; on others they are whoever
+// tasks claim to be from, so authorizers should check an identity the
+// transport verified instead, such as a SPIFFE ID.
+//
+// A task sent by an authenticated sender is owned by it: only requests
+// authenticated as the same sender may read the task, cancel it, resume
+// it or set its push notification config, and only while the Authorizer
+// still allows the sender the task's action. Tasks from unauthenticated
+// senders have no owner.
+
+// AuthorizationRequest describes a task an Authorizer decides on
+type AuthorizationRequest struct {
+	Principal string                 // the task's sender; empty if it names none
+	AgentID   string                 // the agent the task is for, on servers hosting several
+	Action    string                 // the action the task calls
+	Input     map[string]interface{} // the task's input, merged with its message; still a JWE if encrypted
+	Metadata  map[string]interface{}
+	TaskID    string
+}
+
+// Authorizer decides whether tasks may run. An error refuses the task,
+// with the error's message as the reason.
+type Authorizer interface {
+	Authorize(ctx context.Context, req AuthorizationRequest) error
+}
+
+// AuthorizerFunc adapts a function to an Authorizer
+type AuthorizerFunc func(ctx context.Context, req AuthorizationRequest) error
+
+// Authorize calls f
+func (f AuthorizerFunc) Authorize(ctx context.Context, req AuthorizationRequest) error {
+	return f(ctx, req)
+}
+
+// Policy is an Authorizer allowing each principal the actions listed for
+// it and refusing any other. Actions are path.Match patterns, so "*"
+// allows every action and "read-*" those starting with "read-".
+//
+//	a2a.Policy{Allow: map[string][]string{
+//	    "planner": {"summarize", "search"},
+//	    "*":       {"status"},
+//	}}
+type Policy struct {
+	// Allow lists the actions each principal may call. The "*" principal
+	// applies to every principal but the empty one, which tasks naming no
+	// sender have.
+	Allow map[string][]string
+	// Deny lists actions principals may not call even where Allow allows
+	// them, e.g. "*": {"delete-*"}
+	Deny map[string][]string
+}
+
+// Authorize refuses req unless Allow allows its action and Deny does not
+// refuse it
+func (p Policy) Authorize(_ context.Context, req AuthorizationRequest) error {
+	if rule, ok := p.match(p.Deny, req); ok {
+		return fmt.Errorf("%s may not call %q: denied by %q", principalName(req.Principal), req.Action, rule)
+	}
+	if _, ok := p.match(p.Allow, req); !ok {
+		return fmt.Errorf("%s may not call %q", principalName(req.Principal), req.Action)
+	}
+	return nil
+}
+
+// match returns the pattern of rules matching req's action, if any
+func (p Policy) match(rules map[string][]string, req AuthorizationRequest) (string, bool) {
+	principals := []string{req.Principal}
+	if req.Principal != "" {
+		principals = append(principals, "*")
+	}
+	for _, principal := range principals {
+		for _, pattern := range rules[principal] {
+			if ok, _ := path.Match(pattern, req.Action); ok {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// principalName names a principal in errors
+func principalName(principal string) string {
+	if principal == "" {
+		return "anonymous sender"
+	}
+	return principal
+}
+
+// WithAuthorizer refuses tasks authorizer does not allow with
+// CodePermissionDenied, before they run or are queued
+func WithAuthorizer(authorizer Authorizer) ServerOption {
+	return func(s *A2AServer) {
+		s.authorizer = authorizer
+	}
+}
+
+// TaskOwner is the authenticated sender of a stored task, and what it sent
+// the task for
+type TaskOwner struct {
+	Principal string `json:"principal"`
+	AgentID   string `json:"agentId,omitempty"`
+	Action    string `json:"action"`
+}
+
+type principalKey struct{}
+
+// withPrincipal returns ctx naming the sender a request was authenticated
+// as, by its JWS or JWT
+func withPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// principal returns the sender the request ctx serves was authenticated
+// as, if any
+func principal(ctx context.Context) (string, bool) {
+	p, ok := ctx.Value(principalKey{}).(string)
+	return p, ok
+}
+
+// owner returns the owner of task sent by the request ctx serves, or nil if
+// its sender is unauthenticated
+func owner(ctx context.Context, task TaskParams) *TaskOwner {
+	p, ok := principal(ctx)
+	if !ok {
+		return nil
+	}
+	return &TaskOwner{Principal: p, AgentID: task.AgentID, Action: task.Action}
+}
+
+// checkOwner refuses the request ctx serves access to a stored task unless
+// it was authenticated as the task's owner and the Authorizer still allows
+// it the task's action. Tasks without an owner are not checked.
+func (s *A2AServer) checkOwner(ctx context.Context, result *TaskResult) *JSONRPCError {
+	if result.Owner == nil {
+		return nil
+	}
+	caller, ok := principal(ctx)
+	if !ok {
+		return unauthenticated("task was sent by an authenticated sender")
+	}
+	if caller != result.Owner.Principal {
+		s.logger.Warn("refusing access to another sender's task", "taskId", result.TaskID, "caller", caller, "correlationId", result.CorrelationID)
+		return notSender(caller, *result.Owner)
+	}
+	return s.authorize(ctx, TaskParams{TaskID: result.TaskID, AgentID: result.Owner.AgentID, Action: result.Owner.Action, Sender: caller, CorrelationID: result.CorrelationID})
+}
+
+// notSender refuses principal a task owner sent
+func notSender(principal string, owner TaskOwner) *JSONRPCError {
+	return &JSONRPCError{Code: CodePermissionDenied, Message: "Permission denied", Data: rpcData(AuthorizationErrorData{
+		Principal: principal,
+		AgentID:   owner.AgentID,
+		Action:    owner.Action,
+		Reason:    "task was sent by another sender",
+	})}
+}
+
+// authorize asks the server's Authorizer whether task may run
+func (s *A2AServer) authorize(ctx context.Context, task TaskParams) *JSONRPCError {
+	if s.authorizer == nil {
+		return nil
+	}
+	_, input := withMessage(ctx, task)
+	req := AuthorizationRequest{
+		Principal: task.Sender,
+		AgentID:   task.AgentID,
+		Action:    task.Action,
+		Input:     input,
+		Metadata:  task.Metadata,
+		TaskID:    task.TaskID,
+	}
+	err := s.authorizer.Authorize(ctx, req)
+	if err == nil {
+		return nil
+	}
+	s.logger.Warn("refusing unauthorized task", "taskId", task.TaskID, "action", task.Action, "sender", task.Sender, "correlationId", task.CorrelationID, "error", err)
+	return &JSONRPCError{Code: CodePermissionDenied, Message: "Permission denied", Data: rpcData(AuthorizationErrorData{
+		Principal: task.Sender,
+		AgentID:   task.AgentID,
+		Action:    task.Action,
+		Reason:    err.Error(),
+	})}
+}
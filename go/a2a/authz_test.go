@@ -0,0 +1,103 @@
+package a2a
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOwnTasksOnly(t *testing.T) {
+	_, aliceKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, bobKey, _ := ed25519.GenerateKey(rand.Reader)
+	aliceKeys := testKeys(t, "alice", map[string]crypto.Signer{"k1": aliceKey})
+	bobKeys := testKeys(t, "bob", map[string]crypto.Signer{"k1": bobKey})
+	keys := func(ctx context.Context, agentID string) (*JWKSet, error) {
+		if agentID == "bob" {
+			return bobKeys(ctx, agentID)
+		}
+		return aliceKeys(ctx, agentID)
+	}
+	var revoked atomic.Bool
+	server, ts := serveTest(t, WithREST(), WithPushNotifications(PushNotifications{}), WithJWTVerification(JWTVerification{Keys: keys}),
+		WithAuthorizer(AuthorizerFunc(func(ctx context.Context, req AuthorizationRequest) error {
+			if revoked.Load() {
+				return errors.New("revoked")
+			}
+			return nil
+		})))
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	alice := NewAgent("alice", "Alice", nil)
+	alice.Directory = testDirectory{ts.URL}
+	alice.JWT = &JWTSigner{Key: aliceKey, KeyID: "k1"}
+	bob := NewAgent("bob", "Bob", nil)
+	bob.Directory = testDirectory{ts.URL}
+	bob.JWT = &JWTSigner{Key: bobKey, KeyID: "k1"}
+	// anonymous claims to be alice, but has no token
+	anonymous := NewAgent("alice", "Alice", nil)
+	anonymous.Directory = testDirectory{ts.URL}
+
+	task, err := alice.SubmitTask("server", "wait", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := PushNotificationConfig{URL: "https://alice.example/hook"}
+	refused := func(name string, err error, code int) {
+		t.Helper()
+		var rpcErr *JSONRPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != code {
+			t.Errorf("%s: %v, want code %d", name, err, code)
+		}
+	}
+
+	for name, agent := range map[string]*A2AAgent{"another sender": bob, "an unauthenticated caller": anonymous} {
+		code := CodePermissionDenied
+		if agent == anonymous {
+			code = CodeUnauthenticated
+		}
+		_, err := agent.GetTask("server", task.TaskID, "")
+		refused("get by "+name, err, code)
+		_, err = agent.CancelTask("server", task.TaskID, "")
+		refused("cancel by "+name, err, code)
+		_, err = agent.SetTaskPushNotification("server", task.TaskID, config, "")
+		refused("push config by "+name, err, code)
+		_, err = agent.GetTaskPushNotification("server", task.TaskID, "")
+		refused("get push config by "+name, err, code)
+	}
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+TasksPath+"/"+task.TaskID, nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("REST cancel without a token: %v, %v; want 401", resp, err)
+	}
+
+	// message/send for another sender's task ID sets no push config
+	blocking := false
+	_, err = bob.doRequest(context.Background(), ts.URL, "message/send", MessageSendParams{
+		Message:       Message{Role: RoleUser, TaskID: task.TaskID, Parts: []Part{TextPart{Text: "hi"}}},
+		Configuration: &MessageSendConfiguration{Blocking: &blocking, PushNotificationConfig: &PushNotificationConfig{URL: "https://bob.example/hook"}},
+	})
+	refused("message/send for another sender's task", err, CodePermissionDenied)
+	if _, err := alice.GetTaskPushNotification("server", task.TaskID, ""); err == nil {
+		t.Error("push config set by another sender's message")
+	}
+
+	if _, err := alice.GetTask("server", task.TaskID, ""); err != nil {
+		t.Errorf("get by the sender: %v", err)
+	}
+	if _, err := alice.SetTaskPushNotification("server", task.TaskID, config, ""); err != nil {
+		t.Errorf("push config by the sender: %v", err)
+	}
+	revoked.Store(true)
+	_, err = alice.CancelTask("server", task.TaskID, "")
+	refused("cancel the Authorizer refuses", err, CodePermissionDenied)
+	revoked.Store(false)
+	if result, err := alice.CancelTask("server", task.TaskID, ""); err != nil || result.Status != TaskStateCanceled {
+		t.Errorf("cancel by the sender: %v, %v", result, err)
+	}
+}
@@ -0,0 +1,121 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// runningTasks holds how to cancel each task whose handler is running
+type runningTasks struct {
+	mu    sync.Mutex
+	tasks map[string]context.CancelFunc
+}
+
+// track returns a copy of ctx that cancelTask cancels, and a func to call
+// once the task's handler has returned
+func (s *A2AServer) track(ctx context.Context, taskID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.running.mu.Lock()
+	defer s.running.mu.Unlock()
+	if s.running.tasks == nil {
+		s.running.tasks = make(map[string]context.CancelFunc)
+	}
+	s.running.tasks[taskID] = cancel
+	return ctx, func() {
+		s.running.mu.Lock()
+		delete(s.running.tasks, taskID)
+		s.running.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelTask moves a stored task to canceled: a queued task will not run, a
+// running one has its context cancelled and a paused one can no longer be
+// resumed. Tasks run synchronously are cancelled by abandoning the request
+// instead.
+func (s *A2AServer) cancelTask(taskID string) (*TaskResult, *JSONRPCError) {
+	current, rpcErr := s.lookupTask(taskID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	canceled := *current
+	canceled.Status = TaskStateCanceled
+	canceled.StatusMessage = nil
+	canceled.UpdatedAt = time.Now().UTC()
+	if err := s.saveTask(&canceled); err != nil {
+		var transition *InvalidTransitionError
+		if errors.As(err, &transition) {
+			return nil, &JSONRPCError{Code: CodeTaskNotCancelable, Message: "Task cannot be canceled", Data: rpcData(TaskErrorData{TaskID: taskID})}
+		}
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: "Internal error"}
+	}
+
+	s.running.mu.Lock()
+	cancel, running := s.running.tasks[taskID]
+	s.running.mu.Unlock()
+	if running {
+		cancel()
+	}
+	if paused, ok := s.unpause(taskID); ok && current.Status == TaskStateInputRequired {
+		// Nothing is running the task to see it was canceled
+		ctx := ContextWithCorrelationID(context.Background(), canceled.CorrelationID)
+		s.taskCanceled(ctx, paused.params, &canceled)
+	}
+	s.logger.Debug("task canceled", "taskId", taskID, "correlationId", canceled.CorrelationID, "running", running)
+	return &canceled, nil
+}
+
+// isCanceled reports whether a stored task has been canceled
+func (s *A2AServer) isCanceled(taskID string) bool {
+	_, canceled := s.canceledTask(taskID)
+	return canceled
+}
+
+// canceledTask returns the stored state of a task if it has been canceled
+func (s *A2AServer) canceledTask(taskID string) (*TaskResult, bool) {
+	current, err := s.store.Get(taskID)
+	if err != nil || current.Status != TaskStateCanceled {
+		return nil, false
+	}
+	return current, true
+}
+
+// cancelSpecTask handles tasks/cancel
+func (s *A2AServer) cancelSpecTask(ctx context.Context, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	query, rpcErr := decodeParams[TaskIDParams](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if _, rpcErr := s.ownedTask(ctx, query.ID); rpcErr != nil {
+		return nil, rpcErr
+	}
+	result, rpcErr := s.cancelTask(query.ID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return s.marshalResult(result.Task(nil))
+}
+
+// CancelTask asks another agent to cancel an asynchronous task, returning
+// its canceled state. Tasks that already finished fail with
+// CodeTaskNotCancelable.
+func (a *A2AAgent) CancelTask(targetAgentID, taskID, directoryURL string) (*TaskResult, error) {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.doRequest(context.Background(), agentInfo.Endpoint, "tasks/cancel", TaskIDParams{ID: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("task cancel failed: %w", err)
+	}
+	var task Task
+	if err := a.codec().Unmarshal(result, &task); err != nil {
+		return nil, err
+	}
+	return task.Result(), nil
+}
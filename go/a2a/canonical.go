@@ -0,0 +1,489 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TimeFormat is how CanonicalJSON writes timestamps
+type TimeFormat int
+
+const (
+	// TimeRFC3339 writes RFC 3339 strings in UTC, with only the fractional
+	// seconds needed
+	TimeRFC3339 TimeFormat = iota
+	// TimeUnix writes whole seconds since the Unix epoch
+	TimeUnix
+	// TimeUnixMilli writes milliseconds since the Unix epoch
+	TimeUnixMilli
+)
+
+// EmptyFields is which empty values CanonicalJSON leaves out
+type EmptyFields int
+
+const (
+	// EmptyTagged leaves out fields tagged omitempty or omitzero when they
+	// are empty, as encoding/json does
+	EmptyTagged EmptyFields = iota
+	// EmptyKeep writes every field, empty or not
+	EmptyKeep
+	// EmptyOmit leaves out every empty field and object member: null,
+	// false, 0, "", empty arrays and objects, and zero times
+	EmptyOmit
+)
+
+// CanonicalJSON encodes values the same way on every SDK, for payloads that
+// are signed or hashed. Output follows RFC 8785: no insignificant
+// whitespace, object members sorted by their UTF-16 code units, numbers
+// written as JavaScript does and strings escaped minimally. Timestamps and
+// empty fields, which SDKs disagree on, are written as configured; the zero
+// value writes RFC 3339 times and honors omitempty tags.
+type CanonicalJSON struct {
+	Times TimeFormat
+	Empty EmptyFields
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	numberType        = reflect.TypeFor[json.Number]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Marshal encodes v canonically. It reads struct tags as encoding/json
+// does, and values with their own MarshalJSON are re-encoded canonically.
+func (c CanonicalJSON) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.encode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Canonicalize re-encodes a JSON document canonically, so a receiver can
+// check a signature or hash over bytes another SDK produced. Timestamps
+// are left as they are, since they cannot be told from other values.
+func (c CanonicalJSON) Canonicalize(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("canonical JSON: data after top-level value")
+	}
+	return c.Marshal(v)
+}
+
+func (c CanonicalJSON) encode(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		return c.encodeTime(buf, v.Interface().(time.Time))
+	case numberType:
+		return writeCanonicalNumber(buf, v.String())
+	case rawMessageType:
+		if v.Len() == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return c.encodeRaw(buf, v.Bytes())
+	}
+
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		buf.WriteString("null")
+		return nil
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(marshalerType) {
+		v = v.Addr()
+	}
+	if v.Type().Implements(marshalerType) {
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		return c.encodeRaw(buf, data)
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		writeCanonicalString(buf, string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return writeCanonicalFloat(buf, v.Float())
+	case reflect.String:
+		writeCanonicalString(buf, v.String())
+	case reflect.Pointer, reflect.Interface:
+		return c.encode(buf, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			writeCanonicalString(buf, base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		return c.encodeArray(buf, v)
+	case reflect.Array:
+		return c.encodeArray(buf, v)
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return c.encodeMap(buf, v)
+	case reflect.Struct:
+		return c.encodeStruct(buf, v)
+	default:
+		return &json.UnsupportedTypeError{Type: v.Type()}
+	}
+	return nil
+}
+
+func (c CanonicalJSON) encodeTime(buf *bytes.Buffer, t time.Time) error {
+	switch c.Times {
+	case TimeUnix:
+		buf.WriteString(strconv.FormatInt(t.Unix(), 10))
+	case TimeUnixMilli:
+		buf.WriteString(strconv.FormatInt(t.UnixMilli(), 10))
+	default:
+		writeCanonicalString(buf, t.UTC().Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+// encodeRaw re-encodes JSON produced elsewhere
+func (c CanonicalJSON) encodeRaw(buf *bytes.Buffer, data []byte) error {
+	canonical, err := c.Canonicalize(data)
+	if err != nil {
+		return err
+	}
+	buf.Write(canonical)
+	return nil
+}
+
+func (c CanonicalJSON) encodeArray(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := c.encode(buf, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+// canonicalMember is an object member waiting to be sorted
+type canonicalMember struct {
+	name  string
+	value reflect.Value
+	quote bool // the field is tagged ",string"
+}
+
+func (c CanonicalJSON) encodeMap(buf *bytes.Buffer, v reflect.Value) error {
+	var members []canonicalMember
+	iter := v.MapRange()
+	for iter.Next() {
+		name, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		if c.Empty == EmptyOmit && isEmptyValue(iter.Value()) {
+			continue
+		}
+		members = append(members, canonicalMember{name: name, value: iter.Value()})
+	}
+	return c.encodeObject(buf, members)
+}
+
+// mapKey is the member name of a map key, as encoding/json writes it
+func mapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", &json.UnsupportedTypeError{Type: key.Type()}
+}
+
+func (c CanonicalJSON) encodeStruct(buf *bytes.Buffer, v reflect.Value) error {
+	var members []canonicalMember
+	for _, field := range canonicalFields(v.Type()) {
+		value, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			continue // in a nil embedded pointer
+		}
+		if c.omit(field, value) {
+			continue
+		}
+		members = append(members, canonicalMember{name: field.name, value: value, quote: field.quote})
+	}
+	return c.encodeObject(buf, members)
+}
+
+// omit reports whether a struct field is left out under c.Empty
+func (c CanonicalJSON) omit(field canonicalField, value reflect.Value) bool {
+	switch c.Empty {
+	case EmptyKeep:
+		return false
+	case EmptyOmit:
+		return isEmptyValue(value)
+	}
+	return (field.omitEmpty && isEmptyValue(value)) || (field.omitZero && value.IsZero())
+}
+
+func (c CanonicalJSON) encodeObject(buf *bytes.Buffer, members []canonicalMember) error {
+	slices.SortFunc(members, func(a, b canonicalMember) int {
+		return slices.Compare(utf16.Encode([]rune(a.name)), utf16.Encode([]rune(b.name)))
+	})
+	buf.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalString(buf, member.name)
+		buf.WriteByte(':')
+		if !member.quote {
+			if err := c.encode(buf, member.value); err != nil {
+				return err
+			}
+			continue
+		}
+		var quoted bytes.Buffer
+		if err := c.encode(&quoted, member.value); err != nil {
+			return err
+		}
+		writeCanonicalString(buf, quoted.String())
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// canonicalField is a struct field as encoding/json sees it
+type canonicalField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	omitZero  bool
+	quote     bool
+}
+
+// canonicalFields lists the fields encoding/json would write for t. Fields
+// of untagged embedded structs are promoted unless a shallower field has
+// the same name.
+func canonicalFields(t reflect.Type) []canonicalField {
+	var fields []canonicalField
+	taken := make(map[string]bool)
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, sf)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		field := canonicalField{name: name, index: sf.Index}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "omitzero":
+				field.omitZero = true
+			case "string":
+				field.quote = quotable(sf.Type)
+			}
+		}
+		fields = append(fields, field)
+		taken[name] = true
+	}
+
+	for _, sf := range embedded {
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		for _, field := range canonicalFields(ft) {
+			if taken[field.name] {
+				continue
+			}
+			field.index = append(slices.Clone(sf.Index), field.index...)
+			fields = append(fields, field)
+			taken[field.name] = true
+		}
+	}
+	return fields
+}
+
+// quotable reports whether the ",string" option applies to t
+func quotable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty as omitempty means it. Zero
+// times and numbers decoded as zero count too.
+func isEmptyValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time).IsZero()
+	case numberType:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return err == nil && f == 0
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// writeCanonicalNumber writes a decoded number, integers exactly and
+// everything else as a float64
+func writeCanonicalNumber(buf *bytes.Buffer, number string) error {
+	if i, err := strconv.ParseInt(number, 10, 64); err == nil {
+		buf.WriteString(strconv.FormatInt(i, 10))
+		return nil
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("canonical JSON: invalid number %q", number)
+	}
+	return writeCanonicalFloat(buf, f)
+}
+
+// writeCanonicalFloat writes f as JavaScript's Number.prototype.toString
+// does, as RFC 8785 requires
+func writeCanonicalFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return &json.UnsupportedValueError{Str: strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	if f == 0 {
+		buf.WriteByte('0') // including -0
+		return nil
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	b := strconv.AppendFloat(nil, f, format, -1, 64)
+	if format == 'e' {
+		// e-07 becomes e-7
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what must
+// be escaped. Invalid UTF-8 becomes U+FFFD, as with encoding/json.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\b':
+			buf.WriteString(`\b`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
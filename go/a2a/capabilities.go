@@ -0,0 +1,225 @@
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithDirectory makes the server register all of its agent identities with
+// the directory at directoryURL when it starts, and again whenever its
+// capabilities change
+func WithDirectory(directoryURL string) ServerOption {
+	return func(s *A2AServer) {
+		s.directoryURL = directoryURL
+	}
+}
+
+// WithDirectoryProvider is WithDirectory for any Directory, such as one of
+// the providers under a2a/discovery
+func WithDirectoryProvider(directory Directory) ServerOption {
+	return func(s *A2AServer) {
+		s.directory = directory
+	}
+}
+
+// WithTags sets the tags published to the directory, such as "gpu" or
+// "beta"
+func WithTags(tags ...string) ServerOption {
+	return func(s *A2AServer) {
+		s.Tags = tags
+	}
+}
+
+// WithMetadata sets the metadata published to the directory, such as
+// region, team, model or cost tier
+func WithMetadata(metadata map[string]string) ServerOption {
+	return func(s *A2AServer) {
+		s.Metadata = metadata
+	}
+}
+
+// WithSchema publishes the JSON Schemas of a capability's input and
+// output to the directory. Either may be nil.
+func WithSchema(capability string, input, output json.RawMessage) ServerOption {
+	return func(s *A2AServer) {
+		if s.Schemas == nil {
+			s.Schemas = make(map[string]CapabilitySchema)
+		}
+		s.Schemas[capability] = CapabilitySchema{Input: input, Output: output}
+	}
+}
+
+// WithLocation sets the region and zone the server runs in, published to
+// the directory for every agent identity it serves
+func WithLocation(region, zone string) ServerOption {
+	return func(s *A2AServer) {
+		s.Region = region
+		s.Zone = zone
+	}
+}
+
+// WithDirectoryToken sets the token every agent identity served here
+// presents to the directory. Use WithDirectoryCredentials when each agent
+// ID has its own token.
+func WithDirectoryToken(token string) ServerOption {
+	return WithDirectoryCredentials(func(string) string { return token })
+}
+
+// WithDirectoryCredentials sets a function returning the directory token
+// for each agent ID served here
+func WithDirectoryCredentials(credentials func(agentID string) string) ServerOption {
+	return func(s *A2AServer) {
+		s.directoryCredentials = credentials
+	}
+}
+
+// directoryAgent returns a client for registering info with the directory
+func (s *A2AServer) directoryAgent(info AgentInfo) *A2AAgent {
+	agent := NewAgent(info.AgentID, info.Name, info.Capabilities)
+	agent.Description = info.Description
+	agent.Tags = info.Tags
+	agent.Metadata = info.Metadata
+	agent.Schemas = info.Schemas
+	agent.Region = s.Region
+	agent.Zone = s.Zone
+	agent.Directory = s.directory
+	agent.SigningKey = s.signingKey
+	agent.CardVerification = s.cardVerification
+	if s.directoryCredentials != nil {
+		agent.DirectoryToken = s.directoryCredentials(info.AgentID)
+	}
+	return agent
+}
+
+// AddCapability starts advertising capability. The Agent Card reflects the
+// change immediately and the directory, if configured, is updated.
+func (s *A2AServer) AddCapability(capability string) error {
+	s.mu.Lock()
+	for _, existing := range s.Capabilities {
+		if existing == capability {
+			s.mu.Unlock()
+			return nil
+		}
+	}
+	s.Capabilities = append(append([]string(nil), s.Capabilities...), capability)
+	s.mu.Unlock()
+
+	return s.Publish()
+}
+
+// RemoveCapability stops advertising capability. The Agent Card reflects
+// the change immediately and the directory, if configured, is updated.
+func (s *A2AServer) RemoveCapability(capability string) error {
+	s.mu.Lock()
+	capabilities := make([]string, 0, len(s.Capabilities))
+	for _, existing := range s.Capabilities {
+		if existing != capability {
+			capabilities = append(capabilities, existing)
+		}
+	}
+	changed := len(capabilities) != len(s.Capabilities)
+	s.Capabilities = capabilities
+	s.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return s.Publish()
+}
+
+// Publish registers every agent identity served here with the configured
+// directory. It does nothing when no directory is configured. If the
+// directory grants registrations a TTL, the server heartbeats at a third of
+// it until Shutdown.
+func (s *A2AServer) Publish() error {
+	if s.directoryURL == "" && s.directory == nil {
+		return nil
+	}
+
+	var errs []error
+	var ttl time.Duration
+	for _, info := range s.Agents() {
+		agent := s.directoryAgent(info)
+		if err := agent.Register(info.Endpoint, s.directoryURL); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", info.AgentID, err))
+			continue
+		}
+		if agent.TTL > 0 && (ttl == 0 || agent.TTL < ttl) {
+			ttl = agent.TTL
+		}
+	}
+	if ttl > 0 {
+		s.heartbeatOnce.Do(func() { go s.heartbeat(ttl / 3) })
+	}
+	return errors.Join(errs...)
+}
+
+// Load returns the tasks the server is executing and has queued
+func (s *A2AServer) Load() Load {
+	s.drain.mu.Lock()
+	active := s.drain.active
+	s.drain.mu.Unlock()
+	queued := s.queue.len()
+	// active counts accepted tasks, including queued ones
+	return Load{InFlight: max(active-queued, 0), QueueDepth: queued}
+}
+
+// Status is the availability the server reports to its directory:
+// offline once shut down, draining once Drain is called, busy while tasks
+// wait for a worker, and online otherwise
+func (s *A2AServer) Status() AgentStatus {
+	select {
+	case <-s.done:
+		return AgentOffline
+	default:
+	}
+	if s.Draining() {
+		return AgentDraining
+	}
+	if s.queue.len() > 0 {
+		return AgentBusy
+	}
+	return AgentOnline
+}
+
+// reportStatus tells the directory the server's status now, rather than
+// with the next heartbeat
+func (s *A2AServer) reportStatus() {
+	if s.directoryURL == "" && s.directory == nil {
+		return
+	}
+	status := s.Status()
+	for _, info := range s.Agents() {
+		if err := s.directoryAgent(info).ReportStatus(status, s.directoryURL); err != nil {
+			s.logger.Error("reporting status to directory", "agentId", info.AgentID, "status", status, "error", err)
+		}
+	}
+}
+
+// heartbeat renews the server's registrations every interval until
+// Shutdown, reporting the server's load and status and registering again
+// any the directory has already expired
+func (s *A2AServer) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		load, status := s.Load(), s.Status()
+		for _, info := range s.Agents() {
+			agent := s.directoryAgent(info)
+			if agent.heartbeat(HeartbeatParams{AgentID: info.AgentID, Load: &load, Status: status}, s.directoryURL) == nil {
+				continue
+			}
+			if err := agent.Register(info.Endpoint, s.directoryURL); err != nil {
+				s.logger.Error("renewing directory registration", "agentId", info.AgentID, "directory", s.directoryURL, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package a2a
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// An organization vouches for its agents' Agent Cards by signing them, so
+// a proxy, cache or directory between agents cannot change the endpoint,
+// security schemes or keys a card advertises. A server created
+// WithCardSigner adds a JWS (RFC 7515) to the card's signatures, signing
+// the canonical JSON (RFC 8785) of the card without them, and clients with
+// a CardVerification refuse cards no trusted key has signed. Clients trust
+// either the organization's keys themselves or, when signatures carry the
+// signing key's certificate chain (x5c), root certificates it chains to.
+
+// ErrUntrustedAgentCard is returned by FetchAgentCard for cards whose
+// signatures a CardVerification does not trust. It is an
+// ErrInvalidAgentCard.
+var ErrUntrustedAgentCard = fmt.Errorf("%w: no trusted signature", ErrInvalidAgentCard)
+
+// AgentCardSignature is a JWS over an Agent Card, detached from its
+// payload, the card's canonical JSON without signatures
+type AgentCardSignature struct {
+	Protected string                 `json:"protected"` // base64url of the JWS header
+	Signature string                 `json:"signature"` // base64url
+	Header    map[string]interface{} `json:"header,omitempty"`
+}
+
+// CardSigner signs the Agent Cards a server publishes
+type CardSigner struct {
+	// Key is the organization's ed25519.PrivateKey, or an ECDSA or RSA
+	// crypto.Signer, e.g. one held in an HSM
+	Key crypto.Signer
+	// KeyID is the kid clients know Key's public key by
+	KeyID string
+	// Chain is Key's certificate followed by any intermediates, for clients
+	// trusting a root certificate rather than the key
+	Chain []*x509.Certificate
+}
+
+type cardHeader struct {
+	Alg string   `json:"alg"`
+	Kid string   `json:"kid,omitempty"`
+	Typ string   `json:"typ,omitempty"`
+	X5c []string `json:"x5c,omitempty"` // standard base64 DER, leaf first
+}
+
+// WithCardSigner signs the Agent Cards served here with signer. Give it
+// more than once to sign with several keys, e.g. while rotating them.
+func WithCardSigner(signer CardSigner) ServerOption {
+	return func(s *A2AServer) {
+		if _, err := jwsAlgorithm(signer.Key.Public()); err != nil {
+			panic("a2a: WithCardSigner: " + err.Error())
+		}
+		s.card.signers = append(s.card.signers, signer)
+	}
+}
+
+// sign adds a signature by j to card
+func (j CardSigner) sign(card *AgentCard) error {
+	alg, _ := jwsAlgorithm(j.Key.Public())
+	header := cardHeader{Alg: alg, Kid: j.KeyID, Typ: "JOSE"}
+	for _, cert := range j.Chain {
+		header.X5c = append(header.X5c, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	unsigned := *card
+	unsigned.Signatures = nil
+	body, err := json.Marshal(unsigned)
+	if err != nil {
+		return err
+	}
+	payload, err := (CanonicalJSON{}).Canonicalize(body)
+	if err != nil {
+		return err
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	protected := b64(headerJSON)
+	sig, err := jwsSign(j.Key, alg, []byte(protected+"."+b64(payload)))
+	if err != nil {
+		return fmt.Errorf("signing agent card: %w", err)
+	}
+	card.Signatures = append(card.Signatures, AgentCardSignature{Protected: protected, Signature: b64(sig)})
+	return nil
+}
+
+// CardVerification configures which Agent Card signatures a client
+// trusts. Cards without a signature by a trusted key are refused.
+type CardVerification struct {
+	// Keys are the organizations' public keys, matched by kid
+	Keys *JWKSet
+	// Roots are the certificates the x5c chains of signatures must lead
+	// to
+	Roots *x509.CertPool
+	// Organizations, if set, requires the certificate of an x5c chain to
+	// name one of them as its subject's organization
+	Organizations []string
+}
+
+// WithCardVerification refuses Agent Cards not signed as verification
+// requires when fetching the keys of senders to verify their JWS or JWT
+func WithCardVerification(verification CardVerification) ServerOption {
+	return func(s *A2AServer) {
+		s.cardVerification = &verification
+	}
+}
+
+// verify checks that one of signatures is a trusted key's over body, an
+// Agent Card as it was received
+func (v *CardVerification) verify(body []byte, signatures []AgentCardSignature) error {
+	if len(signatures) == 0 {
+		return fmt.Errorf("%w: card is not signed", ErrUntrustedAgentCard)
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return err
+	}
+	delete(members, "signatures")
+	unsigned, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	payload, err := (CanonicalJSON{}).Canonicalize(unsigned)
+	if err != nil {
+		return err
+	}
+
+	var problems []error
+	for _, signature := range signatures {
+		err := v.verifySignature(signature, payload)
+		if err == nil {
+			return nil
+		}
+		problems = append(problems, err)
+	}
+	return fmt.Errorf("%w: %w", ErrUntrustedAgentCard, errors.Join(problems...))
+}
+
+func (v *CardVerification) verifySignature(signature AgentCardSignature, payload []byte) error {
+	decode := base64.RawURLEncoding.DecodeString
+	headerJSON, errHeader := decode(signature.Protected)
+	sig, errSig := decode(signature.Signature)
+	var header cardHeader
+	if errHeader != nil || errSig != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return errors.New("malformed signature")
+	}
+	key, err := v.key(header)
+	if err != nil {
+		return err
+	}
+	input := signature.Protected + "." + base64.RawURLEncoding.EncodeToString(payload)
+	if !jwsVerify(key, header.Alg, []byte(input), sig) {
+		return fmt.Errorf("signature by %q does not verify", header.Kid)
+	}
+	return nil
+}
+
+// key returns the trusted key a signature's header names: a key of Keys
+// with its kid, or the key of an x5c chain leading to Roots
+func (v *CardVerification) key(header cardHeader) (crypto.PublicKey, error) {
+	if jwk, ok := v.Keys.Key(header.Kid); ok && header.Kid != "" {
+		if jwk.Alg != "" && jwk.Alg != header.Alg {
+			return nil, fmt.Errorf("key %q is not used with %s", header.Kid, header.Alg)
+		}
+		return jwk.PublicKey()
+	}
+	if len(header.X5c) == 0 || v.Roots == nil {
+		return nil, fmt.Errorf("key %q is not trusted", header.Kid)
+	}
+
+	certs := make([]*x509.Certificate, len(header.X5c))
+	for i, encoded := range header.X5c {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("malformed x5c")
+		}
+		if certs[i], err = x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf("x5c: %w", err)
+		}
+	}
+	opts := x509.VerifyOptions{Roots: v.Roots, Intermediates: x509.NewCertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return nil, fmt.Errorf("x5c: %w", err)
+	}
+	if len(v.Organizations) > 0 && !slices.ContainsFunc(certs[0].Subject.Organization, func(o string) bool { return slices.Contains(v.Organizations, o) }) {
+		return nil, fmt.Errorf("x5c: certificate is not for %v", v.Organizations)
+	}
+	return certs[0].PublicKey, nil
+}
@@ -0,0 +1,391 @@
+// Package cloudevents carries A2A tasks and task events as CloudEvents
+// 1.0, so A2A traffic can flow through Knative Eventing, event brokers and
+// other CloudEvents-native infrastructure.
+//
+// Tasks are events of type TypeTaskRequest with the task's parameters as
+// data. Handler runs the task requests delivered to it on a server and
+// answers with the task's result, which Knative sends on as a reply event:
+//
+//	http.Handle("/events", cloudevents.Handler(server))
+//
+// Agents send tasks by publishing them to a broker:
+//
+//	event, _ := cloudevents.NewTaskEvent("/agents/my-agent", a2a.TaskParams{AgentID: "calculator-agent", Action: "add", Input: input})
+//	_, err := cloudevents.Send(ctx, nil, brokerURL, event, cloudevents.Binary)
+//
+// A server emits its tasks' lifecycle as events too: WithEvents publishes
+// a TypeTaskResult event when each task is received and when it finishes,
+// and NewStreamEvent turns the events of StreamTask into CloudEvents. The
+// a2aagentid, a2acapability and a2astatus extensions let triggers filter
+// events by agent, capability and task state.
+//
+// Events are encoded over HTTP in binary mode, with attributes as ce-
+// headers and the data as the body, or structured mode, with the whole
+// event as an application/cloudevents+json body. ReadRequest accepts both.
+package cloudevents
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// SpecVersion is the CloudEvents version events are encoded with
+const SpecVersion = "1.0"
+
+// Event types of A2A events
+const (
+	TypeTaskRequest    = "org.a2a.task.request"         // data is a2a.TaskParams
+	TypeTaskResult     = "org.a2a.task.result"          // data is a2a.TaskResult
+	TypeTask           = "org.a2a.task"                 // data is a2a.Task
+	TypeMessage        = "org.a2a.message"              // data is a2a.Message
+	TypeStatusUpdate   = "org.a2a.task.status-update"   // data is a2a.TaskStatusUpdateEvent
+	TypeArtifactUpdate = "org.a2a.task.artifact-update" // data is a2a.TaskArtifactUpdateEvent
+)
+
+// Extension attributes set on A2A events
+const (
+	ExtAgentID       = "a2aagentid"       // the agent the task is for
+	ExtCapability    = "a2acapability"    // the task's action
+	ExtStatus        = "a2astatus"        // the task's state, on results and status updates
+	ExtCorrelationID = "a2acorrelationid" // the task's correlation ID
+)
+
+// Event is a CloudEvent
+type Event struct {
+	ID              string
+	Source          string
+	Type            string
+	Subject         string // the task ID, on A2A events
+	Time            time.Time
+	DataContentType string // empty means application/json
+	DataSchema      string
+	Data            []byte
+	// Extensions are extension attributes, by lowercase name
+	Extensions map[string]string
+}
+
+// NewEvent returns an event of type eventType from source with data as
+// JSON, a random ID and the current time
+func NewEvent(source, eventType string, data interface{}) (Event, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:              newID(),
+		Source:          source,
+		Type:            eventType,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            body,
+		Extensions:      make(map[string]string),
+	}, nil
+}
+
+// NewTaskEvent returns a task request event. A task without an ID takes
+// the event's, so brokers redelivering the event send the same task.
+func NewTaskEvent(source string, task a2a.TaskParams) (Event, error) {
+	id := newID()
+	if task.TaskID == "" {
+		task.TaskID = id
+	}
+	e, err := NewEvent(source, TypeTaskRequest, task)
+	if err != nil {
+		return Event{}, err
+	}
+	e.ID = id
+	e.Subject = task.TaskID
+	e.setExt(ExtAgentID, task.AgentID)
+	e.setExt(ExtCapability, task.Action)
+	e.setExt(ExtCorrelationID, task.CorrelationID)
+	return e, nil
+}
+
+// NewResultEvent returns a task result event for task
+func NewResultEvent(source string, task a2a.TaskParams, result *a2a.TaskResult) (Event, error) {
+	e, err := NewEvent(source, TypeTaskResult, result)
+	if err != nil {
+		return Event{}, err
+	}
+	e.Subject = result.TaskID
+	e.setExt(ExtAgentID, task.AgentID)
+	e.setExt(ExtCapability, task.Action)
+	e.setExt(ExtStatus, string(result.Status))
+	e.setExt(ExtCorrelationID, result.CorrelationID)
+	return e, nil
+}
+
+// NewStreamEvent returns an event for one event of StreamTask or
+// StreamMessage
+func NewStreamEvent(source string, event a2a.StreamEvent) (Event, error) {
+	var e Event
+	var err error
+	switch {
+	case event.Task != nil:
+		e, err = NewEvent(source, TypeTask, event.Task)
+		e.Subject = event.Task.ID
+		e.setExt(ExtStatus, string(event.Task.Status.State))
+		e.setExt(ExtCorrelationID, event.Task.ContextID)
+	case event.Message != nil:
+		e, err = NewEvent(source, TypeMessage, event.Message)
+		e.Subject = event.Message.TaskID
+		e.setExt(ExtCorrelationID, event.Message.ContextID)
+	case event.StatusUpdate != nil:
+		e, err = NewEvent(source, TypeStatusUpdate, event.StatusUpdate)
+		e.Subject = event.StatusUpdate.TaskID
+		e.setExt(ExtStatus, string(event.StatusUpdate.Status.State))
+		e.setExt(ExtCorrelationID, event.StatusUpdate.ContextID)
+	case event.ArtifactUpdate != nil:
+		e, err = NewEvent(source, TypeArtifactUpdate, event.ArtifactUpdate)
+		e.Subject = event.ArtifactUpdate.TaskID
+		e.setExt(ExtCorrelationID, event.ArtifactUpdate.ContextID)
+	default:
+		return Event{}, errors.New("empty stream event")
+	}
+	if err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+// TaskParams decodes a task request event's data. A task without an ID
+// takes the event's.
+func (e Event) TaskParams() (a2a.TaskParams, error) {
+	var task a2a.TaskParams
+	if err := e.decode(TypeTaskRequest, &task); err != nil {
+		return a2a.TaskParams{}, err
+	}
+	if task.TaskID == "" {
+		task.TaskID = e.ID
+	}
+	return task, nil
+}
+
+// TaskResult decodes a task result event's data
+func (e Event) TaskResult() (*a2a.TaskResult, error) {
+	var result a2a.TaskResult
+	if err := e.decode(TypeTaskResult, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StreamEvent decodes the data of a task, message, status update or
+// artifact update event
+func (e Event) StreamEvent() (a2a.StreamEvent, error) {
+	var event a2a.StreamEvent
+	var target interface{}
+	switch e.Type {
+	case TypeTask:
+		event.Task = &a2a.Task{}
+		target = event.Task
+	case TypeMessage:
+		event.Message = &a2a.Message{}
+		target = event.Message
+	case TypeStatusUpdate:
+		event.StatusUpdate = &a2a.TaskStatusUpdateEvent{}
+		target = event.StatusUpdate
+	case TypeArtifactUpdate:
+		event.ArtifactUpdate = &a2a.TaskArtifactUpdateEvent{}
+		target = event.ArtifactUpdate
+	default:
+		return a2a.StreamEvent{}, fmt.Errorf("event type %q is not a stream event", e.Type)
+	}
+	return event, e.decode(e.Type, target)
+}
+
+// Validate reports whether e has the attributes CloudEvents requires
+func (e Event) Validate() error {
+	switch {
+	case e.ID == "":
+		return errors.New("event has no id")
+	case e.Source == "":
+		return errors.New("event has no source")
+	case e.Type == "":
+		return errors.New("event has no type")
+	}
+	for name := range e.Extensions {
+		if !validName(name) || attributes[name] {
+			return fmt.Errorf("invalid extension name %q", name)
+		}
+	}
+	return nil
+}
+
+func (e Event) decode(eventType string, v interface{}) error {
+	if e.Type != eventType {
+		return fmt.Errorf("event type is %q, not %q", e.Type, eventType)
+	}
+	if !isJSON(e.DataContentType) {
+		return fmt.Errorf("unsupported data content type %q", e.DataContentType)
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+func (e *Event) setExt(name, value string) {
+	if value == "" {
+		return
+	}
+	if e.Extensions == nil {
+		e.Extensions = make(map[string]string)
+	}
+	e.Extensions[name] = value
+}
+
+// attributes are the context attributes defined by the specification,
+// which extensions may not use
+var attributes = map[string]bool{
+	"specversion": true, "id": true, "source": true, "type": true, "subject": true,
+	"time": true, "datacontenttype": true, "dataschema": true, "data": true, "data_base64": true,
+}
+
+// MarshalJSON encodes e in the structured JSON format, with JSON data
+// inline and other data as data_base64
+func (e Event) MarshalJSON() ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{
+		"specversion": SpecVersion,
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+	}
+	for name, value := range e.Extensions {
+		m[name] = value
+	}
+	if e.Subject != "" {
+		m["subject"] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		m["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.DataContentType != "" {
+		m["datacontenttype"] = e.DataContentType
+	}
+	if e.DataSchema != "" {
+		m["dataschema"] = e.DataSchema
+	}
+	if e.Data != nil {
+		if isJSON(e.DataContentType) && json.Valid(e.Data) {
+			m["data"] = json.RawMessage(e.Data)
+		} else {
+			m["data_base64"] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes an event in the structured JSON format
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	str := func(name string) (string, error) {
+		var s string
+		if raw, ok := m[name]; ok {
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return "", fmt.Errorf("attribute %s: %w", name, err)
+			}
+		}
+		return s, nil
+	}
+
+	var event Event
+	var specVersion, eventTime string
+	var err error
+	for _, field := range []struct {
+		name   string
+		target *string
+	}{
+		{"specversion", &specVersion}, {"id", &event.ID}, {"source", &event.Source}, {"type", &event.Type},
+		{"subject", &event.Subject}, {"time", &eventTime}, {"datacontenttype", &event.DataContentType}, {"dataschema", &event.DataSchema},
+	} {
+		if *field.target, err = str(field.name); err != nil {
+			return err
+		}
+	}
+	if specVersion != SpecVersion {
+		return fmt.Errorf("unsupported specversion %q", specVersion)
+	}
+	if eventTime != "" {
+		if event.Time, err = time.Parse(time.RFC3339Nano, eventTime); err != nil {
+			return fmt.Errorf("attribute time: %w", err)
+		}
+	}
+	if raw, ok := m["data_base64"]; ok {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return fmt.Errorf("attribute data_base64: %w", err)
+		}
+		if event.Data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return fmt.Errorf("attribute data_base64: %w", err)
+		}
+	} else if raw, ok := m["data"]; ok {
+		event.Data = raw
+		if !isJSON(event.DataContentType) {
+			// non-JSON data in a JSON event is a JSON string
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				event.Data = []byte(s)
+			}
+		}
+	}
+	for name, raw := range m {
+		if attributes[name] {
+			continue
+		}
+		if event.Extensions == nil {
+			event.Extensions = make(map[string]string)
+		}
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			s = string(raw) // numbers and booleans
+		}
+		event.Extensions[name] = s
+	}
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	*e = event
+	return nil
+}
+
+// isJSON reports whether data of contentType is JSON; empty means JSON
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// validName reports whether name is a valid attribute name: lowercase
+// letters and digits
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
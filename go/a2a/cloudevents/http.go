@@ -0,0 +1,316 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Mode is how an event is encoded in an HTTP message
+type Mode int
+
+const (
+	// Binary puts attributes in ce- headers and the data in the body
+	Binary Mode = iota
+	// Structured puts the whole event in an application/cloudevents+json
+	// body
+	Structured
+)
+
+// ContentType is the media type of structured mode events
+const ContentType = "application/cloudevents+json"
+
+// maxEventBytes bounds the events ReadRequest accepts
+const maxEventBytes = 10 << 20
+
+// Encode writes e to header and returns the body carrying it in mode
+func Encode(e Event, mode Mode, header http.Header) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	if mode == Structured {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Content-Type", ContentType+"; charset=utf-8")
+		return body, nil
+	}
+
+	header.Set("ce-specversion", SpecVersion)
+	header.Set("ce-id", escape(e.ID))
+	header.Set("ce-source", escape(e.Source))
+	header.Set("ce-type", escape(e.Type))
+	if e.Subject != "" {
+		header.Set("ce-subject", escape(e.Subject))
+	}
+	if !e.Time.IsZero() {
+		header.Set("ce-time", e.Time.Format(time.RFC3339Nano))
+	}
+	if e.DataSchema != "" {
+		header.Set("ce-dataschema", escape(e.DataSchema))
+	}
+	for name, value := range e.Extensions {
+		header.Set("ce-"+name, escape(value))
+	}
+	contentType := e.DataContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	header.Set("Content-Type", contentType)
+	return e.Data, nil
+}
+
+// Decode reads an event in either mode from an HTTP message's header and
+// body
+func Decode(header http.Header, body []byte) (Event, error) {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == ContentType {
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			return Event{}, fmt.Errorf("decoding structured event: %w", err)
+		}
+		return e, nil
+	}
+	if strings.HasPrefix(mediaType, "application/cloudevents-batch") {
+		return Event{}, errors.New("batched events are not supported")
+	}
+
+	specVersion := header.Get("ce-specversion")
+	if specVersion == "" {
+		return Event{}, errors.New("not a CloudEvent")
+	}
+	if specVersion != SpecVersion {
+		return Event{}, fmt.Errorf("unsupported specversion %q", specVersion)
+	}
+	e := Event{DataContentType: header.Get("Content-Type"), Data: body}
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, "ce-") || len(values) == 0 {
+			continue
+		}
+		name = strings.TrimPrefix(name, "ce-")
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return Event{}, fmt.Errorf("header ce-%s: %w", name, err)
+		}
+		switch name {
+		case "specversion":
+		case "id":
+			e.ID = value
+		case "source":
+			e.Source = value
+		case "type":
+			e.Type = value
+		case "subject":
+			e.Subject = value
+		case "dataschema":
+			e.DataSchema = value
+		case "time":
+			if e.Time, err = time.Parse(time.RFC3339Nano, value); err != nil {
+				return Event{}, fmt.Errorf("header ce-time: %w", err)
+			}
+		default:
+			e.setExt(name, value)
+		}
+	}
+	return e, e.Validate()
+}
+
+// ReadRequest reads the event an HTTP request carries, in either mode
+func ReadRequest(r *http.Request) (Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBytes+1))
+	if err != nil {
+		return Event{}, err
+	}
+	if len(body) > maxEventBytes {
+		return Event{}, errors.New("event too large")
+	}
+	return Decode(r.Header, body)
+}
+
+// NewRequest returns a POST request carrying e to url in mode
+func NewRequest(ctx context.Context, url string, e Event, mode Mode) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := Encode(e, mode, req.Header)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	return req, nil
+}
+
+// Send POSTs e to url, a broker or sink, in mode. A nil client means
+// http.DefaultClient. If the sink answers with an event, as Knative sinks
+// may, Send returns it.
+func Send(ctx context.Context, client *http.Client, url string, e Event, mode Mode) (*Event, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := NewRequest(ctx, url, e, mode)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("sending event: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEventBytes))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 && resp.Header.Get("ce-id") == "" {
+		return nil, nil
+	}
+	reply, err := Decode(resp.Header, body)
+	if err != nil {
+		return nil, nil // not an event
+	}
+	return &reply, nil
+}
+
+// WriteResponse answers an HTTP request with e in mode, as a Knative reply
+func WriteResponse(w http.ResponseWriter, e Event, mode Mode) error {
+	body, err := Encode(e, mode, w.Header())
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+	return err
+}
+
+// Handler returns a sink running the task request events POSTed to it on
+// server and answering with each task's result event, in the mode the
+// request used. Other event types are refused with 400 Bad Request and
+// tasks the server refuses with 422 Unprocessable Entity, so brokers don't
+// redeliver them, but a full queue or draining server is 503 Service
+// Unavailable, so they do.
+func Handler(server *a2a.A2AServer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mode := Binary
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == ContentType {
+			mode = Structured
+		}
+		e, err := ReadRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		task, err := e.TaskParams()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ctx := a2a.ContextWithCorrelationID(r.Context(), task.CorrelationID)
+		result, err := server.RunTask(ctx, task)
+		if err != nil {
+			var rpcErr *a2a.JSONRPCError
+			if errors.As(err, &rpcErr) && (rpcErr.Code == a2a.CodeQueueFull || rpcErr.Code == a2a.CodeServerUnavailable) {
+				http.Error(w, rpcErr.Message, http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		reply, err := NewResultEvent(Source(server), task, result)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		WriteResponse(w, reply, mode)
+	})
+}
+
+// Source returns the source of events about server's tasks: its endpoint
+// or, failing that, /agents/<agentId>
+func Source(server *a2a.A2AServer) string {
+	if server.Endpoint != "" {
+		return server.Endpoint
+	}
+	return "/agents/" + server.AgentID
+}
+
+// Publisher sends events to a broker or sink
+type Publisher struct {
+	URL    string
+	Mode   Mode
+	Client *http.Client // nil means http.DefaultClient
+	// Logger receives publishing errors; nil means slog.Default()
+	Logger *slog.Logger
+}
+
+// Publish sends e
+func (p *Publisher) Publish(ctx context.Context, e Event) error {
+	_, err := Send(ctx, p.Client, p.URL, e, p.Mode)
+	return err
+}
+
+// WithEvents publishes a TypeTaskResult event to p when each of a server's
+// tasks is received, in the submitted state, and when it completes or
+// fails, with the final result. Publishing errors are logged and do not
+// affect the task.
+func WithEvents(p *Publisher) a2a.ServerOption {
+	return func(s *a2a.A2AServer) {
+		publish := func(ctx context.Context, task a2a.TaskParams, result *a2a.TaskResult) {
+			if result == nil {
+				result = &a2a.TaskResult{TaskID: task.TaskID, Status: a2a.TaskStateSubmitted, UpdatedAt: time.Now(), CorrelationID: task.CorrelationID, Metadata: task.Metadata}
+			}
+			e, err := NewResultEvent(Source(s), task, result)
+			if err == nil {
+				err = p.Publish(context.WithoutCancel(ctx), e)
+			}
+			if err != nil {
+				logger := p.Logger
+				if logger == nil {
+					logger = slog.Default()
+				}
+				logger.Error("publishing task event", "taskId", task.TaskID, "correlationId", task.CorrelationID, "status", result.Status, "error", err)
+			}
+		}
+		s.OnTaskReceived(publish)
+		s.OnTaskCompleted(publish)
+		s.OnTaskFailed(publish)
+	}
+}
+
+// escape percent-encodes a header value as the HTTP binding requires:
+// spaces, double quotes, percent signs and anything outside printable
+// ASCII
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package a2a
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Codec encodes and decodes protocol messages. The default is encoding/json;
+// high-throughput deployments can plug in a faster JSON implementation.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewDecoder(r io.Reader) Decoder
+	ContentType() string
+}
+
+// Decoder reads successive values from a stream
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// JSONCodec is the encoding/json Codec
+type JSONCodec struct{}
+
+// Marshal encodes v as JSON
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes JSON data into v
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// NewDecoder returns a streaming JSON decoder reading from r
+func (JSONCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// ContentType returns "application/json"
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// DefaultCodec is used by servers and agents that do not set their own
+var DefaultCodec Codec = JSONCodec{}
+
+// WithCodec sets the codec the server uses for requests and responses
+func WithCodec(codec Codec) ServerOption {
+	return func(s *A2AServer) {
+		s.codec = codec
+	}
+}
+
+func (a *A2AAgent) codec() Codec {
+	if a.Codec != nil {
+		return a.Codec
+	}
+	return DefaultCodec
+}
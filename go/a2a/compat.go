@@ -0,0 +1,227 @@
+package a2a
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// Metadata keys carrying the TaskParams fields message/send has no place
+// for, alongside MetadataAction and MetadataSender
+const (
+	MetadataPriority       = "priority"
+	MetadataIdempotencyKey = "idempotencyKey"
+	MetadataParentTaskID   = "parentTaskId"
+	MetadataRootTaskID     = "rootTaskId"
+	MetadataHop            = "hop"
+)
+
+// taskMetadataKeys are the metadata keys TaskParamsFromMessage reads into
+// TaskParams fields rather than Metadata
+var taskMetadataKeys = []string{MetadataAction, MetadataSender, MetadataPriority, MetadataIdempotencyKey, MetadataParentTaskID, MetadataRootTaskID, MetadataHop}
+
+// MessageSendParams converts legacy task params into message/send params
+// for the same task. The message is p.Message, or built from p.Input with
+// MessageFromInput; with both, Input is appended to the message as parts.
+// The action, sender, priority, idempotency key and trace travel as
+// metadata.
+func (p TaskParams) MessageSendParams() MessageSendParams {
+	msg := MessageFromInput(p.Input)
+	if p.Message != nil {
+		parts := append(slices.Clip(p.Message.Parts), msg.Parts...)
+		msg = *p.Message
+		msg.Parts = parts
+	}
+	if msg.Kind == "" {
+		msg.Kind = "message"
+	}
+	if p.TaskID != "" {
+		msg.TaskID = p.TaskID
+	}
+	if p.CorrelationID != "" {
+		msg.ContextID = p.CorrelationID
+	}
+
+	metadata := maps.Clone(p.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	for key, value := range map[string]string{MetadataAction: p.Action, MetadataSender: p.Sender, MetadataIdempotencyKey: p.IdempotencyKey} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	if p.Priority != 0 {
+		metadata[MetadataPriority] = p.Priority
+	}
+	traceMetadata(metadata, p)
+
+	send := MessageSendParams{Message: msg, Metadata: metadata}
+	if len(p.AcceptedOutputModes) > 0 {
+		send.Configuration = &MessageSendConfiguration{AcceptedOutputModes: p.AcceptedOutputModes}
+	}
+	if p.ReplyTo != nil {
+		if send.Configuration == nil {
+			send.Configuration = &MessageSendConfiguration{}
+		}
+		blocking := false
+		send.Configuration.Blocking = &blocking
+		send.Configuration.PushNotificationConfig = p.ReplyTo
+	}
+	return send
+}
+
+// TaskParamsFromMessage converts message/send params into the legacy task
+// params servers run, the inverse of TaskParams.MessageSendParams. The
+// content stays in Message, which handlers receive merged into their
+// input.
+func TaskParamsFromMessage(send MessageSendParams) TaskParams {
+	msg := send.Message
+	task := TaskParams{
+		TaskID:         msg.TaskID,
+		Action:         metadataString(MetadataAction, msg.Metadata, send.Metadata),
+		Sender:         metadataString(MetadataSender, msg.Metadata, send.Metadata),
+		Message:        &msg,
+		CorrelationID:  msg.ContextID,
+		IdempotencyKey: metadataString(MetadataIdempotencyKey, send.Metadata),
+	}
+	task.Priority = metadataInt(MetadataPriority, send.Metadata)
+	task.ParentTaskID = metadataString(MetadataParentTaskID, send.Metadata)
+	task.RootTaskID = metadataString(MetadataRootTaskID, send.Metadata)
+	task.Hop = metadataInt(MetadataHop, send.Metadata)
+	if send.Configuration != nil {
+		task.AcceptedOutputModes = send.Configuration.AcceptedOutputModes
+	}
+	if len(send.Metadata) > 0 {
+		task.Metadata = maps.Clone(send.Metadata)
+		for _, key := range taskMetadataKeys {
+			delete(task.Metadata, key)
+		}
+		if len(task.Metadata) == 0 {
+			task.Metadata = nil
+		}
+	}
+	return task
+}
+
+// traceMetadata adds the trace of p to metadata
+func traceMetadata(metadata map[string]interface{}, p TaskParams) {
+	if p.ParentTaskID != "" {
+		metadata[MetadataParentTaskID] = p.ParentTaskID
+	}
+	if p.RootTaskID != "" {
+		metadata[MetadataRootTaskID] = p.RootTaskID
+	}
+	if p.Hop != 0 {
+		metadata[MetadataHop] = p.Hop
+	}
+}
+
+// metadataInt returns the number at key in metadata, or 0
+func metadataInt(key string, metadata map[string]interface{}) int {
+	switch n := metadata[key].(type) {
+	case int:
+		return n
+	case float64: // as decoded from JSON
+		return int(n)
+	}
+	return 0
+}
+
+// Result converts a task in the A2A specification's shape into the legacy
+// TaskResult, the inverse of TaskResult.Task. The "output" artifact
+// servers of this SDK add becomes Output again, and a failed task's
+// status message its Error.
+func (t *Task) Result() *TaskResult {
+	result := &TaskResult{
+		TaskID:        t.ID,
+		Status:        t.Status.State,
+		UpdatedAt:     t.Status.Timestamp,
+		CorrelationID: t.ContextID,
+		Metadata:      t.Metadata,
+	}
+	for _, artifact := range t.Artifacts {
+		if data, ok := outputData(artifact); ok && result.Output == nil {
+			result.Output = data
+			continue
+		}
+		result.Artifacts = append(result.Artifacts, artifact)
+	}
+	switch {
+	case t.Status.State == TaskStateFailed:
+		message := "task failed"
+		if t.Status.Message != nil && t.Status.Message.Text() != "" {
+			message = t.Status.Message.Text()
+		}
+		result.Error = &JSONRPCError{Code: CodeTaskFailed, Message: message}
+	case t.Status.Message != nil:
+		result.StatusMessage = t.Status.Message
+	}
+	return result
+}
+
+// outputData returns the output map an "output" artifact carries
+func outputData(artifact Artifact) (map[string]interface{}, bool) {
+	if artifact.ArtifactID != "output" || len(artifact.Parts) != 1 {
+		return nil, false
+	}
+	data, ok := artifact.Parts[0].(DataPart)
+	return data.Data, ok
+}
+
+// LegacyPolicy is how a server treats the methods that predate the A2A
+// specification: a2a/task, a2a/task/submit and a2a/task/get
+type LegacyPolicy int
+
+// Legacy policies, in the order a migration goes through them
+const (
+	LegacyAllow  LegacyPolicy = iota // serve them alongside message/send
+	LegacyWarn                       // serve them, logging each call and marking the response with a Deprecation header
+	LegacyReject                     // refuse them with CodeMethodNotFound, so this SDK's clients switch to message/send
+)
+
+// legacyMethods maps each legacy method to the spec method replacing it
+var legacyMethods = map[string]string{
+	"a2a/task":        "message/send",
+	"a2a/task/submit": "message/send",
+	"a2a/task/get":    "tasks/get",
+}
+
+// WithLegacyPolicy sets how the server treats legacy methods. Every
+// server serves both the legacy methods and their spec replacements, so
+// clients can migrate one at a time; LegacyWarn finds those that haven't
+// and LegacyReject retires the legacy methods.
+func WithLegacyPolicy(policy LegacyPolicy) ServerOption {
+	return func(s *A2AServer) {
+		s.legacyPolicy = policy
+	}
+}
+
+// checkLegacy applies the legacy policy to a request for method, returning
+// the error to answer with if it is refused
+func (s *A2AServer) checkLegacy(w http.ResponseWriter, r *http.Request, method string) *JSONRPCError {
+	deprecated, rpcErr := s.legacyCall(method, "remoteAddr", r.RemoteAddr, "userAgent", r.UserAgent())
+	if deprecated {
+		w.Header().Set("Deprecation", "true")
+	}
+	return rpcErr
+}
+
+// legacyCall applies the legacy policy to a call of method, reporting
+// whether the caller should be told it is deprecated. caller describes the
+// caller in the warning logged.
+func (s *A2AServer) legacyCall(method string, caller ...interface{}) (bool, *JSONRPCError) {
+	replacement, legacy := legacyMethods[method]
+	if !legacy {
+		return false, nil
+	}
+	switch s.legacyPolicy {
+	case LegacyWarn:
+		s.logger.Warn("legacy method called", append([]interface{}{"method", method, "replacement", replacement}, caller...)...)
+		return true, nil
+	case LegacyReject:
+		return false, &JSONRPCError{Code: CodeMethodNotFound, Message: fmt.Sprintf("Method not found; use %s", replacement)}
+	}
+	return false, nil
+}
@@ -0,0 +1,55 @@
+package a2a
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// defaultCompressionThreshold is the smallest response body gzipped when
+// the client accepts it
+const defaultCompressionThreshold = 1024
+
+// WithCompression sets the smallest response, in bytes, that is gzipped
+// for clients sending Accept-Encoding: gzip. A negative value disables
+// compression.
+func WithCompression(minBytes int) ServerOption {
+	return func(s *A2AServer) {
+		s.compressionThreshold = minBytes
+	}
+}
+
+// writeJSON encodes v with the server codec as the response body, gzipping it when it is large
+// enough and the client accepts gzip
+func (s *A2AServer) writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := s.codec.Marshal(v)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	w.Header().Set("Content-Type", s.codec.ContentType())
+	w.Header().Add("Vary", "Accept-Encoding")
+	if s.compressionThreshold < 0 || len(body) < s.compressionThreshold || !acceptsGzip(r) {
+		w.Write(body)
+		return
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	gz.Write(body)
+	gz.Close()
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+)
+
+// CorrelationHeader carries the correlation ID of a request across agents
+const CorrelationHeader = "X-Request-ID"
+
+type correlationKey struct{}
+
+// ContextWithCorrelationID returns a copy of ctx carrying id
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx. Task handlers
+// receive a context carrying the ID of the task they are running.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// WithCorrelationID sends a task as part of the request identified by id
+func WithCorrelationID(id string) TaskOption {
+	return func(p *TaskParams) {
+		p.CorrelationID = id
+	}
+}
+
+// Propagate sends a task as part of the request a handler is serving, so
+// fan-out to other agents can be traced back to it. Within a task handler
+// it also makes the task a sub-task of the running one, setting its
+// ParentTaskID, RootTaskID and Hop; TaskClient's agents do this by
+// themselves.
+//
+//	agent.SendTask(target, "summarize", input, directoryURL, a2a.Propagate(ctx))
+func Propagate(ctx context.Context) TaskOption {
+	return func(p *TaskParams) {
+		if parent, ok := ctx.Value(taskKey{}).(TaskParams); ok {
+			subTaskOf(p, parent)
+		}
+		if id := CorrelationID(ctx); id != "" {
+			p.CorrelationID = id
+		}
+	}
+}
+
+// correlate makes sure a task has a correlation ID, taking it from the
+// request context when the task does not carry one, and returns a context
+// carrying it
+func correlate(ctx context.Context, task *TaskParams) context.Context {
+	if task.CorrelationID == "" {
+		task.CorrelationID = CorrelationID(ctx)
+	}
+	if task.CorrelationID == "" {
+		task.CorrelationID = generateID()
+	}
+	return ContextWithCorrelationID(ctx, task.CorrelationID)
+}
+
+// requestCorrelation returns the request context with the incoming
+// correlation header, echoing the header on the response
+func requestCorrelation(w http.ResponseWriter, r *http.Request) context.Context {
+	id := r.Header.Get(CorrelationHeader)
+	if id != "" {
+		w.Header().Set(CorrelationHeader, id)
+	}
+	return ContextWithCorrelationID(r.Context(), id)
+}
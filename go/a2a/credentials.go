@@ -0,0 +1,93 @@
+package a2a
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// Agents calling servers that require an API key, a bearer token or a
+// client certificate get them from a CredentialProvider rather than their
+// code or configuration, so secrets live in one place and rotate without a
+// restart. The a2a/credentials package provides ones reading environment
+// variables, files and HashiCorp Vault.
+
+// Credential is what an agent presents to the servers it calls
+type Credential struct {
+	// Token is sent as a bearer token
+	Token string
+	// APIKey is sent in the APIKeyHeader header, for servers whose Agent
+	// Card has an apiKey scheme
+	APIKey string
+	// APIKeyHeader is the header named by the apiKey scheme; empty means
+	// X-API-Key
+	APIKeyHeader string
+	// Certificate is presented to servers asking for a client
+	// certificate, by clients from NewCredentialHTTPClient
+	Certificate *tls.Certificate
+}
+
+// CredentialProvider provides an agent's current credential. It is asked
+// for each request, so providers should cache what they fetch and return
+// rotated credentials as soon as they have them.
+type CredentialProvider interface {
+	Credential(ctx context.Context) (Credential, error)
+}
+
+type credentialKey struct{}
+
+// authenticate returns ctx carrying the agent's credential if it has
+// Credentials
+func (a *A2AAgent) authenticate(ctx context.Context) (context.Context, error) {
+	if a.Credentials == nil {
+		return ctx, nil
+	}
+	credential, err := a.Credentials.Credential(ctx)
+	if err != nil {
+		return ctx, fmt.Errorf("getting credential: %w", err)
+	}
+	return context.WithValue(ctx, credentialKey{}, credential), nil
+}
+
+// setCredential adds the token and API key authenticate added to ctx to
+// header. A token does not replace an Authorization header already set.
+func setCredential(ctx context.Context, header http.Header) {
+	credential, ok := ctx.Value(credentialKey{}).(Credential)
+	if !ok {
+		return
+	}
+	if credential.Token != "" && header.Get("Authorization") == "" {
+		header.Set("Authorization", "Bearer "+credential.Token)
+	}
+	if credential.APIKey != "" {
+		name := credential.APIKeyHeader
+		if name == "" {
+			name = "X-API-Key"
+		}
+		header.Set(name, credential.APIKey)
+	}
+}
+
+// NewCredentialHTTPClient returns an HTTP client presenting the
+// certificate of provider's credential to servers asking for one, e.g. for
+// A2AAgent.HTTPClient. The certificate is fetched on every handshake, so
+// rotated ones are used for new connections.
+func NewCredentialHTTPClient(provider CredentialProvider) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			credential, err := provider.Credential(info.Context())
+			if err != nil {
+				return nil, err
+			}
+			if credential.Certificate == nil {
+				// Continue without one; the server decides
+				return &tls.Certificate{}, nil
+			}
+			return credential.Certificate, nil
+		},
+	}
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,27 @@
+// Package credentials provides a2a.CredentialProviders reading an agent's
+// API key, bearer token and client certificate from environment
+// variables, files or HashiCorp Vault. Each picks up rotated credentials
+// on its own: Env on every request, File when the files change and Vault
+// when its refresh interval or the secret's lease runs out.
+//
+//	agent.Credentials = &credentials.Vault{Path: "agents/billing"}
+//	agent.HTTPClient = a2a.NewCredentialHTTPClient(agent.Credentials)
+package credentials
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// keyPair parses a PEM certificate, followed by any intermediates, and its
+// private key, or returns nil if both are empty
+func keyPair(certPEM, keyPEM []byte) (*tls.Certificate, error) {
+	if len(certPEM) == 0 && len(keyPEM) == 0 {
+		return nil, nil
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("credentials: loading certificate: %w", err)
+	}
+	return &cert, nil
+}
@@ -0,0 +1,56 @@
+package credentials
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Env is an a2a.CredentialProvider reading environment variables, as
+// container platforms inject secrets. The variables are read on every
+// request. Those left empty are not read, and unset variables leave their
+// part of the credential empty.
+type Env struct {
+	Token        string // the variable holding the bearer token, e.g. "A2A_TOKEN"
+	APIKey       string // the variable holding the API key, e.g. "A2A_API_KEY"
+	APIKeyHeader string // the header to send the API key in; empty means X-API-Key
+	Cert         string // the variable holding the PEM client certificate
+	Key          string // the variable holding the certificate's PEM private key
+
+	mu   sync.Mutex
+	pem  [2]string // the certificate and key cert was parsed from
+	cert *tls.Certificate
+}
+
+var _ a2a.CredentialProvider = (*Env)(nil)
+
+// Credential returns the credential in the variables
+func (e *Env) Credential(context.Context) (a2a.Credential, error) {
+	credential := a2a.Credential{APIKeyHeader: e.APIKeyHeader}
+	if e.Token != "" {
+		credential.Token = os.Getenv(e.Token)
+	}
+	if e.APIKey != "" {
+		credential.APIKey = os.Getenv(e.APIKey)
+	}
+	if e.Cert == "" && e.Key == "" {
+		return credential, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	pem := [2]string{os.Getenv(e.Cert), os.Getenv(e.Key)}
+	if pem != e.pem {
+		cert, err := keyPair([]byte(pem[0]), []byte(pem[1]))
+		if err != nil {
+			return a2a.Credential{}, fmt.Errorf("%w in %s and %s", err, e.Cert, e.Key)
+		}
+		e.pem, e.cert = pem, cert
+	}
+	credential.Certificate = e.cert
+	return credential, nil
+}
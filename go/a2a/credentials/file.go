@@ -0,0 +1,92 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// File is an a2a.CredentialProvider reading files, as mounted Kubernetes
+// secrets and the Vault Agent write them. Files are read again when they
+// change, and a file rewritten halfway leaves the previous credential in
+// use until the next request. Files left empty are not read.
+type File struct {
+	TokenFile    string // holds the bearer token
+	APIKeyFile   string // holds the API key
+	APIKeyHeader string // the header to send the API key in; empty means X-API-Key
+	CertFile     string // holds the PEM client certificate, followed by its intermediates
+	KeyFile      string // holds the certificate's PEM private key
+
+	mu         sync.Mutex
+	modified   [4]time.Time
+	credential *a2a.Credential
+}
+
+var _ a2a.CredentialProvider = (*File)(nil)
+
+// Credential returns the credential in the files
+func (f *File) Credential(context.Context) (a2a.Credential, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reload(); err != nil {
+		return a2a.Credential{}, err
+	}
+	return *f.credential, nil
+}
+
+// reload reads the files again if any changed since they were last read
+func (f *File) reload() error {
+	names := [4]string{f.TokenFile, f.APIKeyFile, f.CertFile, f.KeyFile}
+	var modified [4]time.Time
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return f.stale(fmt.Errorf("credentials: %w", err))
+		}
+		modified[i] = info.ModTime()
+	}
+	if f.credential != nil && modified == f.modified {
+		return nil
+	}
+
+	var contents [4][]byte
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return f.stale(fmt.Errorf("credentials: %w", err))
+		}
+		contents[i] = data
+	}
+	cert, err := keyPair(contents[2], contents[3])
+	if err != nil {
+		return f.stale(err)
+	}
+	f.credential = &a2a.Credential{
+		Token:        string(bytes.TrimSpace(contents[0])),
+		APIKey:       string(bytes.TrimSpace(contents[1])),
+		APIKeyHeader: f.APIKeyHeader,
+		Certificate:  cert,
+	}
+	f.modified = modified
+	return nil
+}
+
+// stale keeps serving what was last read when reading the files again
+// fails, returning err only if nothing was ever read
+func (f *File) stale(err error) error {
+	if f.credential != nil {
+		return nil
+	}
+	return err
+}
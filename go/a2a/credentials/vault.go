@@ -0,0 +1,250 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Vault is an a2a.CredentialProvider reading a HashiCorp Vault secret
+// through Vault's HTTP API, e.g. a KV version 2 secret a rotation job
+// writes. The secret is read again every Refresh, or sooner once two
+// thirds of its lease have passed, in the background while the last
+// credential read is used. If Vault cannot be reached that credential is
+// used until its lease expires.
+type Vault struct {
+	// Address is Vault's URL; empty means $VAULT_ADDR
+	Address string
+	// Token authenticates to Vault. Empty reads TokenFile, e.g. the Vault
+	// Agent's sink, on every refresh, or else $VAULT_TOKEN.
+	Token     string
+	TokenFile string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// Path is the secret's API path, e.g. "secret/data/agents/billing"
+	// for the KV version 2 secret agents/billing
+	Path string
+	// TokenField, APIKeyField, CertField and KeyField name the secret's
+	// fields; empty means "token", "api_key", "certificate" and
+	// "private_key". The certificate and key are PEM.
+	TokenField  string
+	APIKeyField string
+	CertField   string
+	KeyField    string
+	// APIKeyHeader is the header to send the API key in; empty means
+	// X-API-Key
+	APIKeyHeader string
+	// Refresh is how often the secret is read again; zero means 5 minutes
+	Refresh time.Duration
+	// HTTPClient talks to Vault; nil means a client giving up after 10
+	// seconds
+	HTTPClient *http.Client
+
+	mu         sync.Mutex
+	credential *a2a.Credential
+	refresh    time.Time     // when to read the secret again
+	expires    time.Time     // when credential's lease ends; zero if it has none
+	reading    chan struct{} // closed when the read in progress ends; nil if none is
+	err        error         // why the last read failed
+}
+
+var _ a2a.CredentialProvider = (*Vault)(nil)
+
+// vaultRetry is how long a failed refresh waits before Vault is tried again
+const vaultRetry = 10 * time.Second
+
+// vaultClient talks to Vault for Vaults without an HTTPClient
+var vaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// vaultSecret is the response to reading a secret
+type vaultSecret struct {
+	LeaseDuration int                    `json:"lease_duration"` // seconds
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+// Credential returns the credential in the secret. One due for a refresh
+// is returned while the secret is read again; only without a valid one
+// does Credential wait for Vault.
+func (v *Vault) Credential(ctx context.Context) (a2a.Credential, error) {
+	v.mu.Lock()
+	now := time.Now()
+	if v.credential != nil && now.Before(v.refresh) {
+		defer v.mu.Unlock()
+		return *v.credential, nil
+	}
+	reading := v.startRead(ctx)
+	if v.valid(now) {
+		defer v.mu.Unlock()
+		return *v.credential, nil
+	}
+	v.mu.Unlock()
+
+	select {
+	case <-reading:
+	case <-ctx.Done():
+		return a2a.Credential{}, ctx.Err()
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.valid(time.Now()) {
+		return a2a.Credential{}, v.err
+	}
+	return *v.credential, nil
+}
+
+// valid reports whether the credential last read may still be used. The
+// caller holds v.mu.
+func (v *Vault) valid(now time.Time) bool {
+	return v.credential != nil && (v.expires.IsZero() || now.Before(v.expires))
+}
+
+// startRead reads the secret in the background unless a read is already
+// in progress, returning a channel closed once it ends. The caller holds
+// v.mu.
+func (v *Vault) startRead(ctx context.Context) <-chan struct{} {
+	if v.reading != nil {
+		return v.reading
+	}
+	reading := make(chan struct{})
+	v.reading = reading
+	// The read outlives the request starting it, bounded by the client's
+	// timeout instead
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		credential, lease, err := v.read(ctx)
+		v.mu.Lock()
+		v.update(credential, lease, err)
+		v.reading = nil
+		v.mu.Unlock()
+		close(reading)
+	}()
+	return reading
+}
+
+// update records the outcome of reading the secret and schedules its next
+// refresh. The caller holds v.mu.
+func (v *Vault) update(credential *a2a.Credential, lease time.Duration, err error) {
+	now := time.Now()
+	if err != nil {
+		// Serve the secret while it is valid, trying again shortly
+		v.err = err
+		v.refresh = now.Add(vaultRetry)
+		return
+	}
+	v.credential, v.err = credential, nil
+	refresh := v.Refresh
+	if refresh <= 0 {
+		refresh = 5 * time.Minute
+	}
+	v.expires = time.Time{}
+	if lease > 0 {
+		v.expires = now.Add(lease)
+		refresh = min(refresh, lease*2/3)
+	}
+	v.refresh = now.Add(refresh)
+}
+
+// read reads the secret, returning its credential and lease
+func (v *Vault) read(ctx context.Context) (*a2a.Credential, time.Duration, error) {
+	secret, err := v.get(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	// KV version 2 nests the secret's fields under its metadata
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	field := func(name, fallback string) string {
+		if name == "" {
+			name = fallback
+		}
+		value, _ := data[name].(string)
+		return value
+	}
+	cert, err := keyPair([]byte(field(v.CertField, "certificate")), []byte(field(v.KeyField, "private_key")))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	credential := &a2a.Credential{
+		Token:        field(v.TokenField, "token"),
+		APIKey:       field(v.APIKeyField, "api_key"),
+		APIKeyHeader: v.APIKeyHeader,
+		Certificate:  cert,
+	}
+	return credential, time.Duration(secret.LeaseDuration) * time.Second, nil
+}
+
+// get reads the secret at Path
+func (v *Vault) get(ctx context.Context) (*vaultSecret, error) {
+	address := v.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return nil, errors.New("credentials: no Vault address")
+	}
+	token, err := v.token()
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimSuffix(address, "/") + "/v1/" + strings.TrimPrefix(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	client := v.HTTPClient
+	if client == nil {
+		client = vaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("credentials: reading %s from Vault: %w", v.Path, err)
+	}
+	defer resp.Body.Close()
+
+	var secret vaultSecret
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&secret); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("credentials: decoding %s: %w", v.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("credentials: reading %s from Vault: %s %s", v.Path, resp.Status, strings.Join(secret.Errors, "; "))
+	}
+	return &secret, nil
+}
+
+// token returns the token to authenticate to Vault with
+func (v *Vault) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+	if v.TokenFile != "" {
+		token, err := os.ReadFile(v.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("credentials: %w", err)
+		}
+		return string(bytes.TrimSpace(token)), nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	return "", errors.New("credentials: no Vault token")
+}
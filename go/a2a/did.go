@@ -0,0 +1,395 @@
+package a2a
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Agents in open networks, where no directory or identity provider is
+// trusted by everyone, can take a DID (W3C Decentralized Identifier) as
+// their agent ID. The keys that speak for a DID are in its DID document,
+// which the DID's method locates: a did:key is its own public key, a
+// did:web is published at an HTTPS URL its controller owns. Servers check
+// the JWS and JWT of senders whose ID is a DID against the keys of their
+// DID document rather than an Agent Card, and directories check their
+// registrations the same way.
+
+// DIDServiceType is the type of the service in an agent's DID document
+// whose serviceEndpoint is its A2A endpoint
+const DIDServiceType = "A2AAgent"
+
+// DIDDocument is a DID document, as far as agents use it
+type DIDDocument struct {
+	Context            interface{}             `json:"@context,omitempty"`
+	ID                 string                  `json:"id"`
+	VerificationMethod []DIDVerificationMethod `json:"verificationMethod,omitempty"`
+	// Authentication and AssertionMethod list the methods whose keys may
+	// sign for the DID, by reference (only ID set) or embedded
+	Authentication  []DIDVerificationMethod `json:"authentication,omitempty"`
+	AssertionMethod []DIDVerificationMethod `json:"assertionMethod,omitempty"`
+	Service         []DIDService            `json:"service,omitempty"`
+}
+
+// DIDVerificationMethod is a public key in a DID document
+type DIDVerificationMethod struct {
+	ID           string `json:"id"` // a DID URL, e.g. did:web:example.com#key-1, or relative, #key-1
+	Type         string `json:"type,omitempty"`
+	Controller   string `json:"controller,omitempty"`
+	PublicKeyJWK *JWK   `json:"publicKeyJwk,omitempty"`
+	// PublicKeyMultibase is an Ed25519 or P-256 multikey in base58btc, as
+	// Ed25519VerificationKey2020 and Multikey methods have it
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"`
+}
+
+type didVerificationMethod DIDVerificationMethod
+
+// UnmarshalJSON accepts a verification method or a reference to one
+func (m *DIDVerificationMethod) UnmarshalJSON(data []byte) error {
+	var ref string
+	if json.Unmarshal(data, &ref) == nil {
+		*m = DIDVerificationMethod{ID: ref}
+		return nil
+	}
+	return json.Unmarshal(data, (*didVerificationMethod)(m))
+}
+
+// MarshalJSON writes a method with only an ID as a reference
+func (m DIDVerificationMethod) MarshalJSON() ([]byte, error) {
+	if m == (DIDVerificationMethod{ID: m.ID}) {
+		return json.Marshal(m.ID)
+	}
+	return json.Marshal(didVerificationMethod(m))
+}
+
+// DIDService is a service in a DID document
+type DIDService struct {
+	ID              string      `json:"id"`
+	Type            string      `json:"type"`
+	ServiceEndpoint interface{} `json:"serviceEndpoint"` // a URL, or a set or map of them
+}
+
+// IsDID reports whether an agent ID is a DID
+func IsDID(agentID string) bool {
+	method, rest, ok := strings.Cut(strings.TrimPrefix(agentID, "did:"), ":")
+	return strings.HasPrefix(agentID, "did:") && ok && method != "" && rest != ""
+}
+
+// Keys returns the keys of the methods the document lists for
+// authentication or assertion, with their absolute method IDs as kid.
+// Methods whose key is neither a JWK nor a multikey this package reads are
+// left out.
+func (d *DIDDocument) Keys() *JWKSet {
+	methods := make(map[string]DIDVerificationMethod)
+	for _, method := range d.VerificationMethod {
+		methods[d.absolute(method.ID)] = method
+	}
+	set := &JWKSet{}
+	seen := make(map[string]bool)
+	for _, method := range append(append([]DIDVerificationMethod(nil), d.Authentication...), d.AssertionMethod...) {
+		id := d.absolute(method.ID)
+		if method.PublicKeyJWK == nil && method.PublicKeyMultibase == "" {
+			method = methods[id]
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if jwk, ok := method.jwk(); ok {
+			jwk.Kid = id
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// HasKey reports whether key is one of Keys
+func (d *DIDDocument) HasKey(key crypto.PublicKey) bool {
+	want, err := NewJWK("", key)
+	if err != nil {
+		return false
+	}
+	for _, jwk := range d.Keys().Keys {
+		if jwk.Kty == want.Kty && jwk.Crv == want.Crv && jwk.X == want.X && jwk.Y == want.Y && jwk.N == want.N && jwk.E == want.E {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoint returns the A2A endpoint of the agent the document describes,
+// the first serviceEndpoint URL of a DIDServiceType service
+func (d *DIDDocument) Endpoint() (string, bool) {
+	for _, service := range d.Service {
+		if service.Type != DIDServiceType {
+			continue
+		}
+		switch endpoint := service.ServiceEndpoint.(type) {
+		case string:
+			return endpoint, true
+		case []interface{}:
+			for _, e := range endpoint {
+				if s, ok := e.(string); ok {
+					return s, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// absolute resolves a relative DID URL, "#key-1", against the document
+func (d *DIDDocument) absolute(id string) string {
+	if strings.HasPrefix(id, "#") {
+		return d.ID + id
+	}
+	return id
+}
+
+// jwk returns the method's key as a JWK
+func (m DIDVerificationMethod) jwk() (JWK, bool) {
+	if m.PublicKeyJWK != nil {
+		if _, err := m.PublicKeyJWK.PublicKey(); err != nil {
+			return JWK{}, false
+		}
+		return *m.PublicKeyJWK, true
+	}
+	key, err := decodeMultikey(m.PublicKeyMultibase)
+	if err != nil {
+		return JWK{}, false
+	}
+	jwk, err := NewJWK("", key)
+	return jwk, err == nil
+}
+
+// DIDResolver resolves DIDs to their DID documents
+type DIDResolver interface {
+	ResolveDID(ctx context.Context, did string) (*DIDDocument, error)
+}
+
+// DIDMethods resolves each DID with the resolver of its method, e.g.
+// "key" for did:key
+type DIDMethods map[string]DIDResolver
+
+// ResolveDID implements DIDResolver
+func (m DIDMethods) ResolveDID(ctx context.Context, did string) (*DIDDocument, error) {
+	if !IsDID(did) {
+		return nil, fmt.Errorf("%q is not a DID", did)
+	}
+	method, _, _ := strings.Cut(strings.TrimPrefix(did, "did:"), ":")
+	resolver, ok := m[method]
+	if !ok {
+		return nil, fmt.Errorf("unsupported DID method %q", method)
+	}
+	return resolver.ResolveDID(ctx, did)
+}
+
+// DefaultDIDResolver resolves did:key and did:web DIDs
+var DefaultDIDResolver DIDResolver = DIDMethods{
+	"key": DIDKeyResolver{},
+	"web": &DIDWebResolver{},
+}
+
+// DIDKeyResolver resolves did:key DIDs of Ed25519 and P-256 keys. Their
+// document is derived from the key, so resolving needs no network.
+type DIDKeyResolver struct{}
+
+// ResolveDID implements DIDResolver
+func (DIDKeyResolver) ResolveDID(_ context.Context, did string) (*DIDDocument, error) {
+	fingerprint, ok := strings.CutPrefix(did, "did:key:")
+	if !ok {
+		return nil, fmt.Errorf("%q is not a did:key", did)
+	}
+	key, err := decodeMultikey(fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", did, err)
+	}
+	jwk, err := NewJWK("", key)
+	if err != nil {
+		return nil, err
+	}
+	method := DIDVerificationMethod{ID: did + "#" + fingerprint, Type: "JsonWebKey2020", Controller: did, PublicKeyJWK: &jwk}
+	ref := []DIDVerificationMethod{{ID: method.ID}}
+	return &DIDDocument{
+		Context:            []string{"https://www.w3.org/ns/did/v1"},
+		ID:                 did,
+		VerificationMethod: []DIDVerificationMethod{method},
+		Authentication:     ref,
+		AssertionMethod:    ref,
+	}, nil
+}
+
+// NewDIDKey returns the did:key of an ed25519.PublicKey or a P-256
+// *ecdsa.PublicKey, and the ID of its verification method, to use as
+// KeyID in a JWSSigner or JWTSigner
+func NewDIDKey(key crypto.PublicKey) (did, keyID string, err error) {
+	var multikey []byte
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		multikey = append([]byte{0xed, 0x01}, key...)
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", "", fmt.Errorf("unsupported did:key curve %s", key.Curve.Params().Name)
+		}
+		multikey = append([]byte{0x80, 0x24}, elliptic.MarshalCompressed(key.Curve, key.X, key.Y)...)
+	default:
+		return "", "", fmt.Errorf("unsupported did:key key %T", key)
+	}
+	fingerprint := "z" + base58Encode(multikey)
+	return "did:key:" + fingerprint, "did:key:" + fingerprint + "#" + fingerprint, nil
+}
+
+// decodeMultikey decodes a base58btc multibase Ed25519 or P-256 public key
+func decodeMultikey(multibase string) (crypto.PublicKey, error) {
+	encoded, ok := strings.CutPrefix(multibase, "z")
+	if !ok {
+		return nil, errors.New("key is not base58btc multibase")
+	}
+	multikey, err := base58Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(multikey) == 2+ed25519.PublicKeySize && multikey[0] == 0xed && multikey[1] == 0x01:
+		return ed25519.PublicKey(multikey[2:]), nil
+	case len(multikey) == 2+33 && multikey[0] == 0x80 && multikey[1] == 0x24:
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), multikey[2:])
+		if x == nil {
+			return nil, errors.New("invalid P-256 key")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, errors.New("unsupported multikey type")
+}
+
+// DIDWebResolver resolves did:web DIDs by fetching their document over
+// HTTPS: did:web:example.com from https://example.com/.well-known/did.json
+// and did:web:example.com:agents:billing from
+// https://example.com/agents/billing/did.json
+type DIDWebResolver struct {
+	Client *http.Client // nil means a client with a 10 second timeout
+}
+
+// ResolveDID implements DIDResolver
+func (r *DIDWebResolver) ResolveDID(ctx context.Context, did string) (*DIDDocument, error) {
+	docURL, err := didWebURL(did)
+	if err != nil {
+		return nil, err
+	}
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/did+json, application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", did, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("resolving %s: HTTP %d", did, resp.StatusCode)
+	}
+	var doc DIDDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", did, err)
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("resolving %s: document is for %s", did, doc.ID)
+	}
+	return &doc, nil
+}
+
+// didWebURL returns where a did:web's document is published
+func didWebURL(did string) (string, error) {
+	id, ok := strings.CutPrefix(did, "did:web:")
+	if !ok || id == "" {
+		return "", fmt.Errorf("%q is not a did:web", did)
+	}
+	segments := strings.Split(id, ":")
+	host, err := url.PathUnescape(segments[0]) // a port is written %3A
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("%q is not a did:web", did)
+	}
+	if len(segments) == 1 {
+		return "https://" + host + "/.well-known/did.json", nil
+	}
+	path := make([]string, len(segments)-1)
+	for i, segment := range segments[1:] {
+		if path[i], err = url.PathUnescape(segment); err != nil || path[i] == "" || path[i] == "." || path[i] == ".." {
+			return "", fmt.Errorf("%q is not a did:web", did)
+		}
+		path[i] = url.PathEscape(path[i])
+	}
+	return "https://" + host + "/" + strings.Join(path, "/") + "/did.json", nil
+}
+
+// WithDIDResolver sets how DID documents of senders whose agent ID is a
+// DID are resolved; the default is DefaultDIDResolver
+func WithDIDResolver(resolver DIDResolver) ServerOption {
+	return func(s *A2AServer) {
+		s.didResolver = resolver
+	}
+}
+
+// didKeys returns the keys of a DID's document
+func (s *A2AServer) didKeys(ctx context.Context, did string) (*JWKSet, error) {
+	resolver := s.didResolver
+	if resolver == nil {
+		resolver = DefaultDIDResolver
+	}
+	doc, err := resolver.ResolveDID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Keys(), nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	radix, mod := big.NewInt(58), new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n, radix := new(big.Int), big.NewInt(58)
+	for _, c := range []byte(s) {
+		digit := strings.IndexByte(base58Alphabet, c)
+		if digit < 0 {
+			return nil, errors.New("invalid base58")
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(digit)))
+	}
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,163 @@
+package directory
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AdminPath prefixes the directory's operator endpoints:
+//
+//	GET    /a2a/admin/registrations       every registration, including expired and unhealthy ones
+//	GET    /a2a/admin/registrations/{id}  one registration
+//	DELETE /a2a/admin/registrations/{id}  force-remove an agent
+//	GET    /a2a/admin/dump                the registry as a Dump
+//	POST   /a2a/admin/restore             load a Dump; ?replace=true first removes agents not in it
+const AdminPath = "/a2a/admin/"
+
+// Dump is a snapshot of the registry, as produced by the admin dump
+// endpoint and accepted by restore
+type Dump struct {
+	CreatedAt     time.Time      `json:"createdAt"`
+	Registrations []Registration `json:"registrations"`
+}
+
+// WithAdminToken enables the admin endpoints under AdminPath for requests
+// presenting token as a bearer token. Without it they answer 404.
+func WithAdminToken(token string) Option {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	if s.adminToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	presented := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(s.adminToken)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, AdminPath)
+	switch {
+	case path == "registrations" && r.Method == http.MethodGet:
+		s.adminList(w, r)
+	case strings.HasPrefix(path, "registrations/") && r.Method == http.MethodGet:
+		s.adminGet(w, r, strings.TrimPrefix(path, "registrations/"))
+	case strings.HasPrefix(path, "registrations/") && r.Method == http.MethodDelete:
+		s.adminDelete(w, r, strings.TrimPrefix(path, "registrations/"))
+	case path == "dump" && r.Method == http.MethodGet:
+		s.adminDump(w, r)
+	case path == "restore" && r.Method == http.MethodPost:
+		s.adminRestore(w, r)
+	case path == "registrations" || strings.HasPrefix(path, "registrations/") || path == "dump" || path == "restore":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) adminList(w http.ResponseWriter, r *http.Request) {
+	regs, err := s.store.List(r.Context())
+	if err != nil {
+		s.adminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]Registration{"registrations": regs})
+}
+
+func (s *Server) adminGet(w http.ResponseWriter, r *http.Request, agentID string) {
+	reg, err := s.store.Get(r.Context(), agentID)
+	if err != nil {
+		s.adminError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reg)
+}
+
+func (s *Server) adminDelete(w http.ResponseWriter, r *http.Request, agentID string) {
+	reg, err := s.store.Get(r.Context(), agentID)
+	if err == nil {
+		err = s.store.Delete(r.Context(), agentID)
+	}
+	if err != nil {
+		s.adminError(w, err)
+		return
+	}
+	s.logger.Info(fmt.Sprintf("🛠️ Admin removed agent: %s", agentID))
+	s.emit(EventDeregistered, reg.AgentInfo)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) adminDump(w http.ResponseWriter, r *http.Request) {
+	regs, err := s.store.List(r.Context())
+	if err != nil {
+		s.adminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="a2a-directory.json"`)
+	writeJSON(w, http.StatusOK, Dump{CreatedAt: time.Now().UTC(), Registrations: regs})
+}
+
+func (s *Server) adminRestore(w http.ResponseWriter, r *http.Request) {
+	var dump Dump
+	if err := json.NewDecoder(r.Body).Decode(&dump); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid dump"})
+		return
+	}
+
+	ctx := r.Context()
+	removed := 0
+	if r.URL.Query().Get("replace") == "true" {
+		keep := make(map[string]bool, len(dump.Registrations))
+		for _, reg := range dump.Registrations {
+			keep[reg.AgentID] = true
+		}
+		current, err := s.store.List(ctx)
+		if err != nil {
+			s.adminError(w, err)
+			return
+		}
+		for _, reg := range current {
+			if keep[reg.AgentID] {
+				continue
+			}
+			if err := s.store.Delete(ctx, reg.AgentID); err != nil && !errors.Is(err, ErrNotFound) {
+				s.adminError(w, err)
+				return
+			}
+			removed++
+		}
+	}
+
+	for _, reg := range dump.Registrations {
+		if reg.AgentID == "" || reg.Endpoint == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": errMissingFields.Error()})
+			return
+		}
+		if err := s.store.Put(ctx, reg); err != nil {
+			s.adminError(w, err)
+			return
+		}
+	}
+
+	s.logger.Info(fmt.Sprintf("🛠️ Admin restored %d agents, removed %d", len(dump.Registrations), removed))
+	writeJSON(w, http.StatusOK, map[string]int{"restored": len(dump.Registrations), "removed": removed})
+}
+
+func (s *Server) adminError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Agent not found"})
+		return
+	}
+	s.metrics.storeError("admin")
+	s.logger.Error("directory admin", "error", err)
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal error"})
+}
@@ -0,0 +1,112 @@
+package directory
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthorized is returned when a request may not change an agent's
+// registration
+var ErrUnauthorized = errors.New("directory: unauthorized")
+
+// Authenticator decides whether credential may register, deregister or
+// heartbeat agentID. credential is the request's bearer token, empty if
+// none was sent.
+type Authenticator interface {
+	Authenticate(ctx context.Context, agentID, credential string) error
+}
+
+// WithAuthenticator requires registrations, deregistrations and heartbeats
+// to pass auth. The directory also binds each agent ID to the credential
+// that registered it: until the registration is removed or expires, only
+// the same credential can change it, so one agent cannot take over another
+// agent's ID even when they share a token.
+func WithAuthenticator(auth Authenticator) Option {
+	return func(s *Server) {
+		s.auth = auth
+	}
+}
+
+// TokenAuthenticator accepts fixed bearer tokens
+type TokenAuthenticator struct {
+	// Tokens maps agent IDs to the token each must present. The "*" entry,
+	// if any, is accepted for agent IDs not listed.
+	Tokens map[string]string
+}
+
+// Authenticate implements Authenticator
+func (t TokenAuthenticator) Authenticate(ctx context.Context, agentID, credential string) error {
+	want, ok := t.Tokens[agentID]
+	if !ok {
+		want, ok = t.Tokens["*"]
+	}
+	if !ok || credential == "" || subtle.ConstantTimeCompare([]byte(credential), []byte(want)) != 1 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// HMACAuthenticator accepts tokens that are a signature of the agent ID
+// made with a shared secret, so operators can issue per-agent tokens with
+// AgentToken without configuring the directory for each agent
+type HMACAuthenticator struct {
+	Secret []byte
+}
+
+// Authenticate implements Authenticator
+func (h HMACAuthenticator) Authenticate(ctx context.Context, agentID, credential string) error {
+	if !hmac.Equal([]byte(credential), []byte(AgentToken(h.Secret, agentID))) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// AgentToken returns the token HMACAuthenticator accepts for agentID
+func AgentToken(secret []byte, agentID string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(agentID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type credentialKey struct{}
+
+// ContextWithCredential attaches the bearer token a caller presented, for
+// use with Register, Deregister and Heartbeat outside of HTTP handling
+func ContextWithCredential(ctx context.Context, credential string) context.Context {
+	return context.WithValue(ctx, credentialKey{}, credential)
+}
+
+// withCredential attaches the request's bearer token to ctx
+func withCredential(ctx context.Context, r *http.Request) context.Context {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ctx
+	}
+	return ContextWithCredential(ctx, strings.TrimPrefix(header, "Bearer "))
+}
+
+// authorize checks that the caller in ctx may change agentID, whose
+// current registration is existing (zero if none), and returns the owner
+// fingerprint to record
+func (s *Server) authorize(ctx context.Context, agentID string, existing Registration) (string, error) {
+	if s.auth == nil {
+		return "", nil
+	}
+	credential, _ := ctx.Value(credentialKey{}).(string)
+	if err := s.auth.Authenticate(ctx, agentID, credential); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(credential))
+	owner := hex.EncodeToString(sum[:])
+	if existing.Owner != "" && subtle.ConstantTimeCompare([]byte(existing.Owner), []byte(owner)) != 1 {
+		return "", ErrUnauthorized
+	}
+	return owner, nil
+}
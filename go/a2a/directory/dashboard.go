@@ -0,0 +1,183 @@
+package directory
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// DashboardPath is where WithDashboard serves the HTML dashboard
+const DashboardPath = "/a2a/dashboard"
+
+// recentQueryLimit is how many discovery queries the dashboard shows
+const recentQueryLimit = 50
+
+// WithDashboard serves a read-only HTML overview of the registry at
+// DashboardPath: agents, their capabilities and health, and recent
+// discovery queries. It is unauthenticated, so only enable it where the
+// registry contents are not sensitive or behind your own access control.
+func WithDashboard() Option {
+	return func(s *Server) {
+		s.dashboard = true
+	}
+}
+
+// discoveryQuery is one discovery request shown on the dashboard
+type discoveryQuery struct {
+	At           time.Time
+	Capabilities []string
+	Query        string
+	Results      int
+}
+
+// queryLog keeps the most recent discovery queries
+type queryLog struct {
+	mu      sync.Mutex
+	queries []discoveryQuery
+}
+
+func (l *queryLog) record(q discoveryQuery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queries = append(l.queries, q)
+	if len(l.queries) > recentQueryLimit {
+		l.queries = l.queries[len(l.queries)-recentQueryLimit:]
+	}
+}
+
+// recent returns the queries newest first
+func (l *queryLog) recent() []discoveryQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]discoveryQuery, len(l.queries))
+	for i, q := range l.queries {
+		out[len(out)-1-i] = q
+	}
+	return out
+}
+
+// dashboardAgent is one row of the dashboard's agent table
+type dashboardAgent struct {
+	Registration
+	Status        string
+	StatusClass   string
+	LastHeartbeat string
+	Expires       string
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if !s.dashboard {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	regs, err := s.store.List(r.Context())
+	if err != nil {
+		s.metrics.storeError("dashboard")
+		s.logger.Error("listing agents for dashboard", "error", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	agents := make([]dashboardAgent, len(regs))
+	capabilities := make(map[string]int)
+	for i, reg := range regs {
+		agent := dashboardAgent{Registration: reg, Status: "healthy", StatusClass: "ok", LastHeartbeat: ago(now, reg.LastHeartbeat), Expires: "never"}
+		switch {
+		case reg.expired(now):
+			agent.Status, agent.StatusClass = "expired", "bad"
+		case reg.HealthFailures > 0:
+			agent.Status, agent.StatusClass = "failing health checks", "warn"
+		case reg.Status == a2a.AgentOffline:
+			agent.Status, agent.StatusClass = "offline", "bad"
+		case reg.Status == a2a.AgentDraining, reg.Status == a2a.AgentBusy:
+			agent.Status, agent.StatusClass = string(reg.Status), "warn"
+		}
+		if !reg.ExpiresAt.IsZero() {
+			agent.Expires = reg.ExpiresAt.Sub(now).Round(time.Second).String()
+		}
+		agents[i] = agent
+		for _, capability := range reg.Capabilities {
+			capabilities[capability]++
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = dashboardTemplate.Execute(w, map[string]interface{}{
+		"Agents":       agents,
+		"Capabilities": capabilities,
+		"Queries":      s.queries.recent(),
+		"Now":          now.UTC().Format(time.RFC1123),
+	})
+	if err != nil {
+		s.logger.Error("rendering dashboard", "error", err)
+	}
+}
+
+// ago formats how long before now t was
+func ago(now, t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return now.Sub(t).Round(time.Second).String() + " ago"
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"time": func(t time.Time) string { return t.Format("15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="10">
+<title>A2A Directory</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+h1 { font-size: 1.4rem; }
+h2 { font-size: 1.1rem; margin-top: 2rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #ddd; vertical-align: top; }
+th { background: #f5f5f5; }
+.tag { display: inline-block; background: #eef; border-radius: 3px; padding: 0 .4rem; margin: 0 .2rem .2rem 0; font-size: .85rem; }
+.ok { color: #185c18; } .warn { color: #9a6700; } .bad { color: #a40e26; }
+.muted { color: #777; font-size: .85rem; }
+</style>
+</head>
+<body>
+<h1>🤖 A2A Directory</h1>
+<p class="muted">{{len .Agents}} agents · updated {{.Now}} · refreshes every 10s</p>
+
+<h2>Agents</h2>
+<table>
+<tr><th>Agent</th><th>Endpoint</th><th>Capabilities</th><th>Status</th><th>Last heartbeat</th><th>Expires in</th></tr>
+{{range .Agents}}<tr>
+<td><strong>{{.Name}}</strong><br><span class="muted">{{.AgentID}}</span>{{if .Description}}<br>{{.Description}}{{end}}{{if .Tags}}<br>{{range .Tags}}<span class="tag">#{{.}}</span>{{end}}{{end}}{{range $key, $value := .Metadata}}<br><span class="muted">{{$key}}: {{$value}}</span>{{end}}</td>
+<td>{{.Endpoint}}</td>
+<td>{{range .Capabilities}}<span class="tag">{{.}}</span>{{end}}</td>
+<td class="{{.StatusClass}}">{{.Status}}</td>
+<td>{{.LastHeartbeat}}</td>
+<td>{{.Expires}}</td>
+</tr>{{else}}<tr><td colspan="6" class="muted">No agents registered</td></tr>{{end}}
+</table>
+
+<h2>Capabilities</h2>
+<p>{{range $capability, $count := .Capabilities}}<span class="tag">{{$capability}} × {{$count}}</span>{{else}}<span class="muted">None</span>{{end}}</p>
+
+<h2>Recent discovery</h2>
+<table>
+<tr><th>Time</th><th>Capabilities</th><th>Query</th><th>Results</th></tr>
+{{range .Queries}}<tr><td>{{time .At}}</td><td>{{join .Capabilities ", "}}</td><td>{{.Query}}</td><td>{{.Results}}</td></tr>
+{{else}}<tr><td colspan="4" class="muted">No queries yet</td></tr>{{end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,902 @@
+// Package directory implements an A2A directory: the registry agents
+// register with and discover each other through.
+//
+// # Usage
+//
+//	dir := directory.NewServer()
+//	log.Fatal(dir.ListenAndServe(":8080"))
+//
+// Agents then use the directory URL with A2AAgent.Register, Discover and
+// SendTask. Both JSON-RPC requests (Go and TypeScript SDKs) and bare JSON
+// params (Python SDK) are accepted.
+//
+// # Expiry
+//
+// With WithTTL, registrations that are not renewed by a heartbeat within
+// their TTL stop being returned and are removed by the sweeper that
+// ListenAndServe runs (or RunSweeper, when serving Handler yourself). Agents
+// may ask for their own TTL when registering.
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Registration is an agent's entry in the directory
+type Registration struct {
+	a2a.AgentInfo
+	LastHeartbeat time.Time     `json:"lastHeartbeat"`
+	ExpiresAt     time.Time     `json:"expiresAt,omitempty"` // zero means the entry never expires
+	TTL           time.Duration `json:"ttlNs,omitempty"`     // how far each heartbeat pushes ExpiresAt out
+	// HealthFailures counts consecutive failed health checks; agents with
+	// any are not returned by discovery
+	HealthFailures int `json:"healthFailures,omitempty"`
+	// Version orders writes between replicated directories
+	Version int64 `json:"version,omitempty"`
+	// Owner fingerprints the credential that registered the agent, when
+	// the directory requires authentication
+	Owner string `json:"owner,omitempty"`
+}
+
+// clone returns a copy of reg that shares no slices with it
+func (reg Registration) clone() Registration {
+	reg.Capabilities = append([]string(nil), reg.Capabilities...)
+	reg.Tags = append([]string(nil), reg.Tags...)
+	if reg.Metadata != nil {
+		metadata := make(map[string]string, len(reg.Metadata))
+		for k, v := range reg.Metadata {
+			metadata[k] = v
+		}
+		reg.Metadata = metadata
+	}
+	reg.Schemas = maps.Clone(reg.Schemas)
+	return reg
+}
+
+// renew moves ExpiresAt one TTL past now
+func (reg *Registration) renew(now time.Time) {
+	if reg.TTL > 0 {
+		reg.ExpiresAt = now.Add(reg.TTL)
+	}
+}
+
+// visible reports whether reg should be returned by reads as of now
+func (reg Registration) visible(now time.Time) bool {
+	return !reg.expired(now) && reg.HealthFailures == 0
+}
+
+// expired reports whether reg has expired as of now
+func (reg Registration) expired(now time.Time) bool {
+	return !reg.ExpiresAt.IsZero() && !reg.ExpiresAt.After(now)
+}
+
+// Server is an HTTP A2A directory
+type Server struct {
+	store        RegistryStore
+	logger       *slog.Logger
+	ttl          time.Duration
+	sweep        time.Duration
+	health       *HealthCheck
+	scorer       Scorer
+	federation   *federationState
+	replication  *Replication
+	adminToken   string
+	dashboard    bool
+	auth         Authenticator
+	events       eventHub
+	proof        *EndpointProof
+	limits       *Limits
+	ipLimiter    *rateLimiter
+	tokenLimiter *rateLimiter
+	webhooks     *webhookRegistry
+	serveMetrics bool
+	metrics      serverMetrics
+	queries      queryLog
+}
+
+// defaultSweepInterval is how often ListenAndServe removes expired
+// registrations when WithTTL is not given a shorter TTL
+const defaultSweepInterval = 30 * time.Second
+
+// Option configures a directory Server
+type Option func(*Server)
+
+// WithLogger sets the logger used for directory events
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithStore sets where registrations are kept. The default is a MemoryStore.
+func WithStore(store RegistryStore) Option {
+	return func(s *Server) {
+		s.store = store
+	}
+}
+
+// WithTTL sets how long a registration lasts without a heartbeat when the
+// agent does not ask for a TTL itself. Zero, the default, keeps
+// registrations until they are deregistered.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.ttl = ttl
+	}
+}
+
+// WithSweepInterval sets how often ListenAndServe removes expired
+// registrations from the store. The default is 30s, or half the TTL if
+// that is shorter.
+func WithSweepInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.sweep = interval
+	}
+}
+
+// NewServer creates an empty directory
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		store:  NewMemoryStore(),
+		logger: slog.Default(),
+		scorer: TokenScorer{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.replication != nil {
+		s.store = newReplicatedStore(s)
+	}
+	if s.sweep <= 0 {
+		s.sweep = defaultSweepInterval
+		if s.ttl > 0 && s.ttl/2 < s.sweep {
+			s.sweep = s.ttl / 2
+		}
+	}
+	return s
+}
+
+// Handler returns the directory's HTTP handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/register", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/discover", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/deregister", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/heartbeat", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/report", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/webhooks/add", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/webhooks/remove", s.limit(s.handleRPC))
+	mux.HandleFunc("/a2a/agents", s.limit(s.handleListAgents))
+	mux.HandleFunc("/a2a/agents/", s.limit(s.handleGetAgent))
+	mux.HandleFunc(ReplicationPath, s.handleReplication)
+	mux.HandleFunc(AdminPath, s.handleAdmin)
+	mux.HandleFunc(DashboardPath, s.handleDashboard)
+	mux.HandleFunc(EventsPath, s.limit(s.handleEvents))
+	mux.HandleFunc(MetricsPath, s.handleMetrics)
+	return mux
+}
+
+// ListenAndServe serves the directory on addr, sweeping expired
+// registrations, running health checks, syncing replicas and delivering
+// webhooks in the background
+func (s *Server) ListenAndServe(addr string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.RunSweeper(ctx, s.sweep)
+	go s.RunHealthChecks(ctx)
+	go s.RunReplication(ctx)
+	go s.RunWebhooks(ctx)
+
+	s.logger.Info(fmt.Sprintf("🚀 A2A Directory running on %s", addr))
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// RunSweeper removes expired registrations every interval until ctx is
+// done. Expired entries are already hidden from reads; sweeping reclaims
+// their storage.
+func (s *Server) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Sweep(ctx); err != nil {
+			s.metrics.storeError("sweep")
+			s.logger.Error("sweeping expired registrations", "error", err)
+		}
+	}
+}
+
+// Sweep removes registrations that have expired
+func (s *Server) Sweep(ctx context.Context) error {
+	expired, err := s.store.Expire(ctx, time.Now().UTC())
+	for _, reg := range expired {
+		s.logger.Info(fmt.Sprintf("⌛ Expired agent: %s", reg.AgentID))
+		s.emit(EventExpired, reg.AgentInfo)
+	}
+	return err
+}
+
+// errMissingFields is returned when a registration lacks its ID or endpoint
+var errMissingFields = errors.New("agentId and endpoint are required")
+
+// errInvalidStatus is returned for heartbeats reporting an unknown status
+var errInvalidStatus = errors.New("invalid agent status")
+
+// errInvalidSchema is returned for registrations with schemas that are not
+// JSON objects or booleans, or that describe unregistered capabilities
+var errInvalidSchema = errors.New("invalid schema")
+
+// checkSchemas validates a registration's capability schemas
+func checkSchemas(params a2a.RegisterParams) error {
+	for capability, schema := range params.Schemas {
+		if !slices.Contains(params.Capabilities, capability) {
+			return fmt.Errorf("%w: %q is not a registered capability", errInvalidSchema, capability)
+		}
+		for _, raw := range []json.RawMessage{schema.Input, schema.Output} {
+			if raw == nil {
+				continue
+			}
+			var v interface{}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("%w: %q: %v", errInvalidSchema, capability, err)
+			}
+			switch v.(type) {
+			case map[string]interface{}, bool:
+			default:
+				return fmt.Errorf("%w: %q: a schema must be an object or a boolean", errInvalidSchema, capability)
+			}
+		}
+	}
+	return nil
+}
+
+// Register adds or replaces an agent's registration
+func (s *Server) Register(ctx context.Context, params a2a.RegisterParams) (*a2a.RegisterResult, error) {
+	if params.AgentID == "" || params.Endpoint == "" {
+		return nil, errMissingFields
+	}
+	if err := checkSchemas(params); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.Get(ctx, params.AgentID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	reputation := existing.Reputation // survives re-registration so it cannot be reset
+	if existing.expired(time.Now()) {
+		existing = Registration{}
+	}
+	owner, err := s.authorize(ctx, params.AgentID, existing)
+	if err != nil {
+		s.logger.Warn(fmt.Sprintf("🚫 Rejected registration of %s", params.AgentID), "endpoint", params.Endpoint)
+		return nil, err
+	}
+	if err := s.verifyProof(ctx, params, existing); err != nil {
+		s.logger.Warn(fmt.Sprintf("🚫 Rejected unproven registration of %s", params.AgentID), "endpoint", params.Endpoint)
+		return nil, err
+	}
+	if err := s.checkRegistration(ctx, params, existing, owner); err != nil {
+		s.logger.Warn(fmt.Sprintf("🚫 Rejected registration of %s", params.AgentID), "error", err)
+		return nil, err
+	}
+
+	ttl := s.ttl
+	if params.TTL > 0 {
+		ttl = time.Duration(params.TTL) * time.Second
+	}
+
+	now := time.Now().UTC()
+	reg := Registration{
+		AgentInfo: a2a.AgentInfo{
+			AgentID:      params.AgentID,
+			Name:         params.Name,
+			Capabilities: params.Capabilities,
+			Endpoint:     params.Endpoint,
+			Description:  params.Description,
+			Tags:         params.Tags,
+			Metadata:     params.Metadata,
+			Region:       params.Region,
+			Zone:         params.Zone,
+			Reputation:   reputation,
+			PublicKey:    params.PublicKey,
+			RegisteredAt: now,
+			Schemas:      params.Schemas,
+			Status:       a2a.AgentOnline,
+			LastSeen:     now,
+		},
+		LastHeartbeat: now,
+		TTL:           ttl,
+		Owner:         owner,
+	}
+	reg.renew(now)
+	if err := s.store.Put(ctx, reg); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(fmt.Sprintf("📋 Registered agent: %s (%s)", params.AgentID, params.Name))
+	s.emit(EventRegistered, reg.AgentInfo)
+	return &a2a.RegisterResult{
+		Status:  "registered",
+		AgentID: params.AgentID,
+		TTL:     int((ttl + time.Second - 1) / time.Second),
+	}, nil
+}
+
+// Deregister removes an agent, returning ErrNotFound if it is not registered
+func (s *Server) Deregister(ctx context.Context, agentID string) error {
+	reg, err := s.store.Get(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.authorize(ctx, agentID, reg); err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, agentID); err != nil {
+		return err
+	}
+
+	s.logger.Info(fmt.Sprintf("👋 Deregistered agent: %s", agentID))
+	s.emit(EventDeregistered, reg.AgentInfo)
+	return nil
+}
+
+// Heartbeat records that an agent is alive and extends its registration by
+// its TTL, returning ErrNotFound if it is not registered or has expired
+func (s *Server) Heartbeat(ctx context.Context, agentID string) error {
+	return s.HeartbeatWithLoad(ctx, agentID, nil)
+}
+
+// HeartbeatWithLoad is Heartbeat that also records the agent's load. A nil
+// load keeps the last one reported.
+func (s *Server) HeartbeatWithLoad(ctx context.Context, agentID string, load *a2a.Load) error {
+	return s.HeartbeatWithStatus(ctx, agentID, "", load)
+}
+
+// HeartbeatWithStatus is HeartbeatWithLoad that also records the agent's
+// status. An empty status keeps the last one reported. Agents that are
+// draining or offline are not returned by discovery, but can still be
+// looked up by ID.
+func (s *Server) HeartbeatWithStatus(ctx context.Context, agentID string, status a2a.AgentStatus, load *a2a.Load) error {
+	if status != "" && !status.Valid() {
+		return fmt.Errorf("%w: %q", errInvalidStatus, status)
+	}
+	reg, err := s.store.Get(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if reg.expired(now) {
+		return ErrNotFound
+	}
+	if _, err := s.authorize(ctx, agentID, reg); err != nil {
+		return err
+	}
+	reg.LastHeartbeat = now
+	reg.LastSeen = now
+	reg.renew(now)
+	if load != nil {
+		reg.Load = load
+	}
+	changed := status != "" && status != reg.Status
+	if status != "" {
+		reg.Status = status
+	}
+	if err := s.store.Put(ctx, reg); err != nil {
+		return err
+	}
+	if changed {
+		s.logger.Info(fmt.Sprintf("🚦 Agent %s is %s", agentID, status))
+		s.emit(EventStatusChanged, reg.AgentInfo)
+	}
+	return nil
+}
+
+// Discover returns the agents offering any of the wanted capabilities:
+// local agents ordered by ID, followed by any found through federation
+func (s *Server) Discover(ctx context.Context, wanted []string) ([]a2a.AgentInfo, error) {
+	return s.Find(ctx, a2a.DiscoverParams{Capabilities: wanted})
+}
+
+// Find runs a discovery request: agents offering any of params.Capabilities
+// (or any agent, if none are given) that carry all of params.Tags and
+// params.Metadata. With params.Query they are ranked as by Search;
+// otherwise local agents come ordered by ID, followed by any found through
+// federation.
+func (s *Server) Find(ctx context.Context, params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	defer s.metrics.timeDiscovery(time.Now())
+	if params.Query != "" {
+		return s.search(ctx, params)
+	}
+
+	matches, err := s.candidates(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	matches = s.withPeers(ctx, matches, params)
+	matches = applyReputation(matches, params)
+	matches = applyLocality(matches, params)
+	if params.LeastLoaded {
+		matches = leastLoaded(nearest(matches, params))
+	}
+
+	s.queries.record(discoveryQuery{At: time.Now(), Capabilities: params.Capabilities, Results: len(matches)})
+	s.logger.Info(fmt.Sprintf("🔍 Discovery: wanted %v, found %d agents", params.Capabilities, len(matches)))
+	return matches, nil
+}
+
+// Filter applies a discovery request to a fixed list of agents, as Find
+// does to the registry, for discovery providers that have no directory
+// behind them. params.Query is ignored.
+func Filter(agents []a2a.AgentInfo, params a2a.DiscoverParams) []a2a.AgentInfo {
+	matches := []a2a.AgentInfo{}
+	for _, agent := range agents {
+		if agent.Status.Available() && (len(params.Capabilities) == 0 || hasAny(agent.Capabilities, params.Capabilities)) &&
+			hasAll(agent.Tags, params.Tags) && hasMetadata(agent.Metadata, params.Metadata) {
+			matches = append(matches, agent)
+		}
+	}
+	matches = applyReputation(matches, params)
+	matches = applyLocality(matches, params)
+	if params.LeastLoaded {
+		matches = leastLoaded(nearest(matches, params))
+	}
+	return matches
+}
+
+// candidates returns the local agents matching params, ignoring the query
+func (s *Server) candidates(ctx context.Context, params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	var agents []a2a.AgentInfo
+	var err error
+	if len(params.Capabilities) > 0 {
+		agents, err = s.discover(ctx, params.Capabilities)
+	} else {
+		agents, err = s.Agents(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matches := agents[:0]
+	for _, agent := range agents {
+		if agent.Status.Available() && hasAll(agent.Tags, params.Tags) && hasMetadata(agent.Metadata, params.Metadata) {
+			matches = append(matches, agent)
+		}
+	}
+	return matches, nil
+}
+
+// discover finds local agents offering any of the wanted capabilities.
+// Stores implementing CapabilityIndex are queried per capability; others
+// are scanned.
+func (s *Server) discover(ctx context.Context, wanted []string) ([]a2a.AgentInfo, error) {
+	index, ok := s.store.(CapabilityIndex)
+	if !ok {
+		return s.scan(ctx, wanted)
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	matches := []a2a.AgentInfo{}
+	for _, capability := range wanted {
+		regs, err := index.AgentsWithCapability(ctx, CapabilityName(capability))
+		if err != nil {
+			return nil, err
+		}
+		for _, reg := range regs {
+			if !seen[reg.AgentID] && reg.visible(now) && hasAny(reg.Capabilities, []string{capability}) {
+				seen[reg.AgentID] = true
+				matches = append(matches, reg.AgentInfo)
+			}
+		}
+	}
+	if len(wanted) > 1 {
+		sort.Slice(matches, func(i, j int) bool { return matches[i].AgentID < matches[j].AgentID })
+	}
+
+	return matches, nil
+}
+
+// scan discovers agents by checking every registration
+func (s *Server) scan(ctx context.Context, wanted []string) ([]a2a.AgentInfo, error) {
+	agents, err := s.Agents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []a2a.AgentInfo{}
+	for _, agent := range agents {
+		if hasAny(agent.Capabilities, wanted) {
+			matches = append(matches, agent)
+		}
+	}
+
+	return matches, nil
+}
+
+// Agent returns one registered agent, asking federated peers if it is not
+// registered here, or ErrNotFound
+func (s *Server) Agent(ctx context.Context, agentID string) (a2a.AgentInfo, error) {
+	reg, err := s.get(ctx, agentID)
+	if errors.Is(err, ErrNotFound) {
+		return s.peerAgent(ctx, agentID)
+	}
+	if err != nil {
+		return a2a.AgentInfo{}, err
+	}
+	return reg.AgentInfo, nil
+}
+
+// get returns a registration, treating one that has expired but not yet
+// been swept, or is failing its health checks, as missing
+func (s *Server) get(ctx context.Context, agentID string) (Registration, error) {
+	reg, err := s.store.Get(ctx, agentID)
+	if err != nil {
+		return Registration{}, err
+	}
+	if !reg.visible(time.Now()) {
+		return Registration{}, ErrNotFound
+	}
+	return reg, nil
+}
+
+// Agents returns all registered agents ordered by ID
+func (s *Server) Agents(ctx context.Context) ([]a2a.AgentInfo, error) {
+	regs, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	agents := make([]a2a.AgentInfo, 0, len(regs))
+	for _, reg := range regs {
+		if reg.visible(now) {
+			agents = append(agents, reg.AgentInfo)
+		}
+	}
+	return agents, nil
+}
+
+// applyLocality orders agents in the caller's zone first, then its region,
+// then the rest, dropping those outside it if params requires
+func applyLocality(agents []a2a.AgentInfo, params a2a.DiscoverParams) []a2a.AgentInfo {
+	if params.Region == "" && params.Zone == "" {
+		return agents
+	}
+	kept := agents[:0]
+	for _, agent := range agents {
+		d := distance(agent, params)
+		if (params.RequireZone && d > 0) || (params.RequireRegion && d > 1) {
+			continue
+		}
+		kept = append(kept, agent)
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return distance(kept[i], params) < distance(kept[j], params) })
+	return kept
+}
+
+// distance is 0 for an agent in the caller's zone, 1 in its region and 2
+// elsewhere
+func distance(agent a2a.AgentInfo, params a2a.DiscoverParams) int {
+	switch {
+	case params.Zone != "" && agent.Zone == params.Zone:
+		return 0
+	case params.Region != "" && agent.Region == params.Region:
+		return 1
+	}
+	return 2
+}
+
+// nearest returns the leading agents as close to the caller as the first,
+// so load balancing never trades a local agent for a remote one
+func nearest(agents []a2a.AgentInfo, params a2a.DiscoverParams) []a2a.AgentInfo {
+	for i := range agents {
+		if distance(agents[i], params) != distance(agents[0], params) {
+			return agents[:i]
+		}
+	}
+	return agents
+}
+
+// leastLoaded returns the agent with the lowest reported load, choosing at
+// random among ties so callers do not all pile onto one agent between
+// heartbeats. Agents that have not reported a load rank after those that
+// have.
+func leastLoaded(agents []a2a.AgentInfo) []a2a.AgentInfo {
+	rank := func(agent a2a.AgentInfo) int {
+		if agent.Load == nil {
+			return math.MaxInt
+		}
+		return agent.Load.Total()
+	}
+
+	var best []a2a.AgentInfo
+	for _, agent := range agents {
+		switch {
+		case len(best) == 0 || rank(agent) < rank(best[0]):
+			best = []a2a.AgentInfo{agent}
+		case rank(agent) == rank(best[0]):
+			best = append(best, agent)
+		}
+	}
+	if len(best) == 0 {
+		return best
+	}
+	return []a2a.AgentInfo{best[rand.IntN(len(best))]}
+}
+
+func hasAll(tags, wanted []string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func hasMetadata(metadata, wanted map[string]string) bool {
+	for key, value := range wanted {
+		if have, ok := metadata[key]; !ok || have != value {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAny(capabilities, wanted []string) bool {
+	for _, want := range wanted {
+		for _, capability := range capabilities {
+			if MatchCapability(capability, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rpcRequest is a directory request. Requests without a jsonrpc member are
+// bare params as sent by the Python SDK.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      a2a.RequestID   `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &a2a.JSONRPCError{Code: a2a.CodeParseError, Message: "Parse error"},
+		})
+		return
+	}
+
+	var req rpcRequest
+	json.Unmarshal(body, &req)
+	bare := req.JSONRPC == ""
+	if bare {
+		req.Params = body
+		req.Method = strings.TrimPrefix(r.URL.Path, "/")
+	}
+	if req.Method == "" {
+		req.Method = strings.TrimPrefix(r.URL.Path, "/")
+	}
+
+	ctx := withCredential(withVia(r.Context(), r), r)
+	result, rpcErr := s.dispatch(ctx, req.Method, req.Params)
+
+	if bare {
+		if rpcErr != nil {
+			status := http.StatusBadRequest
+			switch rpcErr.Code {
+			case CodeUnauthorized:
+				status = http.StatusUnauthorized
+			case CodeLimitExceeded:
+				status = http.StatusTooManyRequests
+			}
+			writeJSON(w, status, map[string]string{"error": rpcErr.Message})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	resp := a2a.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	if rpcErr == nil {
+		resp.Result, _ = json.Marshal(result)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// dispatch runs a directory method
+func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (interface{}, *a2a.JSONRPCError) {
+	switch method {
+	case "a2a/register":
+		var p a2a.RegisterParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		result, err := s.Register(ctx, p)
+		if err != nil {
+			return nil, s.rpcError(err)
+		}
+		return result, nil
+
+	case "a2a/discover":
+		var p a2a.DiscoverParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		if len(p.Capabilities) == 0 && len(p.Tags) == 0 && len(p.Metadata) == 0 && p.Query == "" {
+			return nil, &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: "No capabilities specified"}
+		}
+		agents, err := s.Find(ctx, p)
+		if err != nil {
+			return nil, s.rpcError(err)
+		}
+		return a2a.DiscoverResult{Agents: agents}, nil
+
+	case "a2a/deregister":
+		var p a2a.DeregisterParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		if err := s.Deregister(ctx, p.AgentID); err != nil {
+			return nil, s.rpcError(err)
+		}
+		return a2a.RegisterResult{Status: "deregistered", AgentID: p.AgentID}, nil
+
+	case "a2a/report":
+		var p a2a.ReportParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		return s.handleReport(ctx, p)
+
+	case "a2a/heartbeat":
+		var p a2a.HeartbeatParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		if err := s.HeartbeatWithStatus(ctx, p.AgentID, p.Status, p.Load); err != nil {
+			return nil, s.rpcError(err)
+		}
+		return a2a.RegisterResult{Status: "alive", AgentID: p.AgentID}, nil
+
+	case "a2a/webhooks/add":
+		var p a2a.WebhookParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		id, err := s.AddWebhook(ctx, p)
+		if err != nil {
+			return nil, s.rpcError(err)
+		}
+		return a2a.WebhookResult{ID: id}, nil
+
+	case "a2a/webhooks/remove":
+		var p a2a.WebhookResult
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams()
+		}
+		if err := s.RemoveWebhook(ctx, p.ID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, &a2a.JSONRPCError{Code: CodeNotFound, Message: "Webhook not found"}
+			}
+			return nil, s.rpcError(err)
+		}
+		return a2a.WebhookResult{ID: p.ID}, nil
+	}
+
+	return nil, &a2a.JSONRPCError{Code: a2a.CodeMethodNotFound, Message: "Method not found"}
+}
+
+func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	agents, err := s.Agents(r.Context())
+	if err != nil {
+		s.metrics.storeError("list")
+		s.logger.Error("listing agents", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, a2a.DiscoverResult{Agents: agents})
+}
+
+func (s *Server) handleGetAgent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	agentID := strings.TrimPrefix(r.URL.Path, "/a2a/agents/")
+	agent, err := s.Agent(withVia(r.Context(), r), agentID)
+	if errors.Is(err, ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Agent not found"})
+		return
+	}
+	if err != nil {
+		s.metrics.storeError("get")
+		s.logger.Error("getting agent", "agentId", agentID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, agent)
+}
+
+func invalidParams() *a2a.JSONRPCError {
+	return &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: "Invalid params"}
+}
+
+// Error codes of the directory's methods besides the JSON-RPC ones in
+// package a2a
+const (
+	CodeNotFound      = -32002 // no such agent or webhook
+	CodeUnauthorized  = -32005 // bad credentials or unproven endpoint ownership
+	CodeLimitExceeded = -32006 // rate limit, registry size or webhook cap
+)
+
+// rpcError maps a directory error to a JSON-RPC error, logging store
+// failures
+func (s *Server) rpcError(err error) *a2a.JSONRPCError {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return &a2a.JSONRPCError{Code: CodeNotFound, Message: "Agent not found"}
+	case errors.Is(err, ErrUnproven):
+		return &a2a.JSONRPCError{Code: CodeUnauthorized, Message: "Endpoint ownership not proven"}
+	case errors.Is(err, ErrUnauthorized):
+		return &a2a.JSONRPCError{Code: CodeUnauthorized, Message: "Unauthorized"}
+	case errors.Is(err, ErrTooManyAgents):
+		return &a2a.JSONRPCError{Code: CodeLimitExceeded, Message: "Registration limit reached"}
+	case errors.Is(err, errTooManyWebhooks):
+		return &a2a.JSONRPCError{Code: CodeLimitExceeded, Message: "Webhook limit reached"}
+	case errors.Is(err, errWebhooksDisabled):
+		return &a2a.JSONRPCError{Code: a2a.CodeMethodNotFound, Message: "Method not found"}
+	case errors.Is(err, errMissingFields), errors.Is(err, ErrInvalidCapability), errors.Is(err, errInvalidWebhook),
+		errors.Is(err, errInvalidSchema), errors.Is(err, errInvalidStatus):
+		return &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: err.Error()}
+	}
+	s.metrics.storeError("rpc")
+	s.logger.Error("directory store", "error", err)
+	return &a2a.JSONRPCError{Code: a2a.CodeInternalError, Message: "Internal error"}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,260 @@
+// Package etcdstore implements a directory RegistryStore on etcd, so
+// directory replicas share one consistent registry.
+//
+//	client, _ := clientv3.New(clientv3.Config{Endpoints: []string{"localhost:2379"}})
+//	dir := directory.NewServer(directory.WithStore(etcdstore.New(client, "/a2a")))
+//
+// Each registration is stored as JSON under <prefix>/agents/<id>, with one
+// <prefix>/cap/<capability>/<id> key per capability as an index. A
+// registration with an ExpiresAt is attached to an etcd lease of the same
+// length, so an agent that stops heartbeating is evicted by etcd itself,
+// index keys included, even when no directory is running. Watch streams
+// changes so replicas can react to each other's writes.
+package etcdstore
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Store is an etcd-backed directory.RegistryStore
+type Store struct {
+	client *clientv3.Client
+	prefix string
+}
+
+// New creates a store using client, placing all keys under prefix
+func New(client *clientv3.Client, prefix string) *Store {
+	return &Store{client: client, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (s *Store) agentsPrefix() string           { return s.prefix + "/agents/" }
+func (s *Store) agentKey(agentID string) string { return s.agentsPrefix() + agentID }
+func (s *Store) capabilityPrefix(capability string) string {
+	return s.prefix + "/cap/" + directory.CapabilityName(capability) + "/"
+}
+
+// Put adds or replaces a registration. A registration with an ExpiresAt is
+// given a fresh lease; the lease it held before is revoked.
+func (s *Store) Put(ctx context.Context, reg directory.Registration) error {
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+
+	previous, oldLease, err := s.get(ctx, reg.AgentID)
+	if err != nil && err != directory.ErrNotFound {
+		return err
+	}
+
+	var opts []clientv3.OpOption
+	var lease clientv3.LeaseID
+	if !reg.ExpiresAt.IsZero() {
+		ttl := int64(math.Ceil(time.Until(reg.ExpiresAt).Seconds()))
+		if ttl < 1 {
+			ttl = 1
+		}
+		grant, err := s.client.Grant(ctx, ttl)
+		if err != nil {
+			return err
+		}
+		lease = grant.ID
+		opts = append(opts, clientv3.WithLease(lease))
+	}
+
+	ops := []clientv3.Op{clientv3.OpPut(s.agentKey(reg.AgentID), string(data), opts...)}
+	for _, capability := range previous.Capabilities {
+		ops = append(ops, clientv3.OpDelete(s.capabilityPrefix(capability)+reg.AgentID))
+	}
+	for _, capability := range reg.Capabilities {
+		ops = append(ops, clientv3.OpPut(s.capabilityPrefix(capability)+reg.AgentID, reg.AgentID, opts...))
+	}
+	if _, err := s.client.Txn(ctx).Then(dedupeOps(ops)...).Commit(); err != nil {
+		return err
+	}
+
+	if oldLease != clientv3.NoLease && oldLease != lease {
+		s.client.Revoke(ctx, oldLease)
+	}
+	return nil
+}
+
+// Get returns the registration for agentID
+func (s *Store) Get(ctx context.Context, agentID string) (directory.Registration, error) {
+	reg, _, err := s.get(ctx, agentID)
+	return reg, err
+}
+
+func (s *Store) get(ctx context.Context, agentID string) (directory.Registration, clientv3.LeaseID, error) {
+	resp, err := s.client.Get(ctx, s.agentKey(agentID))
+	if err != nil {
+		return directory.Registration{}, clientv3.NoLease, err
+	}
+	if len(resp.Kvs) == 0 {
+		return directory.Registration{}, clientv3.NoLease, directory.ErrNotFound
+	}
+
+	kv := resp.Kvs[0]
+	var reg directory.Registration
+	if err := json.Unmarshal(kv.Value, &reg); err != nil {
+		return directory.Registration{}, clientv3.NoLease, err
+	}
+	return reg, clientv3.LeaseID(kv.Lease), nil
+}
+
+// List returns all registrations ordered by agent ID
+func (s *Store) List(ctx context.Context) ([]directory.Registration, error) {
+	resp, err := s.client.Get(ctx, s.agentsPrefix(), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	regs := make([]directory.Registration, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var reg directory.Registration
+		if err := json.Unmarshal(kv.Value, &reg); err != nil {
+			return nil, err
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+// AgentsWithCapability returns the registrations offering capability, read
+// through the capability index rather than by scanning every agent
+func (s *Store) AgentsWithCapability(ctx context.Context, capability string) ([]directory.Registration, error) {
+	resp, err := s.client.Get(ctx, s.capabilityPrefix(capability), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	regs := make([]directory.Registration, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		reg, err := s.Get(ctx, string(kv.Value))
+		if err == directory.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+// Delete removes the registration for agentID
+func (s *Store) Delete(ctx context.Context, agentID string) error {
+	reg, lease, err := s.get(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, reg, lease)
+}
+
+func (s *Store) remove(ctx context.Context, reg directory.Registration, lease clientv3.LeaseID) error {
+	ops := []clientv3.Op{clientv3.OpDelete(s.agentKey(reg.AgentID))}
+	for _, capability := range reg.Capabilities {
+		ops = append(ops, clientv3.OpDelete(s.capabilityPrefix(capability)+reg.AgentID))
+	}
+	if _, err := s.client.Txn(ctx).Then(dedupeOps(ops)...).Commit(); err != nil {
+		return err
+	}
+	if lease != clientv3.NoLease {
+		s.client.Revoke(ctx, lease)
+	}
+	return nil
+}
+
+// Expire removes registrations that have expired as of now but whose lease
+// etcd has not yet reclaimed. Leases are rounded up to whole seconds, so
+// this only matters to directories that sweep more finely than that.
+func (s *Store) Expire(ctx context.Context, now time.Time) ([]directory.Registration, error) {
+	regs, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []directory.Registration
+	for _, reg := range regs {
+		if reg.ExpiresAt.IsZero() || reg.ExpiresAt.After(now) {
+			continue
+		}
+		_, lease, err := s.get(ctx, reg.AgentID)
+		if err == directory.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		if err := s.remove(ctx, reg, lease); err != nil {
+			return expired, err
+		}
+		expired = append(expired, reg)
+	}
+	return expired, nil
+}
+
+// EventType says whether an agent was registered or removed
+type EventType int
+
+const (
+	// EventPut is an agent registering or renewing
+	EventPut EventType = iota
+	// EventDelete is an agent deregistering or its lease expiring
+	EventDelete
+)
+
+// Event is one change to the registry
+type Event struct {
+	Type    EventType
+	AgentID string
+	// Registration is the new registration for EventPut, zero for EventDelete
+	Registration directory.Registration
+}
+
+// Watch streams registry changes made by any directory using the same etcd
+// prefix until ctx is done
+func (s *Store) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for resp := range s.client.Watch(ctx, s.agentsPrefix(), clientv3.WithPrefix()) {
+			for _, ev := range resp.Events {
+				event := Event{AgentID: strings.TrimPrefix(string(ev.Kv.Key), s.agentsPrefix())}
+				if ev.Type == clientv3.EventTypeDelete {
+					event.Type = EventDelete
+				} else if err := json.Unmarshal(ev.Kv.Value, &event.Registration); err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
+
+// dedupeOps drops repeated operations on the same key, which etcd rejects
+// within one transaction, keeping the last
+func dedupeOps(ops []clientv3.Op) []clientv3.Op {
+	last := make(map[string]int, len(ops))
+	for i, op := range ops {
+		last[string(op.KeyBytes())] = i
+	}
+	out := make([]clientv3.Op, 0, len(last))
+	for i, op := range ops {
+		if last[string(op.KeyBytes())] == i {
+			out = append(out, op)
+		}
+	}
+	return out
+}
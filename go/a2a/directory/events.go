@@ -0,0 +1,121 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// EventsPath streams registry events as Server-Sent Events
+const EventsPath = "/a2a/events"
+
+// EventType names a registry change
+type EventType string
+
+// Registry event types
+const (
+	EventRegistered    EventType = "registered"   // an agent registered or re-registered
+	EventDeregistered  EventType = "deregistered" // an agent deregistered or an operator removed it
+	EventExpired       EventType = "expired"      // a registration was swept after its TTL
+	EventUnhealthy     EventType = "unhealthy"    // an agent failed a health check and is hidden from discovery
+	EventHealthy       EventType = "healthy"      // an unhealthy agent passed a health check again
+	EventRemoved       EventType = "removed"      // an agent was removed after repeated failed health checks
+	EventStatusChanged EventType = "status"       // an agent reported a new status, such as draining
+)
+
+// Event is a change to the registry made by this directory
+type Event struct {
+	Type  EventType     `json:"type"`
+	Agent a2a.AgentInfo `json:"agent"`
+	At    time.Time     `json:"at"`
+}
+
+// eventBufferSize is how many events a slow subscriber may fall behind by
+// before further events are dropped for it
+const eventBufferSize = 64
+
+// eventHub fans events out to subscribers
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// Subscribe returns a channel receiving registry events until ctx is done.
+// Events a subscriber is too slow to receive are dropped. Changes made by
+// replicas or peers are not reported.
+func (s *Server) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, eventBufferSize)
+	s.events.mu.Lock()
+	if s.events.subscribers == nil {
+		s.events.subscribers = make(map[chan Event]struct{})
+	}
+	s.events.subscribers[ch] = struct{}{}
+	s.events.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.events.mu.Lock()
+		delete(s.events.subscribers, ch)
+		s.events.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+// emit sends an event to every subscriber without blocking
+func (s *Server) emit(eventType EventType, agent a2a.AgentInfo) {
+	event := Event{Type: eventType, Agent: agent, At: time.Now().UTC()}
+	s.metrics.event(eventType)
+	s.notifyWebhooks(event)
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	for ch := range s.events.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// handleEvents streams events as Server-Sent Events, one "event: <type>"
+// with the Event as JSON data per change, plus a comment every 15s to keep
+// idle connections open
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events := s.Subscribe(r.Context())
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,231 @@
+package directory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// FederationHeader lists, comma separated, the IDs of the directories a
+// federated request has already passed through
+const FederationHeader = "X-A2A-Federation-Via"
+
+// Federation configures peer directories that discovery fans out to
+type Federation struct {
+	// ID names this directory in FederationHeader; it must be unique
+	// among federated directories. Empty generates one.
+	ID string
+	// Peers are the base URLs of the peer directories
+	Peers []string
+	// MaxHops limits how many directories a request may pass through;
+	// zero means 3
+	MaxHops int
+	// CacheTTL is how long peer answers are reused; zero means 30s and a
+	// negative value disables caching
+	CacheTTL time.Duration
+	// Client sends requests to peers; nil means a client with a 5s timeout
+	Client *http.Client
+}
+
+// WithFederation makes Discover, Search and agent lookups also consult
+// peer directories when run for a client. Local agents come first and take
+// precedence over a peer's agent with the same ID. Peers that fail are
+// logged and skipped. Requests carry FederationHeader so that cycles of
+// peers do not loop.
+func WithFederation(federation Federation) Option {
+	return func(s *Server) {
+		if federation.ID == "" {
+			federation.ID = fmt.Sprintf("dir-%d", time.Now().UnixNano())
+		}
+		if federation.MaxHops <= 0 {
+			federation.MaxHops = 3
+		}
+		if federation.CacheTTL == 0 {
+			federation.CacheTTL = 30 * time.Second
+		}
+		if federation.Client == nil {
+			federation.Client = &http.Client{Timeout: 5 * time.Second}
+		}
+		s.federation = &federationState{Federation: federation, cache: make(map[string]peerAnswer)}
+	}
+}
+
+type federationState struct {
+	Federation
+	mu    sync.Mutex
+	cache map[string]peerAnswer
+}
+
+type peerAnswer struct {
+	agents  []a2a.AgentInfo
+	expires time.Time
+}
+
+type viaKey struct{}
+
+// withVia records the directories an incoming request has passed through
+func withVia(ctx context.Context, r *http.Request) context.Context {
+	header := r.Header.Get(FederationHeader)
+	var via []string
+	for _, id := range strings.Split(header, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			via = append(via, id)
+		}
+	}
+	return context.WithValue(ctx, viaKey{}, via)
+}
+
+// forwardVia returns the FederationHeader value for requests to peers, or
+// false if the request must not be forwarded
+func (s *Server) forwardVia(ctx context.Context) (string, bool) {
+	if s.federation == nil {
+		return "", false
+	}
+	via, _ := ctx.Value(viaKey{}).([]string)
+	if len(via) >= s.federation.MaxHops {
+		return "", false
+	}
+	for _, id := range via {
+		if id == s.federation.ID {
+			return "", false
+		}
+	}
+	return strings.Join(append(via, s.federation.ID), ","), true
+}
+
+// withPeers appends the agents peers return for params to local, skipping
+// IDs already present
+func (s *Server) withPeers(ctx context.Context, local []a2a.AgentInfo, params a2a.DiscoverParams) []a2a.AgentInfo {
+	via, ok := s.forwardVia(ctx)
+	if !ok {
+		return local
+	}
+
+	seen := make(map[string]bool, len(local))
+	for _, agent := range local {
+		seen[agent.AgentID] = true
+	}
+	for _, agent := range s.askPeers(ctx, via, params) {
+		if !seen[agent.AgentID] {
+			seen[agent.AgentID] = true
+			local = append(local, agent)
+		}
+	}
+	return local
+}
+
+// askPeers runs a discovery on every peer concurrently, reusing cached
+// answers, and returns the results in peer order
+func (s *Server) askPeers(ctx context.Context, via string, params a2a.DiscoverParams) []a2a.AgentInfo {
+	f := s.federation
+	key, _ := json.Marshal(params)
+
+	f.mu.Lock()
+	if answer, ok := f.cache[string(key)]; ok && time.Now().Before(answer.expires) {
+		f.mu.Unlock()
+		return answer.agents
+	}
+	f.mu.Unlock()
+
+	answers := make([][]a2a.AgentInfo, len(f.Peers))
+	var wg sync.WaitGroup
+	for i, peer := range f.Peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			agents, err := s.discoverPeer(ctx, peer, via, params)
+			if err != nil {
+				s.logger.Warn("federated discovery failed", "peer", peer, "error", err)
+				return
+			}
+			answers[i] = agents
+		}(i, peer)
+	}
+	wg.Wait()
+
+	var agents []a2a.AgentInfo
+	for _, answer := range answers {
+		agents = append(agents, answer...)
+	}
+	if f.CacheTTL > 0 {
+		f.mu.Lock()
+		f.cache[string(key)] = peerAnswer{agents: agents, expires: time.Now().Add(f.CacheTTL)}
+		f.mu.Unlock()
+	}
+	return agents
+}
+
+func (s *Server) discoverPeer(ctx context.Context, peer, via string, params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	body, err := json.Marshal(a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      a2a.StringID(fmt.Sprintf("fed-%d", time.Now().UnixNano())),
+		Method:  "a2a/discover",
+		Params:  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(peer, "/")+"/a2a/discover", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(FederationHeader, via)
+
+	resp, err := s.federation.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp a2a.JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, err
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("%s", rpcResp.Error.Message)
+	}
+	var result a2a.DiscoverResult
+	if err := json.Unmarshal(rpcResp.Result, &result); err != nil {
+		return nil, err
+	}
+	return result.Agents, nil
+}
+
+// peerAgent looks agentID up on each peer in turn
+func (s *Server) peerAgent(ctx context.Context, agentID string) (a2a.AgentInfo, error) {
+	via, ok := s.forwardVia(ctx)
+	if !ok {
+		return a2a.AgentInfo{}, ErrNotFound
+	}
+
+	for _, peer := range s.federation.Peers {
+		target := strings.TrimSuffix(peer, "/") + "/a2a/agents/" + url.PathEscape(agentID)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			continue
+		}
+		req.Header.Set(FederationHeader, via)
+
+		resp, err := s.federation.Client.Do(req)
+		if err != nil {
+			s.logger.Warn("federated agent lookup failed", "peer", peer, "error", err)
+			continue
+		}
+		var agent a2a.AgentInfo
+		err = json.NewDecoder(resp.Body).Decode(&agent)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && err == nil {
+			return agent, nil
+		}
+	}
+	return a2a.AgentInfo{}, ErrNotFound
+}
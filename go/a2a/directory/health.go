@@ -0,0 +1,165 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// HealthCheck configures active probing of registered agents
+type HealthCheck struct {
+	// Interval between rounds of probes; zero means 30s
+	Interval time.Duration
+	// Timeout for each probe; zero means 5s
+	Timeout time.Duration
+	// MaxFailures is how many consecutive failed probes remove an agent.
+	// Zero means 3. An agent is hidden from discovery after its first
+	// failure and shown again when a probe succeeds.
+	MaxFailures int
+	// Concurrency limits simultaneous probes; zero means 16
+	Concurrency int
+	// Client sends the probes; nil means a client with Timeout
+	Client *http.Client
+}
+
+// WithHealthCheck makes ListenAndServe probe every registered agent's
+// health endpoint (a2a.HealthPath on the endpoint's host) each interval.
+// Agents that cannot be reached, time out or answer 5xx are hidden from
+// discovery and, after MaxFailures consecutive failures, removed. A 404
+// counts as healthy, since agents are not required to serve the path.
+func WithHealthCheck(check HealthCheck) Option {
+	return func(s *Server) {
+		if check.Interval <= 0 {
+			check.Interval = 30 * time.Second
+		}
+		if check.Timeout <= 0 {
+			check.Timeout = 5 * time.Second
+		}
+		if check.MaxFailures <= 0 {
+			check.MaxFailures = 3
+		}
+		if check.Concurrency <= 0 {
+			check.Concurrency = 16
+		}
+		if check.Client == nil {
+			check.Client = &http.Client{Timeout: check.Timeout}
+		}
+		s.health = &check
+	}
+}
+
+// RunHealthChecks probes registered agents every interval until ctx is
+// done. It does nothing unless the server was created WithHealthCheck.
+func (s *Server) RunHealthChecks(ctx context.Context) {
+	if s.health == nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.health.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.CheckHealth(ctx); err != nil {
+			s.metrics.storeError("health")
+			s.logger.Error("checking agent health", "error", err)
+		}
+	}
+}
+
+// CheckHealth probes every registered agent once and records the results
+func (s *Server) CheckHealth(ctx context.Context) error {
+	if s.health == nil {
+		return nil
+	}
+
+	regs, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, s.health.Concurrency)
+	var wg sync.WaitGroup
+	for _, reg := range regs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(reg Registration) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.recordHealth(ctx, reg.AgentID, s.probe(ctx, reg.Endpoint))
+		}(reg)
+	}
+	wg.Wait()
+	return nil
+}
+
+// probe reports whether the agent at endpoint answered its health check
+func (s *Server) probe(ctx context.Context, endpoint string) error {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	target.Path = a2a.HealthPath
+	target.RawQuery = ""
+
+	ctx, cancel := context.WithTimeout(ctx, s.health.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.health.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// recordHealth updates an agent's failure count after a probe, removing it
+// once it reaches MaxFailures. The registration is re-read so a concurrent
+// re-registration is not overwritten.
+func (s *Server) recordHealth(ctx context.Context, agentID string, probeErr error) {
+	reg, err := s.store.Get(ctx, agentID)
+	if err != nil {
+		return
+	}
+
+	if probeErr == nil {
+		if reg.HealthFailures == 0 {
+			return
+		}
+		reg.HealthFailures = 0
+		s.logger.Info(fmt.Sprintf("💚 Agent healthy again: %s", agentID))
+		s.emit(EventHealthy, reg.AgentInfo)
+	} else {
+		reg.HealthFailures++
+		if reg.HealthFailures >= s.health.MaxFailures {
+			if err := s.store.Delete(ctx, agentID); err == nil {
+				s.logger.Info(fmt.Sprintf("💀 Removed unresponsive agent: %s", agentID), "error", probeErr)
+				s.emit(EventRemoved, reg.AgentInfo)
+			}
+			return
+		}
+		s.logger.Warn(fmt.Sprintf("🩺 Health check failed for %s (%d/%d)", agentID, reg.HealthFailures, s.health.MaxFailures), "error", probeErr)
+		if reg.HealthFailures == 1 {
+			s.emit(EventUnhealthy, reg.AgentInfo)
+		}
+	}
+
+	if err := s.store.Put(ctx, reg); err != nil {
+		s.metrics.storeError("health")
+		s.logger.Error("recording agent health", "agentId", agentID, "error", err)
+	}
+}
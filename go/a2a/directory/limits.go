@@ -0,0 +1,220 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// ErrTooManyAgents is returned when a new registration would exceed
+// Limits.MaxAgents or Limits.MaxAgentsPerOwner
+var ErrTooManyAgents = errors.New("directory: registration limit reached")
+
+// ErrInvalidCapability is returned for registrations with malformed or too
+// many capabilities when Limits.ValidateCapabilities is set
+var ErrInvalidCapability = errors.New("directory: invalid capability")
+
+// Limits protects the directory from misconfigured or hostile clients
+// flooding the registry
+type Limits struct {
+	// RequestsPerIP is the sustained request rate, per second, allowed
+	// from one client address; zero means unlimited
+	RequestsPerIP float64
+	// RequestsPerToken is the same for each bearer token, applied on top
+	// of the per-address limit; zero means unlimited
+	RequestsPerToken float64
+	// Burst is how many requests a client may make at once above its
+	// rate; zero means the rate rounded up, at least 1
+	Burst int
+	// TrustProxy takes the client address from the first entry of
+	// X-Forwarded-For, for directories behind a reverse proxy
+	TrustProxy bool
+	// MaxRequestBytes bounds request bodies; zero means 1 MiB
+	MaxRequestBytes int64
+
+	// MaxAgents caps the number of registered agents; zero means
+	// unlimited. Re-registering an existing ID is always allowed.
+	MaxAgents int
+	// MaxAgentsPerOwner caps the agents registered with one credential;
+	// zero means unlimited. It only applies WithAuthenticator.
+	MaxAgentsPerOwner int
+
+	// ValidateCapabilities rejects registrations whose capabilities are
+	// not names of letters, digits and ._:/- up to 128 bytes, optionally
+	// followed by @ and a semantic version, or that offer more than
+	// MaxCapabilities of them
+	ValidateCapabilities bool
+	// MaxCapabilities per registration; zero means 64
+	MaxCapabilities int
+}
+
+// WithLimits sets rate limits, registration caps and capability
+// validation. Clients over their rate get HTTP 429 with a Retry-After
+// header and JSON-RPC error -32006.
+func WithLimits(limits Limits) Option {
+	return func(s *Server) {
+		if limits.MaxRequestBytes <= 0 {
+			limits.MaxRequestBytes = 1 << 20
+		}
+		if limits.MaxCapabilities <= 0 {
+			limits.MaxCapabilities = 64
+		}
+		s.limits = &limits
+		s.ipLimiter = newRateLimiter(limits.RequestsPerIP, limits.Burst)
+		s.tokenLimiter = newRateLimiter(limits.RequestsPerToken, limits.Burst)
+	}
+}
+
+// limit wraps a handler with the request size and rate limits
+func (s *Server) limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.limits == nil {
+			next(w, r)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, s.limits.MaxRequestBytes)
+
+		now := time.Now()
+		wait := s.ipLimiter.allow(s.clientIP(r), now)
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && wait == 0 {
+			wait = s.tokenLimiter.allow(token, now)
+		}
+		if wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeJSON(w, http.StatusTooManyRequests, a2a.JSONRPCResponse{
+				JSONRPC: "2.0",
+				Error:   &a2a.JSONRPCError{Code: CodeLimitExceeded, Message: "Rate limit exceeded"},
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) clientIP(r *http.Request) string {
+	if s.limits.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// capabilityPattern matches a capability name with an optional version
+var capabilityPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/-]{0,127}(@[0-9A-Za-z.+-]{1,64})?$`)
+
+// checkRegistration enforces capability validation and registration caps
+// on a registration of a new or existing agent, owned by owner
+func (s *Server) checkRegistration(ctx context.Context, params a2a.RegisterParams, existing Registration, owner string) error {
+	if s.limits == nil {
+		return nil
+	}
+	if s.limits.ValidateCapabilities {
+		if len(params.Capabilities) > s.limits.MaxCapabilities {
+			return fmt.Errorf("%w: more than %d capabilities", ErrInvalidCapability, s.limits.MaxCapabilities)
+		}
+		for _, capability := range params.Capabilities {
+			_, version, versioned := strings.Cut(capability, "@")
+			if !capabilityPattern.MatchString(capability) {
+				return fmt.Errorf("%w: %q", ErrInvalidCapability, capability)
+			}
+			if _, ok := parseVersion(version); versioned && !ok {
+				return fmt.Errorf("%w: %q has an invalid version", ErrInvalidCapability, capability)
+			}
+		}
+	}
+
+	if existing.AgentID != "" || (s.limits.MaxAgents <= 0 && (s.limits.MaxAgentsPerOwner <= 0 || owner == "")) {
+		return nil
+	}
+	regs, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	if s.limits.MaxAgents > 0 && len(regs) >= s.limits.MaxAgents {
+		return ErrTooManyAgents
+	}
+	if s.limits.MaxAgentsPerOwner > 0 && owner != "" {
+		owned := 0
+		for _, reg := range regs {
+			if reg.Owner == owner {
+				owned++
+			}
+		}
+		if owned >= s.limits.MaxAgentsPerOwner {
+			return ErrTooManyAgents
+		}
+	}
+	return nil
+}
+
+// rateLimiter is a token bucket per client key
+type rateLimiter struct {
+	rate  float64 // tokens per second; zero disables the limiter
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = max(int(math.Ceil(rate)), 1)
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket)}
+}
+
+// allow takes a token for key, returning zero if one was available or how
+// long until one will be
+func (l *rateLimiter) allow(key string, now time.Time) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget clients idle long enough for their bucket to have refilled
+	if now.Sub(l.lastPrune) > time.Minute {
+		full := time.Duration(l.burst / l.rate * float64(time.Second))
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > full {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
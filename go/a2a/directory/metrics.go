@@ -0,0 +1,150 @@
+package directory
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MetricsPath is where WithMetrics serves Prometheus metrics
+const MetricsPath = "/metrics"
+
+// discoveryBuckets are the upper bounds, in seconds, of the discovery
+// latency histogram
+var discoveryBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+// WithMetrics serves registry size, registration, deregistration, expiry
+// and removal counts, discovery latency and store errors at MetricsPath in
+// the Prometheus text format. Like the dashboard it is unauthenticated.
+func WithMetrics() Option {
+	return func(s *Server) {
+		s.serveMetrics = true
+	}
+}
+
+// serverMetrics counts what the directory does. It is updated whether or
+// not metrics are served.
+type serverMetrics struct {
+	mu          sync.Mutex
+	events      map[EventType]uint64
+	storeErrors map[string]uint64
+	discovery   histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+func (m *serverMetrics) event(eventType EventType) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.events == nil {
+		m.events = make(map[EventType]uint64)
+	}
+	m.events[eventType]++
+}
+
+// storeError counts a failed store operation
+func (m *serverMetrics) storeError(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.storeErrors == nil {
+		m.storeErrors = make(map[string]uint64)
+	}
+	m.storeErrors[operation]++
+}
+
+// timeDiscovery records the latency of a discovery that began at start;
+// defer it
+func (m *serverMetrics) timeDiscovery(start time.Time) {
+	seconds := time.Since(start).Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.discovery.counts == nil {
+		m.discovery.counts = make([]uint64, len(discoveryBuckets)+1)
+	}
+	i, _ := slices.BinarySearch(discoveryBuckets, seconds)
+	m.discovery.counts[i]++
+	m.discovery.sum += seconds
+	m.discovery.count++
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !s.serveMetrics {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	healthy, unhealthy := 0, 0
+	regs, err := s.store.List(r.Context())
+	if err != nil {
+		s.metrics.storeError("metrics")
+		s.logger.Error("listing agents for metrics", "error", err)
+	}
+	for _, reg := range regs {
+		if reg.HealthFailures > 0 {
+			unhealthy++
+		} else {
+			healthy++
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err == nil {
+		metric(w, "a2a_directory_agents", "gauge", "Registered agents by health.")
+		fmt.Fprintf(w, "a2a_directory_agents{health=\"healthy\"} %d\n", healthy)
+		fmt.Fprintf(w, "a2a_directory_agents{health=\"unhealthy\"} %d\n", unhealthy)
+	}
+
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	for _, counter := range []struct {
+		name, help string
+		event      EventType
+	}{
+		{"a2a_directory_registrations_total", "Registrations and re-registrations accepted.", EventRegistered},
+		{"a2a_directory_deregistrations_total", "Agents deregistered by themselves or an operator.", EventDeregistered},
+		{"a2a_directory_expired_agents_total", "Registrations swept after their TTL.", EventExpired},
+		{"a2a_directory_removed_agents_total", "Agents removed after repeated failed health checks.", EventRemoved},
+	} {
+		metric(w, counter.name, "counter", counter.help)
+		fmt.Fprintf(w, "%s %d\n", counter.name, s.metrics.events[counter.event])
+	}
+
+	metric(w, "a2a_directory_discovery_duration_seconds", "histogram", "Time taken to answer discovery requests.")
+	var cumulative uint64
+	for i, bound := range discoveryBuckets {
+		if s.metrics.discovery.counts != nil {
+			cumulative += s.metrics.discovery.counts[i]
+		}
+		fmt.Fprintf(w, "a2a_directory_discovery_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "a2a_directory_discovery_duration_seconds_bucket{le=\"+Inf\"} %d\n", s.metrics.discovery.count)
+	fmt.Fprintf(w, "a2a_directory_discovery_duration_seconds_sum %g\n", s.metrics.discovery.sum)
+	fmt.Fprintf(w, "a2a_directory_discovery_duration_seconds_count %d\n", s.metrics.discovery.count)
+
+	metric(w, "a2a_directory_store_errors_total", "counter", "Failed registry store operations by what the directory was doing.")
+	operations := make([]string, 0, len(s.metrics.storeErrors))
+	for operation := range s.metrics.storeErrors {
+		operations = append(operations, operation)
+	}
+	slices.Sort(operations)
+	for _, operation := range operations {
+		fmt.Fprintf(w, "a2a_directory_store_errors_total{operation=%q} %d\n", operation, s.metrics.storeErrors[operation])
+	}
+}
+
+// metric writes the HELP and TYPE lines of a metric family
+func metric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
@@ -0,0 +1,146 @@
+package directory
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// ErrUnproven is returned when a registration does not prove its endpoint
+// ownership. It is an ErrUnauthorized.
+var ErrUnproven = fmt.Errorf("%w: endpoint ownership not proven", ErrUnauthorized)
+
+// EndpointProof configures checks that registrants control the endpoints
+// they register, so one agent cannot register an endpoint it doesn't own
+type EndpointProof struct {
+	// Required rejects registrations without a public key and signature.
+	// Otherwise only signed registrations are checked.
+	Required bool
+	// Challenge has the directory call a2a/verify on the endpoint of each
+	// signed registration with a random nonce and accept it only if the
+	// endpoint answers with a signature from the same key
+	Challenge bool
+	// Timeout for each challenge; zero means 5s
+	Timeout time.Duration
+	// Client sends challenges; nil means a client with Timeout
+	Client *http.Client
+	// DIDResolver resolves the DID documents of agents whose ID is a DID,
+	// whose registrations must always be signed with a key their document
+	// lists; nil means a2a.DefaultDIDResolver
+	DIDResolver a2a.DIDResolver
+}
+
+// WithEndpointProof makes the directory verify registration signatures
+// (a2a.RegistrationPayload signed with the registration's PublicKey) and,
+// with Challenge, that the endpoint holds the same key. Once an agent ID
+// has registered with a key, re-registrations must be signed with it too,
+// except for DIDs, whose DID document says which keys may sign.
+func WithEndpointProof(proof EndpointProof) Option {
+	return func(s *Server) {
+		if proof.Timeout <= 0 {
+			proof.Timeout = 5 * time.Second
+		}
+		if proof.Client == nil {
+			proof.Client = &http.Client{Timeout: proof.Timeout}
+		}
+		if proof.DIDResolver == nil {
+			proof.DIDResolver = a2a.DefaultDIDResolver
+		}
+		s.proof = &proof
+	}
+}
+
+// verifyProof checks a registration's signature and endpoint against the
+// key it was signed with, which must be the key of the existing
+// registration if it had one, or for a DID, a key of its DID document
+func (s *Server) verifyProof(ctx context.Context, params a2a.RegisterParams, existing Registration) error {
+	if s.proof == nil {
+		return nil
+	}
+	did := a2a.IsDID(params.AgentID)
+	if params.PublicKey == "" || params.Signature == "" {
+		if s.proof.Required || existing.PublicKey != "" || did {
+			return ErrUnproven
+		}
+		return nil
+	}
+	if existing.PublicKey != "" && existing.PublicKey != params.PublicKey && !did {
+		return ErrUnproven
+	}
+
+	key, err := a2a.DecodePublicKey(params.PublicKey)
+	if err != nil {
+		return ErrUnproven
+	}
+	if did {
+		doc, err := s.proof.DIDResolver.ResolveDID(ctx, params.AgentID)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("🔏 Could not resolve the DID document of %s", params.AgentID), "error", err)
+			return ErrUnproven
+		}
+		if !doc.HasKey(key) {
+			return ErrUnproven
+		}
+	}
+	if !a2a.VerifySignature(key, a2a.RegistrationPayload(params.AgentID, params.Endpoint), params.Signature) {
+		return ErrUnproven
+	}
+	if s.proof.Challenge {
+		if err := s.challenge(ctx, params.AgentID, params.Endpoint, key); err != nil {
+			s.logger.Warn(fmt.Sprintf("🔏 Endpoint challenge failed for %s", params.AgentID), "endpoint", params.Endpoint, "error", err)
+			return ErrUnproven
+		}
+	}
+	return nil
+}
+
+// challenge asks the endpoint to sign a fresh nonce for agentID
+func (s *Server) challenge(ctx context.Context, agentID, endpoint string, key ed25519.PublicKey) error {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	params := a2a.VerifyParams{AgentID: agentID, Nonce: base64.RawURLEncoding.EncodeToString(nonce)}
+	body, err := json.Marshal(a2a.JSONRPCRequest{JSONRPC: "2.0", ID: a2a.StringID("verify-" + params.Nonce), Method: "a2a/verify", Params: params})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.proof.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.proof.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+
+	var rpcResp a2a.JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("endpoint returned error: %s", rpcResp.Error.Message)
+	}
+	var result a2a.VerifyResult
+	if err := json.Unmarshal(rpcResp.Result, &result); err != nil {
+		return err
+	}
+	if !a2a.VerifySignature(key, a2a.ChallengePayload(agentID, params.Nonce), result.Signature) {
+		return fmt.Errorf("challenge signed with another key")
+	}
+	return nil
+}
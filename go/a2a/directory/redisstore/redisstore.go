@@ -0,0 +1,322 @@
+// Package redisstore implements a directory RegistryStore on Redis, so
+// several directory replicas can share registrations.
+//
+//	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	dir := directory.NewServer(directory.WithStore(redisstore.New(client, "a2a")))
+//
+// Each registration is stored as JSON under <prefix>:agent:<id> with a Redis
+// TTL matching its ExpiresAt, so expired agents disappear even if no
+// directory sweeps them. Set indexes map each capability to its agents, and
+// <prefix>:caps:<id>, which never expires, the capabilities each agent was
+// indexed under, so they can be unindexed once Redis has dropped the
+// registration itself. Writes watch the agent's keys and retry if another
+// replica changed them meanwhile.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxAttempts is how many times a write is tried while other clients keep
+// changing the keys it watches
+const maxAttempts = 10
+
+// Store is a Redis-backed directory.RegistryStore
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// New creates a store using client, namespacing all keys with prefix
+func New(client redis.UniversalClient, prefix string) *Store {
+	return &Store{client: client, prefix: prefix}
+}
+
+func (s *Store) agentKey(agentID string) string { return s.prefix + ":agent:" + agentID }
+func (s *Store) capabilityKey(capability string) string {
+	return s.prefix + ":cap:" + directory.CapabilityName(capability)
+}
+func (s *Store) capabilitiesKey(agentID string) string { return s.prefix + ":caps:" + agentID }
+func (s *Store) indexKey() string                      { return s.prefix + ":agents" }
+func (s *Store) expiryKey() string                     { return s.prefix + ":expiry" }
+
+// Put adds or replaces a registration and updates the capability indexes
+func (s *Store) Put(ctx context.Context, reg directory.Registration) error {
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+
+	return s.watch(ctx, reg.AgentID, func(tx *redis.Tx) error {
+		previous, err := s.indexedCapabilities(ctx, tx, reg.AgentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			key := s.agentKey(reg.AgentID)
+			pipe.Set(ctx, key, data, 0)
+			if reg.ExpiresAt.IsZero() {
+				pipe.ZRem(ctx, s.expiryKey(), reg.AgentID)
+			} else {
+				pipe.PExpireAt(ctx, key, reg.ExpiresAt)
+				pipe.ZAdd(ctx, s.expiryKey(), redis.Z{Score: float64(reg.ExpiresAt.UnixMilli()), Member: reg.AgentID})
+			}
+			pipe.SAdd(ctx, s.indexKey(), reg.AgentID)
+			for _, capability := range previous {
+				pipe.SRem(ctx, s.capabilityKey(capability), reg.AgentID)
+			}
+			pipe.Del(ctx, s.capabilitiesKey(reg.AgentID))
+			for _, capability := range reg.Capabilities {
+				pipe.SAdd(ctx, s.capabilityKey(capability), reg.AgentID)
+				pipe.SAdd(ctx, s.capabilitiesKey(reg.AgentID), capability)
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// watch runs fn in a transaction watching agentID's keys, trying again if
+// they change before it commits
+func (s *Store) watch(ctx context.Context, agentID string, fn func(*redis.Tx) error) error {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		err := s.client.Watch(ctx, fn, s.agentKey(agentID), s.capabilitiesKey(agentID))
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("redisstore: agent %s kept changing: %w", agentID, redis.TxFailedErr)
+}
+
+// indexedCapabilities returns the capabilities agentID is indexed under:
+// those recorded for it, and those of its registration if Redis still has
+// it, for agents put before capabilities were recorded
+func (s *Store) indexedCapabilities(ctx context.Context, tx *redis.Tx, agentID string) ([]string, error) {
+	capabilities, err := tx.SMembers(ctx, s.capabilitiesKey(agentID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	data, err := tx.Get(ctx, s.agentKey(agentID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return capabilities, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var reg directory.Registration
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, err
+	}
+	return append(capabilities, reg.Capabilities...), nil
+}
+
+// Get returns the registration for agentID
+func (s *Store) Get(ctx context.Context, agentID string) (directory.Registration, error) {
+	data, err := s.client.Get(ctx, s.agentKey(agentID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return directory.Registration{}, directory.ErrNotFound
+	}
+	if err != nil {
+		return directory.Registration{}, err
+	}
+
+	var reg directory.Registration
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return directory.Registration{}, err
+	}
+	return reg, nil
+}
+
+// List returns all registrations ordered by agent ID
+func (s *Store) List(ctx context.Context) ([]directory.Registration, error) {
+	ids, err := s.client.SMembers(ctx, s.indexKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	return s.load(ctx, ids)
+}
+
+// AgentsWithCapability returns the registrations offering capability, read
+// through the capability index rather than by scanning every agent. Agents
+// indexed under capability that no longer offer it are unindexed.
+func (s *Store) AgentsWithCapability(ctx context.Context, capability string) ([]directory.Registration, error) {
+	key := s.capabilityKey(capability)
+	ids, err := s.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	regs, err := s.load(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	offering := regs[:0]
+	for _, reg := range regs {
+		if s.offers(reg.Capabilities, key) {
+			offering = append(offering, reg)
+		} else {
+			// Best effort, like unindexing expired agents
+			s.unindex(ctx, reg.AgentID, key)
+		}
+	}
+	return offering, nil
+}
+
+// offers reports whether any of capabilities is indexed under key
+func (s *Store) offers(capabilities []string, key string) bool {
+	return slices.ContainsFunc(capabilities, func(capability string) bool { return s.capabilityKey(capability) == key })
+}
+
+// unindex removes agentID from the capability index key, unless it was put
+// again offering the capability
+func (s *Store) unindex(ctx context.Context, agentID, key string) error {
+	return s.watch(ctx, agentID, func(tx *redis.Tx) error {
+		capabilities, err := s.indexedCapabilities(ctx, tx, agentID)
+		if err != nil || s.offers(capabilities, key) {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SRem(ctx, key, agentID)
+			return nil
+		})
+		return err
+	})
+}
+
+// load fetches registrations by ID, skipping and unindexing those whose
+// keys Redis has already expired
+func (s *Store) load(ctx context.Context, ids []string) ([]directory.Registration, error) {
+	if len(ids) == 0 {
+		return []directory.Registration{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.agentKey(id)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	regs := make([]directory.Registration, 0, len(ids))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// Unindexing is best effort; whoever reads the agent next
+			// tries again
+			s.remove(ctx, ids[i], s.gone)
+			continue
+		}
+		var reg directory.Registration
+		if err := json.Unmarshal([]byte(data), &reg); err != nil {
+			return nil, err
+		}
+		regs = append(regs, reg)
+	}
+
+	sort.Slice(regs, func(i, j int) bool { return regs[i].AgentID < regs[j].AgentID })
+	return regs, nil
+}
+
+// Delete removes the registration for agentID
+func (s *Store) Delete(ctx context.Context, agentID string) error {
+	if _, err := s.Get(ctx, agentID); err != nil {
+		return err
+	}
+	_, err := s.remove(ctx, agentID, nil)
+	return err
+}
+
+// remove deletes agentID's registration and unindexes it, reporting
+// whether it did. Given stale, it keeps registrations stale does not
+// report as still due for removal, e.g. because they were put again.
+func (s *Store) remove(ctx context.Context, agentID string, stale func(context.Context, *redis.Tx, string) (bool, error)) (bool, error) {
+	removed := false
+	err := s.watch(ctx, agentID, func(tx *redis.Tx) error {
+		removed = false
+		if stale != nil {
+			if ok, err := stale(ctx, tx, agentID); err != nil || !ok {
+				return err
+			}
+		}
+		capabilities, err := s.indexedCapabilities(ctx, tx, agentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, s.agentKey(agentID), s.capabilitiesKey(agentID))
+			pipe.SRem(ctx, s.indexKey(), agentID)
+			pipe.ZRem(ctx, s.expiryKey(), agentID)
+			for _, capability := range capabilities {
+				pipe.SRem(ctx, s.capabilityKey(capability), agentID)
+			}
+			return nil
+		})
+		removed = err == nil
+		return err
+	})
+	return removed, err
+}
+
+// gone reports whether Redis has dropped agentID's registration
+func (s *Store) gone(ctx context.Context, tx *redis.Tx, agentID string) (bool, error) {
+	n, err := tx.Exists(ctx, s.agentKey(agentID)).Result()
+	return n == 0, err
+}
+
+// expiredBy returns a func reporting whether an agent's registration
+// expires at or before now, rather than later or never
+func (s *Store) expiredBy(now time.Time) func(context.Context, *redis.Tx, string) (bool, error) {
+	return func(ctx context.Context, tx *redis.Tx, agentID string) (bool, error) {
+		expiresAt, err := tx.ZScore(ctx, s.expiryKey(), agentID).Result()
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return expiresAt <= float64(now.UnixMilli()), err
+	}
+}
+
+// Expire removes registrations that have expired as of now. Entries Redis
+// has already evicted are returned with only their agent ID set. Agents put
+// again meanwhile are kept.
+func (s *Store) Expire(ctx context.Context, now time.Time) ([]directory.Registration, error) {
+	ids, err := s.client.ZRangeByScore(ctx, s.expiryKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([]directory.Registration, 0, len(ids))
+	for _, id := range ids {
+		reg, err := s.Get(ctx, id)
+		if errors.Is(err, directory.ErrNotFound) {
+			reg = directory.Registration{}
+			reg.AgentID = id
+		} else if err != nil {
+			return expired, err
+		}
+		removed, err := s.remove(ctx, id, s.expiredBy(now))
+		if err != nil {
+			return expired, err
+		}
+		if removed {
+			expired = append(expired, reg)
+		}
+	}
+	return expired, nil
+}
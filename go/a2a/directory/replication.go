@@ -0,0 +1,313 @@
+package directory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReplicationPath is where directory replicas exchange registrations
+const ReplicationPath = "/a2a/replication"
+
+// Replication configures gossip between directory replicas that each keep
+// their own store. Replicas sharing one backend (Redis, etcd, SQL) do not
+// need it.
+type Replication struct {
+	// Peers are the base URLs of the other replicas
+	Peers []string
+	// Interval between full state exchanges with every peer, which repair
+	// any pushes that were lost; zero means 30s
+	Interval time.Duration
+	// Token, if set, must be presented by peers as a bearer token
+	Token string
+	// Client sends requests to peers; nil means a client with a 5s timeout
+	Client *http.Client
+}
+
+// WithReplication makes the directory push every change to its peers and
+// periodically pull their full state. Replicas converge on the most
+// recently written version of each registration (last writer wins, by the
+// writer's clock), and a deregistration beats any older write.
+// Registrations expire on every replica independently, as ExpiresAt is
+// replicated with them.
+func WithReplication(replication Replication) Option {
+	return func(s *Server) {
+		if replication.Interval <= 0 {
+			replication.Interval = 30 * time.Second
+		}
+		if replication.Client == nil {
+			replication.Client = &http.Client{Timeout: 5 * time.Second}
+		}
+		s.replication = &replication
+	}
+}
+
+// tombstoneTTL is how long a replica remembers a deregistration so that
+// older copies arriving from peers do not resurrect it
+const tombstoneTTL = 10 * time.Minute
+
+// replicaState is a replica's full state, as exchanged between peers
+type replicaState struct {
+	Registrations []Registration   `json:"registrations"`
+	Deleted       map[string]int64 `json:"deleted,omitempty"` // agent ID → version of the deregistration
+}
+
+// replicatedStore versions every local write, pushes it to peers and
+// applies writes received from them
+type replicatedStore struct {
+	RegistryStore
+	config *Replication
+	server *Server
+
+	mu         sync.Mutex
+	tombstones map[string]int64
+}
+
+func newReplicatedStore(s *Server) *replicatedStore {
+	return &replicatedStore{
+		RegistryStore: s.store,
+		config:        s.replication,
+		server:        s,
+		tombstones:    make(map[string]int64),
+	}
+}
+
+// Put versions reg and replicates it
+func (r *replicatedStore) Put(ctx context.Context, reg Registration) error {
+	reg.Version = time.Now().UnixNano()
+	if err := r.RegistryStore.Put(ctx, reg); err != nil {
+		return err
+	}
+	r.push(replicaState{Registrations: []Registration{reg}})
+	return nil
+}
+
+// Delete removes agentID and replicates the deregistration
+func (r *replicatedStore) Delete(ctx context.Context, agentID string) error {
+	if err := r.RegistryStore.Delete(ctx, agentID); err != nil {
+		return err
+	}
+	version := time.Now().UnixNano()
+	r.mu.Lock()
+	r.tombstones[agentID] = version
+	r.mu.Unlock()
+	r.push(replicaState{Deleted: map[string]int64{agentID: version}})
+	return nil
+}
+
+// AgentsWithCapability uses the wrapped store's index if it has one
+func (r *replicatedStore) AgentsWithCapability(ctx context.Context, capability string) ([]Registration, error) {
+	if index, ok := r.RegistryStore.(CapabilityIndex); ok {
+		return index.AgentsWithCapability(ctx, capability)
+	}
+	regs, err := r.RegistryStore.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches := []Registration{}
+	for _, reg := range regs {
+		if hasAny(reg.Capabilities, []string{CapabilityName(capability)}) {
+			matches = append(matches, reg)
+		}
+	}
+	return matches, nil
+}
+
+// apply merges state received from a peer, keeping whichever version of
+// each registration is newest
+func (r *replicatedStore) apply(ctx context.Context, state replicaState) error {
+	now := time.Now()
+	r.mu.Lock()
+	for id, version := range r.tombstones {
+		if now.Sub(time.Unix(0, version)) > tombstoneTTL {
+			delete(r.tombstones, id)
+		}
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for id, version := range state.Deleted {
+		current, err := r.RegistryStore.Get(ctx, id)
+		if err == nil && current.Version <= version {
+			err = r.RegistryStore.Delete(ctx, id)
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+		r.mu.Lock()
+		if version > r.tombstones[id] {
+			r.tombstones[id] = version
+		}
+		r.mu.Unlock()
+	}
+
+	for _, reg := range state.Registrations {
+		if reg.expired(now) {
+			continue
+		}
+		r.mu.Lock()
+		deleted := r.tombstones[reg.AgentID] >= reg.Version
+		r.mu.Unlock()
+		if deleted {
+			continue
+		}
+		current, err := r.RegistryStore.Get(ctx, reg.AgentID)
+		if err == nil && current.Version >= reg.Version {
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		if err := r.RegistryStore.Put(ctx, reg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// state returns everything this replica holds
+func (r *replicatedStore) state(ctx context.Context) (replicaState, error) {
+	regs, err := r.RegistryStore.List(ctx)
+	if err != nil {
+		return replicaState{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deleted := make(map[string]int64, len(r.tombstones))
+	for id, version := range r.tombstones {
+		deleted[id] = version
+	}
+	return replicaState{Registrations: regs, Deleted: deleted}, nil
+}
+
+// push sends a change to every peer in the background
+func (r *replicatedStore) push(change replicaState) {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return
+	}
+	for _, peer := range r.config.Peers {
+		go func(peer string) {
+			req, err := r.request(context.Background(), http.MethodPost, peer, bytes.NewReader(body))
+			if err != nil {
+				return
+			}
+			resp, err := r.config.Client.Do(req)
+			if err != nil {
+				r.server.logger.Warn("replicating to peer", "peer", peer, "error", err)
+				return
+			}
+			resp.Body.Close()
+		}(peer)
+	}
+}
+
+// pull fetches and applies a peer's full state
+func (r *replicatedStore) pull(ctx context.Context, peer string) error {
+	req, err := r.request(ctx, http.MethodGet, peer, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer returned %s", resp.Status)
+	}
+
+	var state replicaState
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return err
+	}
+	return r.apply(ctx, state)
+}
+
+func (r *replicatedStore) request(ctx context.Context, method, peer string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(peer, "/")+ReplicationPath, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.Token)
+	}
+	return req, nil
+}
+
+// RunReplication exchanges full state with every peer each interval until
+// ctx is done. It does nothing unless the server was created
+// WithReplication.
+func (s *Server) RunReplication(ctx context.Context) {
+	replica, ok := s.store.(*replicatedStore)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(replica.config.Interval)
+	defer ticker.Stop()
+	for {
+		for _, peer := range replica.config.Peers {
+			if err := replica.pull(ctx, peer); err != nil {
+				s.logger.Warn("syncing with peer", "peer", peer, "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleReplication answers peers: GET returns this replica's state and
+// POST applies a peer's changes
+func (s *Server) handleReplication(w http.ResponseWriter, r *http.Request) {
+	replica, ok := s.store.(*replicatedStore)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if token := replica.config.Token; token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		state, err := replica.state(r.Context())
+		if err != nil {
+			s.metrics.storeError("replication")
+			s.logger.Error("reading replica state", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, state)
+
+	case http.MethodPost:
+		var state replicaState
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid replication state"})
+			return
+		}
+		if err := replica.apply(r.Context(), state); err != nil {
+			s.metrics.storeError("replication")
+			s.logger.Error("applying replicated changes", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal error"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
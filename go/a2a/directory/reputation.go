@@ -0,0 +1,107 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// errSelfReport is returned when an agent reports on its own tasks
+var errSelfReport = errors.New("agents cannot report on themselves")
+
+// reputationDecay is how much each existing report is discounted when a
+// new one arrives, so an agent's recent behaviour dominates its score
+const reputationDecay = 0.98
+
+// Report records the outcome of a task the reporter sent to an agent. With
+// an Authenticator configured, the reporter must authenticate as itself;
+// reports on unknown agents return ErrNotFound.
+func (s *Server) Report(ctx context.Context, report a2a.ReportParams) error {
+	if report.AgentID == "" || report.Reporter == "" {
+		return errMissingFields
+	}
+	if report.AgentID == report.Reporter {
+		return errSelfReport
+	}
+	if s.auth != nil {
+		reporter, err := s.get(ctx, report.Reporter)
+		if err != nil {
+			return ErrUnauthorized
+		}
+		if _, err := s.authorize(ctx, report.Reporter, reporter); err != nil {
+			return err
+		}
+	}
+
+	reg, err := s.store.Get(ctx, report.AgentID)
+	if err != nil {
+		return err
+	}
+	reg.Reputation = updateReputation(reg.Reputation, report)
+	return s.store.Put(ctx, reg)
+}
+
+// updateReputation folds one report into rep
+func updateReputation(rep *a2a.Reputation, report a2a.ReportParams) *a2a.Reputation {
+	next := a2a.Reputation{}
+	if rep != nil {
+		next = *rep
+	}
+	next.Successes *= reputationDecay
+	next.Failures *= reputationDecay
+	if report.Success {
+		next.Successes++
+	} else {
+		next.Failures++
+	}
+	if report.LatencyMs > 0 {
+		if next.AvgLatencyMs == 0 {
+			next.AvgLatencyMs = float64(report.LatencyMs)
+		} else {
+			next.AvgLatencyMs = 0.9*next.AvgLatencyMs + 0.1*float64(report.LatencyMs)
+		}
+	}
+	// Laplace smoothing: an agent with no reports scores 0.5
+	next.Score = (next.Successes + 1) / (next.Successes + next.Failures + 2)
+	return &next
+}
+
+// reputationScore is the score discovery uses for agent, 0.5 if nobody has
+// reported on it yet
+func reputationScore(agent a2a.AgentInfo) float64 {
+	if agent.Reputation == nil {
+		return 0.5
+	}
+	return agent.Reputation.Score
+}
+
+// applyReputation filters and orders agents as params asks
+func applyReputation(agents []a2a.AgentInfo, params a2a.DiscoverParams) []a2a.AgentInfo {
+	if params.MinReputation > 0 {
+		kept := agents[:0]
+		for _, agent := range agents {
+			if reputationScore(agent) >= params.MinReputation {
+				kept = append(kept, agent)
+			}
+		}
+		agents = kept
+	}
+	if params.ByReputation {
+		sort.SliceStable(agents, func(i, j int) bool { return reputationScore(agents[i]) > reputationScore(agents[j]) })
+	}
+	return agents
+}
+
+func (s *Server) handleReport(ctx context.Context, p a2a.ReportParams) (interface{}, *a2a.JSONRPCError) {
+	if err := s.Report(ctx, p); err != nil {
+		if errors.Is(err, errSelfReport) {
+			return nil, &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: err.Error()}
+		}
+		return nil, s.rpcError(err)
+	}
+	s.logger.Debug(fmt.Sprintf("⭐ %s reported %s success=%t", p.Reporter, p.AgentID, p.Success))
+	return a2a.RegisterResult{Status: "recorded", AgentID: p.AgentID}, nil
+}
@@ -0,0 +1,235 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Scorer rates how well agents match a free-text discovery query, returning
+// one score per agent. Scores are compared with each other only; agents
+// scoring zero or less are not returned.
+type Scorer interface {
+	Score(ctx context.Context, query string, agents []a2a.AgentInfo) ([]float64, error)
+}
+
+// WithScorer sets how query-based discovery ranks agents. The default is
+// TokenScorer.
+func WithScorer(scorer Scorer) Option {
+	return func(s *Server) {
+		s.scorer = scorer
+	}
+}
+
+// Search returns the agents best matching query, best first. If
+// capabilities is non-empty only agents offering one of them are
+// considered. limit caps the number returned; 0 means no cap. Agents found
+// through federation follow the local ones, since scores from different
+// directories are not comparable.
+func (s *Server) Search(ctx context.Context, query string, capabilities []string, limit int) ([]a2a.AgentInfo, error) {
+	return s.Find(ctx, a2a.DiscoverParams{Capabilities: capabilities, Query: query, Limit: limit})
+}
+
+func (s *Server) search(ctx context.Context, params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	query, limit := params.Query, params.Limit
+	candidates, err := s.candidates(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		agent a2a.AgentInfo
+		score float64
+	}
+	scores, err := s.scorer.Score(ctx, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != len(candidates) {
+		return nil, fmt.Errorf("scorer returned %d scores for %d agents", len(scores), len(candidates))
+	}
+	ranked := make([]scored, 0, len(candidates))
+	for i, agent := range candidates {
+		if scores[i] > 0 {
+			ranked = append(ranked, scored{agent, scores[i]})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	matches := make([]a2a.AgentInfo, len(ranked))
+	for i, r := range ranked {
+		matches[i] = r.agent
+	}
+	if limit == 0 || len(matches) < limit {
+		matches = s.withPeers(ctx, matches, params)
+		if limit > 0 && len(matches) > limit {
+			matches = matches[:limit]
+		}
+	}
+	matches = applyReputation(matches, params)
+	matches = applyLocality(matches, params)
+	if params.LeastLoaded {
+		matches = leastLoaded(nearest(matches, params))
+	}
+	s.queries.record(discoveryQuery{At: time.Now(), Capabilities: params.Capabilities, Query: query, Results: len(matches)})
+	s.logger.Info(fmt.Sprintf("🔍 Search: %q, found %d agents", query, len(matches)))
+	return matches, nil
+}
+
+// TokenScorer scores an agent by the fraction of query words found in its
+// name, description and capability names. Plurals match their singular,
+// and words sharing a stem of five letters or more that differ only in
+// their last few letters match, so "summaries" matches "summarize".
+type TokenScorer struct{}
+
+// Score implements Scorer
+func (TokenScorer) Score(ctx context.Context, query string, agents []a2a.AgentInfo) ([]float64, error) {
+	scores := make([]float64, len(agents))
+	words := tokenize(query)
+	if len(words) == 0 {
+		return scores, nil
+	}
+
+	for i, agent := range agents {
+		have := tokenize(agentText(agent))
+		matched := 0
+		for _, word := range words {
+			for _, candidate := range have {
+				if tokensMatch(word, candidate) {
+					matched++
+					break
+				}
+			}
+		}
+		scores[i] = float64(matched) / float64(len(words))
+	}
+	return scores, nil
+}
+
+// stopWords are ignored by TokenScorer
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "that": true, "can": true,
+	"to": true, "of": true, "for": true, "into": true, "in": true, "on": true,
+	"with": true, "agent": true, "agents": true, "i": true, "me": true, "need": true,
+	"which": true, "who": true, "is": true, "it": true, "or": true, "from": true,
+}
+
+// tokenize lower-cases text and splits it into words, breaking capability
+// names on punctuation and underscores
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if !stopWords[f] {
+			words = append(words, f)
+		}
+	}
+	return words
+}
+
+func tokensMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	a, b = strings.TrimSuffix(a, "s"), strings.TrimSuffix(b, "s")
+	if a == b {
+		return true
+	}
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n >= 5 && n >= min(len(a), len(b))-3
+}
+
+// agentText is the text an agent is searched by
+func agentText(agent a2a.AgentInfo) string {
+	return agent.Name + " " + agent.Description + " " + strings.Join(agent.Capabilities, " ")
+}
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are, typically by calling an embedding model
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbeddingScorer scores agents by cosine similarity between the embedded
+// query and each agent's name, description and capabilities. Agent
+// embeddings are cached by text, so re-registering with the same details
+// costs nothing.
+type EmbeddingScorer struct {
+	Embedder Embedder
+	// MinSimilarity is the similarity an agent must exceed to be returned
+	MinSimilarity float64
+
+	cache sync.Map // agent text → []float32
+}
+
+// NewEmbeddingScorer creates a scorer using embedder
+func NewEmbeddingScorer(embedder Embedder, minSimilarity float64) *EmbeddingScorer {
+	return &EmbeddingScorer{Embedder: embedder, MinSimilarity: minSimilarity}
+}
+
+// Score implements Scorer, embedding the query and any uncached agents in
+// one call
+func (e *EmbeddingScorer) Score(ctx context.Context, query string, agents []a2a.AgentInfo) ([]float64, error) {
+	agentVectors := make([][]float32, len(agents))
+	texts := []string{query}
+	var missing []int
+	for i, agent := range agents {
+		if cached, ok := e.cache.Load(agentText(agent)); ok {
+			agentVectors[i] = cached.([]float32)
+			continue
+		}
+		missing = append(missing, i)
+		texts = append(texts, agentText(agent))
+	}
+
+	vectors, err := e.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	for j, i := range missing {
+		agentVectors[i] = vectors[j+1]
+		e.cache.Store(texts[j+1], vectors[j+1])
+	}
+
+	scores := make([]float64, len(agents))
+	for i, vector := range agentVectors {
+		if similarity := cosine(vectors[0], vector); similarity > e.MinSimilarity {
+			scores[i] = similarity
+		}
+	}
+	return scores, nil
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,292 @@
+// Package sqlstore implements a directory RegistryStore on database/sql,
+// for durable registrations that can be queried by capability and backed
+// up with ordinary database tooling. SQLite and PostgreSQL are supported;
+// import the driver of your choice.
+//
+//	db, _ := sql.Open("pgx", "postgres://localhost/a2a")
+//	store := sqlstore.New(db, sqlstore.Postgres)
+//	if err := store.Migrate(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	dir := directory.NewServer(directory.WithStore(store))
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+// Dialect describes the SQL differences between supported databases
+type Dialect struct {
+	Name string
+	// Placeholder returns the bind parameter for the n-th argument, from 1
+	Placeholder func(n int) string
+}
+
+var (
+	// SQLite uses ? placeholders
+	SQLite = Dialect{Name: "sqlite", Placeholder: func(int) string { return "?" }}
+	// Postgres uses $n placeholders
+	Postgres = Dialect{Name: "postgres", Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }}
+)
+
+// migrations are applied in order; never edit one that has been released,
+// append a new one instead
+var migrations = []string{
+	`CREATE TABLE a2a_agents (
+		agent_id       TEXT PRIMARY KEY,
+		name           TEXT NOT NULL,
+		endpoint       TEXT NOT NULL,
+		data           TEXT NOT NULL,
+		registered_at  BIGINT NOT NULL,
+		last_heartbeat BIGINT NOT NULL,
+		expires_at     BIGINT
+	);
+	CREATE TABLE a2a_agent_capabilities (
+		agent_id   TEXT NOT NULL,
+		capability TEXT NOT NULL,
+		PRIMARY KEY (agent_id, capability)
+	);
+	CREATE INDEX a2a_agent_capabilities_capability ON a2a_agent_capabilities (capability);
+	CREATE INDEX a2a_agents_expires_at ON a2a_agents (expires_at);`,
+}
+
+// Store is a database/sql directory.RegistryStore
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// New creates a store on db. Call Migrate before first use.
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{db: db, dialect: dialect}
+}
+
+// Migrate creates or upgrades the schema, applying each pending migration
+// in its own transaction
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS a2a_schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	var current int
+	row := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM a2a_schema_migrations`)
+	if err := row.Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			for _, stmt := range strings.Split(migrations[version-1], ";") {
+				if strings.TrimSpace(stmt) == "" {
+					continue
+				}
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, s.query(`INSERT INTO a2a_schema_migrations (version) VALUES (?)`), version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("applying migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// Put adds or replaces a registration
+func (s *Store) Put(ctx context.Context, reg directory.Registration) error {
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, s.query(`
+			INSERT INTO a2a_agents (agent_id, name, endpoint, data, registered_at, last_heartbeat, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (agent_id) DO UPDATE SET
+				name = excluded.name,
+				endpoint = excluded.endpoint,
+				data = excluded.data,
+				registered_at = excluded.registered_at,
+				last_heartbeat = excluded.last_heartbeat,
+				expires_at = excluded.expires_at`),
+			reg.AgentID, reg.Name, reg.Endpoint, string(data),
+			reg.RegisteredAt.UnixMilli(), reg.LastHeartbeat.UnixMilli(), nullableMillis(reg.ExpiresAt),
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM a2a_agent_capabilities WHERE agent_id = ?`), reg.AgentID); err != nil {
+			return err
+		}
+		names := make([]string, len(reg.Capabilities))
+		for i, capability := range reg.Capabilities {
+			names[i] = directory.CapabilityName(capability)
+		}
+		for _, capability := range dedupe(names) {
+			_, err := tx.ExecContext(ctx, s.query(`INSERT INTO a2a_agent_capabilities (agent_id, capability) VALUES (?, ?)`), reg.AgentID, capability)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get returns the registration for agentID
+func (s *Store) Get(ctx context.Context, agentID string) (directory.Registration, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.query(`SELECT data FROM a2a_agents WHERE agent_id = ?`), agentID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return directory.Registration{}, directory.ErrNotFound
+	}
+	if err != nil {
+		return directory.Registration{}, err
+	}
+	return decode(data)
+}
+
+// List returns all registrations ordered by agent ID
+func (s *Store) List(ctx context.Context) ([]directory.Registration, error) {
+	return s.list(ctx, `SELECT data FROM a2a_agents ORDER BY agent_id`)
+}
+
+// AgentsWithCapability returns the registrations offering capability using
+// the capability index
+func (s *Store) AgentsWithCapability(ctx context.Context, capability string) ([]directory.Registration, error) {
+	return s.list(ctx, s.query(`
+		SELECT a.data FROM a2a_agents a
+		JOIN a2a_agent_capabilities c ON c.agent_id = a.agent_id
+		WHERE c.capability = ?
+		ORDER BY a.agent_id`), capability)
+}
+
+// Delete removes the registration for agentID
+func (s *Store) Delete(ctx context.Context, agentID string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, s.query(`DELETE FROM a2a_agents WHERE agent_id = ?`), agentID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return directory.ErrNotFound
+		}
+		_, err = tx.ExecContext(ctx, s.query(`DELETE FROM a2a_agent_capabilities WHERE agent_id = ?`), agentID)
+		return err
+	})
+}
+
+// Expire removes registrations that have expired as of now
+func (s *Store) Expire(ctx context.Context, now time.Time) ([]directory.Registration, error) {
+	var expired []directory.Registration
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, s.query(`SELECT data FROM a2a_agents WHERE expires_at IS NOT NULL AND expires_at <= ? ORDER BY agent_id`), now.UnixMilli())
+		if err != nil {
+			return err
+		}
+		expired, err = scan(rows)
+		if err != nil {
+			return err
+		}
+
+		for _, reg := range expired {
+			if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM a2a_agents WHERE agent_id = ?`), reg.AgentID); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM a2a_agent_capabilities WHERE agent_id = ?`), reg.AgentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return expired, err
+}
+
+func (s *Store) list(ctx context.Context, query string, args ...interface{}) ([]directory.Registration, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scan(rows)
+}
+
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// query rewrites ? placeholders for the store's dialect
+func (s *Store) query(q string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString(s.dialect.Placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func scan(rows *sql.Rows) ([]directory.Registration, error) {
+	defer rows.Close()
+	regs := []directory.Registration{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		reg, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		regs = append(regs, reg)
+	}
+	return regs, rows.Err()
+}
+
+func decode(data string) (directory.Registration, error) {
+	var reg directory.Registration
+	err := json.Unmarshal([]byte(data), &reg)
+	return reg, err
+}
+
+func nullableMillis(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UnixMilli()
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by a RegistryStore when no agent has the given ID
+var ErrNotFound = errors.New("directory: agent not found")
+
+// RegistryStore persists directory registrations. Implementations must be
+// safe for concurrent use.
+type RegistryStore interface {
+	// Put adds or replaces the registration for reg.AgentID
+	Put(ctx context.Context, reg Registration) error
+	// Get returns the registration for agentID or ErrNotFound
+	Get(ctx context.Context, agentID string) (Registration, error)
+	// List returns all registrations ordered by agent ID
+	List(ctx context.Context) ([]Registration, error)
+	// Delete removes the registration for agentID or returns ErrNotFound
+	Delete(ctx context.Context, agentID string) error
+	// Expire removes registrations whose ExpiresAt is set and not after now,
+	// returning what it removed
+	Expire(ctx context.Context, now time.Time) ([]Registration, error)
+}
+
+// CapabilityIndex is implemented by stores that can look agents up by
+// capability without scanning every registration. Discover uses it when
+// the store provides it.
+type CapabilityIndex interface {
+	// AgentsWithCapability returns the registrations offering capability,
+	// ordered by agent ID. capability is a bare name and matches every
+	// version, so implementations index by CapabilityName.
+	AgentsWithCapability(ctx context.Context, capability string) ([]Registration, error)
+}
+
+// MemoryStore is an in-memory RegistryStore with a capability index
+type MemoryStore struct {
+	mu     sync.RWMutex
+	agents map[string]Registration
+	byCap  map[string]map[string]struct{} // capability → agent IDs
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		agents: make(map[string]Registration),
+		byCap:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Put adds or replaces a registration
+func (m *MemoryStore) Put(ctx context.Context, reg Registration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(reg.AgentID)
+	m.agents[reg.AgentID] = reg.clone()
+	for _, capability := range reg.Capabilities {
+		name := CapabilityName(capability)
+		ids := m.byCap[name]
+		if ids == nil {
+			ids = make(map[string]struct{})
+			m.byCap[name] = ids
+		}
+		ids[reg.AgentID] = struct{}{}
+	}
+	return nil
+}
+
+// AgentsWithCapability returns the registrations offering capability
+func (m *MemoryStore) AgentsWithCapability(ctx context.Context, capability string) ([]Registration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := m.byCap[capability]
+	regs := make([]Registration, 0, len(ids))
+	for id := range ids {
+		regs = append(regs, m.agents[id].clone())
+	}
+	sortRegistrations(regs)
+	return regs, nil
+}
+
+// remove deletes agentID and its index entries; m.mu must be held
+func (m *MemoryStore) remove(agentID string) {
+	reg, ok := m.agents[agentID]
+	if !ok {
+		return
+	}
+	for _, capability := range reg.Capabilities {
+		name := CapabilityName(capability)
+		if ids := m.byCap[name]; ids != nil {
+			delete(ids, agentID)
+			if len(ids) == 0 {
+				delete(m.byCap, name)
+			}
+		}
+	}
+	delete(m.agents, agentID)
+}
+
+// Get returns the registration for agentID
+func (m *MemoryStore) Get(ctx context.Context, agentID string) (Registration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	reg, ok := m.agents[agentID]
+	if !ok {
+		return Registration{}, ErrNotFound
+	}
+	return reg.clone(), nil
+}
+
+// List returns all registrations ordered by agent ID
+func (m *MemoryStore) List(ctx context.Context) ([]Registration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	regs := make([]Registration, 0, len(m.agents))
+	for _, reg := range m.agents {
+		regs = append(regs, reg.clone())
+	}
+	sortRegistrations(regs)
+	return regs, nil
+}
+
+// Delete removes the registration for agentID
+func (m *MemoryStore) Delete(ctx context.Context, agentID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.agents[agentID]; !ok {
+		return ErrNotFound
+	}
+	m.remove(agentID)
+	return nil
+}
+
+// Expire removes registrations that have expired as of now
+func (m *MemoryStore) Expire(ctx context.Context, now time.Time) ([]Registration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var expired []Registration
+	for id, reg := range m.agents {
+		if reg.expired(now) {
+			expired = append(expired, reg)
+			m.remove(id)
+		}
+	}
+	sortRegistrations(expired)
+	return expired, nil
+}
+
+func sortRegistrations(regs []Registration) {
+	sort.Slice(regs, func(i, j int) bool { return regs[i].AgentID < regs[j].AgentID })
+}
@@ -0,0 +1,150 @@
+package directory
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Capabilities may carry a semantic version after an @, as in
+// "summarize@2.1.0". In discovery, the part after the @ is a constraint
+// instead: comparators separated by commas or spaces, all of which must
+// hold, such as "summarize@>=2, <3". Supported comparators are =, !=, >,
+// >=, <, <=, ^ (same major version), ~ (same minor version) and a bare
+// version; a bare partial version such as "2" or "2.1" matches every
+// version with that prefix. A wanted capability without a constraint
+// matches every version, and an offered capability without a version only
+// matches wanted capabilities without a constraint.
+
+// CapabilityName returns the capability without its version or constraint
+func CapabilityName(capability string) string {
+	name, _, _ := strings.Cut(capability, "@")
+	return name
+}
+
+// MatchCapability reports whether an agent offering offered satisfies a
+// request for wanted
+func MatchCapability(offered, wanted string) bool {
+	offeredName, offeredVersion, _ := strings.Cut(offered, "@")
+	wantedName, constraint, constrained := strings.Cut(wanted, "@")
+	if offeredName != wantedName {
+		return false
+	}
+	if !constrained || strings.TrimSpace(constraint) == "" {
+		return true
+	}
+	version, ok := parseVersion(offeredVersion)
+	if !ok {
+		return false
+	}
+	return satisfies(version, constraint)
+}
+
+// semver is a parsed semantic version. parts counts the numeric parts
+// given, so partial versions can be matched as prefixes.
+type semver struct {
+	major, minor, patch int
+	pre                 string
+	parts               int
+}
+
+func parseVersion(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return semver{}, false
+	}
+	s, _, _ = strings.Cut(s, "+") // build metadata is ignored
+	core, pre, _ := strings.Cut(s, "-")
+
+	var v semver
+	v.pre = pre
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 {
+		return semver{}, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		switch i {
+		case 0:
+			v.major = n
+		case 1:
+			v.minor = n
+		case 2:
+			v.patch = n
+		}
+	}
+	v.parts = len(fields)
+	return v, true
+}
+
+// compare orders versions, ranking a pre-release below its release
+func compare(a, b semver) int {
+	for _, d := range []int{a.major - b.major, a.minor - b.minor, a.patch - b.patch} {
+		if d != 0 {
+			return d
+		}
+	}
+	switch {
+	case a.pre == b.pre:
+		return 0
+	case a.pre == "":
+		return 1
+	case b.pre == "":
+		return -1
+	}
+	return strings.Compare(a.pre, b.pre)
+}
+
+// satisfies reports whether v meets every comparator in constraint
+func satisfies(v semver, constraint string) bool {
+	fields := strings.FieldsFunc(constraint, func(r rune) bool { return r == ',' || r == ' ' })
+	for i := 0; i < len(fields); i++ {
+		comparator := fields[i]
+		// allow a space between the operator and the version, as in ">= 2"
+		if strings.TrimLeft(comparator, "<>=!^~") == "" && i+1 < len(fields) {
+			comparator += fields[i+1]
+			i++
+		}
+		if !satisfiesOne(v, comparator) {
+			return false
+		}
+	}
+	return true
+}
+
+func satisfiesOne(v semver, comparator string) bool {
+	op := comparator[:len(comparator)-len(strings.TrimLeft(comparator, "<>=!^~"))]
+	want, ok := parseVersion(comparator[len(op):])
+	if !ok {
+		return false
+	}
+
+	c := compare(v, want)
+	switch op {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case "!=":
+		return c != 0
+	case "^":
+		return c >= 0 && v.major == want.major
+	case "~":
+		return c >= 0 && v.major == want.major && v.minor == want.minor
+	case "", "=":
+		switch want.parts {
+		case 1:
+			return v.major == want.major
+		case 2:
+			return v.major == want.major && v.minor == want.minor
+		}
+		return c == 0
+	}
+	return false
+}
@@ -0,0 +1,292 @@
+package directory
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// WebhookSignatureHeader carries "sha256=<hex HMAC of the body>" on
+// deliveries to webhooks registered with a secret
+const WebhookSignatureHeader = "X-A2A-Signature"
+
+// errTooManyWebhooks is returned when Webhooks.MaxWebhooks are registered
+var errTooManyWebhooks = errors.New("webhook limit reached")
+
+// errInvalidWebhook is returned for malformed subscriptions
+var errInvalidWebhook = errors.New("invalid webhook")
+
+// errWebhooksDisabled is returned unless the server was created
+// WithWebhooks
+var errWebhooksDisabled = errors.New("webhooks are not enabled")
+
+// defaultWebhookEvents are the events a webhook without Events receives:
+// those after which an agent can no longer be reached through discovery
+var defaultWebhookEvents = []EventType{EventDeregistered, EventExpired, EventRemoved}
+
+// Webhooks configures delivery of registry events to subscribed URLs
+type Webhooks struct {
+	// MaxWebhooks caps the subscriptions; zero means 1000
+	MaxWebhooks int
+	// Timeout for each delivery attempt; zero means 5s
+	Timeout time.Duration
+	// MaxAttempts per delivery, with exponential backoff between them;
+	// zero means 3
+	MaxAttempts int
+	// Workers deliver concurrently; zero means 4
+	Workers int
+	// Client sends deliveries; nil means a client with Timeout
+	Client *http.Client
+	// Token, if set, must be presented as the bearer token to add or
+	// remove webhooks
+	Token string
+}
+
+// WithWebhooks lets clients subscribe URLs to registry events with
+// a2a/webhooks/add, so orchestrators learn that an agent they depend on
+// deregistered, expired or was removed and can reroute work at once.
+// Subscriptions live in memory; with several replicas, subscribe to each.
+func WithWebhooks(config Webhooks) Option {
+	return func(s *Server) {
+		if config.MaxWebhooks <= 0 {
+			config.MaxWebhooks = 1000
+		}
+		if config.Timeout <= 0 {
+			config.Timeout = 5 * time.Second
+		}
+		if config.MaxAttempts <= 0 {
+			config.MaxAttempts = 3
+		}
+		if config.Workers <= 0 {
+			config.Workers = 4
+		}
+		if config.Client == nil {
+			config.Client = &http.Client{Timeout: config.Timeout}
+		}
+		s.webhooks = &webhookRegistry{
+			config:     config,
+			hooks:      make(map[string]webhook),
+			deliveries: make(chan delivery, 1024),
+		}
+	}
+}
+
+// webhook is one subscription
+type webhook struct {
+	id       string
+	url      string
+	agentIDs []string
+	events   []EventType
+	secret   string
+}
+
+func (h webhook) wants(event Event) bool {
+	return slices.Contains(h.events, event.Type) && (len(h.agentIDs) == 0 || slices.Contains(h.agentIDs, event.Agent.AgentID))
+}
+
+// WebhookDelivery is the body POSTed to a webhook
+type WebhookDelivery struct {
+	WebhookID string `json:"webhookId"`
+	Event
+}
+
+type delivery struct {
+	hook      webhook
+	body      []byte
+	eventType EventType
+}
+
+type webhookRegistry struct {
+	config     Webhooks
+	mu         sync.RWMutex
+	hooks      map[string]webhook
+	deliveries chan delivery
+}
+
+// AddWebhook subscribes params.URL to registry events and returns the
+// subscription ID
+func (s *Server) AddWebhook(ctx context.Context, params a2a.WebhookParams) (string, error) {
+	if s.webhooks == nil {
+		return "", errWebhooksDisabled
+	}
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return "", err
+	}
+	target, err := url.Parse(params.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", fmt.Errorf("%w: url must be http or https", errInvalidWebhook)
+	}
+	events := defaultWebhookEvents
+	if len(params.Events) > 0 {
+		events = make([]EventType, len(params.Events))
+		for i, name := range params.Events {
+			events[i] = EventType(name)
+			if !knownEvent(events[i]) {
+				return "", fmt.Errorf("%w: unknown event %q", errInvalidWebhook, name)
+			}
+		}
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	hook := webhook{
+		id:       "wh-" + hex.EncodeToString(id),
+		url:      params.URL,
+		agentIDs: params.AgentIDs,
+		events:   events,
+		secret:   params.Secret,
+	}
+
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+	if len(s.webhooks.hooks) >= s.webhooks.config.MaxWebhooks {
+		return "", errTooManyWebhooks
+	}
+	s.webhooks.hooks[hook.id] = hook
+	s.logger.Info(fmt.Sprintf("🪝 Added webhook %s", hook.id), "url", hook.url, "agents", hook.agentIDs)
+	return hook.id, nil
+}
+
+// RemoveWebhook cancels a subscription, returning ErrNotFound for unknown
+// IDs
+func (s *Server) RemoveWebhook(ctx context.Context, id string) error {
+	if s.webhooks == nil {
+		return errWebhooksDisabled
+	}
+	if err := s.authorizeWebhooks(ctx); err != nil {
+		return err
+	}
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+	if _, ok := s.webhooks.hooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.webhooks.hooks, id)
+	return nil
+}
+
+// authorizeWebhooks checks the caller in ctx presented Webhooks.Token
+func (s *Server) authorizeWebhooks(ctx context.Context) error {
+	if s.webhooks.config.Token == "" {
+		return nil
+	}
+	credential, _ := ctx.Value(credentialKey{}).(string)
+	if subtle.ConstantTimeCompare([]byte(credential), []byte(s.webhooks.config.Token)) != 1 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func knownEvent(eventType EventType) bool {
+	switch eventType {
+	case EventRegistered, EventDeregistered, EventExpired, EventUnhealthy, EventHealthy, EventRemoved:
+		return true
+	}
+	return false
+}
+
+// notifyWebhooks queues event for every webhook that wants it, dropping
+// deliveries when the queue is full
+func (s *Server) notifyWebhooks(event Event) {
+	if s.webhooks == nil {
+		return
+	}
+	s.webhooks.mu.RLock()
+	defer s.webhooks.mu.RUnlock()
+	for _, hook := range s.webhooks.hooks {
+		if !hook.wants(event) {
+			continue
+		}
+		body, _ := json.Marshal(WebhookDelivery{WebhookID: hook.id, Event: event})
+		select {
+		case s.webhooks.deliveries <- delivery{hook: hook, body: body, eventType: event.Type}:
+		default:
+			s.logger.Warn("webhook queue full, dropping delivery", "webhook", hook.id, "event", event.Type)
+		}
+	}
+}
+
+// RunWebhooks delivers queued webhook events until ctx is done. It does
+// nothing unless the server was created WithWebhooks.
+func (s *Server) RunWebhooks(ctx context.Context) {
+	if s.webhooks == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for range s.webhooks.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-s.webhooks.deliveries:
+					s.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver POSTs a delivery, retrying failures and 5xx answers with
+// backoff
+func (s *Server) deliver(ctx context.Context, d delivery) {
+	backoff := time.Second
+	var err error
+	for attempt := 1; attempt <= s.webhooks.config.MaxAttempts; attempt++ {
+		if err = s.post(ctx, d); err == nil {
+			return
+		}
+		if attempt == s.webhooks.config.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	s.logger.Error("delivering webhook", "webhook", d.hook.id, "url", d.hook.url, "event", d.eventType, "error", err)
+}
+
+func (s *Server) post(ctx context.Context, d delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, s.webhooks.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.url, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-A2A-Event", string(d.eventType))
+	if d.hook.secret != "" {
+		mac := hmac.New(sha256.New, []byte(d.hook.secret))
+		mac.Write(d.body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.webhooks.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package a2a
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrAgentNotFound is returned by Directory.Resolve for unknown agents
+var ErrAgentNotFound = errors.New("agent not found")
+
+// Directory is where agents register and find each other. HTTPDirectory
+// talks to a directory server; the packages under a2a/discovery provide
+// mDNS, DNS-SD, Consul and static file implementations, and any other
+// registry can be plugged in by implementing it.
+type Directory interface {
+	// Register adds or replaces the agent described by params
+	Register(params RegisterParams) (RegisterResult, error)
+	// Discover returns the agents matching params
+	Discover(params DiscoverParams) ([]AgentInfo, error)
+	// Resolve returns one agent by ID, or ErrAgentNotFound
+	Resolve(agentID string) (AgentInfo, error)
+	// Deregister removes an agent
+	Deregister(agentID string) error
+}
+
+// Heartbeater is implemented by directories whose registrations expire
+// unless renewed
+type Heartbeater interface {
+	Heartbeat(params HeartbeatParams) error
+}
+
+// Reporter is implemented by directories that track agent reputation
+type Reporter interface {
+	Report(params ReportParams) error
+}
+
+// HTTPDirectory is a Directory served over JSON-RPC by a directory server
+// such as the directory package
+type HTTPDirectory struct {
+	URL string
+	// Token is presented as a bearer token when registering,
+	// deregistering, heartbeating and reporting
+	Token  string
+	Client *http.Client // nil means http.DefaultClient
+	Codec  Codec        // nil means DefaultCodec
+}
+
+// NewHTTPDirectory returns a client for the directory server at url
+func NewHTTPDirectory(url string) *HTTPDirectory {
+	return &HTTPDirectory{URL: url}
+}
+
+// Register registers an agent with the directory
+func (d *HTTPDirectory) Register(params RegisterParams) (RegisterResult, error) {
+	var registered RegisterResult
+	err := d.call("/a2a/register", "a2a/register", params, d.header(), &registered)
+	return registered, err
+}
+
+// Deregister removes an agent from the directory
+func (d *HTTPDirectory) Deregister(agentID string) error {
+	return d.call("/a2a/deregister", "a2a/deregister", DeregisterParams{AgentID: agentID}, d.header(), nil)
+}
+
+// Discover returns the agents matching params
+func (d *HTTPDirectory) Discover(params DiscoverParams) ([]AgentInfo, error) {
+	var discovered DiscoverResult
+	if err := d.call("/a2a/discover", "a2a/discover", params, nil, &discovered); err != nil {
+		return nil, err
+	}
+	return discovered.Agents, nil
+}
+
+// Resolve looks up one agent
+func (d *HTTPDirectory) Resolve(agentID string) (AgentInfo, error) {
+	resp, err := d.client().Get(d.URL + "/a2a/agents/" + url.PathEscape(agentID))
+	if err != nil {
+		return AgentInfo{}, fmt.Errorf("failed to get agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return AgentInfo{}, fmt.Errorf("%w: %s", ErrAgentNotFound, agentID)
+	}
+
+	var agentInfo AgentInfo
+	if err := d.codec().NewDecoder(resp.Body).Decode(&agentInfo); err != nil {
+		return AgentInfo{}, err
+	}
+	return agentInfo, nil
+}
+
+// Heartbeat renews an agent's registration, optionally reporting its load
+func (d *HTTPDirectory) Heartbeat(params HeartbeatParams) error {
+	return d.call("/a2a/heartbeat", "a2a/heartbeat", params, d.header(), nil)
+}
+
+// Report records the outcome of a task for the target agent's reputation
+func (d *HTTPDirectory) Report(params ReportParams) error {
+	return d.call("/a2a/report", "a2a/report", params, d.header(), nil)
+}
+
+// AddWebhook subscribes a URL to registry events, such as agents it
+// depends on deregistering or being pruned, returning the subscription ID
+func (d *HTTPDirectory) AddWebhook(params WebhookParams) (string, error) {
+	var added WebhookResult
+	err := d.call("/a2a/webhooks/add", "a2a/webhooks/add", params, d.header(), &added)
+	return added.ID, err
+}
+
+// RemoveWebhook cancels a subscription made with AddWebhook
+func (d *HTTPDirectory) RemoveWebhook(id string) error {
+	return d.call("/a2a/webhooks/remove", "a2a/webhooks/remove", WebhookResult{ID: id}, d.header(), nil)
+}
+
+// header carries Token on requests that change registrations
+func (d *HTTPDirectory) header() http.Header {
+	if d.Token == "" {
+		return nil
+	}
+	return http.Header{"Authorization": {"Bearer " + d.Token}}
+}
+
+// call sends a JSON-RPC request to path, decoding the result into out if
+// it is not nil
+func (d *HTTPDirectory) call(path, method string, params interface{}, header http.Header, out interface{}) error {
+	result, err := doRequest(d.client(), d.codec(), strings.TrimSuffix(d.URL, "/")+path, method, params, header)
+	if err != nil || out == nil {
+		return err
+	}
+	return d.codec().Unmarshal(result, out)
+}
+
+func (d *HTTPDirectory) client() *http.Client {
+	if d.Client != nil {
+		return d.Client
+	}
+	return http.DefaultClient
+}
+
+func (d *HTTPDirectory) codec() Codec {
+	if d.Codec != nil {
+		return d.Codec
+	}
+	return DefaultCodec
+}
+
+// directory returns the directory an agent uses for a call given
+// directoryURL: the directory server at that URL, or the agent's Directory
+// when the URL is empty
+func (a *A2AAgent) directory(directoryURL string) Directory {
+	if directoryURL == "" && a.Directory != nil {
+		return a.Directory
+	}
+	return &HTTPDirectory{URL: directoryURL, Token: a.DirectoryToken, Client: a.HTTPClient, Codec: a.Codec}
+}
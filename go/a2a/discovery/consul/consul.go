@@ -0,0 +1,331 @@
+// Package consul registers and discovers A2A agents in a Consul service
+// catalog, for deployments that already run Consul.
+//
+// Every agent is registered as an instance of one Consul service (Service,
+// "a2a-agent" by default) with the agent ID as its service ID. Its
+// capability names and tags become Consul tags, so operators can browse
+// and query them with Consul's own tools; the full registration is kept
+// in the service's Meta under a2a-* keys, next to the agent's metadata.
+// Consul checks the agent's health endpoint, and discovery only returns
+// agents whose checks pass.
+package consul
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+// Service meta keys holding the registration
+const (
+	metaName         = "a2a-name"
+	metaEndpoint     = "a2a-endpoint"
+	metaCapabilities = "a2a-capabilities"
+	metaTags         = "a2a-tags"
+	metaDescription  = "a2a-description"
+	metaRegion       = "a2a-region"
+	metaZone         = "a2a-zone"
+)
+
+// Provider registers agents with a Consul agent and discovers them from
+// its catalog
+type Provider struct {
+	// Address is the Consul HTTP API; empty means http://127.0.0.1:8500
+	Address string
+	// Token is the ACL token sent with every request
+	Token string
+	// Datacenter to query; empty means the Consul agent's own
+	Datacenter string
+	// Service is the Consul service agents are registered under; empty
+	// means "a2a-agent"
+	Service string
+	// CheckInterval is how often Consul probes a2a.HealthPath on each
+	// agent; zero means 10s and a negative value registers no check
+	CheckInterval time.Duration
+	// DeregisterAfter is how long a check may stay critical before Consul
+	// removes the agent; zero means 1m
+	DeregisterAfter time.Duration
+	// Client sends requests; nil means http.DefaultClient
+	Client *http.Client
+}
+
+// New creates a provider for the Consul HTTP API at address
+func New(address string) *Provider {
+	return &Provider{Address: address}
+}
+
+// service is a Consul service registration
+type service struct {
+	ID      string            `json:"ID"`
+	Service string            `json:"Service,omitempty"`
+	Name    string            `json:"Name,omitempty"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   *check            `json:"Check,omitempty"`
+}
+
+type check struct {
+	HTTP                           string `json:"HTTP"`
+	Interval                       string `json:"Interval"`
+	Timeout                        string `json:"Timeout,omitempty"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+// Register registers an agent with the Consul agent. Registering an agent
+// ID again replaces it. Consul, not a TTL, decides when an agent goes
+// away, so the result carries no TTL.
+func (p *Provider) Register(params a2a.RegisterParams) (a2a.RegisterResult, error) {
+	if params.AgentID == "" || params.Endpoint == "" {
+		return a2a.RegisterResult{}, fmt.Errorf("consul: agentId and endpoint are required")
+	}
+	endpoint, err := url.Parse(params.Endpoint)
+	if err != nil {
+		return a2a.RegisterResult{}, fmt.Errorf("consul: invalid endpoint: %w", err)
+	}
+	port, err := strconv.Atoi(endpoint.Port())
+	if err != nil {
+		port = 80
+		if endpoint.Scheme == "https" {
+			port = 443
+		}
+	}
+
+	meta := make(map[string]string, len(params.Metadata)+7)
+	for key, value := range params.Metadata {
+		meta[key] = value
+	}
+	meta[metaName] = params.Name
+	meta[metaEndpoint] = params.Endpoint
+	meta[metaCapabilities] = strings.Join(params.Capabilities, ",")
+	setMeta(meta, metaTags, strings.Join(params.Tags, ","))
+	setMeta(meta, metaDescription, params.Description)
+	setMeta(meta, metaRegion, params.Region)
+	setMeta(meta, metaZone, params.Zone)
+
+	reg := service{
+		ID:      params.AgentID,
+		Name:    p.service(),
+		Tags:    consulTags(params),
+		Address: endpoint.Hostname(),
+		Port:    port,
+		Meta:    meta,
+	}
+	if interval := p.CheckInterval; interval >= 0 {
+		if interval == 0 {
+			interval = 10 * time.Second
+		}
+		deregisterAfter := p.DeregisterAfter
+		if deregisterAfter <= 0 {
+			deregisterAfter = time.Minute
+		}
+		health := url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host, Path: a2a.HealthPath}
+		reg.Check = &check{
+			HTTP:                           health.String(),
+			Interval:                       interval.String(),
+			Timeout:                        min(interval, 5*time.Second).String(),
+			DeregisterCriticalServiceAfter: deregisterAfter.String(),
+		}
+	}
+
+	if err := p.do(http.MethodPut, "/v1/agent/service/register", nil, reg, nil); err != nil {
+		return a2a.RegisterResult{}, err
+	}
+	return a2a.RegisterResult{Status: "registered", AgentID: params.AgentID}, nil
+}
+
+// Deregister removes an agent from the Consul agent
+func (p *Provider) Deregister(agentID string) error {
+	return p.do(http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(agentID), nil, nil, nil)
+}
+
+// Discover returns the healthy agents matching params, as a directory
+// would. A single wanted capability is also filtered by Consul, by tag.
+// params.Query is ignored.
+func (p *Provider) Discover(params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	query := url.Values{}
+	if len(params.Capabilities) == 1 {
+		query.Set("tag", directory.CapabilityName(params.Capabilities[0]))
+	}
+	agents, err := p.healthy(query)
+	if err != nil {
+		return nil, err
+	}
+	return directory.Filter(agents, params), nil
+}
+
+// Resolve returns one healthy agent
+func (p *Provider) Resolve(agentID string) (a2a.AgentInfo, error) {
+	query := url.Values{"filter": {fmt.Sprintf("Service.ID == %s", strconv.Quote(agentID))}}
+	agents, err := p.healthy(query)
+	if err != nil {
+		return a2a.AgentInfo{}, err
+	}
+	if len(agents) == 0 {
+		return a2a.AgentInfo{}, a2a.ErrAgentNotFound
+	}
+	return agents[0], nil
+}
+
+// healthy lists the service's instances whose checks all pass
+func (p *Provider) healthy(query url.Values) ([]a2a.AgentInfo, error) {
+	query.Set("passing", "true")
+	var entries []struct {
+		Service service `json:"Service"`
+	}
+	if err := p.do(http.MethodGet, "/v1/health/service/"+url.PathEscape(p.service()), query, nil, &entries); err != nil {
+		return nil, err
+	}
+
+	agents := make([]a2a.AgentInfo, 0, len(entries))
+	for _, entry := range entries {
+		agents = append(agents, agentInfo(entry.Service))
+	}
+	return agents, nil
+}
+
+func (p *Provider) service() string {
+	if p.Service == "" {
+		return "a2a-agent"
+	}
+	return p.Service
+}
+
+// do sends a request to the Consul HTTP API, decoding the response into
+// out if it is not nil
+func (p *Provider) do(method, path string, query url.Values, body, out any) error {
+	address := p.Address
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	}
+	if p.Datacenter != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("dc", p.Datacenter)
+	}
+	target := strings.TrimSuffix(address, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.Token != "" {
+		req.Header.Set("X-Consul-Token", p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("consul: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// consulTags returns the agent's capability names followed by its tags,
+// without duplicates
+func consulTags(params a2a.RegisterParams) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, tag := range params.Capabilities {
+		tag = directory.CapabilityName(tag)
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range params.Tags {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// agentInfo decodes an agent registered by Register. Services registered
+// some other way are reported with their Consul address, tags and meta.
+func agentInfo(svc service) a2a.AgentInfo {
+	meta := make(map[string]string, len(svc.Meta))
+	for key, value := range svc.Meta {
+		meta[key] = value
+	}
+	take := func(key string) string {
+		value := meta[key]
+		delete(meta, key)
+		return value
+	}
+
+	agent := a2a.AgentInfo{
+		AgentID:      svc.ID,
+		Name:         take(metaName),
+		Endpoint:     take(metaEndpoint),
+		Capabilities: split(take(metaCapabilities)),
+		Tags:         split(take(metaTags)),
+		Description:  take(metaDescription),
+		Region:       take(metaRegion),
+		Zone:         take(metaZone),
+	}
+	if agent.Name == "" {
+		agent.Name = svc.ID
+	}
+	if agent.Endpoint == "" {
+		agent.Endpoint = "http://" + net.JoinHostPort(svc.Address, strconv.Itoa(svc.Port))
+	}
+	if agent.Capabilities == nil {
+		agent.Capabilities = svc.Tags
+	}
+	if len(meta) > 0 {
+		agent.Metadata = meta
+	}
+	return agent
+}
+
+func setMeta(meta map[string]string, key, value string) {
+	if value != "" {
+		meta[key] = value
+	}
+}
+
+func split(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
@@ -0,0 +1,111 @@
+// Package did finds agents whose agent ID is a DID through their DID
+// documents, so agents in open networks can reach each other without a
+// directory. An agent's endpoint is the serviceEndpoint of the
+// a2a.DIDServiceType service in its document:
+//
+//	"service": [{
+//	  "id": "#a2a",
+//	  "type": "A2AAgent",
+//	  "serviceEndpoint": "https://billing.example.com"
+//	}]
+//
+// Discover can only search the DIDs the provider is given; their
+// capabilities are read from the Agent Cards at their endpoints.
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+// ErrReadOnly is returned by Register and Deregister: agents publish
+// their DID documents themselves
+var ErrReadOnly = errors.New("did: agents are published in DID documents")
+
+// Provider resolves agents from their DID documents
+type Provider struct {
+	// DIDs are the agents Discover searches
+	DIDs []string
+	// Resolver resolves DID documents; nil means a2a.DefaultDIDResolver
+	Resolver a2a.DIDResolver
+	// Timeout bounds each Discover or Resolve; zero means 10s
+	Timeout time.Duration
+}
+
+// New creates a provider whose Discover searches dids
+func New(dids ...string) *Provider {
+	return &Provider{DIDs: dids}
+}
+
+// Register always fails with ErrReadOnly
+func (p *Provider) Register(params a2a.RegisterParams) (a2a.RegisterResult, error) {
+	return a2a.RegisterResult{}, ErrReadOnly
+}
+
+// Deregister always fails with ErrReadOnly
+func (p *Provider) Deregister(agentID string) error {
+	return ErrReadOnly
+}
+
+// Resolve returns the agent described by the DID document of agentID
+func (p *Provider) Resolve(agentID string) (a2a.AgentInfo, error) {
+	ctx, cancel := p.context()
+	defer cancel()
+	return p.resolve(ctx, agentID)
+}
+
+// Discover resolves each of DIDs and returns the agents matching params,
+// as a directory would. params.Query is ignored. Agents that cannot be
+// resolved are left out.
+func (p *Provider) Discover(params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	ctx, cancel := p.context()
+	defer cancel()
+	agents := make([]a2a.AgentInfo, 0, len(p.DIDs))
+	client := a2a.NewAgent("", "", nil)
+	for _, did := range p.DIDs {
+		agent, err := p.resolve(ctx, did)
+		if err != nil {
+			continue
+		}
+		if card, err := client.FetchAgentCard(ctx, agent.Endpoint); err == nil {
+			agent.Name, agent.Description = card.Name, card.Description
+			for _, skill := range card.Skills {
+				agent.Capabilities = append(agent.Capabilities, skill.ID)
+			}
+		}
+		agents = append(agents, agent)
+	}
+	return directory.Filter(agents, params), nil
+}
+
+func (p *Provider) resolve(ctx context.Context, did string) (a2a.AgentInfo, error) {
+	if !a2a.IsDID(did) {
+		return a2a.AgentInfo{}, a2a.ErrAgentNotFound
+	}
+	resolver := p.Resolver
+	if resolver == nil {
+		resolver = a2a.DefaultDIDResolver
+	}
+	doc, err := resolver.ResolveDID(ctx, did)
+	if err != nil {
+		return a2a.AgentInfo{}, fmt.Errorf("did: %w", err)
+	}
+	endpoint, ok := doc.Endpoint()
+	if !ok {
+		return a2a.AgentInfo{}, fmt.Errorf("did: %s has no %s service", did, a2a.DIDServiceType)
+	}
+	return a2a.AgentInfo{AgentID: did, Endpoint: endpoint}, nil
+}
+
+func (p *Provider) context() (context.Context, context.CancelFunc) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
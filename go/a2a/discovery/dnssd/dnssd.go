@@ -0,0 +1,194 @@
+// Package dnssd finds A2A agents through DNS SRV and TXT records, so
+// agent endpoints can be managed with existing DNS tooling.
+//
+// Every agent in a domain has an SRV record under _a2a._tcp.<domain>
+// pointing at its host and port. The target host carries TXT records with
+// the rest of the registration, one key=value per record:
+//
+//	_a2a._tcp.example.com.           SRV 0 0 9001 summarizer.agents.example.com.
+//	summarizer.agents.example.com.   TXT "id=summarizer"
+//	summarizer.agents.example.com.   TXT "caps=summarize,translate@1.2.0"
+//	summarizer.agents.example.com.   TXT "path=/agents/summarizer"
+//
+// Recognised keys are id, name, caps, tags, scheme (default http), path,
+// region, zone, description and meta.<key>. Without an id, the agent ID is
+// the target's first label. When several SRV records share an agent ID,
+// the one DNS ranks first by priority and weight is used.
+package dnssd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+// ErrReadOnly is returned by Register and Deregister: records are managed
+// in DNS, not through the provider
+var ErrReadOnly = errors.New("dnssd: agents are registered in DNS")
+
+// Provider discovers the agents published in one DNS domain
+type Provider struct {
+	// Domain holds the _a2a._tcp SRV records, e.g. "agents.example.com"
+	Domain string
+	// Resolver performs the lookups; nil means net.DefaultResolver
+	Resolver *net.Resolver
+	// Timeout bounds each Discover or Resolve; zero means 5s
+	Timeout time.Duration
+}
+
+// New creates a provider for domain
+func New(domain string) *Provider {
+	return &Provider{Domain: domain}
+}
+
+// Register always fails with ErrReadOnly
+func (p *Provider) Register(params a2a.RegisterParams) (a2a.RegisterResult, error) {
+	return a2a.RegisterResult{}, ErrReadOnly
+}
+
+// Deregister always fails with ErrReadOnly
+func (p *Provider) Deregister(agentID string) error {
+	return ErrReadOnly
+}
+
+// Discover looks up the domain's agents and returns those matching params,
+// as a directory would. params.Query is ignored.
+func (p *Provider) Discover(params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	agents, err := p.lookup()
+	if err != nil {
+		return nil, err
+	}
+	return directory.Filter(agents, params), nil
+}
+
+// Resolve looks up one agent in the domain
+func (p *Provider) Resolve(agentID string) (a2a.AgentInfo, error) {
+	agents, err := p.lookup()
+	if err != nil {
+		return a2a.AgentInfo{}, err
+	}
+	for _, agent := range agents {
+		if agent.AgentID == agentID {
+			return agent, nil
+		}
+	}
+	return a2a.AgentInfo{}, a2a.ErrAgentNotFound
+}
+
+// lookup reads every agent's SRV and TXT records, in SRV order
+func (p *Provider) lookup() ([]a2a.AgentInfo, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	resolver := p.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, records, err := resolver.LookupSRV(ctx, "a2a", "tcp", p.Domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return []a2a.AgentInfo{}, nil
+		}
+		return nil, fmt.Errorf("dnssd: %w", err)
+	}
+
+	agents := make([]a2a.AgentInfo, len(records))
+	errs := make([]error, len(records))
+	var wg sync.WaitGroup
+	for i, srv := range records {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txt, err := resolver.LookupTXT(ctx, srv.Target)
+			var dnsErr *net.DNSError
+			if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+				errs[i] = fmt.Errorf("dnssd: %w", err)
+				return
+			}
+			agents[i] = agentInfo(srv, txt)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	found := agents[:0]
+	for _, agent := range agents {
+		if !seen[agent.AgentID] {
+			seen[agent.AgentID] = true
+			found = append(found, agent)
+		}
+	}
+	return found, nil
+}
+
+// agentInfo decodes an agent from its SRV record and the TXT records of
+// the SRV target
+func agentInfo(srv *net.SRV, txt []string) a2a.AgentInfo {
+	host := strings.TrimSuffix(srv.Target, ".")
+	fields := make(map[string]string)
+	metadata := make(map[string]string)
+	for _, record := range txt {
+		key, value, _ := strings.Cut(record, "=")
+		if meta, ok := strings.CutPrefix(key, "meta."); ok {
+			metadata[meta] = value
+		} else {
+			fields[key] = value
+		}
+	}
+
+	id := fields["id"]
+	if id == "" {
+		id, _, _ = strings.Cut(host, ".")
+	}
+	scheme := fields["scheme"]
+	if scheme == "" {
+		scheme = "http"
+	}
+	endpoint := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(srv.Port))), Path: fields["path"]}
+
+	agent := a2a.AgentInfo{
+		AgentID:      id,
+		Name:         fields["name"],
+		Capabilities: split(fields["caps"]),
+		Endpoint:     endpoint.String(),
+		Description:  fields["description"],
+		Tags:         split(fields["tags"]),
+		Region:       fields["region"],
+		Zone:         fields["zone"],
+	}
+	if agent.Name == "" {
+		agent.Name = id
+	}
+	if len(metadata) > 0 {
+		agent.Metadata = metadata
+	}
+	return agent
+}
+
+func split(list string) []string {
+	if list == "" {
+		return nil
+	}
+	parts := strings.Split(list, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
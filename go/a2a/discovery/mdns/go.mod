@@ -0,0 +1,19 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/discovery/mdns
+
+go 1.24
+
+require (
+	github.com/hashicorp/mdns v1.0.6
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/miekg/dns v1.1.62
+)
+
+require (
+	golang.org/x/mod v0.18.0 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/tools v0.22.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
@@ -0,0 +1,330 @@
+// Package mdns advertises and finds A2A agents with multicast DNS
+// (Bonjour), so agents on one LAN can discover each other without running
+// a directory.
+//
+// Each registered agent is published as an instance of the _a2a._tcp
+// service named after its agent ID. The SRV and A/AAAA records carry the
+// endpoint's host and port; TXT records carry the rest of the
+// registration:
+//
+//	id=<agent ID>  name=<name>  caps=<a,b>  tags=<a,b>  path=<endpoint path>
+//	scheme=<http|https>  region=<region>  zone=<zone>  meta.<key>=<value>
+package mdns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/mdns"
+	"github.com/miekg/dns"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+// ServiceType is the DNS-SD service type agents are published under
+const ServiceType = "_a2a._tcp"
+
+// quiet discards the mdns package's logging, which reports routine
+// conditions such as IPv6 being unavailable
+var quiet = log.New(io.Discard, "", 0)
+
+// Provider publishes agents on the local network and discovers the agents
+// published by others. The zero value is ready to use.
+type Provider struct {
+	// Domain is the mDNS domain; empty means "local."
+	Domain string
+	// Timeout is how long Discover and Resolve wait for answers; zero
+	// means 1s
+	Timeout time.Duration
+	// Interface is the multicast interface; nil means the system default
+	Interface *net.Interface
+
+	mu       sync.Mutex
+	server   *mdns.Server
+	services map[string]*mdns.MDNSService
+}
+
+// Register publishes an agent until it is deregistered or the provider is
+// closed. Registering an agent ID again replaces its records. mDNS records
+// do not expire while the process runs, so the result carries no TTL.
+func (p *Provider) Register(params a2a.RegisterParams) (a2a.RegisterResult, error) {
+	if params.AgentID == "" || params.Endpoint == "" {
+		return a2a.RegisterResult{}, fmt.Errorf("mdns: agentId and endpoint are required")
+	}
+	service, err := p.service(params)
+	if err != nil {
+		return a2a.RegisterResult{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.services == nil {
+		p.services = make(map[string]*mdns.MDNSService)
+	}
+	p.services[params.AgentID] = service
+	if p.server == nil {
+		server, err := mdns.NewServer(&mdns.Config{Zone: zone{p}, Iface: p.Interface, Logger: quiet})
+		if err != nil {
+			delete(p.services, params.AgentID)
+			return a2a.RegisterResult{}, fmt.Errorf("mdns: %w", err)
+		}
+		p.server = server
+	}
+	return a2a.RegisterResult{Status: "registered", AgentID: params.AgentID}, nil
+}
+
+// Deregister stops publishing an agent
+func (p *Provider) Deregister(agentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.services[agentID]; !ok {
+		return a2a.ErrAgentNotFound
+	}
+	delete(p.services, agentID)
+	return nil
+}
+
+// Discover queries the network for agents and returns those matching
+// params, as a directory would. params.Query is ignored.
+func (p *Provider) Discover(params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	agents, err := p.browse()
+	if err != nil {
+		return nil, err
+	}
+	return directory.Filter(agents, params), nil
+}
+
+// Resolve queries the network for one agent
+func (p *Provider) Resolve(agentID string) (a2a.AgentInfo, error) {
+	agents, err := p.browse()
+	if err != nil {
+		return a2a.AgentInfo{}, err
+	}
+	for _, agent := range agents {
+		if agent.AgentID == agentID {
+			return agent, nil
+		}
+	}
+	return a2a.AgentInfo{}, a2a.ErrAgentNotFound
+}
+
+// Close stops publishing every agent registered with the provider
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.services = nil
+	if p.server == nil {
+		return nil
+	}
+	err := p.server.Shutdown()
+	p.server = nil
+	return err
+}
+
+// service builds the mDNS records for a registration. Endpoints on a
+// loopback or unspecified address are published under this host's name and
+// addresses instead, since they mean nothing to other machines.
+func (p *Provider) service(params a2a.RegisterParams) (*mdns.MDNSService, error) {
+	endpoint, err := url.Parse(params.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("mdns: invalid endpoint: %w", err)
+	}
+	port, err := strconv.Atoi(endpoint.Port())
+	if err != nil {
+		port = 80
+		if endpoint.Scheme == "https" {
+			port = 443
+		}
+	}
+
+	var host string
+	var ips []net.IP
+	if ip := net.ParseIP(endpoint.Hostname()); ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+		ips = []net.IP{ip}
+	} else if name := endpoint.Hostname(); ip == nil && name != "localhost" && name != "" {
+		host = dns.Fqdn(name)
+	}
+	if host == "" {
+		name, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("mdns: %w", err)
+		}
+		host = dns.Fqdn(name)
+		if ips, err = localIPs(); err != nil {
+			return nil, fmt.Errorf("mdns: %w", err)
+		}
+	}
+
+	service, err := mdns.NewMDNSService(params.AgentID, ServiceType, p.Domain, host, port, ips, txtRecords(params, endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("mdns: %w", err)
+	}
+	return service, nil
+}
+
+// localIPs returns this host's unicast addresses, or its loopback
+// addresses when it has no others
+func localIPs() ([]net.IP, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	var ips, loopback []net.IP
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		switch {
+		case !ok || ipnet.IP.IsLinkLocalUnicast():
+		case ipnet.IP.IsLoopback():
+			loopback = append(loopback, ipnet.IP)
+		default:
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return loopback, nil
+	}
+	return ips, nil
+}
+
+func txtRecords(params a2a.RegisterParams, endpoint *url.URL) []string {
+	txt := []string{
+		"id=" + params.AgentID,
+		"name=" + params.Name,
+		"caps=" + strings.Join(params.Capabilities, ","),
+		"scheme=" + endpoint.Scheme,
+		"path=" + endpoint.EscapedPath(),
+	}
+	if len(params.Tags) > 0 {
+		txt = append(txt, "tags="+strings.Join(params.Tags, ","))
+	}
+	if params.Region != "" {
+		txt = append(txt, "region="+params.Region)
+	}
+	if params.Zone != "" {
+		txt = append(txt, "zone="+params.Zone)
+	}
+	for key, value := range params.Metadata {
+		txt = append(txt, "meta."+key+"="+value)
+	}
+	slices.Sort(txt[5:])
+	return txt
+}
+
+// browse collects the agents that answer a query for ServiceType
+func (p *Provider) browse() ([]a2a.AgentInfo, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+
+	entries := make(chan *mdns.ServiceEntry, 64)
+	agents := make(map[string]a2a.AgentInfo)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for entry := range entries {
+			if agent, ok := agentInfo(entry); ok {
+				agents[agent.AgentID] = agent
+			}
+		}
+	}()
+
+	params := mdns.DefaultParams(ServiceType)
+	params.Domain = p.Domain
+	params.Timeout = timeout
+	params.Interface = p.Interface
+	params.Entries = entries
+	params.Logger = quiet
+	err := mdns.QueryContext(context.Background(), params)
+	close(entries)
+	<-done
+	if err != nil {
+		return nil, fmt.Errorf("mdns: %w", err)
+	}
+
+	found := make([]a2a.AgentInfo, 0, len(agents))
+	for _, agent := range agents {
+		found = append(found, agent)
+	}
+	slices.SortFunc(found, func(a, b a2a.AgentInfo) int { return strings.Compare(a.AgentID, b.AgentID) })
+	return found, nil
+}
+
+// agentInfo decodes a service entry published by Register
+func agentInfo(entry *mdns.ServiceEntry) (a2a.AgentInfo, bool) {
+	fields := make(map[string]string)
+	metadata := make(map[string]string)
+	for _, field := range entry.InfoFields {
+		key, value, _ := strings.Cut(field, "=")
+		if meta, ok := strings.CutPrefix(key, "meta."); ok {
+			metadata[meta] = value
+		} else {
+			fields[key] = value
+		}
+	}
+	if fields["id"] == "" {
+		return a2a.AgentInfo{}, false
+	}
+
+	var host string
+	switch {
+	case entry.AddrV4 != nil:
+		host = entry.AddrV4.String()
+	case entry.AddrV6IPAddr != nil:
+		host = entry.AddrV6IPAddr.String()
+	default:
+		host = strings.TrimSuffix(entry.Host, ".")
+	}
+	scheme := fields["scheme"]
+	if scheme == "" {
+		scheme = "http"
+	}
+	endpoint := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(entry.Port))}
+	endpoint.Path, _ = url.PathUnescape(fields["path"])
+
+	agent := a2a.AgentInfo{
+		AgentID:      fields["id"],
+		Name:         fields["name"],
+		Capabilities: split(fields["caps"]),
+		Endpoint:     endpoint.String(),
+		Tags:         split(fields["tags"]),
+		Region:       fields["region"],
+		Zone:         fields["zone"],
+	}
+	if len(metadata) > 0 {
+		agent.Metadata = metadata
+	}
+	return agent, true
+}
+
+func split(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
+
+// zone answers queries with the records of every agent published by the
+// provider
+type zone struct{ p *Provider }
+
+func (z zone) Records(q dns.Question) []dns.RR {
+	z.p.mu.Lock()
+	defer z.p.mu.Unlock()
+	var records []dns.RR
+	for _, service := range z.p.services {
+		records = append(records, service.Records(q)...)
+	}
+	return records
+}
@@ -0,0 +1,224 @@
+// Package static serves discovery from a file listing agents, for
+// air-gapped or fully declarative deployments with no discovery service.
+//
+// The file is JSON, or YAML when the provider is given a YAML Unmarshal
+// function such as gopkg.in/yaml.v3's:
+//
+//	agents:
+//	  - id: summarizer
+//	    name: Summarizer
+//	    endpoint: http://summarizer.internal:9001
+//	    capabilities: [summarize@1.2.0, translate]
+//	    tags: [gpu]
+//	    metadata: {team: docs}
+//	    region: eu-west-1
+//	    zone: eu-west-1a
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+// ErrReadOnly is returned by Register and Deregister: agents are listed
+// in the file, not registered through the provider
+var ErrReadOnly = errors.New("static: agents are listed in the file")
+
+// File is the layout of an agents file
+type File struct {
+	Agents []Agent `json:"agents" yaml:"agents"`
+}
+
+// Agent is one agent in an agents file
+type Agent struct {
+	ID           string            `json:"id" yaml:"id"`
+	Name         string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Endpoint     string            `json:"endpoint" yaml:"endpoint"`
+	Capabilities []string          `json:"capabilities" yaml:"capabilities"`
+	Description  string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags         []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Region       string            `json:"region,omitempty" yaml:"region,omitempty"`
+	Zone         string            `json:"zone,omitempty" yaml:"zone,omitempty"`
+}
+
+// Provider answers discovery from the agents in a file
+type Provider struct {
+	// Path is the agents file
+	Path string
+	// Unmarshal parses the file; nil means encoding/json. Files ending in
+	// .yaml or .yml need one, e.g. yaml.Unmarshal.
+	Unmarshal func(data []byte, v any) error
+	// Interval is how often Watch checks the file for changes; zero
+	// means 2s
+	Interval time.Duration
+	// Logger reports files Watch could not load; nil means slog.Default()
+	Logger *slog.Logger
+
+	mu      sync.RWMutex
+	agents  []a2a.AgentInfo
+	modTime time.Time
+	size    int64
+}
+
+// Load reads the agents file at path, which must be JSON. Use a Provider
+// with Unmarshal set and call Reload for YAML.
+func Load(path string) (*Provider, error) {
+	p := &Provider{Path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload reads the file again, replacing the agents only if it is valid
+func (p *Provider) Reload() error {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return fmt.Errorf("static: %w", err)
+	}
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return fmt.Errorf("static: %w", err)
+	}
+
+	unmarshal := p.Unmarshal
+	if unmarshal == nil {
+		if strings.HasSuffix(p.Path, ".yaml") || strings.HasSuffix(p.Path, ".yml") {
+			return fmt.Errorf("static: %s is YAML; set Provider.Unmarshal to a YAML parser", p.Path)
+		}
+		unmarshal = json.Unmarshal
+	}
+	var file File
+	if err := unmarshal(data, &file); err != nil {
+		return fmt.Errorf("static: parsing %s: %w", p.Path, err)
+	}
+	agents, err := file.agents()
+	if err != nil {
+		return fmt.Errorf("static: %s: %w", p.Path, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.agents = agents
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	return nil
+}
+
+// agents validates the file's agents and returns them ordered by ID
+func (f File) agents() ([]a2a.AgentInfo, error) {
+	seen := make(map[string]bool)
+	agents := make([]a2a.AgentInfo, 0, len(f.Agents))
+	for i, agent := range f.Agents {
+		if agent.ID == "" || agent.Endpoint == "" {
+			return nil, fmt.Errorf("agent %d: id and endpoint are required", i+1)
+		}
+		if seen[agent.ID] {
+			return nil, fmt.Errorf("agent %q is listed twice", agent.ID)
+		}
+		seen[agent.ID] = true
+
+		name := agent.Name
+		if name == "" {
+			name = agent.ID
+		}
+		agents = append(agents, a2a.AgentInfo{
+			AgentID:      agent.ID,
+			Name:         name,
+			Capabilities: agent.Capabilities,
+			Endpoint:     agent.Endpoint,
+			Description:  agent.Description,
+			Tags:         agent.Tags,
+			Metadata:     agent.Metadata,
+			Region:       agent.Region,
+			Zone:         agent.Zone,
+		})
+	}
+	slices.SortFunc(agents, func(a, b a2a.AgentInfo) int { return strings.Compare(a.AgentID, b.AgentID) })
+	return agents, nil
+}
+
+// Watch reloads the file whenever its size or modification time changes,
+// until ctx is done. A file that fails to load is logged and the previous
+// agents are kept until the file changes again.
+func (p *Provider) Watch(ctx context.Context) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p.mu.RLock()
+	modTime, size := p.modTime, p.size
+	p.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(p.Path)
+		if err != nil {
+			logger.Error("watching agents file", "path", p.Path, "error", err)
+			continue
+		}
+		if info.ModTime().Equal(modTime) && info.Size() == size {
+			continue
+		}
+		modTime, size = info.ModTime(), info.Size()
+		if err := p.Reload(); err != nil {
+			logger.Error("reloading agents file", "path", p.Path, "error", err)
+			continue
+		}
+		logger.Info(fmt.Sprintf("📄 Reloaded agents file: %s", p.Path))
+	}
+}
+
+// Register always fails with ErrReadOnly
+func (p *Provider) Register(params a2a.RegisterParams) (a2a.RegisterResult, error) {
+	return a2a.RegisterResult{}, ErrReadOnly
+}
+
+// Deregister always fails with ErrReadOnly
+func (p *Provider) Deregister(agentID string) error {
+	return ErrReadOnly
+}
+
+// Discover returns the listed agents matching params, as a directory
+// would. params.Query is ignored.
+func (p *Provider) Discover(params a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	p.mu.RLock()
+	agents := slices.Clone(p.agents)
+	p.mu.RUnlock()
+	return directory.Filter(agents, params), nil
+}
+
+// Resolve returns one listed agent
+func (p *Provider) Resolve(agentID string) (a2a.AgentInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, agent := range p.agents {
+		if agent.AgentID == agentID {
+			return agent, nil
+		}
+	}
+	return a2a.AgentInfo{}, a2a.ErrAgentNotFound
+}
@@ -0,0 +1,118 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// HealthPath is where servers report whether they accept new tasks
+const HealthPath = "/health"
+
+// drainState counts accepted tasks so draining can wait for them
+type drainState struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{}   // closed once draining and no tasks are active
+	waiters  []chan struct{} // closed once no tasks are active
+}
+
+// acquireTask records a newly accepted task. It returns false while the
+// server is draining.
+func (s *A2AServer) acquireTask() bool {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	if s.drain.draining {
+		return false
+	}
+	s.drain.active++
+	return true
+}
+
+// releaseTask records that an accepted task reached a final state
+func (s *A2AServer) releaseTask() {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	s.drain.active--
+	if s.drain.active > 0 {
+		return
+	}
+	if s.drain.draining {
+		close(s.drain.idle)
+	}
+	for _, waiter := range s.drain.waiters {
+		close(waiter)
+	}
+	s.drain.waiters = nil
+}
+
+// Idle waits until no accepted task is queued, running or waiting for a
+// retry, or ctx ends. Unlike Drain it keeps accepting tasks, for hosts that
+// freeze the process between requests and so must not answer until
+// asynchronous work is done.
+func (s *A2AServer) Idle(ctx context.Context) error {
+	s.drain.mu.Lock()
+	if s.drain.active == 0 {
+		s.drain.mu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	s.drain.waiters = append(s.drain.waiters, idle)
+	s.drain.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain stops the server from accepting new tasks, makes the health
+// endpoint report not-ready and tells the directory the server is
+// draining, then waits until in-flight and queued tasks have finished or
+// ctx ends. The server keeps answering other requests.
+func (s *A2AServer) Drain(ctx context.Context) error {
+	s.drain.mu.Lock()
+	started := !s.drain.draining
+	if started {
+		s.drain.draining = true
+		s.drain.idle = make(chan struct{})
+		if s.drain.active == 0 {
+			close(s.drain.idle)
+		}
+		s.logger.Info("draining", "activeTasks", s.drain.active)
+	}
+	idle := s.drain.idle
+	s.drain.mu.Unlock()
+	if started {
+		s.reportStatus()
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Draining reports whether Drain has been called
+func (s *A2AServer) Draining() bool {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	return s.drain.draining
+}
+
+func (s *A2AServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	status, code := "ok", http.StatusOK
+	if s.Draining() {
+		status, code = "draining", http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
@@ -0,0 +1,197 @@
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error codes defined by the A2A specification
+const (
+	CodeTaskNotFound                 = -32001
+	CodeTaskNotCancelable            = -32002
+	CodePushNotificationNotSupported = -32003
+	CodeUnsupportedOperation         = -32004 // e.g. an action with no handler, or streaming over a connection that can't
+	CodeContentTypeNotSupported      = -32005
+	CodeInvalidAgentResponse         = -32006
+)
+
+// Error codes this SDK adds
+const (
+	// CodeTaskFailed is the code of a failed TaskResult's Error when the
+	// handler's error does not set one. It is never a JSON-RPC error, so
+	// sharing CodeTaskNotFound's number, as older versions of the protocol
+	// did, is unambiguous.
+	CodeTaskFailed = -32001
+	// CodeQueueFull refuses asynchronous tasks while the server's queue is
+	// at Limits.MaxQueuedTasks
+	CodeQueueFull = -32020
+	// CodeServerUnavailable refuses tasks while the server drains or shuts
+	// down; another instance may take them
+	CodeServerUnavailable = -32021
+	// CodeHopLimitExceeded refuses sub-tasks nested deeper than
+	// Limits.MaxHops, which usually means agents delegating in a loop
+	CodeHopLimitExceeded = -32022
+	// CodeInvalidSignature refuses requests whose JWS does not verify, and
+	// unsigned task and message requests to servers requiring signatures
+	CodeInvalidSignature = -32023
+	// CodeUnauthenticated refuses requests whose JWT does not verify, task
+	// and message requests whose sender is not the JWT's subject, and those
+	// without a JWT to servers requiring them
+	CodeUnauthenticated = -32024
+	// CodePermissionDenied refuses tasks the server's Authorizer does not
+	// allow
+	CodePermissionDenied = -32025
+)
+
+// TaskErrorData is the Data of errors about a particular task, such as
+// CodeTaskNotFound
+type TaskErrorData struct {
+	TaskID string `json:"taskId"`
+}
+
+// ContentTypeErrorData is the Data of CodeContentTypeNotSupported errors
+type ContentTypeErrorData struct {
+	Mode      string   `json:"mode,omitempty"` // the input mode refused, if any
+	Supported []string `json:"supported"`      // the modes the skill takes or produces
+}
+
+// HopLimitData is the Data of CodeHopLimitExceeded errors
+type HopLimitData struct {
+	TaskID     string `json:"taskId"`
+	RootTaskID string `json:"rootTaskId"`
+	Hop        int    `json:"hop"`
+	MaxHops    int    `json:"maxHops"`
+}
+
+// AuthorizationErrorData is the Data of CodePermissionDenied errors
+type AuthorizationErrorData struct {
+	Principal string `json:"principal,omitempty"` // the sender refused; empty if the task named none
+	AgentID   string `json:"agentId,omitempty"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"` // why the Authorizer refused the task
+}
+
+// UnsupportedOperationData is the Data of CodeUnsupportedOperation errors
+type UnsupportedOperationData struct {
+	Operation string `json:"operation"` // the method or action refused
+}
+
+// rpcData encodes v as the Data of a JSONRPCError
+func rpcData(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// taskNotFound is the error for requests naming an unknown task
+func taskNotFound(taskID string) *JSONRPCError {
+	return &JSONRPCError{Code: CodeTaskNotFound, Message: "Task not found", Data: rpcData(TaskErrorData{TaskID: taskID})}
+}
+
+// DecodeData decodes the error's Data into v, e.g. a *TaskErrorData
+func (e *JSONRPCError) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return errors.New("error has no data")
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// ErrorCode returns the code of the *JSONRPCError or *TaskError in err's
+// chain, or 0 if there is none
+func ErrorCode(err error) int {
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr.Code
+	}
+	return 0
+}
+
+// TaskError is an error a task handler can return to describe a failure
+// in more detail than a plain error. It is also how clients see the error
+// of a failed TaskResult.
+type TaskError struct {
+	Code      int
+	Message   string
+	Data      map[string]interface{}
+	Retryable bool
+	Err       error
+}
+
+// Error implements the error interface
+func (e *TaskError) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable marks err as transient so the server may re-execute the task
+// according to its retry policy
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TaskError{Code: CodeTaskFailed, Message: err.Error(), Retryable: true, Err: err}
+}
+
+// IsRetryable reports whether err was marked as retryable. Errors that are
+// not a TaskError are treated as permanent.
+func IsRetryable(err error) bool {
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr.Retryable
+	}
+	return false
+}
+
+// taskFailure converts a handler error into the structured error carried by
+// a failed TaskResult
+func taskFailure(err error) *JSONRPCError {
+	failure := &JSONRPCError{Code: CodeTaskFailed, Message: err.Error()}
+
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		if taskErr.Code != 0 {
+			failure.Code = taskErr.Code
+		}
+		if len(taskErr.Data) > 0 {
+			failure.Data, _ = json.Marshal(taskErr.Data)
+		}
+	}
+
+	return failure
+}
+
+// Err returns the failure of a task as a *TaskError, or nil if the task
+// has not failed
+func (r *TaskResult) Err() error {
+	if r.Status != TaskStateFailed {
+		return nil
+	}
+	if r.Error == nil {
+		return &TaskError{Code: CodeTaskFailed, Message: "task failed"}
+	}
+
+	taskErr := &TaskError{Code: r.Error.Code, Message: r.Error.Message}
+	if len(r.Error.Data) > 0 {
+		json.Unmarshal(r.Error.Data, &taskErr.Data)
+	}
+	return taskErr
+}
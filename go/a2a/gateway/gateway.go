@@ -0,0 +1,209 @@
+// Package gateway relays A2A requests from a public endpoint to agents on
+// private networks that accept no incoming connections. Each agent's server
+// dials out to the gateway with a Relay and keeps the connection open; the
+// gateway authenticates callers and forwards their requests over it:
+//
+//	gw := gateway.NewServer(gateway.WithAuthenticator(directory.HMACAuthenticator{Secret: secret}))
+//	log.Fatal(http.ListenAndServeTLS(":443", certFile, keyFile, gw.Handler()))
+//
+//	// behind the firewall
+//	server := a2a.NewServerWithOptions("billing", a2a.WithEndpoint(gateway.Endpoint("https://gw.example.com", "billing")))
+//	relay := &gateway.Relay{URL: "https://gw.example.com", Token: token}
+//	go relay.Listen(ctx, server)
+//
+// The connection carries HTTP/2 with the gateway as the client and the
+// agent's server.Handler() answering, so everything served under an
+// agent's path reaches it: JSON-RPC, message/stream, its Agent Card, REST
+// and uploads, with the server's middleware applied.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// RelayPath is where relays connect to the gateway
+const RelayPath = "/a2a/relay"
+
+// AgentsHeader lists, comma-separated, the agents a relay serves: the
+// relayed server's own agent first, then those it hosts
+const AgentsHeader = "A2A-Relay-Agents"
+
+// upgradeProtocol is what relays ask to switch their connection to
+const upgradeProtocol = "a2a-relay"
+
+// ErrUnauthorized is returned when a relay's credential may not serve one
+// of its agents
+var ErrUnauthorized = errors.New("gateway: unauthorized")
+
+// Authenticator decides whether credential, a bearer token or empty, may
+// serve agentID when a relay connects, or call it. directory's
+// TokenAuthenticator and HMACAuthenticator implement it.
+type Authenticator interface {
+	Authenticate(ctx context.Context, agentID, credential string) error
+}
+
+// Endpoint returns the endpoint at which the gateway at gatewayURL serves
+// agentID, the one to register for it
+func Endpoint(gatewayURL, agentID string) string {
+	return strings.TrimSuffix(gatewayURL, "/") + a2a.AgentPath(agentID)
+}
+
+// Server is an A2A gateway
+type Server struct {
+	auth       Authenticator
+	callerAuth Authenticator
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	tunnels map[string][]*tunnel // by agent ID, most recently connected last
+	next    int
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithLogger sets the logger used for gateway events
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithAuthenticator requires relays to pass auth for each agent they
+// serve. Without it, any process reaching RelayPath can take any agent's
+// requests.
+func WithAuthenticator(auth Authenticator) Option {
+	return func(s *Server) {
+		s.auth = auth
+	}
+}
+
+// WithCallerAuthenticator requires requests to pass auth for the agent
+// they are for. The credential is not forwarded to the agent. Agent Cards
+// stay public, so callers can discover what credential to present.
+func WithCallerAuthenticator(auth Authenticator) Option {
+	return func(s *Server) {
+		s.callerAuth = auth
+	}
+}
+
+// NewServer returns a gateway
+func NewServer(opts ...Option) *Server {
+	s := &Server{logger: slog.Default(), tunnels: make(map[string][]*tunnel)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Handler returns the gateway's HTTP handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(RelayPath, s.handleRelay)
+	mux.HandleFunc(a2a.AgentPath(""), s.handleAgent)
+	return mux
+}
+
+// ListenAndServe serves the gateway on addr
+func (s *Server) ListenAndServe(addr string) error {
+	s.logger.Info(fmt.Sprintf("🚪 A2A Gateway running on %s", addr))
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// ListenAndServeTLS serves the gateway on addr over HTTPS. Relays connect
+// over HTTP/1.1; callers may use HTTP/2.
+func (s *Server) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	s.logger.Info(fmt.Sprintf("🚪 A2A Gateway running on %s", addr))
+	return http.ListenAndServeTLS(addr, certFile, keyFile, s.Handler())
+}
+
+// Connected returns the IDs of the agents with a relay connected, sorted
+func (s *Server) Connected() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.tunnels))
+	for id := range s.tunnels {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// handleAgent forwards a request under a2a.AgentPath(agentID) to a relay
+// serving the agent. Agents with several relays connected, such as
+// replicas, have requests spread across them.
+func (s *Server) handleAgent(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, a2a.AgentPath(""))
+	agentID, rest, _ := strings.Cut(rest, "/")
+	if agentID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	rest = "/" + rest
+
+	if s.callerAuth != nil && !strings.HasPrefix(rest, "/.well-known/") {
+		if err := s.callerAuth.Authenticate(r.Context(), agentID, bearer(r)); err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	t := s.tunnel(agentID)
+	if t == nil {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Agent not connected", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The relayed server answers its own agent at its root
+	path := rest
+	if agentID != t.agents[0] {
+		path = a2a.AgentPath(agentID) + rest
+	}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetXForwarded()
+			pr.Out.URL.Scheme = "http"
+			pr.Out.URL.Host = "relay"
+			pr.Out.URL.Path, pr.Out.URL.RawPath = path, ""
+			if s.callerAuth != nil {
+				pr.Out.Header.Del("Authorization")
+			}
+		},
+		Transport:     t.transport,
+		FlushInterval: -1, // pass events on as they arrive
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			s.logger.Warn("relaying request", "agentId", agentID, "error", err)
+			http.Error(w, "Bad gateway", http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// tunnel returns a relay serving agentID, taking turns between several
+func (s *Server) tunnel(agentID string) *tunnel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tunnels := s.tunnels[agentID]
+	if len(tunnels) == 0 {
+		return nil
+	}
+	s.next++
+	return tunnels[s.next%len(tunnels)]
+}
+
+// bearer returns the request's bearer token, empty if none was sent
+func bearer(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
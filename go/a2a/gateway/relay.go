@@ -0,0 +1,207 @@
+package gateway
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Relay connects a server to a gateway, so the gateway can forward the
+// requests of callers it cannot reach on its own
+type Relay struct {
+	// URL is the gateway's, e.g. "https://gw.example.com"
+	URL string
+	// Token is sent to the gateway as a bearer token, for its
+	// Authenticator to check against each agent the server serves
+	Token string
+	// TLSConfig is used for https:// gateways; nil means the defaults
+	TLSConfig *tls.Config
+	Logger    *slog.Logger // nil means slog.Default()
+}
+
+func (r *Relay) logger() *slog.Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return slog.Default()
+}
+
+// Listen connects to the gateway and serves server's agent and those it
+// hosts, as they are when each connection is made, over it until ctx is
+// done, reconnecting with backoff whenever the connection drops. It returns
+// ErrUnauthorized if the gateway refuses the relay's credential, without
+// retrying.
+func (r *Relay) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := r.serve(ctx, server)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrUnauthorized) {
+			return err
+		}
+		if time.Since(start) > time.Minute {
+			backoff = time.Second // the connection was up, not flapping
+		}
+		r.logger().Warn("relay disconnected, reconnecting", "gateway", r.URL, "in", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// serve makes one connection to the gateway and serves server over it
+// until it drops or ctx is done
+func (r *Relay) serve(ctx context.Context, server *a2a.A2AServer) error {
+	conn, err := r.connect(ctx, server)
+	if err != nil {
+		return err
+	}
+	r.logger().Info("relay connected", "gateway", r.URL)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	httpServer := &http.Server{
+		Handler:   server.Handler(),
+		Protocols: protocols,
+		HTTP2:     &http.HTTP2Config{SendPingTimeout: pingInterval, PingTimeout: pingTimeout},
+	}
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if httpServer.Shutdown(shutdownCtx) != nil {
+			httpServer.Close()
+		}
+	})
+	defer stop()
+	return httpServer.Serve(newConnListener(conn))
+}
+
+// connect dials the gateway and switches the connection to the relay
+// protocol
+func (r *Relay) connect(ctx context.Context, server *a2a.A2AServer) (*tunnelConn, error) {
+	u, err := url.Parse(strings.TrimSuffix(r.URL, "/") + RelayPath)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: %w", err)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "https":
+		config := r.TLSConfig.Clone()
+		if config == nil {
+			config = new(tls.Config)
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		config.NextProtos = []string{"http/1.1"} // upgrades need HTTP/1.1
+		conn, err = (&tls.Dialer{Config: config}).DialContext(ctx, "tcp", addr)
+	case "http":
+		conn, err = new(net.Dialer).DialContext(ctx, "tcp", addr)
+	default:
+		return nil, fmt.Errorf("gateway: %q is not an http:// or https:// URL", r.URL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gateway: %w", err)
+	}
+
+	var agents []string
+	for _, agent := range server.Agents() {
+		agents = append(agents, agent.AgentID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", upgradeProtocol)
+	req.Header.Set(AgentsHeader, strings.Join(agents, ","))
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	br := bufio.NewReader(conn)
+	if err = req.Write(conn); err == nil {
+		var resp *http.Response
+		if resp, err = http.ReadResponse(br, req); err == nil {
+			resp.Body.Close()
+			switch resp.StatusCode {
+			case http.StatusSwitchingProtocols:
+			case http.StatusUnauthorized:
+				err = ErrUnauthorized
+			default:
+				err = fmt.Errorf("gateway: HTTP %d", resp.StatusCode)
+			}
+		}
+	}
+	if err != nil || !stop() {
+		conn.Close()
+		return nil, cmp.Or(err, ctx.Err())
+	}
+	return newTunnelConn(conn, br), nil
+}
+
+// connListener hands out one connection, then blocks until it or the
+// listener closes. Closing the listener leaves the connection to finish
+// the requests in progress.
+type connListener struct {
+	conn     *tunnelConn
+	accepted sync.Once
+	done     chan struct{}
+	closed   sync.Once
+}
+
+func newConnListener(conn *tunnelConn) *connListener {
+	return &connListener{conn: conn, done: make(chan struct{})}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.accepted.Do(func() { conn = l.conn })
+	if conn != nil {
+		return conn, nil
+	}
+	select {
+	case <-l.conn.closed:
+	case <-l.done:
+	}
+	return nil, errTunnelClosed
+}
+
+func (l *connListener) Close() error {
+	l.closed.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
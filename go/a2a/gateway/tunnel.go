@@ -0,0 +1,178 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Relay connections are pinged after this long without traffic and
+// dropped if the ping goes unanswered for pingTimeout, so connections a
+// NAT or firewall silently dropped are noticed
+const (
+	pingInterval = 30 * time.Second
+	pingTimeout  = 15 * time.Second
+)
+
+// errTunnelClosed fails requests once a relay's connection has closed
+var errTunnelClosed = errors.New("gateway: relay disconnected")
+
+// tunnel is a relay's connection, over which the gateway sends requests
+// as an HTTP/2 client
+type tunnel struct {
+	agents    []string // the relayed server's own agent first
+	transport *http.Transport
+}
+
+// handleRelay takes a relay's connection: it authenticates the relay for
+// each agent it serves, switches the connection to the relay protocol and
+// starts sending the agents' requests over it
+func (s *Server) handleRelay(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), upgradeProtocol) {
+		w.Header().Set("Upgrade", upgradeProtocol)
+		http.Error(w, "Upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+	var agents []string
+	for _, id := range strings.Split(r.Header.Get(AgentsHeader), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			agents = append(agents, id)
+		}
+	}
+	if len(agents) == 0 {
+		http.Error(w, AgentsHeader+" required", http.StatusBadRequest)
+		return
+	}
+	if s.auth != nil {
+		for _, id := range agents {
+			if err := s.auth.Authenticate(r.Context(), id, bearer(r)); err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Relays need HTTP/1.1", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + upgradeProtocol + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+
+	c := newTunnelConn(conn, rw.Reader)
+	t := &tunnel{agents: agents, transport: newTunnelTransport(c)}
+	// Open the HTTP/2 connection before requests can race to dial it
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := t.check(ctx); err != nil {
+		s.logger.Warn("relay connection failed", "agents", agents, "remote", r.RemoteAddr, "error", err)
+		c.Close()
+		return
+	}
+	s.add(t)
+	s.logger.Info("relay connected", "agents", agents, "remote", r.RemoteAddr)
+	go func() {
+		<-c.closed
+		s.remove(t)
+		t.transport.CloseIdleConnections()
+		s.logger.Info("relay disconnected", "agents", agents, "remote", r.RemoteAddr)
+	}()
+}
+
+// check asks the relayed server for its health
+func (t *tunnel) check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://relay"+a2a.HealthPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *Server) add(t *tunnel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range t.agents {
+		s.tunnels[id] = append(s.tunnels[id], t)
+	}
+}
+
+func (s *Server) remove(t *tunnel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range t.agents {
+		tunnels := s.tunnels[id]
+		for i, other := range tunnels {
+			if other == t {
+				tunnels = append(tunnels[:i:i], tunnels[i+1:]...)
+				break
+			}
+		}
+		if len(tunnels) == 0 {
+			delete(s.tunnels, id)
+		} else {
+			s.tunnels[id] = tunnels
+		}
+	}
+}
+
+// newTunnelTransport returns an HTTP/2 client sending every request over
+// conn. conn is dialed once: when it closes, requests fail.
+func newTunnelTransport(conn net.Conn) *http.Transport {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	var once sync.Once
+	return &http.Transport{
+		Protocols: protocols,
+		DialContext: func(context.Context, string, string) (net.Conn, error) {
+			dialed := errTunnelClosed
+			once.Do(func() { dialed = nil })
+			if dialed != nil {
+				return nil, dialed
+			}
+			return conn, nil
+		},
+		HTTP2: &http.HTTP2Config{SendPingTimeout: pingInterval, PingTimeout: pingTimeout},
+	}
+}
+
+// tunnelConn is a relay's connection, reading first what was buffered
+// while switching protocols and reporting when it closes
+type tunnelConn struct {
+	net.Conn
+	r      *bufio.Reader
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newTunnelConn(conn net.Conn, r *bufio.Reader) *tunnelConn {
+	return &tunnelConn{Conn: conn, r: r, closed: make(chan struct{})}
+}
+
+func (c *tunnelConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *tunnelConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { close(c.closed) })
+	return err
+}
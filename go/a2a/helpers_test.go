@@ -0,0 +1,40 @@
+package a2a
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testDirectory resolves every agent to one endpoint
+type testDirectory struct{ endpoint string }
+
+func (d testDirectory) Register(params RegisterParams) (RegisterResult, error) {
+	return RegisterResult{}, nil
+}
+
+func (d testDirectory) Discover(params DiscoverParams) ([]AgentInfo, error) {
+	return nil, nil
+}
+
+func (d testDirectory) Resolve(agentID string) (AgentInfo, error) {
+	return AgentInfo{AgentID: agentID, Endpoint: d.endpoint}, nil
+}
+
+func (d testDirectory) Deregister(agentID string) error {
+	return nil
+}
+
+// serveTest serves a server created with opts over HTTP, with the test
+// server's URL as its endpoint
+func serveTest(t *testing.T, opts ...ServerOption) (*A2AServer, *httptest.Server) {
+	t.Helper()
+	var server *A2AServer
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	opts = append([]ServerOption{WithName("Test Server"), WithEndpoint(ts.URL)}, opts...)
+	server = NewServerWithOptions("server", opts...)
+	return server, ts
+}
@@ -0,0 +1,109 @@
+package a2a
+
+import "context"
+
+// TaskHook is called with a task at a point in its lifecycle. result is nil
+// for OnTaskReceived hooks.
+type TaskHook func(ctx context.Context, task TaskParams, result *TaskResult)
+
+// hooks holds the lifecycle callbacks registered on a server
+type hooks struct {
+	taskReceived  []TaskHook
+	taskCompleted []TaskHook
+	taskFailed    []TaskHook
+	taskCanceled  []TaskHook
+	startup       []func()
+	shutdown      []func()
+}
+
+// OnTaskReceived registers a hook called when a task is accepted, before
+// its handler runs
+func (s *A2AServer) OnTaskReceived(hook TaskHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks.taskReceived = append(s.hooks.taskReceived, hook)
+}
+
+// OnTaskCompleted registers a hook called after a task completes
+func (s *A2AServer) OnTaskCompleted(hook TaskHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks.taskCompleted = append(s.hooks.taskCompleted, hook)
+}
+
+// OnTaskFailed registers a hook called after a task fails for good, i.e.
+// once no retries remain
+func (s *A2AServer) OnTaskFailed(hook TaskHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks.taskFailed = append(s.hooks.taskFailed, hook)
+}
+
+// OnTaskCanceled registers a hook called after a task is canceled, once
+// whatever was running or queued for it has stopped
+func (s *A2AServer) OnTaskCanceled(hook TaskHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks.taskCanceled = append(s.hooks.taskCanceled, hook)
+}
+
+// OnStartup registers a hook called once the server is listening
+func (s *A2AServer) OnStartup(hook func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks.startup = append(s.hooks.startup, hook)
+}
+
+// OnShutdown registers a hook called after the server has shut down
+func (s *A2AServer) OnShutdown(hook func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks.shutdown = append(s.hooks.shutdown, hook)
+}
+
+func (s *A2AServer) taskReceived(ctx context.Context, task TaskParams) {
+	s.mu.RLock()
+	received := s.hooks.taskReceived
+	s.mu.RUnlock()
+	for _, hook := range received {
+		hook(ctx, task, nil)
+	}
+}
+
+// taskFinished calls the completed or failed hooks for a final result.
+// Tasks waiting for input have not finished, and call them once resumed.
+func (s *A2AServer) taskFinished(ctx context.Context, task TaskParams, result *TaskResult) {
+	s.mu.RLock()
+	var finished []TaskHook
+	switch result.Status {
+	case TaskStateCompleted:
+		finished = s.hooks.taskCompleted
+	case TaskStateFailed:
+		finished = s.hooks.taskFailed
+	}
+	s.mu.RUnlock()
+	for _, hook := range finished {
+		hook(ctx, task, result)
+	}
+}
+
+// taskCanceled calls the canceled hooks, once per task: from cancelTask
+// for tasks waiting for input, and otherwise from the task's runner once it
+// sees the task was canceled
+func (s *A2AServer) taskCanceled(ctx context.Context, task TaskParams, result *TaskResult) {
+	s.mu.RLock()
+	canceled := s.hooks.taskCanceled
+	s.mu.RUnlock()
+	for _, hook := range canceled {
+		hook(ctx, task, result)
+	}
+}
+
+func (s *A2AServer) runHooks(list func(*hooks) []func()) {
+	s.mu.RLock()
+	funcs := list(&s.hooks)
+	s.mu.RUnlock()
+	for _, hook := range funcs {
+		hook()
+	}
+}
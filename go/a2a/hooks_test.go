@@ -0,0 +1,72 @@
+package a2a
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHooksSkipPausedTasks(t *testing.T) {
+	server, ts := serveTest(t)
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		if input["answer"] == nil {
+			return nil, RequireInput("What is the answer?")
+		}
+		return map[string]interface{}{"answer": input["answer"]}, nil
+	})
+	var completed, failed atomic.Int32
+	server.OnTaskCompleted(func(ctx context.Context, task TaskParams, result *TaskResult) {
+		completed.Add(1)
+	})
+	server.OnTaskFailed(func(ctx context.Context, task TaskParams, result *TaskResult) {
+		failed.Add(1)
+	})
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+
+	paused, err := agent.SendTask("server", "ask", nil, "")
+	if err != nil || paused.Status != TaskStateInputRequired {
+		t.Fatalf("task: %v, %v; want it waiting for input", paused, err)
+	}
+	if n := completed.Load(); n != 0 {
+		t.Errorf("completed hooks called %d times for a paused task", n)
+	}
+	result, err := agent.ResumeTask("server", paused.TaskID, map[string]interface{}{"answer": 42}, "")
+	if err != nil || result.Status != TaskStateCompleted {
+		t.Fatalf("resumed task: %v, %v", result, err)
+	}
+	if n, f := completed.Load(), failed.Load(); n != 1 || f != 0 {
+		t.Errorf("completed hooks called %d times and failed hooks %d, want once and never", n, f)
+	}
+}
+
+func TestHooksCancelEvictedTasks(t *testing.T) {
+	server, ts := serveTest(t, WithLimits(Limits{MaxPausedTasks: 1}))
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return nil, RequireInput("What is the answer?")
+	})
+	var canceled []string
+	server.OnTaskCanceled(func(ctx context.Context, task TaskParams, result *TaskResult) {
+		canceled = append(canceled, task.TaskID)
+	})
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+
+	first, err := agent.SendTask("server", "ask", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := agent.SendTask("server", "ask", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(canceled) != 1 || canceled[0] != first.TaskID {
+		t.Fatalf("canceled hooks called for %v, want only the first task", canceled)
+	}
+	if evicted, err := agent.GetTask("server", first.TaskID, ""); err != nil || evicted.Status != TaskStateCanceled {
+		t.Errorf("evicted task: %v, %v; want it canceled", evicted, err)
+	}
+	if !server.isPaused(second.TaskID) || server.isPaused(first.TaskID) {
+		t.Error("want only the second task waiting for input")
+	}
+}
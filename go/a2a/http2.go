@@ -0,0 +1,36 @@
+package a2a
+
+import "net/http"
+
+// WithH2C lets the server accept unencrypted HTTP/2 (h2c) alongside
+// HTTP/1.1, so busy agents can multiplex many concurrent task requests over
+// one TCP connection in plaintext clusters. TLS servers negotiate HTTP/2
+// without this option.
+func WithH2C() ServerOption {
+	return func(s *A2AServer) {
+		s.h2c = true
+	}
+}
+
+// protocols returns the HTTP protocols the server accepts
+func (s *A2AServer) protocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(s.h2c)
+	return protocols
+}
+
+// NewH2CClient returns an HTTP client that speaks HTTP/2 with prior
+// knowledge to http:// endpoints and negotiates HTTP/2 for https://
+// endpoints. Use it as A2AAgent.HTTPClient when talking to servers started
+// with WithH2C.
+func NewH2CClient() *http.Client {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Protocols = protocols
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,197 @@
+package a2a
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the result cache servers use unless WithResultCache is given
+const (
+	defaultResultCacheSize = 10000
+	defaultResultCacheTTL  = time.Hour
+)
+
+// ResultCache stores task results by idempotency key so retried requests
+// return the original result instead of running the handler again. Keys
+// are scoped to the task's agent, action and sender.
+type ResultCache interface {
+	Get(key string) (*TaskResult, bool)
+	Put(key string, result *TaskResult, ttl time.Duration)
+}
+
+// WithResultCache sets where results are remembered by idempotency key and
+// for how long. A nil cache disables idempotency handling.
+func WithResultCache(cache ResultCache, ttl time.Duration) ServerOption {
+	return func(s *A2AServer) {
+		s.resultCache = cache
+		s.resultTTL = ttl
+	}
+}
+
+// WithIdempotencyKey marks a task so that resending it with the same key
+// returns the stored result rather than executing it again
+func WithIdempotencyKey(key string) TaskOption {
+	return func(p *TaskParams) {
+		p.IdempotencyKey = key
+	}
+}
+
+// idempotencyKey scopes a task's key to its agent, action and sender so
+// different callers cannot see each other's results, nor one action's
+// result answer another
+func idempotencyKey(task TaskParams) string {
+	if task.IdempotencyKey == "" {
+		return ""
+	}
+	return strings.Join([]string{task.AgentID, task.Action, task.Sender, task.IdempotencyKey}, "\x00")
+}
+
+// idempotentRuns holds a channel for each idempotency key whose task is
+// being started or run, closed once its result is remembered or it fails
+type idempotentRuns struct {
+	mu   sync.Mutex
+	runs map[string]chan struct{}
+}
+
+// claimResult returns the remembered result for a task, first waiting for
+// a duplicate already in flight. Without one it claims the task's key,
+// returning a func that releases it, which the caller calls once it has
+// remembered the task's result or given up on it.
+func (s *A2AServer) claimResult(ctx context.Context, task TaskParams) (*TaskResult, func(), *JSONRPCError) {
+	key := idempotencyKey(task)
+	if key == "" || s.resultCache == nil {
+		return nil, func() {}, nil
+	}
+	for {
+		s.idempotent.mu.Lock()
+		running, ok := s.idempotent.runs[key]
+		if !ok {
+			break
+		}
+		s.idempotent.mu.Unlock()
+		select {
+		case <-running:
+		case <-ctx.Done():
+			return nil, nil, &JSONRPCError{Code: CodeInternalError, Message: "Request canceled"}
+		}
+	}
+	if s.idempotent.runs == nil {
+		s.idempotent.runs = make(map[string]chan struct{})
+	}
+	done := make(chan struct{})
+	s.idempotent.runs[key] = done
+	s.idempotent.mu.Unlock()
+	release := func() {
+		s.idempotent.mu.Lock()
+		delete(s.idempotent.runs, key)
+		s.idempotent.mu.Unlock()
+		close(done)
+	}
+
+	// Checked holding the key, so a run remembering its result just before
+	// is seen
+	if cached, ok := s.cachedResult(task); ok {
+		release()
+		return cached, nil, nil
+	}
+	return nil, release, nil
+}
+
+// cachedResult returns the remembered result for a task, refreshed from the
+// task store if it was still running when remembered
+func (s *A2AServer) cachedResult(task TaskParams) (*TaskResult, bool) {
+	key := idempotencyKey(task)
+	if key == "" || s.resultCache == nil {
+		return nil, false
+	}
+
+	result, ok := s.resultCache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if !result.Status.Terminal() {
+		if current, err := s.store.Get(result.TaskID); err == nil {
+			result = current
+		}
+	}
+	return result, true
+}
+
+// rememberResult stores a task's result under its idempotency key
+func (s *A2AServer) rememberResult(task TaskParams, result *TaskResult) {
+	key := idempotencyKey(task)
+	if key == "" || s.resultCache == nil {
+		return
+	}
+	stored := *result
+	s.resultCache.Put(key, &stored, s.resultTTL)
+}
+
+// MemoryResultCache is an in-memory ResultCache holding at most a fixed
+// number of entries, evicting the least recently used
+type MemoryResultCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // front is most recently used
+}
+
+type cacheEntry struct {
+	key     string
+	result  *TaskResult
+	expires time.Time
+}
+
+// NewMemoryResultCache creates a cache holding up to maxEntries results
+func NewMemoryResultCache(maxEntries int) *MemoryResultCache {
+	return &MemoryResultCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns an unexpired copy of the result stored under key
+func (c *MemoryResultCache) Get(key string) (*TaskResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	result := *entry.result
+	return &result, true
+}
+
+// Put stores result under key for ttl, evicting the least recently used
+// entry when the cache is full
+func (c *MemoryResultCache) Put(key string, result *TaskResult, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{key: key, result: result, expires: time.Now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
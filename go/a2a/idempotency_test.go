@@ -0,0 +1,52 @@
+package a2a
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotentDuplicates(t *testing.T) {
+	server, ts := serveTest(t)
+	var runs atomic.Int32
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		runs.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return map[string]interface{}{"action": action}, nil
+	})
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+
+	// Duplicates sent while the first runs wait for its result
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := agent.SendTask("server", "charge", nil, "", WithIdempotencyKey("order-1"))
+			if err != nil || result.Output["action"] != "charge" {
+				t.Errorf("duplicate: %v, %v", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := runs.Load(); n != 1 {
+		t.Errorf("handler ran %d times for duplicates, want once", n)
+	}
+
+	// The key is another action's own
+	result, err := agent.SendTask("server", "refund", nil, "", WithIdempotencyKey("order-1"))
+	if err != nil || result.Output["action"] != "refund" {
+		t.Errorf("another action with the key: %v, %v; want it run", result, err)
+	}
+	other := NewAgent("other", "Other", nil)
+	other.Directory = testDirectory{ts.URL}
+	if _, err := other.SendTask("server", "charge", nil, "", WithIdempotencyKey("order-1")); err != nil {
+		t.Fatal(err)
+	}
+	if n := runs.Load(); n != 3 {
+		t.Errorf("handler ran %d times, want 3", n)
+	}
+}
@@ -0,0 +1,230 @@
+package a2a
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Defaults for how many tasks may wait for input and for how long, unless
+// the server's Limits say otherwise
+const (
+	defaultMaxPausedTasks = 10000
+	defaultInputTimeout   = time.Hour
+)
+
+// InputRequiredError is returned by a task handler to pause the task in
+// the input-required state until the client sends more input
+type InputRequiredError struct {
+	Prompt Message // what the handler needs, shown to the client as the task's status message
+}
+
+// Error implements the error interface
+func (e *InputRequiredError) Error() string {
+	return "input required: " + e.Prompt.Text()
+}
+
+// RequireInput returns an error that pauses the task a handler is running,
+// asking the client for what prompt describes. The client resumes the task
+// with ResumeTask, or a message/send for the same task ID, and the handler
+// is called again with the new input merged into the old.
+func RequireInput(prompt string) error {
+	return &InputRequiredError{Prompt: NewMessage(RoleAgent, TextPart{Text: prompt})}
+}
+
+type historyKey struct{}
+
+// TaskHistory returns the turns of an interactive task before the current
+// one, oldest first: what the client sent and each prompt the handler
+// paused with
+func TaskHistory(ctx context.Context) []Message {
+	history, _ := ctx.Value(historyKey{}).([]Message)
+	return history
+}
+
+// pausedTasks holds tasks waiting for input, by task ID
+type pausedTasks struct {
+	mu    sync.Mutex
+	tasks map[string]pausedTask
+	swept time.Time // when tasks waiting too long were last looked for
+}
+
+type pausedTask struct {
+	params  TaskParams // with the input the handler was called with
+	history []Message
+	since   time.Time // when the task paused
+}
+
+// pause records a task the handler paused with input, so it can be resumed
+func (s *A2AServer) pause(task TaskParams, input map[string]interface{}, prompt Message) {
+	history := append(slices.Clip(task.history), turn(task, input))
+	task.Input = input
+	task.Message = nil
+	task.history = nil
+
+	s.paused.mu.Lock()
+	if s.paused.tasks == nil {
+		s.paused.tasks = make(map[string]pausedTask)
+	}
+	s.paused.tasks[task.TaskID] = pausedTask{params: task, history: append(history, prompt), since: time.Now()}
+	expired := s.paused.sweep(s.inputTimeout(), cmp.Or(s.limits.MaxPausedTasks, defaultMaxPausedTasks))
+	s.paused.mu.Unlock()
+
+	// Tasks nobody resumed are canceled rather than left waiting forever.
+	// cancelTask forgets them, but not those it cannot cancel.
+	for _, taskID := range expired {
+		s.logger.Debug("task stopped waiting for input", "taskId", taskID)
+		s.cancelTask(taskID)
+		s.unpause(taskID)
+	}
+}
+
+// sweep returns the IDs of the tasks that waited longer than timeout and,
+// while more than max are waiting, of those waiting longest, for the
+// caller to cancel. The caller holds p.mu.
+func (p *pausedTasks) sweep(timeout time.Duration, max int) []string {
+	var expired []string
+	now := time.Now()
+	if now.Sub(p.swept) >= min(timeout, time.Minute) {
+		p.swept = now
+		for taskID, paused := range p.tasks {
+			if now.Sub(paused.since) > timeout {
+				expired = append(expired, taskID)
+			}
+		}
+	}
+	if over := len(p.tasks) - max; over > len(expired) {
+		// Tasks that waited longer than timeout are among the oldest
+		expired = slices.SortedFunc(maps.Keys(p.tasks), func(a, b string) int {
+			return p.tasks[a].since.Compare(p.tasks[b].since)
+		})[:over]
+	}
+	return expired
+}
+
+// inputTimeout is how long a paused task waits for input
+func (s *A2AServer) inputTimeout() time.Duration {
+	return cmp.Or(s.limits.InputTimeout, defaultInputTimeout)
+}
+
+// turn is what the client sent in one turn of a task
+func turn(task TaskParams, input map[string]interface{}) Message {
+	if task.Message != nil {
+		return *task.Message
+	}
+	return MessageFromInput(input)
+}
+
+// unpause forgets a task that is no longer waiting for input, returning
+// it if it was
+func (s *A2AServer) unpause(taskID string) (pausedTask, bool) {
+	s.paused.mu.Lock()
+	defer s.paused.mu.Unlock()
+	paused, ok := s.paused.tasks[taskID]
+	delete(s.paused.tasks, taskID)
+	return paused, ok
+}
+
+// isPaused reports whether the task is waiting for input
+func (s *A2AServer) isPaused(taskID string) bool {
+	_, ok := s.waiting(taskID)
+	return ok
+}
+
+// waiting returns the task with taskID if it is waiting for input
+func (s *A2AServer) waiting(taskID string) (pausedTask, bool) {
+	s.paused.mu.Lock()
+	defer s.paused.mu.Unlock()
+	paused, ok := s.paused.tasks[taskID]
+	if !ok || time.Since(paused.since) > s.inputTimeout() {
+		return pausedTask{}, false
+	}
+	return paused, true
+}
+
+// resumes returns the paused task that task continues, if any, defaulting
+// the action, target agent and correlation ID of task to the paused
+// task's. It refuses tasks from senders other than the paused task's and
+// tasks to resume that are not paused.
+func (s *A2AServer) resumes(task *TaskParams) (*pausedTask, *JSONRPCError) {
+	paused, ok := s.waiting(task.TaskID)
+	if !ok {
+		if task.Resume {
+			return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "Task is not waiting for input", Data: rpcData(TaskErrorData{TaskID: task.TaskID})}
+		}
+		return nil, nil
+	}
+	if task.Sender != paused.params.Sender {
+		s.logger.Warn("refusing to resume another sender's task", "taskId", task.TaskID, "sender", task.Sender, "correlationId", task.CorrelationID)
+		return nil, notSender(task.Sender, TaskOwner{Principal: paused.params.Sender, AgentID: paused.params.AgentID, Action: paused.params.Action})
+	}
+
+	if task.Action == "" {
+		task.Action = paused.params.Action
+	}
+	if task.AgentID == "" {
+		task.AgentID = paused.params.AgentID
+	}
+	if task.CorrelationID == "" {
+		task.CorrelationID = paused.params.CorrelationID
+	}
+	return &paused, nil
+}
+
+// resume continues the paused task with the new input in task, merging it
+// over the old. The task stays paused until its handler runs again.
+func (paused *pausedTask) resume(task *TaskParams) {
+	// The new turn's message (with any input sent alongside it) has to win
+	// over the old input, so merge it in here rather than in withMessage
+	latest := task.Input
+	if task.Message != nil {
+		latest = task.Message.Input()
+		maps.Copy(latest, task.Input)
+	}
+	input := maps.Clone(paused.params.Input)
+	if input == nil {
+		input = make(map[string]interface{}, len(latest))
+	}
+	maps.Copy(input, latest)
+	task.Input = input
+	task.history = paused.history
+}
+
+// withHistory makes an interactive task's earlier turns available to its
+// handler
+func withHistory(ctx context.Context, task TaskParams) context.Context {
+	if len(task.history) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, historyKey{}, task.history)
+}
+
+// inputRequired reports whether a handler error pauses the task, and with
+// which prompt
+func inputRequired(err error) (Message, bool) {
+	var required *InputRequiredError
+	if !errors.As(err, &required) {
+		return Message{}, false
+	}
+	prompt := required.Prompt
+	if prompt.Role == "" {
+		prompt.Role = RoleAgent
+	}
+	if prompt.MessageID == "" {
+		prompt.MessageID = generateID()
+	}
+	prompt.Kind = "message"
+	return prompt, true
+}
+
+// ResumeTask continues a task paused in the input-required state, sending
+// the input asked for in the result's StatusMessage. The task may pause
+// again.
+func (a *A2AAgent) ResumeTask(targetAgentID, taskID string, input map[string]interface{}, directoryURL string, opts ...TaskOption) (*TaskResult, error) {
+	opts = append([]TaskOption{func(p *TaskParams) { p.TaskID, p.Resume = taskID, true }}, opts...)
+	return a.sendTask("a2a/task", targetAgentID, "", input, directoryURL, opts)
+}
@@ -0,0 +1,106 @@
+// Package adaptertest checks that a web framework adapter routes requests
+// to an A2A server the way serving its handler directly would. Each
+// adapter's tests call Test with a func mounting a server on a new router
+// of its framework.
+package adaptertest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Mount returns a new router serving server under prefix, with its Agent
+// Card also at the site root
+type Mount func(prefix string, server *a2a.A2AServer) http.Handler
+
+// Test checks the routes of a router mount builds for each prefix: the
+// JSON-RPC endpoint with and without a trailing slash, hosted agents
+// reached through the path, Agent Cards, the health check, and requests
+// that must be refused
+func Test(t *testing.T, mount Mount, prefixes ...string) {
+	for _, prefix := range prefixes {
+		t.Run("prefix="+prefix, func(t *testing.T) {
+			testPrefix(t, mount(prefix, newServer()), prefix)
+		})
+	}
+}
+
+func newServer() *a2a.A2AServer {
+	server := a2a.NewServerWithOptions("echo", a2a.WithName("Echo"), a2a.WithCapabilities("echo"))
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return input, nil
+	})
+	server.HostAgent("helper", "Helper", []string{"help"}).HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return map[string]interface{}{"text": "helped"}, nil
+	})
+	return server
+}
+
+// request is a request to a mounted server and what it should get back
+type request struct {
+	name   string
+	method string
+	path   string
+	want   int    // status code; 0 means any client error
+	field  string // of the response, checked against value: "name" of an Agent Card, or of the task output
+	value  string
+}
+
+func testPrefix(t *testing.T, router http.Handler, prefix string) {
+	task := `{"jsonrpc":"2.0","id":1,"method":"a2a/task","params":{"action":"echo","input":{"text":"hi"}}}`
+	prefix = strings.TrimSuffix(prefix, "/")
+	root := prefix
+	if root == "" {
+		root = "/"
+	}
+	cases := []request{
+		{"JSON-RPC", http.MethodPost, root, http.StatusOK, "text", "hi"},
+		{"JSON-RPC with trailing slash", http.MethodPost, prefix + "/", http.StatusOK, "text", "hi"},
+		{"hosted agent", http.MethodPost, prefix + "/agents/helper", http.StatusOK, "text", "helped"},
+		{"unknown hosted agent", http.MethodPost, prefix + "/agents/nobody", http.StatusNotFound, "", ""},
+		{"JSON-RPC by GET", http.MethodGet, prefix + "/", http.StatusMethodNotAllowed, "", ""},
+		{"agent card", http.MethodGet, prefix + a2a.AgentCardPath, http.StatusOK, "name", "Echo"},
+		{"hosted agent card", http.MethodGet, prefix + "/agents/helper" + a2a.AgentCardPath, http.StatusOK, "name", "Helper"},
+		{"health", http.MethodGet, prefix + a2a.HealthPath, http.StatusOK, "", ""},
+		{"agent card at the root", http.MethodGet, a2a.AgentCardPath, http.StatusOK, "name", "Echo"},
+		{"agent card at the root by POST", http.MethodPost, a2a.AgentCardPath, 0, "", ""},
+	}
+	if prefix != "" {
+		cases = append(cases, request{"outside the prefix", http.MethodPost, "/elsewhere", http.StatusNotFound, "", ""})
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(task)))
+			if tc.want == 0 && (rec.Code < 400 || rec.Code >= 500) || tc.want != 0 && rec.Code != tc.want {
+				t.Fatalf("%s %s: HTTP %d, Location %q, want %d", tc.method, tc.path, rec.Code, rec.Header().Get("Location"), tc.want)
+			}
+			if tc.field == "" {
+				return
+			}
+			var resp struct {
+				Result struct {
+					Output map[string]interface{} `json:"output"`
+				} `json:"result"`
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("%s %s: %v: %s", tc.method, tc.path, err, rec.Body)
+			}
+			got := resp.Name
+			if tc.field != "name" {
+				got, _ = resp.Result.Output[tc.field].(string)
+			}
+			if got != tc.value {
+				t.Errorf("%s %s: %s is %q, want %q: %s", tc.method, tc.path, tc.field, got, tc.value, rec.Body)
+			}
+		})
+	}
+}
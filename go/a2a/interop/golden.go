@@ -0,0 +1,220 @@
+package interop
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// generatedID matches the IDs this SDK generates, which golden fixtures
+// turn into variables
+var generatedID = regexp.MustCompile(`^id-[0-9]+$`)
+
+// timestampFields are the fields golden fixtures match with Any
+var timestampFields = map[string]bool{"timestamp": true, "updatedAt": true}
+
+// scenario is a conversation Golden records
+type scenario struct {
+	name        string
+	description string
+	run         func(agent *a2a.A2AAgent) error
+}
+
+var scenarios = []scenario{
+	{"a2a-go/task", "SendTask with the legacy a2a/task method", func(agent *a2a.A2AAgent) error {
+		_, err := agent.SendTask(AgentID, "echo", map[string]interface{}{"n": 1}, "")
+		return err
+	}},
+	{"a2a-go/task-failed", "SendTask to a handler that fails", func(agent *a2a.A2AAgent) error {
+		agent.SendTask(AgentID, "fail", map[string]interface{}{"message": "out of paper"}, "")
+		return nil
+	}},
+	{"a2a-go/task-canceled", "SubmitTask, then CancelTask and GetTask", func(agent *a2a.A2AAgent) error {
+		submitted, err := agent.SubmitTask(AgentID, "wait", nil, "")
+		if err != nil {
+			return err
+		}
+		if _, err := agent.CancelTask(AgentID, submitted.TaskID, ""); err != nil {
+			return err
+		}
+		_, err = agent.GetTask(AgentID, submitted.TaskID, "")
+		return err
+	}},
+	{"a2a-go/task-as-message", "SendTask with PreferMessages set, as message/send", func(agent *a2a.A2AAgent) error {
+		agent.PreferMessages = true
+		_, err := agent.SendTask(AgentID, "echo", map[string]interface{}{"n": 1}, "")
+		return err
+	}},
+	{"a2a-go/message-send", "SendMessage with a text message", func(agent *a2a.A2AAgent) error {
+		_, err := agent.SendMessage(AgentID, a2a.NewMessage(a2a.RoleUser, a2a.TextPart{Text: "hello"}), "")
+		return err
+	}},
+	{"a2a-go/message-stream", "StreamMessage with a text message", func(agent *a2a.A2AAgent) error {
+		return agent.StreamMessage(AgentID, a2a.NewMessage(a2a.RoleUser, a2a.TextPart{Text: "hello"}), "", func(a2a.StreamEvent) error { return nil })
+	}},
+}
+
+// Golden records what this SDK's A2AAgent sends for its task and message
+// calls, and what an A2AServer running NewServer answers, as fixtures for
+// other SDKs to replay: their servers must answer this SDK's requests
+// alike. IDs this SDK generates become variables and timestamps Any.
+func Golden() ([]Fixture, error) {
+	fixtures := make([]Fixture, 0, len(scenarios))
+	for _, sc := range scenarios {
+		fixture, err := record(sc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sc.name, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+func record(sc scenario) (Fixture, error) {
+	server := httptest.NewServer(NewServer().Handler())
+	defer server.Close()
+
+	rec := &recorder{}
+	agent := a2a.NewAgent("interop-client", "Interop Client", nil)
+	agent.HTTPClient = &http.Client{Transport: rec}
+	agent.Directory = directory{AgentID: AgentID, Endpoint: server.URL, Capabilities: []string{"echo", "fail", "wait"}}
+	if err := sc.run(agent); err != nil {
+		return Fixture{}, err
+	}
+
+	fixture := Fixture{Name: sc.name, SDK: "a2a-go", Description: sc.description}
+	names := make(map[string]string)
+	for _, step := range rec.steps {
+		var err error
+		if step.Request, err = normalize(step.Request, names); err != nil {
+			return Fixture{}, err
+		}
+		if step.Response != nil {
+			if step.Response, err = normalize(step.Response, names); err != nil {
+				return Fixture{}, err
+			}
+		}
+		for i := range step.Stream {
+			if step.Stream[i], err = normalize(step.Stream[i], names); err != nil {
+				return Fixture{}, err
+			}
+		}
+		fixture.Steps = append(fixture.Steps, step)
+	}
+	return fixture, nil
+}
+
+// normalize turns the generated IDs in data into variables, named in the
+// order they are first seen, and timestamps into Any
+func normalize(data json.RawMessage, names map[string]string) (json.RawMessage, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			if !generatedID.MatchString(v) {
+				return v
+			}
+			if _, ok := names[v]; !ok {
+				names[v] = fmt.Sprintf("{{id%d}}", len(names)+1)
+			}
+			return names[v]
+		case map[string]interface{}:
+			for key, value := range v {
+				if timestampFields[key] {
+					v[key] = Any
+					continue
+				}
+				v[key] = walk(value)
+			}
+		case []interface{}:
+			for i, value := range v {
+				v[i] = walk(value)
+			}
+		}
+		return v
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(walk(v)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(out.Bytes()), nil
+}
+
+// recorder is an http.RoundTripper recording each request it carries and
+// its response as a Step
+type recorder struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	step := Step{Request: body}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		for _, line := range strings.Split(string(data), "\n") {
+			if payload, ok := strings.CutPrefix(line, "data:"); ok {
+				step.Stream = append(step.Stream, json.RawMessage(strings.TrimSpace(payload)))
+			}
+		}
+	} else {
+		step.Response = data
+	}
+	r.mu.Lock()
+	r.steps = append(r.steps, step)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// directory is an a2a.Directory holding one agent
+type directory a2a.AgentInfo
+
+func (d directory) Register(a2a.RegisterParams) (a2a.RegisterResult, error) {
+	return a2a.RegisterResult{}, nil
+}
+
+func (d directory) Discover(a2a.DiscoverParams) ([]a2a.AgentInfo, error) {
+	return []a2a.AgentInfo{a2a.AgentInfo(d)}, nil
+}
+
+func (d directory) Resolve(agentID string) (a2a.AgentInfo, error) {
+	if agentID != d.AgentID {
+		return a2a.AgentInfo{}, a2a.ErrAgentNotFound
+	}
+	return a2a.AgentInfo(d), nil
+}
+
+func (d directory) Deregister(string) error {
+	return nil
+}
@@ -0,0 +1,386 @@
+// Package interop checks that A2A servers and clients built with different
+// SDKs understand each other on the wire. Fixtures are recorded exchanges:
+// the JSON-RPC requests another SDK sends, such as a2a-python's or
+// a2a-js's, with what it expects back. Check replays them against a
+// server and reports each response that doesn't match:
+//
+//	for _, result := range interop.CheckHandler(interop.NewServer().Handler(), interop.Fixtures()) {
+//		if result.Err != nil {
+//			log.Printf("%s: %v", result.Fixture, result.Err)
+//		}
+//	}
+//
+// The other way round, Golden records what this SDK sends and answers as
+// fixtures, for the other SDKs' test suites to replay against their
+// servers. The a2a-interop command runs both.
+package interop
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Any matches any value in a fixture's expected responses, e.g. a
+// timestamp
+const Any = "<any>"
+
+// AgentID is the agent NewServer serves, which fixtures address
+const AgentID = "interop-agent"
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+// Fixture is a recorded conversation with an A2A server. A string
+// "{{name}}" in it is a variable: in an expected response it captures the
+// value found there the first time and matches only that value after; in
+// a request it is replaced by the captured value, or by a new ID if there
+// is none yet.
+type Fixture struct {
+	Name        string `json:"name"`
+	SDK         string `json:"sdk"` // the SDK that sent the requests, e.g. "a2a-python"
+	Description string `json:"description,omitempty"`
+	Steps       []Step `json:"steps"`
+}
+
+// Step is one request of a fixture and what the server must answer.
+// Responses are matched loosely: objects must have at least the fields
+// expected, with matching values, so servers may add fields.
+type Step struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Stream, for message/stream, is the JSON-RPC response each event
+	// must carry, in order
+	Stream []json.RawMessage `json:"stream,omitempty"`
+}
+
+// Result is the outcome of checking one fixture
+type Result struct {
+	Fixture string
+	Err     error // nil if every step matched
+}
+
+// Fixtures returns the fixtures shipped with this package, recorded from
+// other SDKs against the agent NewServer returns
+func Fixtures() []Fixture {
+	fixtures, err := readFixtures(fixtureFiles, "fixtures")
+	if err != nil {
+		panic(err) // embedded at build time
+	}
+	return fixtures
+}
+
+// ReadFixtures reads the fixtures in the .json files of dir
+func ReadFixtures(dir string) ([]Fixture, error) {
+	return readFixtures(os.DirFS(dir), ".")
+}
+
+func readFixtures(fsys fs.FS, dir string) ([]Fixture, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var fixtures []Fixture
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// WriteFixtures writes each fixture to dir as <name>.json, slashes in its
+// name replaced by dashes
+func WriteFixtures(dir string, fixtures []Fixture) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, fixture := range fixtures {
+		var data bytes.Buffer
+		enc := json.NewEncoder(&data)
+		enc.SetEscapeHTML(false) // keep Any readable
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(fixture); err != nil {
+			return err
+		}
+		name := strings.ReplaceAll(fixture.Name, "/", "-") + ".json"
+		if err := os.WriteFile(filepath.Join(dir, name), data.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewServer returns the agent fixtures are recorded against, AgentID,
+// which servers built with other SDKs must mirror to replay them, with
+// the capabilities:
+//
+//	echo  returns its input, or {"text": ...} for a text message
+//	fail  fails with its input's "message"
+//	wait  runs until the task is canceled
+func NewServer(opts ...a2a.ServerOption) *a2a.A2AServer {
+	server := a2a.NewServerWithOptions(AgentID, append([]a2a.ServerOption{
+		a2a.WithName("Interop Agent"),
+		a2a.WithCapabilities("echo", "fail", "wait"),
+	}, opts...)...)
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		switch action {
+		case "fail":
+			message, _ := input["message"].(string)
+			return nil, errors.New(message)
+		case "wait":
+			<-ctx.Done()
+		}
+		return input, nil
+	})
+	return server
+}
+
+// CheckHandler replays fixtures against an A2A server's handler
+func CheckHandler(handler http.Handler, fixtures []Fixture) []Result {
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	return Check(server.Client(), server.URL, fixtures)
+}
+
+// Check replays fixtures against the A2A server at url, which must serve
+// AgentID as NewServer does. A nil client means http.DefaultClient.
+func Check(client *http.Client, url string, fixtures []Fixture) []Result {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	results := make([]Result, len(fixtures))
+	for i, fixture := range fixtures {
+		results[i] = Result{Fixture: fixture.Name, Err: check(client, url, fixture)}
+	}
+	return results
+}
+
+func check(client *http.Client, url string, fixture Fixture) error {
+	vars := make(map[string]interface{})
+	for i, step := range fixture.Steps {
+		if err := checkStep(client, url, step, vars); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func checkStep(client *http.Client, url string, step Step, vars map[string]interface{}) error {
+	var request interface{}
+	if err := json.Unmarshal(step.Request, &request); err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	body, err := json.Marshal(substitute(request, vars))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if step.Stream != nil {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	got, err := readResponses(resp)
+	if err != nil {
+		return err
+	}
+
+	want := step.Stream
+	if want == nil {
+		want = []json.RawMessage{step.Response}
+	}
+	if len(got) != len(want) {
+		return fmt.Errorf("got %d responses, want %d", len(got), len(want))
+	}
+	for i := range want {
+		var w interface{}
+		if err := json.Unmarshal(want[i], &w); err != nil {
+			return fmt.Errorf("expected response: %w", err)
+		}
+		at := "response"
+		if step.Stream != nil {
+			at = fmt.Sprintf("event %d", i+1)
+		}
+		if err := match(at, w, got[i], vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readResponses returns the JSON-RPC response of resp, or each one of an
+// event stream
+func readResponses(resp *http.Response) ([]interface{}, error) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var v interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+			return nil, fmt.Errorf("response: %w", err)
+		}
+		return []interface{}{v}, nil
+	}
+
+	var responses []interface{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 16<<20)
+	var data []byte
+	flush := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("event %d: %w", len(responses)+1, err)
+		}
+		responses = append(responses, v)
+		data = data[:0]
+		return nil
+	}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(payload, []byte(" "))...)
+		} else if len(line) == 0 {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return responses, flush()
+}
+
+// variable returns the name of the variable v is, if it is one
+func variable(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "{{") || !strings.HasSuffix(s, "}}") || len(s) < 5 {
+		return "", false
+	}
+	return s[2 : len(s)-2], true
+}
+
+// substitute replaces the variables in v with their values, giving those
+// without one a new ID
+func substitute(v interface{}, vars map[string]interface{}) interface{} {
+	if name, ok := variable(v); ok {
+		if _, ok := vars[name]; !ok {
+			vars[name] = fmt.Sprintf("interop-%s-%d", name, len(vars)+1)
+		}
+		return vars[name]
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = substitute(value, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = substitute(value, vars)
+		}
+		return out
+	}
+	return v
+}
+
+// match reports where got differs from want, at names where they are
+func match(at string, want, got interface{}, vars map[string]interface{}) error {
+	if want == Any {
+		return nil
+	}
+	if name, ok := variable(want); ok {
+		value, set := vars[name]
+		if !set {
+			vars[name] = got
+			return nil
+		}
+		want = value
+	}
+	switch want := want.(type) {
+	case map[string]interface{}:
+		got, ok := got.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: got %s, want an object", at, describe(got))
+		}
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := got[key]
+			if !ok {
+				return fmt.Errorf("%s.%s: missing", at, key)
+			}
+			if err := match(at+"."+key, want[key], value, vars); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		got, ok := got.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: got %s, want an array", at, describe(got))
+		}
+		if len(got) != len(want) {
+			return fmt.Errorf("%s: got %d elements, want %d", at, len(got), len(want))
+		}
+		for i := range want {
+			if err := match(fmt.Sprintf("%s[%d]", at, i), want[i], got[i], vars); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("%s: got %s, want %s", at, describe(got), describe(want))
+	}
+	return nil
+}
+
+func describe(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > 80 {
+		return string(data[:77]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,80 @@
+package a2a
+
+import (
+	"context"
+	"sync"
+)
+
+// The methods below serve tasks arriving by transports other than
+// JSON-RPC over HTTP, such as gRPC, through the same code as the JSON-RPC
+// methods, so limits, hooks, retries, draining and the task store apply
+// alike. Tasks are addressed to hosted agents by their AgentID. Errors
+// refusing a task are *JSONRPCError, for transports to carry their codes.
+
+// RunTask runs a task to completion, as a2a/task does
+func (s *A2AServer) RunTask(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	result, rpcErr := s.runTask(ctx, task)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result, nil
+}
+
+// SubmitTask queues a task and returns its submitted state, as
+// a2a/task/submit does
+func (s *A2AServer) SubmitTask(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	result, rpcErr := s.enqueueTask(ctx, task)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result, nil
+}
+
+// GetTask returns the current state of an asynchronous task, as
+// a2a/task/get does
+func (s *A2AServer) GetTask(taskID string) (*TaskResult, error) {
+	result, rpcErr := s.lookupTask(taskID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result, nil
+}
+
+// CancelTask cancels an asynchronous task and returns its canceled state,
+// as tasks/cancel does
+func (s *A2AServer) CancelTask(taskID string) (*TaskResult, error) {
+	result, rpcErr := s.cancelTask(taskID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result, nil
+}
+
+// StreamTask runs a task, calling handle with each event message/stream
+// would send: the submitted task, artifact updates and a final status
+// update. If handle returns an error, the task's context is cancelled and
+// StreamTask returns the error once the handler has returned.
+func (s *A2AServer) StreamTask(ctx context.Context, task TaskParams, handle func(StreamEvent) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var handleErr error
+	rpcErr := s.streamTask(ctx, task, func(event StreamEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if handleErr != nil {
+			return
+		}
+		if handleErr = handle(event); handleErr != nil {
+			cancel()
+		}
+	})
+	if handleErr != nil {
+		return handleErr
+	}
+	if rpcErr != nil {
+		return rpcErr
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package a2a
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Relays, brokers and shared gateways carrying tasks between agents can
+// read them. An agent with an EncryptionKey encrypts the input of its
+// tasks as a JWE (RFC 7516) to the encryption key in the target's Agent
+// Card, naming its own public key in the JWE header, and servers created
+// WithEncryptionKey decrypt the input before the handler runs and encrypt
+// its output to the key named. Only the ends hold the keys, so nothing in
+// between can read either.
+//
+// The JWE replaces the input, or the output: {"jwe": "<compact JWE>"}.
+// Its key is agreed with ECDH-ES and the payload, the input's JSON, is
+// encrypted with A256GCM. Message parts, artifacts and metadata are sent
+// as they are; what a handler gets is the decrypted input merged with the
+// task's message.
+
+const (
+	jweAlgorithm  = "ECDH-ES"
+	jweEncryption = "A256GCM"
+)
+
+// jweHeader is the protected header of an input's or output's JWE
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Kid string `json:"kid,omitempty"` // the recipient's key
+	Epk *JWK   `json:"epk"`
+	Rpk *JWK   `json:"rpk,omitempty"` // the key to encrypt the output to; without it the output is sent as is
+}
+
+// WithEncryptionKey publishes key's public key in the Agent Card for
+// senders to encrypt task input to, decrypting the input encrypted to it
+// and encrypting the output of those tasks to their senders. key is an
+// X25519 or NIST curve key. Give it more than once to rotate keys; the
+// first is the one senders use.
+func WithEncryptionKey(keyID string, key *ecdh.PrivateKey) ServerOption {
+	return func(s *A2AServer) {
+		jwk, err := NewJWK(keyID, key.PublicKey())
+		if err != nil {
+			panic("a2a: WithEncryptionKey: " + err.Error())
+		}
+		jwk.Alg = jweAlgorithm
+		s.card.jwks = append(s.card.jwks, jwk)
+		if s.encryptionKeys == nil {
+			s.encryptionKeys = make(map[string]*ecdh.PrivateKey)
+		}
+		s.encryptionKeys[keyID] = key
+	}
+}
+
+// encryptionKey returns the key a card publishes for encrypting to
+func encryptionKey(card *AgentCard) (JWK, bool) {
+	if card.JWKS == nil {
+		return JWK{}, false
+	}
+	for _, key := range card.JWKS.Keys {
+		if key.Use == "enc" && (key.Alg == "" || key.Alg == jweAlgorithm) {
+			return key, true
+		}
+	}
+	return JWK{}, false
+}
+
+// encrypted returns the JWE a task's input or output carries, if any
+func encrypted(payload map[string]interface{}) (string, bool) {
+	token, ok := payload["jwe"].(string)
+	return token, ok
+}
+
+// sealInput encrypts the input of task to the encryption key in the Agent
+// Card at endpoint, asking for the output to be encrypted to the agent's
+// EncryptionKey
+func (a *A2AAgent) sealInput(ctx context.Context, endpoint string, task *TaskParams) error {
+	card, err := a.FetchAgentCard(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("fetching encryption key: %w", err)
+	}
+	recipient, ok := encryptionKey(card)
+	if !ok {
+		return fmt.Errorf("%s publishes no encryption key", card.Name)
+	}
+	reply, err := NewJWK("", a.EncryptionKey.PublicKey())
+	if err != nil {
+		return err
+	}
+	task.Input, err = sealJWE(task.Input, recipient, &reply)
+	return err
+}
+
+// openOutput decrypts a task's output if it was encrypted to the agent
+func (a *A2AAgent) openOutput(result *TaskResult) error {
+	token, ok := encrypted(result.Output)
+	if !ok || a.EncryptionKey == nil {
+		return nil
+	}
+	output, _, err := openJWE(token, func(string) *ecdh.PrivateKey { return a.EncryptionKey })
+	if err != nil {
+		return fmt.Errorf("decrypting output: %w", err)
+	}
+	result.Output = output
+	return nil
+}
+
+// openInput decrypts a handler's input if it carries a JWE encrypted to
+// one of the server's keys, and returns the key to encrypt its output to
+func (s *A2AServer) openInput(input map[string]interface{}) (map[string]interface{}, *JWK, error) {
+	token, ok := encrypted(input)
+	if !ok || len(s.encryptionKeys) == 0 {
+		return input, nil, nil
+	}
+	decrypted, header, err := openJWE(token, func(kid string) *ecdh.PrivateKey { return s.encryptionKeys[kid] })
+	if err != nil {
+		return input, nil, &TaskError{Code: CodeInvalidParams, Message: "cannot decrypt input: " + err.Error()}
+	}
+	// Text and files of the task's message stay beside it
+	input = maps.Clone(input)
+	delete(input, "jwe")
+	maps.Copy(input, decrypted)
+	return input, header.Rpk, nil
+}
+
+// sealJWE returns payload encrypted to recipient as a JWE naming reply, if
+// set, as the key to answer to
+func sealJWE(payload map[string]interface{}, recipient JWK, reply *JWK) (map[string]interface{}, error) {
+	public, err := recipient.ecdhKey()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := public.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	epk, err := NewJWK("", ephemeral.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	epk.Use = ""
+	z, err := ephemeral.ECDH(public)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(jweHeader{Alg: jweAlgorithm, Enc: jweEncryption, Kid: recipient.Kid, Epk: &epk, Rpk: reply})
+	if err != nil {
+		return nil, err
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	protected := b64(header)
+	gcm, err := jweCipher(z)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	rand.Read(iv)
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(plaintext)], sealed[len(plaintext):]
+	// Direct key agreement has no encrypted key
+	return map[string]interface{}{"jwe": protected + ".." + b64(iv) + "." + b64(ciphertext) + "." + b64(tag)}, nil
+}
+
+// openJWE decrypts a compact JWE with the private key named by its kid
+func openJWE(token string, key func(kid string) *ecdh.PrivateKey) (map[string]interface{}, jweHeader, error) {
+	var header jweHeader
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return nil, header, errors.New("not a compact JWE with direct key agreement")
+	}
+	decode := base64.RawURLEncoding.DecodeString
+	headerJSON, errHeader := decode(parts[0])
+	iv, errIV := decode(parts[2])
+	ciphertext, errText := decode(parts[3])
+	tag, errTag := decode(parts[4])
+	if errHeader != nil || errIV != nil || errText != nil || errTag != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return nil, header, errors.New("malformed JWE")
+	}
+	if header.Alg != jweAlgorithm || header.Enc != jweEncryption || header.Epk == nil {
+		return nil, header, fmt.Errorf("unsupported JWE algorithm %s with %s", header.Alg, header.Enc)
+	}
+	private := key(header.Kid)
+	if private == nil {
+		return nil, header, fmt.Errorf("unknown key %q", header.Kid)
+	}
+	epk, err := header.Epk.ecdhKey()
+	if err != nil {
+		return nil, header, fmt.Errorf("epk: %w", err)
+	}
+	z, err := private.ECDH(epk)
+	if err != nil {
+		return nil, header, err
+	}
+	gcm, err := jweCipher(z)
+	if err != nil {
+		return nil, header, err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, header, errors.New("malformed JWE")
+	}
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, header, errors.New("JWE does not decrypt")
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, header, err
+	}
+	return payload, header, nil
+}
+
+// jweCipher returns the A256GCM cipher keyed with the key derived from the
+// shared secret z
+func jweCipher(z []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(concatKDF(z, jweEncryption, nil, nil, 256))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// concatKDF derives a key of bits bits for direct key agreement with enc
+// from the shared secret z, by the Concat KDF (RFC 7518, section 4.6.2)
+func concatKDF(z []byte, enc string, apu, apv []byte, bits int) []byte {
+	var key []byte
+	for counter := uint32(1); len(key)*8 < bits; counter++ {
+		// The counter, z, then the algorithm, apu and apv, each with its
+		// length, and the key length
+		var info []byte
+		info = binary.BigEndian.AppendUint32(info, counter)
+		info = append(info, z...)
+		for _, field := range [][]byte{[]byte(enc), apu, apv} {
+			info = binary.BigEndian.AppendUint32(info, uint32(len(field)))
+			info = append(info, field...)
+		}
+		info = binary.BigEndian.AppendUint32(info, uint32(bits))
+		round := sha256.Sum256(info)
+		key = append(key, round[:]...)
+	}
+	return key[:bits/8]
+}
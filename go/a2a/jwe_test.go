@@ -0,0 +1,216 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJWERoundTrip(t *testing.T) {
+	for _, curve := range []ecdh.Curve{ecdh.X25519(), ecdh.P256(), ecdh.P384(), ecdh.P521()} {
+		key, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipient, err := NewJWK("k1", key.PublicKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+		replyKey, _ := curve.GenerateKey(rand.Reader)
+		reply, _ := NewJWK("", replyKey.PublicKey())
+
+		payload := map[string]interface{}{"text": "hello", "nested": map[string]interface{}{"n": 1.5}}
+		sealed, err := sealJWE(payload, recipient, &reply)
+		if err != nil {
+			t.Fatalf("%v: sealJWE: %v", curve, err)
+		}
+		token, ok := encrypted(sealed)
+		if !ok || len(sealed) != 1 || strings.Contains(token, "hello") {
+			t.Fatalf("%v: sealed payload is %v", curve, sealed)
+		}
+		opened, header, err := openJWE(token, func(kid string) *ecdh.PrivateKey {
+			if kid == "k1" {
+				return key
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%v: openJWE: %v", curve, err)
+		}
+		if !reflect.DeepEqual(opened, payload) {
+			t.Errorf("%v: opened %v, want %v", curve, opened, payload)
+		}
+		if header.Rpk == nil || *header.Rpk != reply {
+			t.Errorf("%v: rpk is %v, want %v", curve, header.Rpk, reply)
+		}
+	}
+}
+
+// TestConcatKDF checks the key derived in RFC 7518, Appendix C
+func TestConcatKDF(t *testing.T) {
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	alice, err := ecdh.P256().NewPrivateKey(decode("0_NxaRPUMQoAJt50Gz8YiTr8gRTwyEaCumd-MToTmIo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   "weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ",
+		Y:   "e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck",
+	}.ecdhKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, err := alice.ECDH(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantZ := []byte{158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132, 38, 156,
+		251, 49, 110, 163, 218, 128, 106, 72, 246, 218, 167, 121, 140, 254, 144, 196}
+	if !bytes.Equal(z, wantZ) {
+		t.Fatalf("Z is %v, want %v", z, wantZ)
+	}
+
+	key := concatKDF(z, "A128GCM", []byte("Alice"), []byte("Bob"), 128)
+	if got := base64.RawURLEncoding.EncodeToString(key); got != "VqqN6vgjbSBcIijNcacQGg" {
+		t.Errorf("derived key %s, want VqqN6vgjbSBcIijNcacQGg", got)
+	}
+}
+
+func TestOpenJWERefuses(t *testing.T) {
+	key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	other, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	recipient, _ := NewJWK("k1", key.PublicKey())
+	sealed, err := sealJWE(map[string]interface{}{"text": "hello"}, recipient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := encrypted(sealed)
+	keys := func(kid string) *ecdh.PrivateKey {
+		if kid == "k1" {
+			return key
+		}
+		return nil
+	}
+
+	// tamper flips a bit in one of the token's parts
+	tamper := func(part int) string {
+		parts := strings.Split(token, ".")
+		b, _ := base64.RawURLEncoding.DecodeString(parts[part])
+		b[len(b)-1] ^= 1
+		parts[part] = base64.RawURLEncoding.EncodeToString(b)
+		return strings.Join(parts, ".")
+	}
+	// withKid names another key in the token's header
+	withKid := func(kid string) string {
+		parts := strings.Split(token, ".")
+		header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+		header = bytes.Replace(header, []byte(`"kid":"k1"`), []byte(`"kid":"`+kid+`"`), 1)
+		parts[0] = base64.RawURLEncoding.EncodeToString(header)
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		keys  func(kid string) *ecdh.PrivateKey
+	}{
+		{"header tampered", tamper(0), keys},
+		{"iv tampered", tamper(2), keys},
+		{"ciphertext tampered", tamper(3), keys},
+		{"tag tampered", tamper(4), keys},
+		{"unknown kid", withKid("k2"), keys},
+		{"wrong key", token, func(string) *ecdh.PrivateKey { return other }},
+		{"encrypted key", strings.Replace(token, "..", ".AAAA.", 1), keys},
+		{"truncated", token[:strings.LastIndex(token, ".")], keys},
+	}
+	for _, tt := range tests {
+		if payload, _, err := openJWE(tt.token, tt.keys); err == nil {
+			t.Errorf("%s: opened as %v", tt.name, payload)
+		}
+	}
+}
+
+// TestOpenInputLeavesInput checks decrypting a task's input does not
+// change the map the task holds, which retries and hooks read again
+func TestOpenInputLeavesInput(t *testing.T) {
+	key, _ := ecdh.P256().GenerateKey(rand.Reader)
+	server := NewServerWithOptions("server", WithEncryptionKey("k1", key))
+	card := server.AgentCard()
+	recipient, _ := encryptionKey(&card)
+	sealed, err := sealJWE(map[string]interface{}{"secret": "s3cret"}, recipient, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := map[string]interface{}{"jwe": sealed["jwe"], "text": "hello"}
+
+	opened, reply, err := server.openInput(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (map[string]interface{}{"secret": "s3cret", "text": "hello"}); !reflect.DeepEqual(opened, want) {
+		t.Errorf("opened input %v, want %v", opened, want)
+	}
+	if reply != nil {
+		t.Errorf("reply key %v, want none", reply)
+	}
+	if want := (map[string]interface{}{"jwe": sealed["jwe"], "text": "hello"}); !reflect.DeepEqual(input, want) {
+		t.Errorf("input changed to %v", input)
+	}
+}
+
+func TestEncryptedTask(t *testing.T) {
+	serverKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	var wire bytes.Buffer
+	record := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			wire.Write(body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(recordingWriter{w, &wire}, r)
+		})
+	}
+	server, ts := serveTest(t, WithEncryptionKey("k1", serverKey), WithMiddleware(record))
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return map[string]interface{}{"echo": input["secret"]}, nil
+	})
+
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+	agent.EncryptionKey, _ = ecdh.X25519().GenerateKey(rand.Reader)
+	result, err := agent.SendTask("server", "echo", map[string]interface{}{"secret": "s3cret"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output["echo"] != "s3cret" {
+		t.Errorf("output %v, want the decrypted input echoed", result.Output)
+	}
+	if strings.Contains(wire.String(), "s3cret") {
+		t.Errorf("input or output sent in the clear: %s", wire.String())
+	}
+}
+
+// recordingWriter copies a response to a buffer
+type recordingWriter struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+}
+
+func (w recordingWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
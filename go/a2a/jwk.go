@@ -0,0 +1,158 @@
+package a2a
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key as a JSON Web Key (RFC 7517), as published in an
+// Agent Card's jwks
+type JWK struct {
+	Kty string `json:"kty"` // "OKP", "EC" or "RSA"
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"` // "sig" or "enc"
+	Alg string `json:"alg,omitempty"` // the only algorithm the key is used with, if set
+	Crv string `json:"crv,omitempty"` // OKP: "Ed25519" or "X25519"; EC: "P-256", "P-384" or "P-521"
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"` // RSA modulus
+	E   string `json:"e,omitempty"` // RSA exponent
+}
+
+// JWKSet is a set of JSON Web Keys
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK returns key, an ed25519.PublicKey, *ecdsa.PublicKey or
+// *rsa.PublicKey, as a signing JWK with the given ID. An *ecdh.PublicKey
+// becomes an encryption JWK.
+func NewJWK(keyID string, key crypto.PublicKey) (JWK, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: keyID, Use: "sig"}
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv, jwk.X = "OKP", "Ed25519", b64(key)
+	case *ecdh.PublicKey:
+		jwk.Use = "enc"
+		point := key.Bytes()
+		if key.Curve() == ecdh.X25519() {
+			jwk.Kty, jwk.Crv, jwk.X = "OKP", "X25519", b64(point)
+			break
+		}
+		// point is uncompressed: 4, then x and y
+		size := len(point) / 2
+		jwk.Kty, jwk.X, jwk.Y = "EC", b64(point[1:1+size]), b64(point[1+size:])
+		switch key.Curve() {
+		case ecdh.P256():
+			jwk.Crv = "P-256"
+		case ecdh.P384():
+			jwk.Crv = "P-384"
+		case ecdh.P521():
+			jwk.Crv = "P-521"
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.Kty, jwk.Crv = "EC", key.Curve.Params().Name
+		jwk.X, jwk.Y = b64(key.X.FillBytes(make([]byte, size))), b64(key.Y.FillBytes(make([]byte, size)))
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N, jwk.E = b64(key.N.Bytes()), b64(big.NewInt(int64(key.E)).Bytes())
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+	return jwk, nil
+}
+
+// PublicKey returns the key as an ed25519.PublicKey, *ecdsa.PublicKey or
+// *rsa.PublicKey, or an X25519 key as an *ecdh.PublicKey
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "OKP":
+		x, err := decode(k.X)
+		if k.Crv == "X25519" && err == nil {
+			key, err := ecdh.X25519().NewPublicKey(x)
+			if err != nil {
+				return nil, errors.New("invalid X25519 JWK")
+			}
+			return key, nil
+		}
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 JWK")
+		}
+		return ed25519.PublicKey(x), nil
+	case "EC":
+		var curve elliptic.Curve
+		var point ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, point = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, point = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, point = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported JWK curve %q", k.Crv)
+		}
+		x, errX := decode(k.X)
+		y, errY := decode(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("invalid EC JWK")
+		}
+		// ecdh checks the point is on the curve
+		if _, err := point.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, errors.New("invalid EC JWK")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "RSA":
+		n, errN := decode(k.N)
+		e, errE := decode(k.E)
+		if errN != nil || errE != nil || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA JWK, or shorter than 2048 bits")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return nil, fmt.Errorf("unsupported JWK type %q", k.Kty)
+}
+
+// Key returns the key with the given ID, or the only key when keyID is
+// empty
+func (s *JWKSet) Key(keyID string) (JWK, bool) {
+	if s == nil {
+		return JWK{}, false
+	}
+	if keyID == "" && len(s.Keys) == 1 {
+		return s.Keys[0], true
+	}
+	for _, key := range s.Keys {
+		if key.Kid == keyID && keyID != "" {
+			return key, true
+		}
+	}
+	return JWK{}, false
+}
+
+// ecdhKey returns the key as an *ecdh.PublicKey, for key agreement
+func (k JWK) ecdhKey() (*ecdh.PublicKey, error) {
+	key, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *ecdh.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		return key.ECDH()
+	}
+	return nil, fmt.Errorf("%s key cannot agree on keys", k.Kty)
+}
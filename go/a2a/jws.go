@@ -0,0 +1,418 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Agents in different organizations share no secrets, so an agent with a
+// JWSSigner signs the params of its task and message requests with its
+// private key as a JWS (RFC 7515) and publishes the public key in its
+// Agent Card's jwks. Servers created WithJWSVerification resolve the
+// sender named in the JWS header through their directory, fetch its Agent
+// Card and verify the signature with the key it publishes.
+//
+// A compact JWS replaces the params: {"jws": "<header>.<params>.<sig>"}.
+// A detached one leaves the params readable and adds a "jws" member,
+// "<header>..<sig>", signing the canonical JSON of the other members, so
+// proxies and logs that read the params keep working. Servers without
+// verification unwrap both without checking them.
+
+// signedMethods are the methods JWSSigner signs and JWSVerification.Required
+// requires signed: those carrying a task or message
+var signedMethods = map[string]bool{
+	"a2a/task":        true,
+	"a2a/task/submit": true,
+	"message/send":    true,
+	"message/stream":  true,
+}
+
+// JWSSigner signs an agent's task and message requests, set as
+// A2AAgent.JWS
+type JWSSigner struct {
+	// Key is an ed25519.PrivateKey, or an ECDSA (P-256, P-384 or P-521) or
+	// RSA crypto.Signer, e.g. a key held in an HSM
+	Key crypto.Signer
+	// KeyID is the kid of Key's public key in the agent's Agent Card
+	KeyID string
+	// Detached sends the params as they are, beside a detached JWS
+	Detached bool
+}
+
+// jwsHeader is the protected header of a request's JWS
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Iss string `json:"iss"` // the sending agent, whose Agent Card has the key
+	Iat int64  `json:"iat"` // when it was signed, in Unix seconds
+}
+
+// sign returns params signed as agentID
+func (j *JWSSigner) sign(agentID string, codec Codec, params interface{}) (json.RawMessage, error) {
+	alg, err := jwsAlgorithm(j.Key.Public())
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(jwsHeader{Alg: alg, Kid: j.KeyID, Iss: agentID, Iat: time.Now().Unix()})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := codec.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	if j.Detached {
+		if payload, err = (CanonicalJSON{}).Canonicalize(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	input := b64(header) + "." + b64(payload)
+	sig, err := jwsSign(j.Key, alg, []byte(input))
+	if err != nil {
+		return nil, fmt.Errorf("signing request: %w", err)
+	}
+	if !j.Detached {
+		return json.Marshal(map[string]string{"jws": input + "." + b64(sig)})
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &members); err != nil {
+		return nil, errors.New("signing request: detached JWS needs object params")
+	}
+	members["jws"], _ = json.Marshal(b64(header) + ".." + b64(sig))
+	return json.Marshal(members)
+}
+
+// request returns a JSON-RPC request for method, signed if the agent has
+// a JWSSigner and method starts a task
+func (a *A2AAgent) request(method string, params interface{}) (JSONRPCRequest, error) {
+	req := newRequest(method, params)
+	if a.JWS != nil && signedMethods[method] {
+		signed, err := a.JWS.sign(a.AgentID, a.codec(), params)
+		if err != nil {
+			return req, err
+		}
+		req.Params = signed
+	}
+	return req, nil
+}
+
+// jwsAlgorithm returns the JWS algorithm signing with key's private key
+func jwsAlgorithm(key crypto.PublicKey) (string, error) {
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return "EdDSA", nil
+	case *ecdsa.PublicKey:
+		switch key.Curve.Params().BitSize {
+		case 256:
+			return "ES256", nil
+		case 384:
+			return "ES384", nil
+		case 521:
+			return "ES512", nil
+		}
+	case *rsa.PublicKey:
+		return "RS256", nil
+	}
+	return "", fmt.Errorf("unsupported signing key %T", key)
+}
+
+// jwsHash returns the hash an ECDSA or RSA algorithm signs with
+func jwsHash(alg string) crypto.Hash {
+	switch alg {
+	case "ES384":
+		return crypto.SHA384
+	case "ES512":
+		return crypto.SHA512
+	}
+	return crypto.SHA256
+}
+
+func digest(hash crypto.Hash, input []byte) []byte {
+	switch hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384(input)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(input)
+		return sum[:]
+	}
+	sum := sha256.Sum256(input)
+	return sum[:]
+}
+
+// jwsSign signs input with key as alg. ECDSA signatures, which
+// crypto.Signers return as ASN.1, are converted to JWS's fixed-size R||S.
+func jwsSign(key crypto.Signer, alg string, input []byte) ([]byte, error) {
+	if alg == "EdDSA" {
+		return key.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+	hash := jwsHash(alg)
+	sig, err := key.Sign(rand.Reader, digest(hash, input), hash)
+	if err != nil || !strings.HasPrefix(alg, "ES") {
+		return sig, err
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		return nil, err
+	}
+	size := (key.Public().(*ecdsa.PublicKey).Curve.Params().BitSize + 7) / 8
+	return append(rs.R.FillBytes(make([]byte, size)), rs.S.FillBytes(make([]byte, size))...), nil
+}
+
+// jwsVerify reports whether sig is key's signature of input as alg. The
+// algorithm must be the one key signs with, so a key cannot be used with
+// another.
+func jwsVerify(key crypto.PublicKey, alg string, input, sig []byte) bool {
+	if want, err := jwsAlgorithm(key); err != nil || want != alg {
+		return false
+	}
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, input, sig)
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(key, digest(jwsHash(alg), input), r, s)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest(crypto.SHA256, input), sig) == nil
+	}
+	return false
+}
+
+// JWSVerification configures how a server checks JWS-signed requests
+type JWSVerification struct {
+	// Required refuses task and message requests that are not signed
+	Required bool
+	// Keys returns the keys agentID signs with. Nil resolves the agent
+	// through the server's directory and reads the jwks of its Agent
+	// Card, or resolves its DID document if agentID is a DID.
+	Keys func(ctx context.Context, agentID string) (*JWKSet, error)
+	// MaxAge refuses signatures made longer ago, or dated further ahead,
+	// limiting how long a captured request can be replayed; zero means 5
+	// minutes
+	MaxAge time.Duration
+	// CacheTTL is how long keys are reused before Keys is called again;
+	// zero means 10 minutes. An unknown kid calls it again at most once a
+	// minute, so rotated keys are picked up.
+	CacheTTL time.Duration
+}
+
+// WithJWSVerification verifies the JWS of signed requests, refusing those
+// whose signature doesn't verify or whose sender is not the agent that
+// signed them
+func WithJWSVerification(verification JWSVerification) ServerOption {
+	return func(s *A2AServer) {
+		if verification.MaxAge <= 0 {
+			verification.MaxAge = 5 * time.Minute
+		}
+		if verification.CacheTTL <= 0 {
+			verification.CacheTTL = 10 * time.Minute
+		}
+		s.jws = &jwsVerifier{config: verification, keys: make(map[string]cachedKeys)}
+	}
+}
+
+// WithJWSKey publishes key, the public key of an agent's JWSSigner or
+// JWTSigner, in the jwks of the Agent Cards served here
+func WithJWSKey(keyID string, key crypto.PublicKey) ServerOption {
+	return func(s *A2AServer) {
+		jwk, err := NewJWK(keyID, key)
+		if err != nil {
+			panic("a2a: WithJWSKey: " + err.Error())
+		}
+		jwk.Alg, _ = jwsAlgorithm(key)
+		s.card.jwks = append(s.card.jwks, jwk)
+	}
+}
+
+// jwsRefetchInterval is how often an unknown kid may refetch keys
+const jwsRefetchInterval = time.Minute
+
+type jwsVerifier struct {
+	config JWSVerification
+	mu     sync.Mutex
+	keys   map[string]cachedKeys // by agent ID
+}
+
+type cachedKeys struct {
+	set     *JWKSet
+	fetched time.Time
+}
+
+// invalidSignature is the error refusing a request's JWS
+func invalidSignature(reason string) *JSONRPCError {
+	return requestError(CodeInvalidSignature, "Invalid signature", RequestErrorData{Field: "jws", Reason: reason})
+}
+
+// openJWS returns the params a request's JWS signs, verified if the server
+// verifies signatures, or params as they are if unsigned. Once verified,
+// ctx is returned naming the signer as the request's principal.
+func (s *A2AServer) openJWS(ctx context.Context, method string, params json.RawMessage) (context.Context, json.RawMessage, *JSONRPCError) {
+	var members map[string]json.RawMessage
+	if !bytes.Contains(params, []byte(`"jws"`)) || json.Unmarshal(params, &members) != nil || members["jws"] == nil {
+		if s.jws != nil && s.jws.config.Required && signedMethods[method] {
+			return ctx, nil, invalidSignature("request is not signed")
+		}
+		return ctx, params, nil
+	}
+
+	var token string
+	if err := json.Unmarshal(members["jws"], &token); err != nil {
+		return ctx, nil, invalidSignature("not a string")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ctx, nil, invalidSignature("not a JWS")
+	}
+	decode := base64.RawURLEncoding.DecodeString
+	var payload []byte
+	var err error
+	if parts[1] == "" {
+		delete(members, "jws")
+		if params, err = json.Marshal(members); err != nil {
+			return ctx, nil, invalidSignature(err.Error())
+		}
+		if payload, err = (CanonicalJSON{}).Canonicalize(params); err != nil {
+			return ctx, nil, invalidSignature(err.Error())
+		}
+		parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	} else {
+		if len(members) != 1 {
+			return ctx, nil, invalidSignature("params beside a compact JWS are not signed")
+		}
+		if params, err = decode(parts[1]); err != nil {
+			return ctx, nil, invalidSignature("payload is not base64url")
+		}
+	}
+	if s.jws == nil {
+		return ctx, params, nil
+	}
+
+	headerJSON, errHeader := decode(parts[0])
+	sig, errSig := decode(parts[2])
+	var header jwsHeader
+	if errHeader != nil || errSig != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return ctx, nil, invalidSignature("malformed JWS")
+	}
+	if header.Iss == "" {
+		return ctx, nil, invalidSignature("header names no iss")
+	}
+	if age := time.Since(time.Unix(header.Iat, 0)); age > s.jws.config.MaxAge || age < -s.jws.config.MaxAge {
+		return ctx, nil, invalidSignature("iat is too old or in the future")
+	}
+	fetch := s.jws.config.Keys
+	if fetch == nil {
+		fetch = s.cardKeys
+	}
+	jwk, err := s.jws.key(ctx, header.Iss, header.Kid, fetch)
+	if err != nil {
+		return ctx, nil, invalidSignature(err.Error())
+	}
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return ctx, nil, invalidSignature(err.Error())
+	}
+	if (jwk.Alg != "" && jwk.Alg != header.Alg) || !jwsVerify(key, header.Alg, []byte(parts[0]+"."+parts[1]), sig) {
+		return ctx, nil, invalidSignature("signature does not verify")
+	}
+	if sender := claimedSender(params); sender != "" && sender != header.Iss {
+		return ctx, nil, invalidSignature(fmt.Sprintf("signed by %q, not sender %q", header.Iss, sender))
+	}
+	return withPrincipal(ctx, header.Iss), params, nil
+}
+
+// claimedSender returns the sender task or message params name
+func claimedSender(params json.RawMessage) string {
+	var claimed struct {
+		Sender  string `json:"sender"`
+		Message *struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"message"`
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	if json.Unmarshal(params, &claimed) != nil {
+		return ""
+	}
+	if claimed.Sender != "" {
+		return claimed.Sender
+	}
+	if claimed.Message != nil {
+		return metadataString(MetadataSender, claimed.Message.Metadata, claimed.Metadata)
+	}
+	return ""
+}
+
+// key returns the key agentID signed with, from the cache or fetch
+func (v *jwsVerifier) key(ctx context.Context, agentID, keyID string, fetch func(ctx context.Context, agentID string) (*JWKSet, error)) (JWK, error) {
+	v.mu.Lock()
+	cached, ok := v.keys[agentID]
+	v.mu.Unlock()
+	age := time.Since(cached.fetched)
+	if ok && age < v.config.CacheTTL {
+		if jwk, found := cached.set.Key(keyID); found || age < jwsRefetchInterval {
+			if !found {
+				return JWK{}, fmt.Errorf("no key %q for %s", keyID, agentID)
+			}
+			return jwk, nil
+		}
+	}
+
+	set, err := fetch(ctx, agentID)
+	if err != nil {
+		return JWK{}, fmt.Errorf("resolving the keys of %s: %v", agentID, err)
+	}
+	v.mu.Lock()
+	v.keys[agentID] = cachedKeys{set: set, fetched: time.Now()}
+	v.mu.Unlock()
+	jwk, found := set.Key(keyID)
+	if !found {
+		return JWK{}, fmt.Errorf("no key %q for %s", keyID, agentID)
+	}
+	return jwk, nil
+}
+
+// cardKeys resolves agentID through the server's directory and returns
+// the jwks of its Agent Card, or if agentID is a DID, the keys of its DID
+// document
+func (s *A2AServer) cardKeys(ctx context.Context, agentID string) (*JWKSet, error) {
+	if IsDID(agentID) {
+		return s.didKeys(ctx, agentID)
+	}
+	if s.directoryURL == "" && s.directory == nil {
+		return nil, errors.New("no directory to resolve the sender with")
+	}
+	agent := s.directoryAgent(s.info())
+	info, err := agent.resolveAgent(agentID, s.directoryURL)
+	if err != nil {
+		return nil, err
+	}
+	card, err := agent.FetchAgentCard(ctx, info.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if card.JWKS == nil {
+		return nil, errors.New("agent card publishes no jwks")
+	}
+	return card.JWKS, nil
+}
@@ -0,0 +1,208 @@
+package a2a
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeys returns JWS and JWT verification Keys resolving agentID to
+// the public keys of signers, by kid
+func testKeys(t *testing.T, agentID string, signers map[string]crypto.Signer) func(ctx context.Context, agentID string) (*JWKSet, error) {
+	t.Helper()
+	set := &JWKSet{}
+	for kid, signer := range signers {
+		jwk, err := NewJWK(kid, signer.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		jwk.Alg, _ = jwsAlgorithm(signer.Public())
+		set.Keys = append(set.Keys, jwk)
+	}
+	return func(ctx context.Context, id string) (*JWKSet, error) {
+		if id != agentID {
+			return nil, errors.New("unknown agent")
+		}
+		return set, nil
+	}
+}
+
+// testSigners returns a key of each kind a JWSSigner or JWTSigner takes
+func testSigners(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := map[string]crypto.Signer{"ed25519": ed}
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers[curve.Params().Name] = key
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers["rsa"] = key
+	return signers
+}
+
+func TestJWSRoundTrip(t *testing.T) {
+	signers := testSigners(t)
+	server := NewServerWithOptions("server", WithJWSVerification(JWSVerification{Required: true, Keys: testKeys(t, "alice", signers)}))
+	params := TaskParams{TaskID: "t1", Action: "echo", Sender: "alice", Input: map[string]interface{}{"text": "hello"}}
+	want, _ := json.Marshal(params)
+
+	for kid, key := range signers {
+		for _, detached := range []bool{false, true} {
+			signer := &JWSSigner{Key: key, KeyID: kid, Detached: detached}
+			signed, err := signer.sign("alice", DefaultCodec, params)
+			if err != nil {
+				t.Fatalf("%s, detached %v: sign: %v", kid, detached, err)
+			}
+			_, opened, rpcErr := server.openJWS(context.Background(), "a2a/task", signed)
+			if rpcErr != nil {
+				t.Fatalf("%s, detached %v: openJWS: %v", kid, detached, rpcErr)
+			}
+			if !sameJSON(t, opened, want) {
+				t.Errorf("%s, detached %v: opened %s, want %s", kid, detached, opened, want)
+			}
+		}
+	}
+}
+
+func TestOpenJWSRefuses(t *testing.T) {
+	signers := testSigners(t)
+	_, stranger, _ := ed25519.GenerateKey(rand.Reader)
+	server := NewServerWithOptions("server", WithJWSVerification(JWSVerification{Required: true, Keys: testKeys(t, "alice", signers)}))
+	params := TaskParams{TaskID: "t1", Action: "echo", Sender: "alice", Input: map[string]interface{}{"text": "hello"}}
+
+	sign := func(agentID string, signer *JWSSigner, params interface{}) json.RawMessage {
+		signed, err := signer.sign(agentID, DefaultCodec, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	compact := sign("alice", &JWSSigner{Key: signers["ed25519"], KeyID: "ed25519"}, params)
+	detached := sign("alice", &JWSSigner{Key: signers["ed25519"], KeyID: "ed25519", Detached: true}, params)
+	// rewrite replaces old with new in the JWS payload of compact
+	rewrite := func(old, new string) json.RawMessage {
+		var members map[string]string
+		json.Unmarshal(compact, &members)
+		parts := strings.Split(members["jws"], ".")
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(payload), old, new, 1)))
+		tampered, _ := json.Marshal(map[string]string{"jws": strings.Join(parts, ".")})
+		return tampered
+	}
+	old := time.Now().Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name   string
+		params json.RawMessage
+	}{
+		{"unsigned", json.RawMessage(`{"taskId":"t1","action":"echo","sender":"alice"}`)},
+		{"compact payload tampered", rewrite(`"hello"`, `"goodbye"`)},
+		{"detached params tampered", json.RawMessage(strings.Replace(string(detached), `"hello"`, `"goodbye"`, 1))},
+		{"params beside a compact JWS", json.RawMessage(strings.Replace(string(compact), `{`, `{"sender":"mallory",`, 1))},
+		{"unknown kid", sign("alice", &JWSSigner{Key: signers["ed25519"], KeyID: "other"}, params)},
+		{"key of another kid", sign("alice", &JWSSigner{Key: stranger, KeyID: "ed25519"}, params)},
+		{"unknown signer", sign("mallory", &JWSSigner{Key: stranger, KeyID: "ed25519"}, TaskParams{Action: "echo"})},
+		{"signed by another sender", sign("alice", &JWSSigner{Key: signers["ed25519"], KeyID: "ed25519"}, TaskParams{Action: "echo", Sender: "bob"})},
+		{"expired", signAt(t, signers["ed25519"], "ed25519", "alice", old, params)},
+	}
+	for _, tt := range tests {
+		_, opened, rpcErr := server.openJWS(context.Background(), "a2a/task", tt.params)
+		if rpcErr == nil {
+			t.Errorf("%s: opened as %s", tt.name, opened)
+		} else if rpcErr.Code != CodeInvalidSignature {
+			t.Errorf("%s: error %v, want CodeInvalidSignature", tt.name, rpcErr)
+		}
+	}
+}
+
+// signAt returns params signed as a compact JWS dated iat
+func signAt(t *testing.T, key crypto.Signer, kid, agentID string, iat int64, params interface{}) json.RawMessage {
+	t.Helper()
+	alg, _ := jwsAlgorithm(key.Public())
+	header, _ := json.Marshal(jwsHeader{Alg: alg, Kid: kid, Iss: agentID, Iat: iat})
+	payload, _ := json.Marshal(params)
+	b64 := base64.RawURLEncoding.EncodeToString
+	input := b64(header) + "." + b64(payload)
+	sig, err := jwsSign(key, alg, []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, _ := json.Marshal(map[string]string{"jws": input + "." + b64(sig)})
+	return signed
+}
+
+func TestJWSUnverified(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	server := NewServerWithOptions("server")
+	params := TaskParams{Action: "echo", Input: map[string]interface{}{"text": "hello"}}
+	want, _ := json.Marshal(params)
+	for _, detached := range []bool{false, true} {
+		signed, err := (&JWSSigner{Key: key, KeyID: "k1", Detached: detached}).sign("alice", DefaultCodec, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, opened, rpcErr := server.openJWS(context.Background(), "a2a/task", signed)
+		if rpcErr != nil || !sameJSON(t, opened, want) {
+			t.Errorf("detached %v: opened %s, %v; want %s", detached, opened, rpcErr, want)
+		}
+	}
+}
+
+func TestSignedTask(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keys := testKeys(t, "client", map[string]crypto.Signer{"k1": key})
+	server, ts := serveTest(t, WithJWSVerification(JWSVerification{Required: true, Keys: keys}))
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return map[string]interface{}{"sender": sender}, nil
+	})
+
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+	if _, err := agent.SendTask("server", "echo", nil, ""); err == nil {
+		t.Error("unsigned task accepted")
+	}
+	for _, detached := range []bool{false, true} {
+		agent.JWS = &JWSSigner{Key: key, KeyID: "k1", Detached: detached}
+		result, err := agent.SendTask("server", "echo", nil, "")
+		if err != nil {
+			t.Fatalf("detached %v: %v", detached, err)
+		}
+		if result.Output["sender"] != "client" {
+			t.Errorf("detached %v: sender %v, want client", detached, result.Output["sender"])
+		}
+	}
+}
+
+// sameJSON reports whether two JSON values are equal
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(x, y)
+}
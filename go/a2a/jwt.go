@@ -0,0 +1,357 @@
+package a2a
+
+import (
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Where a JWS signs a request's params, a JWT identifies the agent sending
+// any request: an agent with a JWTSigner sends each one with a short-lived
+// token it signs, naming itself as issuer and subject and the endpoint as
+// audience. Servers created WithJWTVerification fetch the keys from the
+// sender's Agent Card, as they do for JWS, and take the verified subject
+// as the sender of tasks and messages, refusing requests claiming another.
+//
+// Over HTTP the token is a bearer token in the Authorization header. Over
+// a Loopback it travels in the request's context.
+
+// JWTSigner identifies an agent to the servers it calls, set as
+// A2AAgent.JWT. Publish its public key with WithJWSKey.
+type JWTSigner struct {
+	// Key is an ed25519.PrivateKey, or an ECDSA (P-256, P-384 or P-521) or
+	// RSA crypto.Signer
+	Key crypto.Signer
+	// KeyID is the kid of Key's public key in the agent's Agent Card
+	KeyID string
+	// TTL is how long each token is valid; zero means 1 minute
+	TTL time.Duration
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+type jwtClaims struct {
+	Iss string      `json:"iss"`
+	Sub string      `json:"sub,omitempty"`
+	Aud jwtAudience `json:"aud,omitempty"`
+	Iat int64       `json:"iat,omitempty"`
+	Exp int64       `json:"exp"`
+	Jti string      `json:"jti,omitempty"`
+}
+
+// jwtAudience is a JWT's aud, which is a string or an array of them
+type jwtAudience []string
+
+func (a jwtAudience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// token returns a JWT identifying agentID to the agent at audience
+func (j *JWTSigner) token(agentID, audience string) (string, error) {
+	alg, err := jwsAlgorithm(j.Key.Public())
+	if err != nil {
+		return "", err
+	}
+	jti := make([]byte, 16)
+	rand.Read(jti)
+	now := time.Now()
+	header, err := json.Marshal(jwtHeader{Alg: alg, Kid: j.KeyID, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(jwtClaims{
+		Iss: agentID,
+		Sub: agentID,
+		Aud: jwtAudience{audience},
+		Iat: now.Unix(),
+		Exp: now.Add(cmp.Or(j.TTL, time.Minute)).Unix(),
+		Jti: hex.EncodeToString(jti),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	input := b64(header) + "." + b64(claims)
+	sig, err := jwsSign(j.Key, alg, []byte(input))
+	if err != nil {
+		return "", fmt.Errorf("signing JWT: %w", err)
+	}
+	return input + "." + b64(sig), nil
+}
+
+type jwtKey struct{}
+
+// identify returns ctx carrying a JWT for endpoint if the agent has a
+// JWTSigner, and its credential if it has Credentials
+func (a *A2AAgent) identify(ctx context.Context, endpoint string) (context.Context, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil || a.JWT == nil {
+		return ctx, err
+	}
+	token, err := a.JWT.token(a.AgentID, endpoint)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, jwtKey{}, token), nil
+}
+
+// jwtFromContext returns the JWT identify added to ctx
+func jwtFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(jwtKey{}).(string)
+	return token
+}
+
+// bearerJWT returns the JWT in an Authorization header, ignoring bearer
+// tokens of other kinds
+func bearerJWT(authorization string) string {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || strings.Count(token, ".") != 2 {
+		return ""
+	}
+	return token
+}
+
+// JWTVerification configures how a server checks the JWTs requests come
+// with
+type JWTVerification struct {
+	// Required refuses task and message requests without a token
+	Required bool
+	// Keys returns the keys agentID signs with. Nil resolves the agent
+	// through the server's directory and reads the jwks of its Agent
+	// Card, or resolves its DID document if agentID is a DID.
+	Keys func(ctx context.Context, agentID string) (*JWKSet, error)
+	// Audience lists the aud values accepted besides the endpoint of the
+	// agent a request is for, e.g. the URL of a proxy in front of it
+	Audience []string
+	// Leeway allows for clocks this far apart when checking exp and iat;
+	// zero means 30 seconds
+	Leeway time.Duration
+	// CacheTTL is how long keys are reused before Keys is called again;
+	// zero means 10 minutes
+	CacheTTL time.Duration
+}
+
+// WithJWTVerification verifies the JWTs requests come with, refusing
+// requests whose token doesn't verify and task and message requests whose
+// sender is not the token's subject. Tasks and messages naming no sender
+// get the subject as their sender.
+func WithJWTVerification(verification JWTVerification) ServerOption {
+	return func(s *A2AServer) {
+		if verification.Leeway <= 0 {
+			verification.Leeway = 30 * time.Second
+		}
+		if verification.CacheTTL <= 0 {
+			verification.CacheTTL = 10 * time.Minute
+		}
+		s.jwt = &jwtVerifier{
+			config: verification,
+			keys:   &jwsVerifier{config: JWSVerification{CacheTTL: verification.CacheTTL}, keys: make(map[string]cachedKeys)},
+		}
+	}
+}
+
+type jwtVerifier struct {
+	config JWTVerification
+	keys   *jwsVerifier // caches the senders' keys
+}
+
+// unauthenticated is the error refusing a request's JWT
+func unauthenticated(reason string) *JSONRPCError {
+	return requestError(CodeUnauthenticated, "Unauthenticated", RequestErrorData{Field: "authorization", Reason: reason})
+}
+
+// openJWT verifies token, the JWT a request for agentID came with, if the
+// server verifies them, and returns params with the token's subject as
+// their sender and ctx naming it as the request's principal
+func (s *A2AServer) openJWT(ctx context.Context, token, agentID, method string, params json.RawMessage) (context.Context, json.RawMessage, *JSONRPCError) {
+	if s.jwt == nil {
+		return ctx, params, nil
+	}
+	if !signedMethods[method] {
+		if token != "" {
+			subject, err := s.verifyJWT(ctx, token, agentID)
+			if err != nil {
+				return ctx, nil, unauthenticated(err.Error())
+			}
+			ctx, rpcErr := jwtPrincipal(ctx, subject)
+			return ctx, params, rpcErr
+		}
+		return ctx, params, nil
+	}
+	claimed := claimedSender(params)
+	sender, rpcErr := s.jwtSender(ctx, token, agentID, claimed)
+	if rpcErr != nil {
+		return ctx, nil, rpcErr
+	}
+	if token != "" {
+		if ctx, rpcErr = jwtPrincipal(ctx, sender); rpcErr != nil {
+			return ctx, nil, rpcErr
+		}
+	}
+	if sender == claimed {
+		return ctx, params, nil
+	}
+	params, err := withSender(method, params, sender)
+	if err != nil {
+		return ctx, nil, unauthenticated(err.Error())
+	}
+	return ctx, params, nil
+}
+
+// jwtPrincipal returns ctx naming subject, a JWT's, as the request's
+// principal, refusing requests a JWS signed as another
+func jwtPrincipal(ctx context.Context, subject string) (context.Context, *JSONRPCError) {
+	if signer, ok := principal(ctx); ok && signer != subject {
+		return ctx, unauthenticated(fmt.Sprintf("token is for %q, not signer %q", subject, signer))
+	}
+	return withPrincipal(ctx, subject), nil
+}
+
+// jwtSender verifies token, the JWT a task or message for agentID came
+// with, and returns its subject as the sender, refusing a claimed sender
+// that is not it. Without a token it returns claimed, unless the server
+// requires one.
+func (s *A2AServer) jwtSender(ctx context.Context, token, agentID, claimed string) (string, *JSONRPCError) {
+	if s.jwt == nil {
+		return claimed, nil
+	}
+	if token == "" {
+		if s.jwt.config.Required {
+			return "", unauthenticated("request has no JWT")
+		}
+		return claimed, nil
+	}
+	subject, err := s.verifyJWT(ctx, token, agentID)
+	if err != nil {
+		return "", unauthenticated(err.Error())
+	}
+	if claimed != "" && claimed != subject {
+		return "", unauthenticated(fmt.Sprintf("token is for %q, not sender %q", subject, claimed))
+	}
+	return subject, nil
+}
+
+// verifyJWT verifies a JWT sent to agentID and returns its subject
+func (s *A2AServer) verifyJWT(ctx context.Context, token, agentID string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("not a JWT")
+	}
+	decode := base64.RawURLEncoding.DecodeString
+	headerJSON, errHeader := decode(parts[0])
+	claimsJSON, errClaims := decode(parts[1])
+	sig, errSig := decode(parts[2])
+	var header jwtHeader
+	var claims jwtClaims
+	if errHeader != nil || errClaims != nil || errSig != nil ||
+		json.Unmarshal(headerJSON, &header) != nil || json.Unmarshal(claimsJSON, &claims) != nil {
+		return "", errors.New("malformed JWT")
+	}
+
+	leeway := s.jwt.config.Leeway
+	now := time.Now()
+	switch {
+	case claims.Iss == "":
+		return "", errors.New("token names no iss")
+	case claims.Sub != "" && claims.Sub != claims.Iss:
+		return "", fmt.Errorf("%s may not issue tokens for %s", claims.Iss, claims.Sub)
+	case claims.Exp == 0 || now.After(time.Unix(claims.Exp, 0).Add(leeway)):
+		return "", errors.New("token has expired")
+	case time.Unix(claims.Iat, 0).After(now.Add(leeway)):
+		return "", errors.New("iat is in the future")
+	case !s.jwtAudience(agentID, claims.Aud):
+		return "", errors.New("token is for another audience")
+	}
+
+	fetch := s.jwt.config.Keys
+	if fetch == nil {
+		fetch = s.cardKeys
+	}
+	jwk, err := s.jwt.keys.key(ctx, claims.Iss, header.Kid, fetch)
+	if err != nil {
+		return "", err
+	}
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return "", err
+	}
+	if (jwk.Alg != "" && jwk.Alg != header.Alg) || !jwsVerify(key, header.Alg, []byte(parts[0]+"."+parts[1]), sig) {
+		return "", errors.New("signature does not verify")
+	}
+	return claims.Iss, nil
+}
+
+// jwtAudience reports whether a token for audience may be used with
+// agentID: if it names the agent's endpoint, its loopback endpoint, or
+// one of JWTVerification.Audience
+func (s *A2AServer) jwtAudience(agentID string, audience jwtAudience) bool {
+	info, ok := s.agentInfo(cmp.Or(agentID, s.AgentID))
+	if !ok {
+		return false
+	}
+	for _, aud := range audience {
+		aud = strings.TrimSuffix(aud, "/")
+		if aud == strings.TrimSuffix(info.Endpoint, "/") || aud == LoopbackEndpoint(info.AgentID) {
+			return true
+		}
+		for _, accepted := range s.jwt.config.Audience {
+			if aud == strings.TrimSuffix(accepted, "/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withSender returns task or message params naming sender as their sender
+func withSender(method string, params json.RawMessage, sender string) (json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(params, &members); err != nil {
+		return nil, err
+	}
+	value, _ := json.Marshal(sender)
+	if method == "a2a/task" || method == "a2a/task/submit" {
+		members["sender"] = value
+		return json.Marshal(members)
+	}
+
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(members["message"], &message); err != nil {
+		return nil, errors.New("no message")
+	}
+	metadata := map[string]json.RawMessage{}
+	if raw, ok := message["metadata"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &metadata); err != nil {
+			return nil, err
+		}
+	}
+	metadata[MetadataSender] = value
+	message["metadata"], _ = json.Marshal(metadata)
+	members["message"], _ = json.Marshal(message)
+	return json.Marshal(members)
+}
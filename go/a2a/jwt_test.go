@@ -0,0 +1,166 @@
+package a2a
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jwtTestEndpoint = "https://server.example/a2a"
+
+// signJWT returns a JWT with claims signed by key
+func signJWT(t *testing.T, key crypto.Signer, kid string, claims jwtClaims) string {
+	t.Helper()
+	alg, _ := jwsAlgorithm(key.Public())
+	header, _ := json.Marshal(jwtHeader{Alg: alg, Kid: kid, Typ: "JWT"})
+	payload, _ := json.Marshal(claims)
+	b64 := base64.RawURLEncoding.EncodeToString
+	input := b64(header) + "." + b64(payload)
+	sig, err := jwsSign(key, alg, []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + b64(sig)
+}
+
+func TestJWTRoundTrip(t *testing.T) {
+	signers := testSigners(t)
+	server := NewServerWithOptions("server", WithEndpoint(jwtTestEndpoint), WithJWTVerification(JWTVerification{Keys: testKeys(t, "alice", signers)}))
+
+	for kid, key := range signers {
+		token, err := (&JWTSigner{Key: key, KeyID: kid}).token("alice", jwtTestEndpoint)
+		if err != nil {
+			t.Fatalf("%s: token: %v", kid, err)
+		}
+		subject, err := server.verifyJWT(context.Background(), token, "")
+		if err != nil || subject != "alice" {
+			t.Errorf("%s: verified as %q, %v; want alice", kid, subject, err)
+		}
+	}
+}
+
+func TestOpenJWT(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	server := NewServerWithOptions("server", WithEndpoint(jwtTestEndpoint), WithJWTVerification(JWTVerification{
+		Required: true,
+		Keys:     testKeys(t, "alice", map[string]crypto.Signer{"k1": key}),
+	}))
+	token, err := (&JWTSigner{Key: key, KeyID: "k1"}).token("alice", jwtTestEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// A task naming no sender gets the token's subject
+	_, params, rpcErr := server.openJWT(ctx, token, "", "a2a/task", json.RawMessage(`{"action":"echo"}`))
+	if rpcErr != nil || claimedSender(params) != "alice" {
+		t.Errorf("anonymous task: params %s, %v; want alice as sender", params, rpcErr)
+	}
+	_, params, rpcErr = server.openJWT(ctx, token, "", "a2a/task", json.RawMessage(`{"action":"echo","sender":"alice"}`))
+	if rpcErr != nil || claimedSender(params) != "alice" {
+		t.Errorf("alice's task: params %s, %v", params, rpcErr)
+	}
+	if _, _, rpcErr = server.openJWT(ctx, token, "", "a2a/task", json.RawMessage(`{"action":"echo","sender":"bob"}`)); rpcErr == nil || rpcErr.Code != CodeUnauthenticated {
+		t.Errorf("task claiming another sender: %v, want CodeUnauthenticated", rpcErr)
+	}
+	if _, _, rpcErr = server.openJWT(ctx, "", "", "a2a/task", json.RawMessage(`{"action":"echo"}`)); rpcErr == nil || rpcErr.Code != CodeUnauthenticated {
+		t.Errorf("task without a token: %v, want CodeUnauthenticated", rpcErr)
+	}
+	// Only tasks and messages need one
+	if _, _, rpcErr = server.openJWT(ctx, "", "", "tasks/get", json.RawMessage(`{"id":"t1"}`)); rpcErr != nil {
+		t.Errorf("tasks/get without a token: %v", rpcErr)
+	}
+	if _, _, rpcErr = server.openJWT(ctx, "not.a.jwt", "", "tasks/get", json.RawMessage(`{"id":"t1"}`)); rpcErr == nil {
+		t.Error("tasks/get with a bad token accepted")
+	}
+}
+
+func TestVerifyJWTRefuses(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	_, stranger, _ := ed25519.GenerateKey(rand.Reader)
+	server := NewServerWithOptions("server", WithEndpoint(jwtTestEndpoint), WithJWTVerification(JWTVerification{
+		Keys: testKeys(t, "alice", map[string]crypto.Signer{"k1": key}),
+	}))
+	now := time.Now()
+	valid := jwtClaims{Iss: "alice", Sub: "alice", Aud: jwtAudience{jwtTestEndpoint}, Iat: now.Unix(), Exp: now.Add(time.Minute).Unix()}
+	with := func(change func(*jwtClaims)) jwtClaims {
+		claims := valid
+		change(&claims)
+		return claims
+	}
+	token := signJWT(t, key, "k1", valid)
+	if _, err := server.verifyJWT(context.Background(), token, ""); err != nil {
+		t.Fatalf("valid token refused: %v", err)
+	}
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "not-a-jwt"},
+		{"signature of other claims", parts[0] + "." + strings.Split(signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Exp++ })), ".")[1] + "." + parts[2]},
+		{"signed by another key", signJWT(t, stranger, "k1", valid)},
+		{"unknown kid", signJWT(t, key, "k2", valid)},
+		{"unknown issuer", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Iss, c.Sub = "mallory", "mallory" }))},
+		{"no issuer", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Iss = "" }))},
+		{"subject other than issuer", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Sub = "bob" }))},
+		{"expired", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Exp = now.Add(-time.Hour).Unix() }))},
+		{"no exp", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Exp = 0 }))},
+		{"iat in the future", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Iat = now.Add(time.Hour).Unix() }))},
+		{"another audience", signJWT(t, key, "k1", with(func(c *jwtClaims) { c.Aud = jwtAudience{"https://other.example"} }))},
+	}
+	for _, tt := range tests {
+		if subject, err := server.verifyJWT(context.Background(), tt.token, ""); err == nil {
+			t.Errorf("%s: verified as %q", tt.name, subject)
+		}
+	}
+}
+
+func TestAuthenticatedTask(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keys := testKeys(t, "client", map[string]crypto.Signer{"k1": key})
+	server, ts := serveTest(t, WithJWTVerification(JWTVerification{Required: true, Keys: keys}))
+	server.HandleTaskFunc(func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return map[string]interface{}{"sender": sender}, nil
+	})
+	server.HandleUpload(func(ctx context.Context, action string, body io.Reader, contentType, sender string) (map[string]interface{}, error) {
+		return map[string]interface{}{"sender": sender}, nil
+	})
+
+	agent := NewAgent("client", "Client", nil)
+	agent.Directory = testDirectory{ts.URL}
+	var rpcErr *JSONRPCError
+	if _, err := agent.SendTask("server", "echo", nil, ""); !errors.As(err, &rpcErr) || rpcErr.Code != CodeUnauthenticated {
+		t.Errorf("task without a token: %v, want CodeUnauthenticated", err)
+	}
+	if _, err := agent.UploadTask("server", "echo", strings.NewReader("data"), "text/plain", ""); !errors.As(err, &rpcErr) || rpcErr.Code != CodeUnauthenticated {
+		t.Errorf("upload without a token: %v, want CodeUnauthenticated", err)
+	}
+
+	agent.JWT = &JWTSigner{Key: key, KeyID: "k1"}
+	result, err := agent.SendTask("server", "echo", nil, "")
+	if err != nil || result.Output["sender"] != "client" {
+		t.Errorf("task: %v, %v; want client as sender", result, err)
+	}
+	result, err = agent.UploadTask("server", "echo", strings.NewReader("data"), "text/plain", "")
+	if err != nil || result.Output["sender"] != "client" {
+		t.Errorf("upload: %v, %v; want client as sender", result, err)
+	}
+
+	// A token for another agent does not carry its sender
+	impostor := NewAgent("intruder", "Intruder", nil)
+	impostor.Directory = testDirectory{ts.URL}
+	impostor.JWT = &JWTSigner{Key: key, KeyID: "k1"}
+	if _, err := impostor.UploadTask("server", "echo", strings.NewReader("data"), "text/plain", ""); !errors.As(err, &rpcErr) || rpcErr.Code != CodeUnauthenticated {
+		t.Errorf("upload by another agent: %v, want CodeUnauthenticated", err)
+	}
+}
@@ -0,0 +1,391 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LongPolling configures WithLongPolling
+type LongPolling struct {
+	MaxWait   time.Duration // longest a poll is held waiting for events; 0 means 30s
+	MaxEvents int           // events kept per task, older ones are replaced by a Task; 0 means 1000
+	Retention time.Duration // how long a finished task's events are kept; 0 means 10m
+}
+
+// WithLongPolling records the events of asynchronous and interactive
+// tasks, as message/stream would send them, so clients behind proxies that
+// block or buffer event streams can follow tasks with tasks/poll instead.
+// A poll is answered as soon as there are events after its cursor, or
+// empty once MaxWait passes. Each event comes with the cursor to resume
+// after it, so a client that lost its connection or restarts misses
+// nothing; a cursor the server no longer knows, e.g. after it restarted,
+// resumes with the task's current state as a Task.
+func WithLongPolling(config LongPolling) ServerOption {
+	return func(s *A2AServer) {
+		if config.MaxWait <= 0 {
+			config.MaxWait = 30 * time.Second
+		}
+		if config.MaxEvents <= 0 {
+			config.MaxEvents = 1000
+		}
+		if config.Retention <= 0 {
+			config.Retention = 10 * time.Minute
+		}
+		s.poll.config = &config
+	}
+}
+
+// TaskPollParams are the params of tasks/poll
+type TaskPollParams struct {
+	ID string `json:"id"`
+	// Cursor resumes after the event it came with; empty starts with the
+	// task's first event
+	Cursor string `json:"cursor,omitempty"`
+	// WaitMs is the longest to wait for an event; 0 means the server's
+	// MaxWait, which also caps it
+	WaitMs int64 `json:"waitMs,omitempty"`
+}
+
+// TaskPollResult is the result of tasks/poll
+type TaskPollResult struct {
+	Events []TaskPollEvent `json:"events"`
+	Cursor string          `json:"cursor"` // to poll from next
+	Final  bool            `json:"final"`  // the task has finished and no more events follow
+}
+
+// TaskPollEvent is one event of tasks/poll with the cursor to resume
+// after it
+type TaskPollEvent struct {
+	Cursor string          `json:"cursor"`
+	Event  json.RawMessage `json:"event"` // a Task, status-update or artifact-update, as message/stream sends them
+}
+
+// pollState holds the events of tasks for tasks/poll, by task ID
+type pollState struct {
+	config *LongPolling // nil when long polling is off
+	mu     sync.Mutex
+	logs   map[string]*eventLog
+}
+
+// eventLog is the recorded events of a task, or of one turn of an
+// interactive task
+type eventLog struct {
+	id      string // tells its cursors from those of logs it replaced or that replace it
+	first   int    // the sequence number of events[0]
+	events  []StreamEvent
+	last    *TaskResult   // the state the events lead to
+	changed chan struct{} // closed and replaced when events are added
+}
+
+// cursor returns the cursor to resume from the event numbered seq
+func (l *eventLog) cursor(seq int) string {
+	return l.id + "." + strconv.Itoa(seq)
+}
+
+// next returns the sequence number of the next event
+func (l *eventLog) next() int {
+	return l.first + len(l.events)
+}
+
+// seq returns the sequence number cursor resumes from, reporting false if
+// it is not one of this log's or its events are no longer kept
+func (l *eventLog) seq(cursor string) (int, bool) {
+	if cursor == "" {
+		return 0, l.first == 0
+	}
+	id, n, ok := strings.Cut(cursor, ".")
+	if !ok || id != l.id {
+		return 0, false
+	}
+	seq, err := strconv.Atoi(n)
+	if err != nil || seq < l.first || seq > l.next() {
+		return 0, false
+	}
+	return seq, true
+}
+
+// newLog starts a log of events with result as a Task
+func (s *A2AServer) newLog(result *TaskResult) *eventLog {
+	task := result.Task(nil)
+	return &eventLog{id: generateID(), events: []StreamEvent{{Task: &task}}, last: result, changed: make(chan struct{})}
+}
+
+// recordEvents adds the events leading to a task's new state to its log.
+// A task resuming with new input starts a new log, as message/stream
+// starts a new stream.
+func (s *A2AServer) recordEvents(result *TaskResult) {
+	if s.poll.config == nil {
+		return
+	}
+	s.poll.mu.Lock()
+	defer s.poll.mu.Unlock()
+	if s.poll.logs == nil {
+		s.poll.logs = make(map[string]*eventLog)
+	}
+
+	log, ok := s.poll.logs[result.TaskID]
+	switch {
+	case ok && !(log.last.Status == TaskStateInputRequired && result.Status != TaskStateInputRequired):
+		log.events = append(log.events, taskEvents(log.last, result)...)
+		log.last = result
+		if excess := len(log.events) - s.poll.config.MaxEvents; excess > 0 {
+			log.events = log.events[excess:]
+			log.first += excess
+		}
+	default:
+		if ok {
+			close(log.changed)
+		}
+		log = s.newLog(result)
+		s.poll.logs[result.TaskID] = log
+	}
+	close(log.changed)
+	log.changed = make(chan struct{})
+
+	if result.Status.Terminal() {
+		time.AfterFunc(s.poll.config.Retention, func() {
+			s.poll.mu.Lock()
+			defer s.poll.mu.Unlock()
+			if s.poll.logs[result.TaskID] == log {
+				delete(s.poll.logs, result.TaskID)
+			}
+		})
+	}
+}
+
+// taskEvents returns the events taking a task from prev to result: an
+// artifact-update per artifact or chunk added, then, if its state changed,
+// a status-update, after an artifact-update with the output of a
+// completed task
+func taskEvents(prev, result *TaskResult) []StreamEvent {
+	var events []StreamEvent
+	artifactUpdate := func(artifact Artifact, appended bool) {
+		events = append(events, StreamEvent{ArtifactUpdate: &TaskArtifactUpdateEvent{
+			TaskID:    result.TaskID,
+			ContextID: result.CorrelationID,
+			Kind:      "artifact-update",
+			Artifact:  artifact,
+			Append:    appended,
+			LastChunk: artifact.LastChunk,
+		}})
+	}
+	for i, artifact := range result.Artifacts {
+		if i >= len(prev.Artifacts) {
+			artifactUpdate(artifact, false)
+			continue
+		}
+		if seen := len(prev.Artifacts[i].Parts); len(artifact.Parts) > seen {
+			artifact.Parts = artifact.Parts[seen:]
+			artifactUpdate(artifact, true)
+		}
+	}
+	if result.Status == prev.Status {
+		return events
+	}
+	if len(result.Output) > 0 {
+		output := outputArtifact(result.Output, len(result.Artifacts))
+		output.LastChunk = true
+		artifactUpdate(output, false)
+	}
+	events = append(events, StreamEvent{StatusUpdate: &TaskStatusUpdateEvent{
+		TaskID:    result.TaskID,
+		ContextID: result.CorrelationID,
+		Kind:      "status-update",
+		Status:    result.Task(nil).Status,
+		Final:     result.Status.Terminal(),
+	}})
+	return events
+}
+
+// pollTask handles tasks/poll, answering once there are events after the
+// cursor, the task has finished, or the wait is over
+func (s *A2AServer) pollTask(ctx context.Context, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	query, rpcErr := decodeParams[TaskPollParams](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if s.poll.config == nil {
+		return nil, &JSONRPCError{Code: CodeUnsupportedOperation, Message: "Long polling not supported", Data: rpcData(UnsupportedOperationData{Operation: "tasks/poll"})}
+	}
+	if current, err := s.store.Get(query.ID); err == nil {
+		if rpcErr := s.checkOwner(ctx, current); rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+	wait := s.poll.config.MaxWait
+	if query.WaitMs > 0 && time.Duration(query.WaitMs)*time.Millisecond < wait {
+		wait = time.Duration(query.WaitMs) * time.Millisecond
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		result, changed, rpcErr := s.pollEvents(query)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		if len(result.Events) > 0 || result.Final {
+			return s.marshalResult(result)
+		}
+		select {
+		case <-changed:
+			query.Cursor = result.Cursor
+		case <-timer.C:
+			return s.marshalResult(result)
+		case <-ctx.Done():
+			return s.marshalResult(result)
+		case <-s.done:
+			return s.marshalResult(result)
+		}
+	}
+}
+
+// pollEvents returns a task's events after query's cursor, and a channel
+// closed when there are more
+func (s *A2AServer) pollEvents(query TaskPollParams) (*TaskPollResult, <-chan struct{}, *JSONRPCError) {
+	s.poll.mu.Lock()
+	log, ok := s.poll.logs[query.ID]
+	s.poll.mu.Unlock()
+	if !ok {
+		// Not recorded since the server started, or no longer kept
+		current, rpcErr := s.lookupTask(query.ID)
+		if rpcErr != nil {
+			return nil, nil, rpcErr
+		}
+		s.poll.mu.Lock()
+		if log, ok = s.poll.logs[query.ID]; !ok {
+			if s.poll.logs == nil {
+				s.poll.logs = make(map[string]*eventLog)
+			}
+			log = s.newLog(current)
+			s.poll.logs[query.ID] = log
+		}
+		s.poll.mu.Unlock()
+	}
+
+	s.poll.mu.Lock()
+	defer s.poll.mu.Unlock()
+	result := &TaskPollResult{Events: []TaskPollEvent{}, Cursor: log.cursor(log.next()), Final: log.last.Status.Terminal()}
+	add := func(event StreamEvent, cursor string) *JSONRPCError {
+		body, err := s.codec.Marshal(event.Result())
+		if err != nil {
+			return &JSONRPCError{Code: CodeInternalError, Message: "Internal error"}
+		}
+		result.Events = append(result.Events, TaskPollEvent{Cursor: cursor, Event: body})
+		return nil
+	}
+
+	seq, ok := log.seq(query.Cursor)
+	if !ok {
+		// Resume with the task as it is now
+		task := log.last.Task(nil)
+		if rpcErr := add(StreamEvent{Task: &task}, result.Cursor); rpcErr != nil {
+			return nil, nil, rpcErr
+		}
+		return result, log.changed, nil
+	}
+	for ; seq < log.next(); seq++ {
+		if rpcErr := add(log.events[seq-log.first], log.cursor(seq+1)); rpcErr != nil {
+			return nil, nil, rpcErr
+		}
+	}
+	return result, log.changed, nil
+}
+
+// PollTask follows a task on another agent by long polling tasks/poll,
+// for networks whose proxies block or buffer message/stream's event
+// streams. handle is called with each event, a Task first and then
+// status-update and artifact-update events as message/stream sends them,
+// and the cursor to resume after it, until the task finishes or handle
+// returns an error, which PollTask then returns. Pass "" as cursor to
+// start with the task's first event, or a cursor handle was given to
+// resume after it. HTTPClient's timeout, if any, must outlast the
+// target's MaxWait.
+func (a *A2AAgent) PollTask(targetAgentID, taskID, cursor, directoryURL string, handle func(event StreamEvent, cursor string) error) error {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
+		return err
+	}
+	return a.pollTask(context.Background(), agentInfo.Endpoint, taskID, cursor, handle)
+}
+
+func (a *A2AAgent) pollTask(ctx context.Context, endpoint, taskID, cursor string, handle func(event StreamEvent, cursor string) error) error {
+	for {
+		result, err := a.doRequest(ctx, endpoint, "tasks/poll", TaskPollParams{ID: taskID, Cursor: cursor})
+		if err != nil {
+			return fmt.Errorf("poll failed: %w", err)
+		}
+		var page TaskPollResult
+		if err := a.codec().Unmarshal(result, &page); err != nil {
+			return fmt.Errorf("poll failed: %w", err)
+		}
+		for _, e := range page.Events {
+			event, err := a.decodeEvent(e.Event)
+			if err != nil {
+				return fmt.Errorf("poll failed: %w", err)
+			}
+			if err := handle(event, e.Cursor); err != nil {
+				return err
+			}
+			cursor = e.Cursor
+		}
+		if page.Final {
+			return nil
+		}
+		if page.Cursor != "" {
+			cursor = page.Cursor
+		}
+	}
+}
+
+// pollMessage is StreamMessage for agents set to LongPoll: msg is sent
+// with a non-blocking message/send and its task followed with tasks/poll
+// until it finishes or needs input
+func (a *A2AAgent) pollMessage(endpoint string, params MessageSendParams, handle func(StreamEvent) error) error {
+	blocking := false
+	params.Configuration = &MessageSendConfiguration{Blocking: &blocking}
+	result, err := a.doRequest(context.Background(), endpoint, "message/send", params)
+	if err != nil {
+		return fmt.Errorf("stream failed: %w", err)
+	}
+	event, err := a.decodeEvent(result)
+	if err != nil {
+		return fmt.Errorf("stream failed: %w", err)
+	}
+	if event.Task == nil {
+		return handle(event)
+	}
+
+	var handleErr error // returned as it is
+	err = a.pollTask(context.Background(), endpoint, event.Task.ID, "", func(event StreamEvent, _ string) error {
+		if handleErr = handle(event); handleErr != nil {
+			return handleErr
+		}
+		var state TaskState
+		switch {
+		case event.Task != nil:
+			state = event.Task.Status.State
+		case event.StatusUpdate != nil:
+			state = event.StatusUpdate.Status.State
+		}
+		if state == TaskStateInputRequired {
+			return errStreamEnd
+		}
+		return nil
+	})
+	switch {
+	case handleErr != nil:
+		return handleErr
+	case errors.Is(err, errStreamEnd):
+		return nil
+	case err != nil:
+		return fmt.Errorf("stream failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LoopbackScheme is the URL scheme of loopback endpoints, whose opaque
+// part is an agent ID, e.g. loopback:billing-agent
+const LoopbackScheme = "loopback"
+
+// LoopbackEndpoint returns the endpoint to register for an agent reached
+// through a Loopback
+func LoopbackEndpoint(agentID string) string {
+	return LoopbackScheme + ":" + agentID
+}
+
+// Loopback connects servers compiled into one binary, so their agents can
+// send each other tasks by function call instead of over HTTP. Tasks go to
+// the server serving their AgentID and run through the same code as the
+// JSON-RPC methods, so limits, hooks, retries and the task store apply as
+// over the network. Params and results are shared with the handler rather
+// than encoded, unless WithSerialization is given.
+//
+// A Loopback is also a Transport, so agents can find each other in a
+// directory as over the network, with LoopbackEndpoint as their endpoint:
+//
+//	agent.Transports = map[string]a2a.Transport{a2a.LoopbackScheme: loopback}
+//
+// Requests sent that way are always encoded, as they are JSON-RPC.
+type Loopback struct {
+	mu        sync.RWMutex
+	servers   map[string]*A2AServer // by agent ID, hosted agents included
+	serialize bool
+}
+
+// LoopbackOption configures a Loopback
+type LoopbackOption func(*Loopback)
+
+// WithSerialization round-trips params, results and stream events
+// through each server's codec, as a network transport would, so tests
+// catch values that would not survive encoding
+func WithSerialization() LoopbackOption {
+	return func(l *Loopback) {
+		l.serialize = true
+	}
+}
+
+// NewLoopback creates a loopback with no servers
+func NewLoopback(opts ...LoopbackOption) *Loopback {
+	l := &Loopback{servers: make(map[string]*A2AServer)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Serve makes server's agents, hosted agents included, reachable through
+// the loopback. Agents hosted on server afterwards need Serve again.
+func (l *Loopback) Serve(server *A2AServer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, agent := range server.Agents() {
+		l.servers[agent.AgentID] = server
+	}
+}
+
+// Remove makes an agent unreachable through the loopback
+func (l *Loopback) Remove(agentID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.servers, agentID)
+}
+
+// Agents returns the agents reachable through the loopback, by ID
+func (l *Loopback) Agents() []AgentInfo {
+	l.mu.RLock()
+	servers := make(map[*A2AServer]bool)
+	for _, server := range l.servers {
+		servers[server] = true
+	}
+	l.mu.RUnlock()
+
+	var agents []AgentInfo
+	for server := range servers {
+		for _, agent := range server.Agents() {
+			if l.server(agent.AgentID) == server {
+				agents = append(agents, agent)
+			}
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].AgentID < agents[j].AgentID })
+	return agents
+}
+
+// server returns the server serving agentID, or nil
+func (l *Loopback) server(agentID string) *A2AServer {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.servers[agentID]
+}
+
+// lookup returns the server serving agentID, or ErrAgentNotFound
+func (l *Loopback) lookup(agentID string) (*A2AServer, error) {
+	server := l.server(agentID)
+	if server == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, agentID)
+	}
+	return server, nil
+}
+
+// SendTask runs a task on the agent named by its AgentID and returns the
+// result, as A2AAgent.SendTask does
+func (l *Loopback) SendTask(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	server, err := l.lookup(task.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	if task, err = roundTrip(l, server, task); err != nil {
+		return nil, err
+	}
+	return l.result(server, func() (*TaskResult, error) { return server.RunTask(ctx, task) })
+}
+
+// SubmitTask queues a task on the agent named by its AgentID and returns
+// its submitted state, as A2AAgent.SubmitTask does
+func (l *Loopback) SubmitTask(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	server, err := l.lookup(task.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	if task, err = roundTrip(l, server, task); err != nil {
+		return nil, err
+	}
+	return l.result(server, func() (*TaskResult, error) { return server.SubmitTask(ctx, task) })
+}
+
+// GetTask returns the current state of an asynchronous task on agentID
+func (l *Loopback) GetTask(agentID, taskID string) (*TaskResult, error) {
+	server, err := l.lookup(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return l.result(server, func() (*TaskResult, error) { return server.GetTask(taskID) })
+}
+
+// CancelTask cancels an asynchronous task on agentID and returns its
+// canceled state
+func (l *Loopback) CancelTask(agentID, taskID string) (*TaskResult, error) {
+	server, err := l.lookup(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return l.result(server, func() (*TaskResult, error) { return server.CancelTask(taskID) })
+}
+
+// StreamTask runs a task on the agent named by its AgentID, calling
+// handle with each event message/stream would send, as
+// A2AServer.StreamTask does
+func (l *Loopback) StreamTask(ctx context.Context, task TaskParams, handle func(StreamEvent) error) error {
+	server, err := l.lookup(task.AgentID)
+	if err != nil {
+		return err
+	}
+	if task, err = roundTrip(l, server, task); err != nil {
+		return err
+	}
+	return server.StreamTask(ctx, task, func(event StreamEvent) error {
+		event, err := roundTrip(l, server, event)
+		if err != nil {
+			return err
+		}
+		return handle(event)
+	})
+}
+
+// Send implements Transport for loopback endpoints
+func (l *Loopback) Send(ctx context.Context, endpoint string, req JSONRPCRequest) (*JSONRPCResponse, error) {
+	var resp *JSONRPCResponse
+	err := l.Stream(ctx, endpoint, req, func(r *JSONRPCResponse) error {
+		resp = r
+		return nil
+	})
+	if err == nil && resp == nil {
+		err = fmt.Errorf("no response from %s", endpoint)
+	}
+	return resp, err
+}
+
+// Stream implements Transport for loopback endpoints
+func (l *Loopback) Stream(ctx context.Context, endpoint string, req JSONRPCRequest, handle func(*JSONRPCResponse) error) error {
+	agentID, ok := strings.CutPrefix(endpoint, LoopbackScheme+":")
+	if !ok {
+		return fmt.Errorf("not a loopback endpoint: %s", endpoint)
+	}
+	server, err := l.lookup(agentID)
+	if err != nil {
+		return err
+	}
+	codec := server.codec
+	if codec == nil {
+		codec = DefaultCodec
+	}
+	body, err := codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if agentID == server.AgentID {
+		agentID = ""
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var handleErr error
+	server.handleJSONRPC(ctx, agentID, body, func(resp *JSONRPCResponse) {
+		if handleErr == nil {
+			if handleErr = handle(resp); handleErr != nil {
+				cancel()
+			}
+		}
+	}, "transport", "loopback")
+	return handleErr
+}
+
+// Listen implements Transport, serving server through the loopback until
+// ctx is done
+func (l *Loopback) Listen(ctx context.Context, server *A2AServer) error {
+	l.Serve(server)
+	<-ctx.Done()
+	for _, agent := range server.Agents() {
+		if l.server(agent.AgentID) == server {
+			l.Remove(agent.AgentID)
+		}
+	}
+	return nil
+}
+
+// result calls fn, round-tripping its result when serializing
+func (l *Loopback) result(server *A2AServer, fn func() (*TaskResult, error)) (*TaskResult, error) {
+	result, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	return roundTrip(l, server, result)
+}
+
+// roundTrip returns v encoded and decoded with server's codec when the
+// loopback serializes, and v itself otherwise
+func roundTrip[T any](l *Loopback, server *A2AServer, v T) (T, error) {
+	if !l.serialize {
+		return v, nil
+	}
+	codec := server.codec
+	if codec == nil {
+		codec = DefaultCodec
+	}
+	var decoded T
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return decoded, err
+	}
+	err = codec.Unmarshal(data, &decoded)
+	return decoded, err
+}
@@ -0,0 +1,343 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// ClientName is the name the bridge gives MCP servers in initialize, with
+// the A2A protocol version as its version
+const ClientName = "a2a-mcp-bridge"
+
+// conn carries JSON-RPC messages to an MCP server. *a2a.StdioClient is
+// one, as MCP's stdio framing is the one ServeStdio uses.
+type conn interface {
+	Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
+	Notify(method string, params interface{}) error
+	Close() error
+}
+
+// Client calls the tools of an MCP server
+type Client struct {
+	conn         conn
+	serverInfo   Implementation
+	instructions string
+}
+
+// StartClient starts cmd, an MCP server speaking stdio, and initializes a
+// session with it. Close stops it.
+func StartClient(ctx context.Context, cmd *exec.Cmd) (*Client, error) {
+	stdio, err := a2a.StartStdioAgent(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return connect(ctx, stdio)
+}
+
+// NewStdioClient initializes a session with an MCP server reading
+// requests from w and writing responses to r
+func NewStdioClient(ctx context.Context, r io.Reader, w io.Writer) (*Client, error) {
+	return connect(ctx, a2a.NewStdioClient(r, w))
+}
+
+// NewHTTPClient initializes a session with an MCP server served over
+// streamable HTTP at url. A nil client means http.DefaultClient.
+func NewHTTPClient(ctx context.Context, url string, client *http.Client) (*Client, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return connect(ctx, &httpConn{url: url, client: client})
+}
+
+// connect initializes a session over c, closing c if that fails
+func connect(ctx context.Context, c conn) (*Client, error) {
+	result, err := c.Call(ctx, "initialize", initializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      Implementation{Name: ClientName, Version: a2a.ProtocolVersion},
+	})
+	if err == nil {
+		var init initializeResult
+		if err = json.Unmarshal(result, &init); err == nil {
+			if hc, ok := c.(*httpConn); ok {
+				hc.setVersion(init.ProtocolVersion)
+			}
+			if err = c.Notify("notifications/initialized", nil); err == nil {
+				return &Client{conn: c, serverInfo: init.ServerInfo, instructions: init.Instructions}, nil
+			}
+		}
+	}
+	c.Close()
+	return nil, fmt.Errorf("MCP initialize failed: %w", err)
+}
+
+// ServerInfo returns the name and version the server reported
+func (c *Client) ServerInfo() Implementation {
+	return c.serverInfo
+}
+
+// Instructions returns what the server said about using its tools
+func (c *Client) Instructions() string {
+	return c.instructions
+}
+
+// Close ends the session, stopping a server started with StartClient
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Tools returns the server's tools, following pagination
+func (c *Client) Tools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	var cursor string
+	for {
+		result, err := c.conn.Call(ctx, "tools/list", listToolsParams{Cursor: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("tools/list failed: %w", err)
+		}
+		var page listToolsResult
+		if err := json.Unmarshal(result, &page); err != nil {
+			return nil, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool calls a tool. Failures of the tool itself are results with
+// IsError set; errors are failures to call it.
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	result, err := c.conn.Call(ctx, "tools/call", callToolParams{Name: name, Arguments: arguments})
+	if err != nil {
+		return nil, fmt.Errorf("tools/call failed: %w", err)
+	}
+	var toolResult CallToolResult
+	if err := json.Unmarshal(result, &toolResult); err != nil {
+		return nil, err
+	}
+	return &toolResult, nil
+}
+
+// TaskFunc returns a task handler calling client's tool named by the
+// task's action with the task's input as arguments. The output is the
+// tool's structured result or, failing that, its text as "text" and any
+// other content blocks as "content". Tool errors fail the task.
+func TaskFunc(client *Client) a2a.TaskFunc {
+	return func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		result, err := client.CallTool(ctx, action, input)
+		if err != nil {
+			var rpcErr *a2a.JSONRPCError
+			if errors.As(err, &rpcErr) {
+				return nil, &a2a.TaskError{Code: rpcErr.Code, Message: rpcErr.Message, Err: err}
+			}
+			return nil, err
+		}
+		if result.IsError {
+			return nil, &a2a.TaskError{Code: a2a.CodeTaskFailed, Message: result.Text()}
+		}
+		return toolOutput(result), nil
+	}
+}
+
+// toolOutput returns a tool result as task output
+func toolOutput(result *CallToolResult) map[string]interface{} {
+	if result.StructuredContent != nil {
+		return result.StructuredContent
+	}
+	output := make(map[string]interface{})
+	var other []Content
+	for _, c := range result.Content {
+		if c.Type != "text" {
+			other = append(other, c)
+		}
+	}
+	if text := result.Text(); text != "" {
+		output["text"] = text
+	}
+	if len(other) > 0 {
+		output["content"] = other
+	}
+	return output
+}
+
+// NewAgent returns an A2A server for an MCP server's tools: each tool is a
+// capability, with the tool's schemas as the capability's, and tasks call
+// the tool their action names. The MCP server's instructions become the
+// agent's description.
+func NewAgent(ctx context.Context, client *Client, agentID, name string, port int) (*a2a.A2AServer, error) {
+	tools, err := client.Tools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	capabilities := make([]string, 0, len(tools))
+	schemas := make(map[string]a2a.CapabilitySchema)
+	for _, t := range tools {
+		capabilities = append(capabilities, t.Name)
+		schemas[t.Name] = a2a.CapabilitySchema{Input: t.InputSchema, Output: t.OutputSchema}
+	}
+
+	server := a2a.NewServer(agentID, name, capabilities, port)
+	server.Description = client.Instructions()
+	server.Schemas = schemas
+	server.HandleTaskFunc(TaskFunc(client))
+	return server, nil
+}
+
+// httpConn speaks MCP's streamable HTTP transport: each message is POSTed
+// and answered with JSON or an event stream carrying the response
+type httpConn struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+	version   string
+	nextID    int64
+}
+
+func (c *httpConn) setVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = version
+}
+
+// Call implements conn
+func (c *httpConn) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+
+	resp, err := c.post(ctx, a2a.JSONRPCRequest{JSONRPC: "2.0", ID: a2a.NumberID(id), Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if session := resp.Header.Get("Mcp-Session-Id"); session != "" {
+		c.mu.Lock()
+		c.sessionID = session
+		c.mu.Unlock()
+	}
+
+	var rpcResp *a2a.JSONRPCResponse
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		rpcResp, err = readEvents(resp.Body, id)
+	} else {
+		rpcResp = new(a2a.JSONRPCResponse)
+		err = json.NewDecoder(resp.Body).Decode(rpcResp)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rpcResp.Error != nil {
+		return nil, rpcResp.Error
+	}
+	return rpcResp.Result, nil
+}
+
+// readEvents returns the response with ID id from an event stream,
+// skipping the server's other messages
+func readEvents(r io.Reader, id int64) (*a2a.JSONRPCResponse, error) {
+	want := a2a.NumberID(id).String()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(payload, []byte(" "))...)
+			continue
+		}
+		if len(line) > 0 || len(data) == 0 {
+			continue
+		}
+		var resp a2a.JSONRPCResponse
+		err := json.Unmarshal(data, &resp)
+		data = data[:0]
+		if err == nil && resp.ID.String() == want {
+			return &resp, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("event stream ended without a response")
+}
+
+// Notify implements conn
+func (c *httpConn) Notify(method string, params interface{}) error {
+	body := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if params != nil {
+		body["params"] = params
+	}
+	resp, err := c.post(context.Background(), body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// post sends a message with the session's headers
+func (c *httpConn) post(ctx context.Context, msg interface{}) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	c.mu.Lock()
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	if c.version != "" {
+		req.Header.Set("MCP-Protocol-Version", c.version)
+	}
+	c.mu.Unlock()
+	return c.client.Do(req)
+}
+
+// Close ends the session, as servers with sessions ask clients to
+func (c *httpConn) Close() error {
+	c.mu.Lock()
+	session := c.sessionID
+	c.mu.Unlock()
+	if session == "" {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Mcp-Session-Id", session)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
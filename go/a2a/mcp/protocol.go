@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// ProtocolVersion is the MCP revision the bridge speaks by default
+const ProtocolVersion = "2025-06-18"
+
+// protocolVersions are the revisions the bridge accepts, newest first
+var protocolVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// negotiate returns the revision to answer a client asking for version
+// with: the same one if supported, or the newest otherwise
+func negotiate(version string) string {
+	if slices.Contains(protocolVersions, version) {
+		return version
+	}
+	return ProtocolVersion
+}
+
+// Tool describes a tool as tools/list returns it
+type Tool struct {
+	Name         string          `json:"name"`
+	Title        string          `json:"title,omitempty"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  json.RawMessage `json:"inputSchema"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+}
+
+// Content is a block of a tool result: text, or base64 data of an image
+// or audio clip
+type Content struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// CallToolResult is the result of tools/call. Failures of the tool itself
+// are results with IsError set, not JSON-RPC errors, so the model sees
+// them.
+type CallToolResult struct {
+	Content           []Content              `json:"content"`
+	StructuredContent map[string]interface{} `json:"structuredContent,omitempty"`
+	IsError           bool                   `json:"isError,omitempty"`
+}
+
+// Text returns the result's text blocks, one per line
+func (r *CallToolResult) Text() string {
+	var text string
+	for _, c := range r.Content {
+		if c.Type != "text" {
+			continue
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += c.Text
+	}
+	return text
+}
+
+// Implementation names an MCP client or server and its version
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type initializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ClientInfo      Implementation         `json:"clientInfo"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      Implementation         `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+type listToolsParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type listToolsResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type callToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
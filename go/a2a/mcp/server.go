@@ -0,0 +1,268 @@
+// Package mcp bridges A2A and the Model Context Protocol, so LLM hosts
+// speaking MCP can use A2A agents as tools and MCP servers can take part
+// in A2A as agents.
+//
+// A Server presents agents' capabilities as MCP tools, one per agent and
+// capability, named by ToolName. Calling a tool sends the agent a task with
+// the tool's arguments as input, and the task's output is the tool's
+// structured result. Serve it to a host over stdio or streamable HTTP:
+//
+//	agent := a2a.NewAgent("mcp-bridge", "MCP Bridge", nil)
+//	agents, _ := a2a.NewHTTPDirectory(directoryURL).Discover(a2a.DiscoverParams{Capabilities: []string{"search"}})
+//	bridge := mcp.NewServer(agent, directoryURL, agents...)
+//	bridge.ServeStdio(ctx, os.Stdin, os.Stdout)
+//	// or: http.Handle("/mcp", bridge)
+//
+// The other way, NewAgent wraps an MCP server as an A2A server whose
+// capabilities are the MCP server's tools; a task's action names the tool
+// and its input is the tool's arguments:
+//
+//	client, _ := mcp.StartClient(ctx, exec.Command("npx", "-y", "@modelcontextprotocol/server-filesystem", "/data"))
+//	defer client.Close()
+//	server, _ := mcp.NewAgent(ctx, client, "files-agent", "Files", 9002)
+//	server.Serve()
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/tools"
+)
+
+// ToolName returns the name of the tool calling capability on agentID, as
+// tools.Name does
+func ToolName(agentID, capability string) string {
+	return tools.Name(agentID, capability)
+}
+
+// Server is an MCP server whose tools send tasks to A2A agents
+type Server struct {
+	// Name and Version are reported to hosts; empty means the sending
+	// agent's ID and the A2A protocol version
+	Name    string
+	Version string
+	// Instructions tells the host's model how to use the tools
+	Instructions string
+
+	agent      *a2a.A2AAgent
+	dispatcher *tools.Dispatcher
+
+	mu    sync.RWMutex
+	tools []Tool
+	names map[string]int // index in tools, by name
+}
+
+// NewServer returns a server sending tasks with agent, resolving agents
+// with directoryURL, and offering the capabilities of agents as tools
+func NewServer(agent *a2a.A2AAgent, directoryURL string, agents ...a2a.AgentInfo) *Server {
+	s := &Server{agent: agent, dispatcher: tools.NewDispatcher(agent, directoryURL, nil), names: make(map[string]int)}
+	s.Add(agents...)
+	return s
+}
+
+// Add offers the capabilities of agents as tools. A tool whose name is
+// already taken replaces the earlier one.
+func (s *Server) Add(agents ...a2a.AgentInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, info := range agents {
+		for _, capability := range info.Capabilities {
+			def := tools.FromCapability(info, capability)
+			s.dispatcher.Add(def)
+			t := Tool{
+				Name:         def.Name,
+				Title:        fmt.Sprintf("%s (%s)", capability, info.Name),
+				Description:  def.Description,
+				InputSchema:  def.Parameters,
+				OutputSchema: info.Schemas[capability].Output,
+			}
+			if i, ok := s.names[t.Name]; ok {
+				s.tools[i] = t
+				continue
+			}
+			s.names[t.Name] = len(s.tools)
+			s.tools = append(s.tools, t)
+		}
+	}
+}
+
+// Tools returns the tools offered, in the order added
+func (s *Server) Tools() []Tool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Tool(nil), s.tools...)
+}
+
+// ServeStdio serves MCP requests read from r, one per line, writing each
+// response to w as one line, for hosts running the bridge as a
+// subprocess. Requests run concurrently. ServeStdio returns nil when r
+// ends, once every request read has been answered, ctx's error once it is
+// done, or the error that stopped it.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp := s.handle(line)
+				if resp == nil {
+					return
+				}
+				body, _ := json.Marshal(resp)
+				mu.Lock()
+				defer mu.Unlock()
+				w.Write(append(body, '\n'))
+			}()
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ServeHTTP serves MCP's streamable HTTP transport, answering each POSTed
+// request with a JSON response. The server keeps no sessions and sends no
+// server-initiated messages, so GET is not allowed.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	resp := s.handle(body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// request is an MCP request or, without an ID, a notification
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      a2a.RequestID   `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// handle answers one message, returning nil for notifications
+func (s *Server) handle(body []byte) *a2a.JSONRPCResponse {
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return &a2a.JSONRPCResponse{JSONRPC: "2.0", Error: &a2a.JSONRPCError{Code: a2a.CodeParseError, Message: "Parse error"}}
+	}
+	if req.ID.IsNull() {
+		return nil
+	}
+
+	resp := &a2a.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID}
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		json.Unmarshal(req.Params, &params)
+		result = s.initialize(params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = listToolsResult{Tools: s.Tools()}
+	case "tools/call":
+		var params callToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			resp.Error = &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: "Invalid params"}
+			return resp
+		}
+		result, resp.Error = s.callTool(params)
+	default:
+		resp.Error = &a2a.JSONRPCError{Code: a2a.CodeMethodNotFound, Message: "Method not found"}
+	}
+	if resp.Error == nil {
+		resp.Result, _ = json.Marshal(result)
+	}
+	return resp
+}
+
+func (s *Server) initialize(params initializeParams) initializeResult {
+	name := s.Name
+	if name == "" {
+		name = s.agent.AgentID
+	}
+	version := s.Version
+	if version == "" {
+		version = a2a.ProtocolVersion
+	}
+	return initializeResult{
+		ProtocolVersion: negotiate(params.ProtocolVersion),
+		Capabilities:    map[string]interface{}{"tools": map[string]interface{}{}},
+		ServerInfo:      Implementation{Name: name, Version: version},
+		Instructions:    s.Instructions,
+	}
+}
+
+// callTool sends the task a tool call stands for. Tasks that fail or stop
+// short of completing are error results.
+func (s *Server) callTool(params callToolParams) (*CallToolResult, *a2a.JSONRPCError) {
+	s.mu.RLock()
+	_, ok := s.names[params.Name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: "Unknown tool: " + params.Name}
+	}
+
+	var arguments json.RawMessage
+	if params.Arguments != nil {
+		arguments, _ = json.Marshal(params.Arguments)
+	}
+	result := s.dispatcher.Dispatch(tools.Call{Name: params.Name, Arguments: arguments})
+	toolResult := &CallToolResult{Content: []Content{{Type: "text", Text: result.Content}}, IsError: result.IsError}
+	if !result.IsError {
+		toolResult.StructuredContent = result.Task.Output
+		if toolResult.StructuredContent == nil {
+			toolResult.StructuredContent = make(map[string]interface{})
+		}
+	}
+	return toolResult, nil
+}
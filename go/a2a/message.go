@@ -0,0 +1,243 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Message roles
+const (
+	RoleUser  = "user"  // sent by the client
+	RoleAgent = "agent" // sent by the agent doing the work
+)
+
+// Message is one turn of communication in the A2A specification's shape:
+// text, files and structured data in a single payload
+type Message struct {
+	Role      string                 `json:"role"`
+	Parts     []Part                 `json:"parts"`
+	MessageID string                 `json:"messageId"`
+	TaskID    string                 `json:"taskId,omitempty"`
+	ContextID string                 `json:"contextId,omitempty"`
+	Kind      string                 `json:"kind"` // always "message"
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// NewMessage returns a message from role with a fresh ID
+func NewMessage(role string, parts ...Part) Message {
+	return Message{Role: role, Parts: parts, MessageID: generateID(), Kind: "message"}
+}
+
+// Part is a piece of a message's content: a TextPart, FilePart or DataPart
+type Part interface {
+	// PartKind is "text", "file" or "data"
+	PartKind() string
+}
+
+// TextPart is plain text
+type TextPart struct {
+	Text     string                 `json:"text"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FilePart is a file, sent inline or by reference
+type FilePart struct {
+	File     File                   `json:"file"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// File is the content of a FilePart: either the Bytes themselves (base64
+// on the wire) or a URI to fetch them from
+type File struct {
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Bytes    []byte `json:"bytes,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+// DataPart is structured JSON data, such as a form or tool arguments
+type DataPart struct {
+	Data     map[string]interface{} `json:"data"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func (TextPart) PartKind() string { return "text" }
+func (FilePart) PartKind() string { return "file" }
+func (DataPart) PartKind() string { return "data" }
+
+// MarshalJSON adds the part's "kind" discriminator
+func (p TextPart) MarshalJSON() ([]byte, error) {
+	type plain TextPart
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{p.PartKind(), plain(p)})
+}
+
+// MarshalJSON adds the part's "kind" discriminator
+func (p FilePart) MarshalJSON() ([]byte, error) {
+	type plain FilePart
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{p.PartKind(), plain(p)})
+}
+
+// MarshalJSON adds the part's "kind" discriminator
+func (p DataPart) MarshalJSON() ([]byte, error) {
+	type plain DataPart
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{p.PartKind(), plain(p)})
+}
+
+// UnmarshalJSON decodes each part into the type named by its "kind"
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type plain Message
+	var decoded struct {
+		plain
+		Parts []json.RawMessage `json:"parts"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = Message(decoded.plain)
+	var err error
+	m.Parts, err = unmarshalParts(decoded.Parts)
+	return err
+}
+
+// unmarshalParts decodes a list of parts by their kinds
+func unmarshalParts(raws []json.RawMessage) ([]Part, error) {
+	var parts []Part
+	for _, raw := range raws {
+		part, err := unmarshalPart(raw)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// unmarshalPart decodes one part by its kind
+func unmarshalPart(raw json.RawMessage) (Part, error) {
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(raw, &kind); err != nil {
+		return nil, err
+	}
+	switch kind.Kind {
+	case "text":
+		var part TextPart
+		err := json.Unmarshal(raw, &part)
+		return part, err
+	case "file":
+		var part FilePart
+		err := json.Unmarshal(raw, &part)
+		return part, err
+	case "data":
+		var part DataPart
+		err := json.Unmarshal(raw, &part)
+		return part, err
+	}
+	return nil, fmt.Errorf("unknown part kind %q", kind.Kind)
+}
+
+// Text returns the message's text parts joined by newlines
+func (m Message) Text() string {
+	var texts []string
+	for _, part := range m.Parts {
+		if text, ok := part.(TextPart); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Input flattens the message into the map-based input task handlers take:
+// the fields of its data parts, its text under "text" and its files under
+// "files" as a []File. Data fields named "text" or "files" win.
+func (m Message) Input() map[string]interface{} {
+	input := make(map[string]interface{})
+	var files []File
+	for _, part := range m.Parts {
+		if file, ok := part.(FilePart); ok {
+			files = append(files, file.File)
+		}
+	}
+	if text := m.Text(); text != "" {
+		input["text"] = text
+	}
+	if len(files) > 0 {
+		input["files"] = files
+	}
+	for _, part := range m.Parts {
+		if data, ok := part.(DataPart); ok {
+			maps.Copy(input, data.Data)
+		}
+	}
+	return input
+}
+
+// MessageFromInput is the inverse of Message.Input: a user message with
+// input["text"] as a text part, each File in input["files"] as a file part
+// and every other field in one data part
+func MessageFromInput(input map[string]interface{}) Message {
+	msg := NewMessage(RoleUser)
+	data := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		data[key] = value
+	}
+	if text, ok := data["text"].(string); ok {
+		msg.Parts = append(msg.Parts, TextPart{Text: text})
+		delete(data, "text")
+	}
+	if files, ok := data["files"].([]File); ok {
+		for _, file := range files {
+			msg.Parts = append(msg.Parts, FilePart{File: file})
+		}
+		delete(data, "files")
+	}
+	if len(data) > 0 {
+		msg.Parts = append(msg.Parts, DataPart{Data: data})
+	}
+	return msg
+}
+
+// WithMessage sends msg with the task. Handlers receive its content merged
+// into their input as by Message.Input, and the message itself from
+// TaskMessage.
+func WithMessage(msg Message) TaskOption {
+	return func(p *TaskParams) {
+		p.Message = &msg
+	}
+}
+
+type messageKey struct{}
+
+// TaskMessage returns the message sent with the task a handler is running,
+// if any
+func TaskMessage(ctx context.Context) (Message, bool) {
+	msg, ok := ctx.Value(messageKey{}).(*Message)
+	if !ok {
+		return Message{}, false
+	}
+	return *msg, true
+}
+
+// withMessage makes a task's message available to its handler and merges
+// it into the input, keeping Input's own fields where both set one
+func withMessage(ctx context.Context, task TaskParams) (context.Context, map[string]interface{}) {
+	if task.Message == nil {
+		return ctx, task.Input
+	}
+	input := task.Message.Input()
+	maps.Copy(input, task.Input)
+	return context.WithValue(ctx, messageKey{}, task.Message), input
+}
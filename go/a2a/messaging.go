@@ -0,0 +1,446 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Servers answer the A2A specification's message/send and message/stream
+// alongside the a2a/task methods, so SDKs in other languages can call
+// them. A message becomes a task: its taskId and contextId are the task's
+// ID and correlation ID, the handler receives it as by WithMessage, and the
+// action is taken from the "action" metadata of the message or the params,
+// defaulting to the agent's first capability. Each output map comes back
+// as a data artifact named "output".
+
+// Metadata keys the message methods read the legacy task fields from
+const (
+	MetadataAction = "action"
+	MetadataSender = "sender"
+)
+
+// MessageSendParams are the params of message/send and message/stream
+type MessageSendParams struct {
+	Message       Message                   `json:"message"`
+	Configuration *MessageSendConfiguration `json:"configuration,omitempty"`
+	Metadata      map[string]interface{}    `json:"metadata,omitempty"`
+}
+
+// MessageSendConfiguration tunes how message/send answers
+type MessageSendConfiguration struct {
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	// Blocking waits for the task to finish; nil means true. Otherwise the
+	// submitted task is returned at once, to be polled with a2a/task/get.
+	Blocking *bool `json:"blocking,omitempty"`
+	// PushNotificationConfig is set on the task as by
+	// tasks/pushNotificationConfig/set
+	PushNotificationConfig *PushNotificationConfig `json:"pushNotificationConfig,omitempty"`
+}
+
+// Task is the A2A specification's view of a task, returned by message/send
+type Task struct {
+	ID        string                 `json:"id"`
+	ContextID string                 `json:"contextId"`
+	Status    TaskStatus             `json:"status"`
+	Artifacts []Artifact             `json:"artifacts,omitempty"`
+	History   []Message              `json:"history,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Kind      string                 `json:"kind"` // always "task"
+}
+
+// TaskStatusUpdateEvent is streamed by message/stream when a task changes
+// state; the last one has Final set
+type TaskStatusUpdateEvent struct {
+	TaskID    string     `json:"taskId"`
+	ContextID string     `json:"contextId"`
+	Kind      string     `json:"kind"` // always "status-update"
+	Status    TaskStatus `json:"status"`
+	Final     bool       `json:"final"`
+}
+
+// TaskArtifactUpdateEvent is streamed by message/stream when a task adds
+// an artifact or a chunk of one
+type TaskArtifactUpdateEvent struct {
+	TaskID    string   `json:"taskId"`
+	ContextID string   `json:"contextId"`
+	Kind      string   `json:"kind"` // always "artifact-update"
+	Artifact  Artifact `json:"artifact"`
+	Append    bool     `json:"append,omitempty"`
+	LastChunk bool     `json:"lastChunk,omitempty"`
+}
+
+// StreamEvent is one event of a message/stream response. Exactly one
+// field is set.
+type StreamEvent struct {
+	Task           *Task
+	Message        *Message
+	StatusUpdate   *TaskStatusUpdateEvent
+	ArtifactUpdate *TaskArtifactUpdateEvent
+}
+
+// Task returns the result in the A2A specification's shape, with msg, if
+// any, as its history
+func (r *TaskResult) Task(msg *Message) Task {
+	task := Task{
+		ID:        r.TaskID,
+		ContextID: r.CorrelationID,
+		Status:    r.TaskStatus(),
+		Artifacts: r.Artifacts,
+		Metadata:  r.Metadata,
+		Kind:      "task",
+	}
+	if len(r.Output) > 0 {
+		task.Artifacts = append(task.Artifacts, outputArtifact(r.Output, len(task.Artifacts)))
+	}
+	if r.Status == TaskStateFailed && task.Status.Message == nil && r.Error != nil {
+		failure := NewMessage(RoleAgent, TextPart{Text: r.Error.Message})
+		task.Status.Message = &failure
+	}
+	if msg != nil {
+		task.History = []Message{*msg}
+	}
+	return task
+}
+
+// outputArtifact carries a legacy output map
+func outputArtifact(output map[string]interface{}, index int) Artifact {
+	return Artifact{ArtifactID: "output", Name: "output", Parts: []Part{DataPart{Data: output}}, Index: index, LastChunk: true}
+}
+
+// messageTask turns message/send params into the task they start
+func (s *A2AServer) messageTask(agentID string, params json.RawMessage) (TaskParams, MessageSendParams, *JSONRPCError) {
+	send, rpcErr := decodeParams[MessageSendParams](s, params)
+	if rpcErr != nil {
+		return TaskParams{}, send, rpcErr
+	}
+	if len(send.Message.Parts) == 0 {
+		return TaskParams{}, send, &JSONRPCError{Code: CodeInvalidParams, Message: "Message has no parts"}
+	}
+
+	task := TaskParamsFromMessage(send)
+	task.AgentID = agentID
+	if task.TaskID == "" {
+		task.TaskID = generateID()
+	}
+	if task.Action == "" && !s.isPaused(task.TaskID) {
+		info := s.info()
+		if agentID != "" {
+			info, _ = s.agentInfo(agentID)
+		}
+		if len(info.Capabilities) > 0 {
+			task.Action = info.Capabilities[0]
+		}
+	}
+	return task, send, nil
+}
+
+// metadataString returns the first string value of key in the metadata
+func metadataString(key string, metadata ...map[string]interface{}) string {
+	for _, m := range metadata {
+		if value, ok := m[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// sendMessage handles message/send
+func (s *A2AServer) sendMessage(ctx context.Context, agentID string, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	task, send, rpcErr := s.messageTask(agentID, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if send.Configuration != nil && send.Configuration.PushNotificationConfig != nil {
+		// Set once the task is admitted, before it starts, so no
+		// notification can be missed
+		if rpcErr := s.checkPushConfig(*send.Configuration.PushNotificationConfig); rpcErr != nil {
+			return nil, rpcErr
+		}
+		task.pushConfig = send.Configuration.PushNotificationConfig
+	}
+
+	var result *TaskResult
+	if send.Configuration != nil && send.Configuration.Blocking != nil && !*send.Configuration.Blocking {
+		result, rpcErr = s.enqueueTask(ctx, task)
+	} else {
+		result, rpcErr = s.runTask(ctx, task)
+	}
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if result.CorrelationID == "" {
+		result.CorrelationID = task.CorrelationID
+	}
+	return s.marshalResult(result.Task(task.Message))
+}
+
+// streamMessage handles message/stream, answering with Server-Sent Events
+// each carrying a JSON-RPC response: the submitted Task, an artifact-update
+// per artifact chunk, then a final status-update
+func (s *A2AServer) streamMessage(ctx context.Context, w http.ResponseWriter, r *http.Request, agentID string, req serverRequest) {
+	task, _, rpcErr := s.messageTask(agentID, req.Params)
+	flusher, ok := w.(http.Flusher)
+	if rpcErr == nil && !ok {
+		rpcErr = &JSONRPCError{Code: CodeUnsupportedOperation, Message: "Streaming not supported", Data: rpcData(UnsupportedOperationData{Operation: "message/stream"})}
+	}
+	if rpcErr != nil {
+		s.writeJSON(w, r, JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var mu sync.Mutex
+	send := func(result interface{}, rpcErr *JSONRPCError) {
+		resp := JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+		if rpcErr == nil {
+			resp.Result, _ = s.codec.Marshal(result)
+		}
+		body, _ := s.codec.Marshal(resp)
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "data: %s\n\n", body)
+		flusher.Flush()
+	}
+
+	if rpcErr := s.streamTask(ctx, task, func(event StreamEvent) { send(event.Result(), nil) }); rpcErr != nil {
+		send(nil, rpcErr)
+	}
+}
+
+// streamTask runs a task, sending the submitted Task, an artifact-update
+// event per artifact chunk and output, and a final status-update event.
+// Errors refusing the task are returned after the submitted Task is sent.
+func (s *A2AServer) streamTask(ctx context.Context, task TaskParams, send func(StreamEvent)) *JSONRPCError {
+	ctx = correlate(ctx, &task)
+	submitted := TaskResult{TaskID: task.TaskID, Status: TaskStateSubmitted, UpdatedAt: time.Now().UTC(), CorrelationID: task.CorrelationID, Metadata: task.Metadata}
+	first := submitted.Task(task.Message)
+	send(StreamEvent{Task: &first})
+
+	ctx, artifacts := withArtifacts(ctx)
+	artifacts.onChange = func(_ []Artifact, chunk Artifact) {
+		send(StreamEvent{ArtifactUpdate: &TaskArtifactUpdateEvent{
+			TaskID:    task.TaskID,
+			ContextID: task.CorrelationID,
+			Kind:      "artifact-update",
+			Artifact:  chunk,
+			Append:    chunk.Append,
+			LastChunk: chunk.LastChunk,
+		}})
+	}
+
+	result, rpcErr := s.runTask(ctx, task)
+	if rpcErr != nil {
+		return rpcErr
+	}
+	if len(result.Output) > 0 {
+		send(StreamEvent{ArtifactUpdate: &TaskArtifactUpdateEvent{
+			TaskID:    task.TaskID,
+			ContextID: task.CorrelationID,
+			Kind:      "artifact-update",
+			Artifact:  outputArtifact(result.Output, len(result.Artifacts)),
+			LastChunk: true,
+		}})
+	}
+	status := result.Task(nil).Status
+	send(StreamEvent{StatusUpdate: &TaskStatusUpdateEvent{TaskID: task.TaskID, ContextID: task.CorrelationID, Kind: "status-update", Status: status, Final: true}})
+	return nil
+}
+
+// Result returns the event's one set field, the result message/stream
+// sends for it
+func (e StreamEvent) Result() interface{} {
+	switch {
+	case e.Task != nil:
+		return e.Task
+	case e.Message != nil:
+		return e.Message
+	case e.StatusUpdate != nil:
+		return e.StatusUpdate
+	}
+	return e.ArtifactUpdate
+}
+
+// SendMessage sends msg to another agent with message/send and returns the
+// task it ran. Peers that answer with a message instead of a task are
+// reported as a completed task whose status carries it. Peers running
+// older versions of this SDK are sent an a2a/task carrying msg instead.
+func (a *A2AAgent) SendMessage(targetAgentID string, msg Message, directoryURL string) (*Task, error) {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
+		return nil, err
+	}
+	params := a.messageParams(msg)
+
+	result, err := a.doRequest(context.Background(), agentInfo.Endpoint, "message/send", params)
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == CodeMethodNotFound {
+		return a.sendMessageAsTask(targetAgentID, params.Message, directoryURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("message failed: %w", err)
+	}
+
+	event, err := a.decodeEvent(result)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case event.Task != nil:
+		return event.Task, nil
+	case event.Message != nil:
+		return &Task{
+			ID:        event.Message.TaskID,
+			ContextID: event.Message.ContextID,
+			Status:    TaskStatus{State: TaskStateCompleted, Message: event.Message},
+			Kind:      "task",
+		}, nil
+	}
+	return nil, fmt.Errorf("message failed: unexpected %s result", result)
+}
+
+// StreamMessage sends msg with message/stream and calls handle with each
+// event until the task finishes or handle returns an error, which
+// StreamMessage then returns. Peers that don't stream are sent msg with
+// SendMessage, and handle receives the finished task. Agents set to
+// LongPoll follow the task with tasks/poll instead.
+func (a *A2AAgent) StreamMessage(targetAgentID string, msg Message, directoryURL string, handle func(StreamEvent) error) error {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
+		return err
+	}
+	params := a.messageParams(msg)
+	if a.LongPoll {
+		return a.pollMessage(agentInfo.Endpoint, params, handle)
+	}
+
+	req, err := a.request("message/stream", params)
+	if err != nil {
+		return err
+	}
+	ctx, err := a.identify(context.Background(), agentInfo.Endpoint)
+	if err != nil {
+		return err
+	}
+	var fallback bool
+	var handleErr error // returned as it is
+	err = a.transport(agentInfo.Endpoint).Stream(ctx, agentInfo.Endpoint, req, func(resp *JSONRPCResponse) error {
+		if resp.Error != nil {
+			fallback = resp.Error.Code == CodeMethodNotFound
+			return resp.Error
+		}
+		event, err := a.decodeEvent(resp.Result)
+		if err != nil {
+			return err
+		}
+		if handleErr = handle(event); handleErr != nil {
+			return handleErr
+		}
+		if event.StatusUpdate != nil && event.StatusUpdate.Final {
+			return errStreamEnd
+		}
+		return nil
+	})
+	switch {
+	case handleErr != nil:
+		return handleErr
+	case fallback:
+		task, err := a.SendMessage(targetAgentID, params.Message, directoryURL)
+		if err != nil {
+			return err
+		}
+		return handle(StreamEvent{Task: task})
+	case err != nil && err != errStreamEnd:
+		return fmt.Errorf("stream failed: %w", err)
+	}
+	return nil
+}
+
+// messageParams fills in what msg leaves out for sending
+func (a *A2AAgent) messageParams(msg Message) MessageSendParams {
+	if msg.Role == "" {
+		msg.Role = RoleUser
+	}
+	if msg.MessageID == "" {
+		msg.MessageID = generateID()
+	}
+	msg.Kind = "message"
+	if metadataString(MetadataSender, msg.Metadata) == "" && a.AgentID != "" {
+		metadata := map[string]interface{}{MetadataSender: a.AgentID}
+		for key, value := range msg.Metadata {
+			metadata[key] = value
+		}
+		msg.Metadata = metadata
+	}
+	send := MessageSendParams{Message: msg}
+	if a.parent != nil {
+		var sub TaskParams
+		subTaskOf(&sub, *a.parent)
+		if send.Message.ContextID == "" {
+			send.Message.ContextID = sub.CorrelationID
+		}
+		send.Metadata = make(map[string]interface{})
+		traceMetadata(send.Metadata, sub)
+	}
+	return send
+}
+
+// sendMessageAsTask is SendMessage for peers without message/send
+func (a *A2AAgent) sendMessageAsTask(targetAgentID string, msg Message, directoryURL string) (*Task, error) {
+	action := metadataString(MetadataAction, msg.Metadata)
+	result, err := a.sendTask("a2a/task", targetAgentID, action, nil, directoryURL, []TaskOption{
+		WithMessage(msg),
+		WithCorrelationID(msg.ContextID),
+		func(p *TaskParams) {
+			if msg.TaskID != "" {
+				p.TaskID = msg.TaskID
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	task := result.Task(&msg)
+	return &task, nil
+}
+
+// decodeEvent decodes a message/send or message/stream result by its kind
+func (a *A2AAgent) decodeEvent(result json.RawMessage) (StreamEvent, error) {
+	return decodeEvent(a.codec(), result)
+}
+
+func decodeEvent(codec Codec, result json.RawMessage) (StreamEvent, error) {
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := codec.Unmarshal(result, &kind); err != nil {
+		return StreamEvent{}, err
+	}
+	var event StreamEvent
+	var target interface{}
+	switch kind.Kind {
+	case "task":
+		event.Task = &Task{}
+		target = event.Task
+	case "message":
+		event.Message = &Message{}
+		target = event.Message
+	case "status-update":
+		event.StatusUpdate = &TaskStatusUpdateEvent{}
+		target = event.StatusUpdate
+	case "artifact-update":
+		event.ArtifactUpdate = &TaskArtifactUpdateEvent{}
+		target = event.ArtifactUpdate
+	default:
+		return StreamEvent{}, fmt.Errorf("unknown event kind %q", kind.Kind)
+	}
+	return event, codec.Unmarshal(result, target)
+}
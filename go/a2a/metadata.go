@@ -0,0 +1,77 @@
+package a2a
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// WithTaskMetadata sends custom fields with the task, such as a tenant,
+// billing code or trace baggage. Handlers read them with TaskMetadata and
+// they are returned in the task's result. Later calls add to earlier ones.
+func WithTaskMetadata(metadata map[string]interface{}) TaskOption {
+	return func(p *TaskParams) {
+		if p.Metadata == nil {
+			p.Metadata = make(map[string]interface{}, len(metadata))
+		}
+		maps.Copy(p.Metadata, metadata)
+	}
+}
+
+type metadataKey struct{}
+
+// taskMetadata holds the metadata of a running task: what it was sent
+// with and what its handler set
+type taskMetadata struct {
+	mu     sync.Mutex
+	sent   map[string]interface{}
+	result map[string]interface{}
+}
+
+// TaskMetadata returns the metadata sent with the task a handler is
+// running, if any. It must not be modified; use SetResultMetadata.
+func TaskMetadata(ctx context.Context) map[string]interface{} {
+	metadata, ok := ctx.Value(metadataKey{}).(*taskMetadata)
+	if !ok {
+		return nil
+	}
+	return metadata.sent
+}
+
+// SetResultMetadata sets a metadata field of the result of the task a
+// handler is running. The result carries the metadata the task was sent
+// with, overridden by fields set here.
+func SetResultMetadata(ctx context.Context, key string, value interface{}) error {
+	metadata, ok := ctx.Value(metadataKey{}).(*taskMetadata)
+	if !ok {
+		return errNoTask
+	}
+	metadata.mu.Lock()
+	defer metadata.mu.Unlock()
+	if metadata.result == nil {
+		metadata.result = make(map[string]interface{})
+	}
+	metadata.result[key] = value
+	return nil
+}
+
+// withMetadata makes a task's metadata available to its handler
+func withMetadata(ctx context.Context, task TaskParams) (context.Context, *taskMetadata) {
+	metadata := &taskMetadata{sent: task.Metadata}
+	return context.WithValue(ctx, metadataKey{}, metadata), metadata
+}
+
+// merged returns the metadata a task's result carries
+func (m *taskMetadata) merged() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 && len(m.result) == 0 {
+		return nil
+	}
+	merged := maps.Clone(m.sent)
+	if merged == nil {
+		merged = make(map[string]interface{}, len(m.result))
+	}
+	maps.Copy(merged, m.result)
+	return merged
+}
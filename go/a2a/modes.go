@@ -0,0 +1,99 @@
+package a2a
+
+import (
+	"mime"
+	"slices"
+	"strings"
+)
+
+// Media types of message parts without their own
+const (
+	ModeText = "text/plain"       // text parts
+	ModeJSON = "application/json" // data parts and map-based task input
+)
+
+// WithInputMode declares the media type of the task's input, checked
+// against the InputModes of the skill handling it
+func WithInputMode(mode string) TaskOption {
+	return func(p *TaskParams) {
+		p.InputMode = mode
+	}
+}
+
+// WithAcceptedOutputModes lists the media types the sender can use as
+// output. The task is refused if the skill handling it declares
+// OutputModes and none of them is accepted.
+func WithAcceptedOutputModes(modes ...string) TaskOption {
+	return func(p *TaskParams) {
+		p.AcceptedOutputModes = modes
+	}
+}
+
+// checkModes refuses tasks whose input or accepted output modes the
+// skill named by their action does not support. Skills declaring no
+// modes accept any.
+func (s *A2AServer) checkModes(task TaskParams) *JSONRPCError {
+	skill, ok := s.card.skills[task.Action]
+	if !ok {
+		return nil
+	}
+	if len(skill.InputModes) > 0 {
+		for _, mode := range task.inputModes() {
+			if !slices.ContainsFunc(skill.InputModes, func(supported string) bool { return modeMatches(supported, mode) }) {
+				return &JSONRPCError{Code: CodeContentTypeNotSupported, Message: "Unsupported input mode", Data: rpcData(ContentTypeErrorData{Mode: mode, Supported: skill.InputModes})}
+			}
+		}
+	}
+	if len(skill.OutputModes) > 0 && len(task.AcceptedOutputModes) > 0 {
+		for _, mode := range skill.OutputModes {
+			if slices.ContainsFunc(task.AcceptedOutputModes, func(accepted string) bool { return modeMatches(accepted, mode) }) {
+				return nil
+			}
+		}
+		return &JSONRPCError{Code: CodeContentTypeNotSupported, Message: "No accepted output mode", Data: rpcData(ContentTypeErrorData{Supported: skill.OutputModes})}
+	}
+	return nil
+}
+
+// inputModes returns the media types a task's input comes in: InputMode
+// if set, and the type of each part of its message
+func (p TaskParams) inputModes() []string {
+	var modes []string
+	if p.InputMode != "" {
+		modes = append(modes, p.InputMode)
+	}
+	if p.Message != nil {
+		for _, part := range p.Message.Parts {
+			switch part := part.(type) {
+			case TextPart:
+				modes = append(modes, ModeText)
+			case DataPart:
+				modes = append(modes, ModeJSON)
+			case FilePart:
+				if part.File.MimeType != "" {
+					modes = append(modes, part.File.MimeType)
+				}
+			}
+		}
+	}
+	return modes
+}
+
+// modeMatches reports whether the media type mode is covered by pattern,
+// which may be a wildcard such as "image/*" or "*/*". Parameters such as
+// charset are ignored.
+func modeMatches(pattern, mode string) bool {
+	pattern, mode = mediaType(pattern), mediaType(mode)
+	if pattern == "*/*" || pattern == mode {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	return ok && strings.HasPrefix(mode, prefix+"/")
+}
+
+func mediaType(mode string) string {
+	if parsed, _, err := mime.ParseMediaType(mode); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(mode))
+}
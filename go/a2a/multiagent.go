@@ -0,0 +1,150 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// agentPathPrefix is the path under which hosted agents are served
+const agentPathPrefix = "/agents/"
+
+// HostedAgent is an additional agent identity served by an A2AServer. Each
+// hosted agent has its own capabilities and task handler and is reachable
+// at <server endpoint>/agents/<agentId>, or at the server root when the
+// request's agentId names it.
+type HostedAgent struct {
+	AgentID      string
+	Name         string
+	Capabilities []string
+	Endpoint     string
+	Description  string
+	Tags         []string
+	Metadata     map[string]string
+	Schemas      map[string]CapabilitySchema // by capability
+	taskFunc     TaskFunc
+}
+
+// HandleTask registers the hosted agent's task handler
+func (h *HostedAgent) HandleTask(handler TaskHandler) {
+	h.taskFunc = legacyTaskFunc(handler)
+}
+
+// HandleTaskFunc registers a hosted agent task handler that can return an error
+func (h *HostedAgent) HandleTaskFunc(handler TaskFunc) {
+	h.taskFunc = handler
+}
+
+// Info returns the hosted agent's registration info
+func (h *HostedAgent) Info() AgentInfo {
+	return AgentInfo{
+		AgentID:      h.AgentID,
+		Name:         h.Name,
+		Capabilities: h.Capabilities,
+		Endpoint:     h.Endpoint,
+		Description:  h.Description,
+		Tags:         h.Tags,
+		Metadata:     h.Metadata,
+		Schemas:      h.Schemas,
+	}
+}
+
+// HostAgent adds an agent identity to the server. Hosting an agent ID a
+// second time replaces the previous identity.
+func (s *A2AServer) HostAgent(agentID, name string, capabilities []string) *HostedAgent {
+	hosted := &HostedAgent{
+		AgentID:      agentID,
+		Name:         name,
+		Capabilities: capabilities,
+		Endpoint:     strings.TrimSuffix(s.Endpoint, "/") + AgentPath(agentID),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hosted[agentID] = hosted
+	return hosted
+}
+
+// info returns the server's own identity
+func (s *A2AServer) info() AgentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return AgentInfo{
+		AgentID:      s.AgentID,
+		Name:         s.Name,
+		Capabilities: s.Capabilities,
+		Endpoint:     s.Endpoint,
+		Description:  s.Description,
+		Tags:         s.Tags,
+		Metadata:     s.Metadata,
+		Region:       s.Region,
+		Zone:         s.Zone,
+		Schemas:      s.Schemas,
+	}
+}
+
+// Agents returns the server's own identity followed by all hosted agents
+func (s *A2AServer) Agents() []AgentInfo {
+	agents := []AgentInfo{s.info()}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.hosted))
+	for id := range s.hosted {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		agents = append(agents, s.hosted[id].Info())
+	}
+	return agents
+}
+
+// agentInfo returns the identity registered under agentID
+func (s *A2AServer) agentInfo(agentID string) (AgentInfo, bool) {
+	for _, agent := range s.Agents() {
+		if agent.AgentID == agentID {
+			return agent, true
+		}
+	}
+	return AgentInfo{}, false
+}
+
+// taskFuncFor returns the handler of the agent a task is addressed to.
+// Tasks that name no hosted agent go to the server's own handler, so
+// clients addressing the server by another ID keep working.
+func (s *A2AServer) taskFuncFor(agentID string) (TaskFunc, error) {
+	s.mu.RLock()
+	hosted, ok := s.hosted[agentID]
+	s.mu.RUnlock()
+
+	taskFunc := s.taskFunc
+	if ok {
+		taskFunc = hosted.taskFunc
+	}
+	if taskFunc == nil {
+		return nil, errNoHandler
+	}
+	return taskFunc, nil
+}
+
+// AgentPath returns the path under the server's endpoint at which a hosted
+// agent is served
+func AgentPath(agentID string) string {
+	return agentPathPrefix + agentID
+}
+
+// pathAgent returns the agent ID addressed by the request path, if any
+func pathAgent(r *http.Request) string {
+	if !strings.HasPrefix(r.URL.Path, agentPathPrefix) {
+		return ""
+	}
+	return strings.Trim(strings.TrimPrefix(r.URL.Path, agentPathPrefix), "/")
+}
+
+func legacyTaskFunc(handler TaskHandler) TaskFunc {
+	return func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		return handler(action, input, sender), nil
+	}
+}
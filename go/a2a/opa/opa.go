@@ -0,0 +1,173 @@
+// Package opa authorizes tasks with Open Policy Agent policies, so security
+// teams can manage who may call which actions in Rego, outside the agents'
+// code. Each task becomes the policy's input document:
+//
+//	{
+//	  "principal": "planner",
+//	  "agentId": "",
+//	  "action": "summarize",
+//	  "input": {"text": "..."},
+//	  "metadata": {"tenant": "acme"},
+//	  "taskId": "..."
+//	}
+//
+// and the policy decides with a boolean, or an object with allow and an
+// optional reason:
+//
+//	package a2a.authz
+//
+//	default allow := false
+//
+//	allow if {
+//	    input.principal == "planner"
+//	    input.action in {"summarize", "search"}
+//	}
+//
+// A Client queries OPA running as a server or sidecar through its Data
+// API. The a2a/opa/rego module evaluates policies embedded in the agent's
+// process instead. Either way, tasks are refused when the policy does not
+// allow them, its result is undefined, or OPA cannot be reached.
+//
+//	server := a2a.NewServerWithOptions("docs",
+//	    a2a.WithAuthorizer(opa.NewAuthorizer(&opa.Client{URL: "http://localhost:8181/v1/data/a2a/authz"})),
+//	)
+package opa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// ErrUndefined is returned when a policy has no result for a task, e.g.
+// because the Data API path names no rule
+var ErrUndefined = errors.New("opa: policy result is undefined")
+
+// Input is the input document policies are evaluated with
+type Input struct {
+	Principal string                 `json:"principal"`
+	AgentID   string                 `json:"agentId"`
+	Action    string                 `json:"action"`
+	Input     map[string]interface{} `json:"input"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	TaskID    string                 `json:"taskId"`
+}
+
+// Decision is a policy's result
+type Decision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"` // why the task is refused, if the policy says
+}
+
+// Evaluator evaluates a policy with an input document
+type Evaluator interface {
+	Evaluate(ctx context.Context, input Input) (Decision, error)
+}
+
+// NewAuthorizer returns an a2a.Authorizer refusing the tasks evaluator
+// does not allow, and every task while it fails
+func NewAuthorizer(evaluator Evaluator) a2a.Authorizer {
+	return a2a.AuthorizerFunc(func(ctx context.Context, req a2a.AuthorizationRequest) error {
+		decision, err := evaluator.Evaluate(ctx, Input{
+			Principal: req.Principal,
+			AgentID:   req.AgentID,
+			Action:    req.Action,
+			Input:     req.Input,
+			Metadata:  req.Metadata,
+			TaskID:    req.TaskID,
+		})
+		switch {
+		case err != nil:
+			return err
+		case !decision.Allow && decision.Reason != "":
+			return errors.New(decision.Reason)
+		case !decision.Allow:
+			return errors.New("denied by policy")
+		}
+		return nil
+	})
+}
+
+// DecisionOf reads a policy's result, a boolean or an object with an allow
+// boolean and an optional reason string, as decoded from JSON
+func DecisionOf(result interface{}) (Decision, error) {
+	switch result := result.(type) {
+	case nil:
+		return Decision{}, ErrUndefined
+	case bool:
+		return Decision{Allow: result}, nil
+	case map[string]interface{}:
+		allow, ok := result["allow"].(bool)
+		if !ok {
+			return Decision{}, errors.New("opa: policy result has no allow boolean")
+		}
+		reason, _ := result["reason"].(string)
+		return Decision{Allow: allow, Reason: reason}, nil
+	}
+	return Decision{}, fmt.Errorf("opa: policy result is a %T, not a boolean or object", result)
+}
+
+// Client is an Evaluator querying an OPA server through its Data API
+type Client struct {
+	// URL is the Data API URL of the rule or package deciding, e.g.
+	// http://localhost:8181/v1/data/a2a/authz
+	URL string
+	// Token is sent as a bearer token, for OPA servers requiring one
+	Token string
+	// HTTPClient sends the queries; nil means http.DefaultClient
+	HTTPClient *http.Client
+	// Timeout bounds each query; zero means 2s
+	Timeout time.Duration
+}
+
+var _ Evaluator = (*Client)(nil)
+
+// Evaluate queries the policy at URL with input
+func (c *Client) Evaluate(ctx context.Context, input Input) (Decision, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]Input{"input": input})
+	if err != nil {
+		return Decision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("opa: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Decision{}, fmt.Errorf("opa: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var result struct {
+		Result interface{} `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return Decision{}, fmt.Errorf("opa: decoding result: %w", err)
+	}
+	return DecisionOf(result.Result)
+}
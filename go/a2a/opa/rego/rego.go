@@ -0,0 +1,68 @@
+// Package a2arego evaluates the Rego policies authorizing tasks inside the
+// agent's process, with OPA embedded as a library, so no OPA server has to
+// run beside it. Policies are written as for an opa.Client.
+//
+//	evaluator, err := a2arego.New(ctx, "data.a2a.authz", map[string]string{"authz.rego": policy})
+//	server := a2a.NewServerWithOptions("docs", a2a.WithAuthorizer(opa.NewAuthorizer(evaluator)))
+package a2arego
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/opa"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// Evaluator is an opa.Evaluator evaluating a query prepared once
+type Evaluator struct {
+	query rego.PreparedEvalQuery
+}
+
+var _ opa.Evaluator = (*Evaluator)(nil)
+
+// New compiles modules, Rego source by file name, and prepares query, e.g.
+// "data.a2a.authz" or "data.a2a.authz.allow", for evaluating them
+func New(ctx context.Context, query string, modules map[string]string) (*Evaluator, error) {
+	options := []func(*rego.Rego){rego.Query(query)}
+	for name, source := range modules {
+		options = append(options, rego.Module(name, source))
+	}
+	prepared, err := rego.New(options...).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2arego: %w", err)
+	}
+	return &Evaluator{query: prepared}, nil
+}
+
+// Load is New with the policies in the Rego files and directories at
+// paths
+func Load(ctx context.Context, query string, paths ...string) (*Evaluator, error) {
+	prepared, err := rego.New(rego.Query(query), rego.Load(paths, nil)).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2arego: %w", err)
+	}
+	return &Evaluator{query: prepared}, nil
+}
+
+// Evaluate evaluates the query with input
+func (e *Evaluator) Evaluate(ctx context.Context, input opa.Input) (opa.Decision, error) {
+	// Policies see the input as they would over the Data API
+	data, err := json.Marshal(input)
+	if err != nil {
+		return opa.Decision{}, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return opa.Decision{}, err
+	}
+	results, err := e.query.Eval(ctx, rego.EvalInput(document))
+	if err != nil {
+		return opa.Decision{}, fmt.Errorf("a2arego: %w", err)
+	}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return opa.Decision{}, opa.ErrUndefined
+	}
+	return opa.DecisionOf(results[0].Expressions[0].Value)
+}
@@ -0,0 +1,385 @@
+package a2a
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// OpenAPIPath is where servers publish an OpenAPI document describing
+// their JSON-RPC endpoint
+const OpenAPIPath = "/openapi.json"
+
+// OpenAPIVersion is the OpenAPI version of the documents servers publish.
+// 3.1 schemas are JSON Schema, so capability schemas are used as they are.
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIDocument is an OpenAPI document describing an agent as an API,
+// for gateways and client generators
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Servers    []OpenAPIServer            `json:"servers,omitempty"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+	Security   []map[string][]string      `json:"security,omitempty"`
+}
+
+// OpenAPIInfo describes the API
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is a URL the API is served at
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIPathItem holds the operations on one path
+type OpenAPIPathItem struct {
+	Get    *OpenAPIOperation `json:"get,omitempty"`
+	Post   *OpenAPIOperation `json:"post,omitempty"`
+	Delete *OpenAPIOperation `json:"delete,omitempty"`
+}
+
+// OpenAPIOperation is one operation of the API
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter is a path, query or header parameter of an operation
+type OpenAPIParameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+// OpenAPIRequestBody is the body an operation takes
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is a response an operation may give
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType gives the schema of a body in one media type
+type OpenAPIMediaType struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+// OpenAPIComponents holds the schemas and security schemes the document
+// refers to
+type OpenAPIComponents struct {
+	Schemas         map[string]json.RawMessage `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme  `json:"securitySchemes,omitempty"`
+}
+
+// OpenAPI builds the OpenAPI document for the server's own identity
+func (s *A2AServer) OpenAPI() OpenAPIDocument {
+	return s.openAPI(s.info(), s.Description, true)
+}
+
+// openAPI describes the JSON-RPC endpoint of the agent info identifies:
+// a request and response schema per capability, calling a2a/task or
+// a2a/task/submit with the capability's input and output schemas, plus
+// every other method in generic form. root adds the paths served only at
+// the server's root.
+func (s *A2AServer) openAPI(info AgentInfo, description string, root bool) OpenAPIDocument {
+	card := s.agentCard(info, description)
+	version := card.Version
+	if version == "" {
+		version = ProtocolVersion
+	}
+	doc := OpenAPIDocument{
+		OpenAPI:  OpenAPIVersion,
+		Info:     OpenAPIInfo{Title: card.Name, Description: description, Version: version},
+		Paths:    make(map[string]OpenAPIPathItem),
+		Security: card.Security,
+		Components: OpenAPIComponents{
+			Schemas:         make(map[string]json.RawMessage),
+			SecuritySchemes: card.SecuritySchemes,
+		},
+	}
+	if info.Endpoint != "" {
+		doc.Servers = []OpenAPIServer{{URL: info.Endpoint}}
+	}
+
+	schemas := doc.Components.Schemas
+	action := map[string]interface{}{"type": "string"}
+	if len(info.Capabilities) > 0 {
+		action["enum"] = info.Capabilities
+	}
+	schemas["TaskParams"] = schemaJSON(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"action", "input"},
+		"properties": map[string]interface{}{
+			"taskId":         map[string]interface{}{"type": "string", "description": "Generated by the server if empty"},
+			"agentId":        map[string]interface{}{"type": "string"},
+			"action":         action,
+			"sender":         map[string]interface{}{"type": "string"},
+			"input":          map[string]interface{}{"type": "object"},
+			"priority":       map[string]interface{}{"type": "integer"},
+			"correlationId":  map[string]interface{}{"type": "string"},
+			"idempotencyKey": map[string]interface{}{"type": "string"},
+			"metadata":       map[string]interface{}{"type": "object"},
+		},
+	})
+	schemas["TaskResult"] = schemaJSON(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"taskId", "status"},
+		"properties": map[string]interface{}{
+			"taskId": map[string]interface{}{"type": "string"},
+			"status": map[string]interface{}{"type": "string", "enum": []TaskState{
+				TaskStateSubmitted, TaskStateWorking, TaskStateInputRequired, TaskStateCompleted, TaskStateCanceled, TaskStateFailed,
+			}},
+			"updatedAt":     map[string]interface{}{"type": "string", "format": "date-time"},
+			"output":        map[string]interface{}{"type": "object"},
+			"attempts":      map[string]interface{}{"type": "integer"},
+			"error":         ref("JSONRPCError"),
+			"correlationId": map[string]interface{}{"type": "string"},
+			"metadata":      map[string]interface{}{"type": "object"},
+		},
+	})
+	schemas["JSONRPCError"] = schemaJSON(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"code", "message"},
+		"properties": map[string]interface{}{
+			"code":    map[string]interface{}{"type": "integer"},
+			"message": map[string]interface{}{"type": "string"},
+			"data":    map[string]interface{}{},
+		},
+	})
+	schemas["JSONRPCRequest"] = schemaJSON(rpcRequestSchema(map[string]interface{}{"type": "string", "enum": openAPIMethods}, map[string]interface{}{"type": "object"}))
+	schemas["JSONRPCResponse"] = schemaJSON(rpcResponseSchema(map[string]interface{}{}))
+
+	var tasks, results []interface{}
+	requests := make([]interface{}, 0, len(info.Capabilities)+1)
+	responses := make([]interface{}, 0, len(info.Capabilities)+1)
+	for _, capability := range info.Capabilities {
+		name := schemaName(capability)
+		input, output := json.RawMessage(`{"type":"object"}`), json.RawMessage(`{"type":"object"}`)
+		if schema, ok := info.Schemas[capability]; ok {
+			if len(schema.Input) > 0 {
+				input = schema.Input
+			}
+			if len(schema.Output) > 0 {
+				output = schema.Output
+			}
+		}
+		schemas[name+"Input"] = input
+		schemas[name+"Output"] = output
+
+		task := map[string]interface{}{
+			"title": capability,
+			"allOf": []interface{}{ref("TaskParams"), map[string]interface{}{
+				"properties": map[string]interface{}{
+					"action": map[string]interface{}{"const": capability},
+					"input":  ref(name + "Input"),
+				},
+			}},
+		}
+		if skill, ok := card.Skill(capability); ok && skill.Description != "" {
+			task["description"] = skill.Description
+		}
+		schemas[name+"Task"] = schemaJSON(task)
+		schemas[name+"Result"] = schemaJSON(map[string]interface{}{
+			"allOf": []interface{}{ref("TaskResult"), map[string]interface{}{
+				"properties": map[string]interface{}{"output": ref(name + "Output")},
+			}},
+		})
+		schemas[name+"Request"] = schemaJSON(rpcRequestSchema(map[string]interface{}{"type": "string", "enum": []string{"a2a/task", "a2a/task/submit"}}, ref(name+"Task")))
+		schemas[name+"Response"] = schemaJSON(rpcResponseSchema(ref(name + "Result")))
+		tasks = append(tasks, ref(name+"Task"))
+		results = append(results, ref(name+"Result"))
+		requests = append(requests, ref(name+"Request"))
+		responses = append(responses, ref(name+"Response"))
+	}
+	requests = append(requests, ref("JSONRPCRequest"))
+	responses = append(responses, ref("JSONRPCResponse"))
+
+	doc.Paths["/"] = OpenAPIPathItem{Post: &OpenAPIOperation{
+		OperationID: "jsonrpc",
+		Summary:     "Call a JSON-RPC method",
+		Description: "Runs a task with a2a/task, queues one with a2a/task/submit, or calls any other A2A method. Responses to message/stream are a text/event-stream of JSON-RPC responses.",
+		RequestBody: &OpenAPIRequestBody{Required: true, Content: jsonContent(schemaJSON(map[string]interface{}{"anyOf": requests}))},
+		Responses: map[string]OpenAPIResponse{
+			"200": {
+				Description: "A JSON-RPC response, whose error is set if the call failed",
+				Content:     jsonContent(schemaJSON(map[string]interface{}{"anyOf": responses})),
+			},
+		},
+	}}
+	doc.Paths[AgentCardPath] = OpenAPIPathItem{Get: &OpenAPIOperation{
+		OperationID: "getAgentCard",
+		Summary:     "Get the Agent Card",
+		Responses:   map[string]OpenAPIResponse{"200": {Description: "The Agent Card", Content: jsonContent(json.RawMessage(`{"type":"object"}`))}},
+	}}
+	if s.rest {
+		s.openAPIREST(&doc, tasks, results)
+	}
+	if root {
+		doc.Paths[HealthPath] = OpenAPIPathItem{Get: &OpenAPIOperation{
+			OperationID: "getHealth",
+			Summary:     "Check the server's health",
+			Responses: map[string]OpenAPIResponse{
+				"200": {Description: "The server is healthy"},
+				"503": {Description: "The server is draining or shutting down"},
+			},
+		}}
+	}
+	return doc
+}
+
+// openAPIREST adds the paths WithREST serves to doc, with tasks and
+// results the schemas of each capability's tasks and results
+func (s *A2AServer) openAPIREST(doc *OpenAPIDocument, tasks, results []interface{}) {
+	doc.Components.Schemas["Error"] = schemaJSON(map[string]interface{}{
+		"type":       "object",
+		"required":   []string{"error"},
+		"properties": map[string]interface{}{"error": ref("JSONRPCError")},
+	})
+	task, result := schemaJSON(ref("TaskParams")), schemaJSON(ref("TaskResult"))
+	if len(tasks) > 0 {
+		task = schemaJSON(map[string]interface{}{"anyOf": tasks})
+		result = schemaJSON(map[string]interface{}{"anyOf": results})
+	}
+	errorResponse := func(description string) OpenAPIResponse {
+		return OpenAPIResponse{Description: description, Content: jsonContent(schemaJSON(ref("Error")))}
+	}
+	taskID := OpenAPIParameter{Name: "id", In: "path", Required: true, Schema: json.RawMessage(`{"type":"string"}`)}
+
+	doc.Paths[TasksPath] = OpenAPIPathItem{Post: &OpenAPIOperation{
+		OperationID: "createTask",
+		Summary:     "Run a task",
+		Description: "Runs the task and returns its result, or with Prefer: respond-async queues it and returns its submitted state.",
+		Parameters: []OpenAPIParameter{{
+			Name: "Prefer", In: "header", Description: "respond-async to queue the task",
+			Schema: json.RawMessage(`{"type":"string"}`),
+		}},
+		RequestBody: &OpenAPIRequestBody{Required: true, Content: jsonContent(task)},
+		Responses: map[string]OpenAPIResponse{
+			"200":     {Description: "The task's result", Content: jsonContent(result)},
+			"202":     {Description: "The task was queued; its URL is in Location", Content: jsonContent(schemaJSON(ref("TaskResult")))},
+			"default": errorResponse("The task was refused"),
+		},
+	}}
+	doc.Paths[TasksPath+"/{id}"] = OpenAPIPathItem{
+		Get: &OpenAPIOperation{
+			OperationID: "getTask",
+			Summary:     "Get a task's state",
+			Parameters:  []OpenAPIParameter{taskID},
+			Responses: map[string]OpenAPIResponse{
+				"200": {Description: "The task's current state", Content: jsonContent(result)},
+				"401": errorResponse("The task was sent by an authenticated sender, and the request has no token"),
+				"403": errorResponse("The task was sent by another sender"),
+				"404": errorResponse("No such task"),
+			},
+		},
+		Delete: &OpenAPIOperation{
+			OperationID: "cancelTask",
+			Summary:     "Cancel a task",
+			Parameters:  []OpenAPIParameter{taskID},
+			Responses: map[string]OpenAPIResponse{
+				"200": {Description: "The canceled task", Content: jsonContent(schemaJSON(ref("TaskResult")))},
+				"401": errorResponse("The task was sent by an authenticated sender, and the request has no token"),
+				"403": errorResponse("The task was sent by another sender"),
+				"404": errorResponse("No such task"),
+				"409": errorResponse("The task has already finished"),
+			},
+		},
+	}
+}
+
+// openAPIMethods are the JSON-RPC methods servers answer
+var openAPIMethods = []string{
+	"a2a/task", "a2a/task/submit", "a2a/task/get", "message/send", "message/stream",
+	"tasks/get", "tasks/cancel", "tasks/pushNotificationConfig/set", "tasks/pushNotificationConfig/get",
+	"tasks/poll", "a2a/verify", "a2a/discover",
+}
+
+// handleOpenAPI serves the OpenAPI document of the server or of a hosted
+// agent at <agent path>/openapi.json
+func (s *A2AServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	doc := s.OpenAPI()
+	if strings.HasPrefix(r.URL.Path, agentPathPrefix) {
+		agentID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, agentPathPrefix), OpenAPIPath)
+		s.mu.RLock()
+		hosted, ok := s.hosted[agentID]
+		s.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		doc = s.openAPI(hosted.Info(), hosted.Description, false)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+func rpcRequestSchema(method, params interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"jsonrpc", "id", "method"},
+		"properties": map[string]interface{}{
+			"jsonrpc": map[string]interface{}{"const": "2.0"},
+			"id":      map[string]interface{}{"type": []string{"string", "integer"}},
+			"method":  method,
+			"params":  params,
+		},
+	}
+}
+
+func rpcResponseSchema(result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"jsonrpc", "id"},
+		"properties": map[string]interface{}{
+			"jsonrpc": map[string]interface{}{"const": "2.0"},
+			"id":      map[string]interface{}{"type": []string{"string", "integer", "null"}},
+			"result":  result,
+			"error":   ref("JSONRPCError"),
+		},
+	}
+}
+
+func jsonContent(schema json.RawMessage) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{"application/json": {Schema: schema}}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func schemaJSON(schema interface{}) json.RawMessage {
+	body, _ := json.Marshal(schema)
+	return body
+}
+
+// invalidSchemaName matches the characters OpenAPI rejects in component
+// names
+var invalidSchemaName = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// schemaName returns the prefix of a capability's component names
+func schemaName(capability string) string {
+	return invalidSchemaName.ReplaceAllString(capability, "_")
+}
@@ -0,0 +1,144 @@
+package a2a
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware wraps the server's HTTP handler
+type Middleware func(http.Handler) http.Handler
+
+// Limits bounds the resources a server spends on incoming work
+type Limits struct {
+	MaxRequestBytes int64 // maximum JSON-RPC request body size; 0 means unlimited
+	Workers         int   // goroutines executing asynchronous tasks; 0 means 4
+	MaxQueuedTasks  int   // asynchronous tasks waiting for a worker; 0 means unlimited
+	MaxUploadBytes  int64 // maximum streamed upload size; 0 means unlimited
+	MaxHops         int   // how deep sub-tasks may nest before they are refused as a loop; 0 means 16
+	MaxPausedTasks  int   // tasks waiting for input; beyond it the one waiting longest is canceled; 0 means 10000
+	// InputTimeout is how long a task waits for input before it is
+	// canceled; 0 means 1 hour
+	InputTimeout time.Duration
+}
+
+// ServerOption configures an A2AServer created with NewServerWithOptions
+type ServerOption func(*A2AServer)
+
+// WithName sets the agent's human-readable name
+func WithName(name string) ServerOption {
+	return func(s *A2AServer) {
+		s.Name = name
+	}
+}
+
+// WithCapabilities sets the capabilities the agent advertises
+func WithCapabilities(capabilities ...string) ServerOption {
+	return func(s *A2AServer) {
+		s.Capabilities = capabilities
+	}
+}
+
+// WithAddr sets the address the server listens on, e.g. ":9001" or
+// "0.0.0.0:9001"
+func WithAddr(addr string) ServerOption {
+	return func(s *A2AServer) {
+		s.addr = addr
+	}
+}
+
+// WithTLS serves HTTPS using config. Certificates are taken from the config,
+// or from certFile and keyFile when they are not empty.
+func WithTLS(config *tls.Config, certFile, keyFile string) ServerOption {
+	return func(s *A2AServer) {
+		if config == nil {
+			config = &tls.Config{}
+		}
+		s.tlsConfig = config
+		s.certFile = certFile
+		s.keyFile = keyFile
+	}
+}
+
+// WithLogger sets the logger used for server events
+func WithLogger(logger *slog.Logger) ServerOption {
+	return func(s *A2AServer) {
+		s.logger = logger
+	}
+}
+
+// WithMiddleware wraps the server's HTTP handler. The first middleware given
+// is the outermost.
+func WithMiddleware(middleware ...Middleware) ServerOption {
+	return func(s *A2AServer) {
+		s.middleware = append(s.middleware, middleware...)
+	}
+}
+
+// WithLimits sets resource limits for the server
+func WithLimits(limits Limits) ServerOption {
+	return func(s *A2AServer) {
+		s.limits = limits
+	}
+}
+
+// WithTaskStore sets where asynchronous task state is kept
+func WithTaskStore(store TaskStore) ServerOption {
+	return func(s *A2AServer) {
+		s.store = store
+	}
+}
+
+// WithEndpoint sets the URL other agents use to reach this server. Set it
+// whenever the listen address is not reachable as-is, such as behind NAT,
+// a load balancer, or in a container.
+func WithEndpoint(endpoint string) ServerOption {
+	return func(s *A2AServer) {
+		s.Endpoint = endpoint
+	}
+}
+
+// NewServerWithOptions creates a new A2A server configured by opts. Without
+// WithAddr the server listens on :8080.
+func NewServerWithOptions(agentID string, opts ...ServerOption) *A2AServer {
+	s := &A2AServer{
+		AgentID:              agentID,
+		Name:                 agentID,
+		addr:                 ":8080",
+		compressionThreshold: defaultCompressionThreshold,
+		logger:               slog.Default(),
+		store:                NewMemoryTaskStore(),
+		queue:                newTaskQueue(),
+		retryPolicies:        make(map[string]RetryPolicy),
+		hosted:               make(map[string]*HostedAgent),
+		done:                 make(chan struct{}),
+		resultCache:          NewMemoryResultCache(defaultResultCacheSize),
+		resultTTL:            defaultResultCacheTTL,
+		codec:                DefaultCodec,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	host, port, err := net.SplitHostPort(s.addr)
+	if err == nil {
+		s.Port, _ = strconv.Atoi(port)
+	}
+	if s.Endpoint == "" {
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		scheme := "http"
+		if s.tlsConfig != nil {
+			scheme = "https"
+		}
+		s.Endpoint = fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, port))
+	}
+
+	return s
+}
@@ -0,0 +1,211 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParseMode sets how strictly the server reads JSON-RPC requests
+type ParseMode int
+
+const (
+	// ParseLenient, the default, takes requests that are close enough: the
+	// jsonrpc version is not checked, unknown fields are ignored, IDs that
+	// are not a string, number or null are answered with a null ID and
+	// params sent as a one-element array are unwrapped
+	ParseLenient ParseMode = iota
+	// ParseStrict rejects requests that bend the wire format, saying what
+	// was wrong in the error: a missing or wrong jsonrpc version, an ID that
+	// is not a string, integer or null, a missing method and unknown
+	// fields, in the request or its params. Run it in conformance tests to
+	// catch sloppy clients.
+	ParseStrict
+)
+
+// RequestErrorData is the Data of requests refused under ParseStrict
+type RequestErrorData struct {
+	Field  string `json:"field,omitempty"` // the offending field, if known
+	Reason string `json:"reason"`
+}
+
+// WithParseMode sets how strictly the server reads requests
+func WithParseMode(mode ParseMode) ServerOption {
+	return func(s *A2AServer) {
+		s.parseMode = mode
+	}
+}
+
+// readRequest decodes a request body under the server's parse mode
+func (s *A2AServer) readRequest(body io.Reader) (serverRequest, *JSONRPCError) {
+	strict := s.parseMode == ParseStrict
+	decoder := s.codec.NewDecoder(body)
+	if strict {
+		disallowUnknownFields(decoder)
+	}
+
+	var req serverRequest
+	err := decoder.Decode(&req)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return serverRequest{}, &JSONRPCError{Code: CodeInvalidRequest, Message: "Request too large"}
+	}
+	if err != nil {
+		if strict && !isSyntaxError(err) {
+			return serverRequest{}, requestError(CodeInvalidRequest, "Invalid request", describeDecodeError(err))
+		}
+		return serverRequest{}, &JSONRPCError{Code: CodeParseError, Message: "Parse error"}
+	}
+
+	if reason := checkID(req.ID, strict); reason != "" {
+		if strict {
+			return req, requestError(CodeInvalidRequest, "Invalid request", RequestErrorData{Field: "id", Reason: reason})
+		}
+		req.ID = RequestID{} // answered with a null ID, as the ID cannot be echoed
+	}
+
+	if strict {
+		switch {
+		case req.JSONRPC == "":
+			return req, requestError(CodeInvalidRequest, "Invalid request", RequestErrorData{Field: "jsonrpc", Reason: `missing; must be "2.0"`})
+		case req.JSONRPC != "2.0":
+			return req, requestError(CodeInvalidRequest, "Invalid request", RequestErrorData{Field: "jsonrpc", Reason: fmt.Sprintf("must be \"2.0\", got %q", req.JSONRPC)})
+		case req.Method == "":
+			return req, requestError(CodeInvalidRequest, "Invalid request", RequestErrorData{Field: "method", Reason: "missing"})
+		}
+	} else {
+		req.Params = unwrapParams(req.Params)
+	}
+	return req, nil
+}
+
+// checkID returns why a request's ID is refused, if it is. JSON-RPC IDs
+// are strings, numbers or null; strict parsing also refuses numbers with a
+// fraction or exponent, which the specification discourages.
+func checkID(id RequestID, strict bool) string {
+	switch kind := id.kind(); kind {
+	case "string", "null":
+		return ""
+	case "number":
+		if strict && bytes.ContainsAny(id.raw, ".eE") {
+			return "must be an integer, got " + string(id.raw)
+		}
+		return ""
+	default:
+		return "must be a string, number or null, got " + kind
+	}
+}
+
+// unwrapParams unwraps params sent positionally as a one-element array
+func unwrapParams(params json.RawMessage) json.RawMessage {
+	var positional []json.RawMessage
+	if json.Unmarshal(params, &positional) == nil && len(positional) == 1 {
+		return positional[0]
+	}
+	return params
+}
+
+// jsonKind names the JSON type of raw
+func jsonKind(raw json.RawMessage) string {
+	switch raw[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// disallowUnknownFields makes decoder reject unknown fields, if it can.
+// encoding/json's decoder can, as can those of most drop-in replacements.
+func disallowUnknownFields(decoder Decoder) {
+	if d, ok := decoder.(interface{ DisallowUnknownFields() }); ok {
+		d.DisallowUnknownFields()
+	}
+}
+
+// isSyntaxError reports whether err is malformed input rather than a value
+// of the wrong shape
+func isSyntaxError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// describeDecodeError explains why a value failed to decode
+func describeDecodeError(err error) RequestErrorData {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return RequestErrorData{Field: typeErr.Field, Reason: fmt.Sprintf("must be %s, got %s", jsonType(typeErr.Type), typeErr.Value)}
+	}
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if field, err := strconv.Unquote(name); err == nil {
+			return RequestErrorData{Field: field, Reason: "unknown field"}
+		}
+	}
+	return RequestErrorData{Reason: strings.TrimPrefix(err.Error(), "json: ")}
+}
+
+// jsonType names the JSON type a Go type decodes from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return t.String()
+}
+
+// requestError is an error whose message spells out data, so it reads well
+// in logs without decoding the data
+func requestError(code int, message string, data RequestErrorData) *JSONRPCError {
+	if data.Field != "" {
+		message += ": " + data.Field
+	}
+	return &JSONRPCError{Code: code, Message: message + ": " + data.Reason, Data: rpcData(data)}
+}
+
+// decodeParams decodes raw JSON-RPC params into T. Under ParseStrict,
+// unknown fields are refused; parts of messages and artifacts, which decode
+// themselves, are checked only for their kind.
+func decodeParams[T any](s *A2AServer, params json.RawMessage) (T, *JSONRPCError) {
+	var decoded T
+	if len(params) == 0 {
+		return decoded, nil
+	}
+
+	if s.parseMode != ParseStrict {
+		if err := s.codec.Unmarshal(params, &decoded); err != nil {
+			return decoded, &JSONRPCError{Code: CodeInvalidParams, Message: "Invalid params"}
+		}
+		return decoded, nil
+	}
+
+	decoder := s.codec.NewDecoder(bytes.NewReader(params))
+	disallowUnknownFields(decoder)
+	if err := decoder.Decode(&decoded); err != nil {
+		return decoded, requestError(CodeInvalidParams, "Invalid params", describeDecodeError(err))
+	}
+	return decoded, nil
+}
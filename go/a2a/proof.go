@@ -0,0 +1,94 @@
+package a2a
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Agents with an ed25519 signing key prove they own the endpoint they
+// register: the registration carries the public key and a signature over
+// RegistrationPayload, and directories that check endpoint ownership call
+// a2a/verify on the endpoint with a nonce, expecting it signed with the
+// same key. A2AServers created WithSigningKey do both.
+
+// VerifyParams is a directory's challenge to a registered endpoint
+type VerifyParams struct {
+	AgentID string `json:"agentId"`
+	Nonce   string `json:"nonce"`
+}
+
+// VerifyResult answers a challenge with a base64 signature over
+// ChallengePayload
+type VerifyResult struct {
+	Signature string `json:"signature"`
+}
+
+// RegistrationPayload is what a signed registration's Signature covers
+func RegistrationPayload(agentID, endpoint string) []byte {
+	return []byte("a2a-registration\n" + agentID + "\n" + endpoint)
+}
+
+// ChallengePayload is what an answer to a2a/verify signs
+func ChallengePayload(agentID, nonce string) []byte {
+	return []byte("a2a-challenge\n" + agentID + "\n" + nonce)
+}
+
+// EncodePublicKey returns key as carried in RegisterParams.PublicKey
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// DecodePublicKey parses a key encoded by EncodePublicKey
+func DecodePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// VerifySignature reports whether the base64 signature is key's signature
+// of payload
+func VerifySignature(key ed25519.PublicKey, payload []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	return err == nil && ed25519.Verify(key, payload, sig)
+}
+
+func sign(key ed25519.PrivateKey, payload []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+}
+
+// WithSigningKey signs the registrations of every agent identity served
+// here with key and answers directories' ownership challenges
+func WithSigningKey(key ed25519.PrivateKey) ServerOption {
+	return func(s *A2AServer) {
+		s.signingKey = key
+	}
+}
+
+// handleVerify answers a directory's challenge for an agent served here
+func (s *A2AServer) handleVerify(pathAgentID string, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	if s.signingKey == nil {
+		return nil, &JSONRPCError{Code: CodeMethodNotFound, Message: "Method not found"}
+	}
+	verify, rpcErr := decodeParams[VerifyParams](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if verify.Nonce == "" {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "Invalid params"}
+	}
+	// Only answer for the identity this endpoint serves, so the signature
+	// cannot vouch for a registration pointing elsewhere
+	served := s.AgentID
+	if pathAgentID != "" {
+		served = pathAgentID
+	}
+	if verify.AgentID != served {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "Agent not served at this endpoint"}
+	}
+	result, _ := s.codec.Marshal(VerifyResult{Signature: sign(s.signingKey, ChallengePayload(verify.AgentID, verify.Nonce))})
+	return result, nil
+}
@@ -0,0 +1,254 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PushNotificationTokenHeader carries the token of a push notification
+// config, so receivers can check a notification is for them
+const PushNotificationTokenHeader = "X-A2A-Notification-Token"
+
+// PushNotificationConfig is where a server POSTs a task when it finishes
+// or needs input, in the A2A specification's shape
+type PushNotificationConfig struct {
+	ID             string                              `json:"id,omitempty"`
+	URL            string                              `json:"url"`
+	Token          string                              `json:"token,omitempty"` // sent in PushNotificationTokenHeader
+	Authentication *PushNotificationAuthenticationInfo `json:"authentication,omitempty"`
+}
+
+// PushNotificationAuthenticationInfo is how the server authenticates to
+// the notification URL. With the "Bearer" scheme, Credentials is sent as
+// a bearer token.
+type PushNotificationAuthenticationInfo struct {
+	Schemes     []string `json:"schemes"`
+	Credentials string   `json:"credentials,omitempty"`
+}
+
+// TaskPushNotificationConfig is a task's push notification config, the
+// params and result of tasks/pushNotificationConfig/set
+type TaskPushNotificationConfig struct {
+	TaskID                 string                 `json:"taskId"`
+	PushNotificationConfig PushNotificationConfig `json:"pushNotificationConfig"`
+}
+
+// TaskIDParams names a task in the A2A specification's methods
+type TaskIDParams struct {
+	ID       string                 `json:"id"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// PushNotifications configures how WithPushNotifications delivers them
+type PushNotifications struct {
+	Client      *http.Client  // nil means a client with Timeout
+	Timeout     time.Duration // per delivery attempt; 0 means 5s
+	MaxAttempts int           // deliveries failing with a 5xx or network error are retried; 0 means 3
+	// AllowURL vets the URLs clients ask to be notified at, e.g. to keep
+	// them off internal networks; nil allows any http or https URL
+	AllowURL func(*url.URL) bool
+}
+
+// WithPushNotifications lets clients set a push notification config on
+// their tasks with tasks/pushNotificationConfig/set, or in the
+// configuration of message/send. When an asynchronous or interactive
+// task finishes or needs input, the server POSTs it as a Task to the
+// config's URL.
+func WithPushNotifications(config PushNotifications) ServerOption {
+	return func(s *A2AServer) {
+		if config.Timeout <= 0 {
+			config.Timeout = 5 * time.Second
+		}
+		if config.MaxAttempts <= 0 {
+			config.MaxAttempts = 3
+		}
+		if config.Client == nil {
+			config.Client = &http.Client{Timeout: config.Timeout}
+		}
+		s.push.config = &config
+	}
+}
+
+// pushState holds the push notification configs of tasks, by task ID
+type pushState struct {
+	config  *PushNotifications // nil when push notifications are not supported
+	mu      sync.Mutex
+	configs map[string]PushNotificationConfig
+}
+
+// setPushNotification handles tasks/pushNotificationConfig/set
+func (s *A2AServer) setPushNotification(ctx context.Context, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	config, rpcErr := decodeParams[TaskPushNotificationConfig](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if rpcErr := s.checkPushConfig(config.PushNotificationConfig); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if _, rpcErr := s.ownedTask(ctx, config.TaskID); rpcErr != nil {
+		return nil, rpcErr
+	}
+	s.setPushConfig(config.TaskID, config.PushNotificationConfig)
+	// A task that finished before its config was set is delivered at once.
+	// Reading it after setting the config means a task finishing meanwhile
+	// is delivered at least once.
+	if current, err := s.store.Get(config.TaskID); err == nil {
+		s.notifyPush(current)
+	}
+	return s.marshalResult(config)
+}
+
+// checkPushConfig refuses push notification configs when they are not
+// supported or their URL is not allowed
+func (s *A2AServer) checkPushConfig(config PushNotificationConfig) *JSONRPCError {
+	if s.push.config == nil {
+		return &JSONRPCError{Code: CodePushNotificationNotSupported, Message: "Push Notification is not supported"}
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		(s.push.config.AllowURL != nil && !s.push.config.AllowURL(u)) {
+		return &JSONRPCError{Code: CodeInvalidParams, Message: "Invalid push notification URL"}
+	}
+	return nil
+}
+
+// setPushConfig stores a task's push notification config
+func (s *A2AServer) setPushConfig(taskID string, config PushNotificationConfig) {
+	s.push.mu.Lock()
+	defer s.push.mu.Unlock()
+	if s.push.configs == nil {
+		s.push.configs = make(map[string]PushNotificationConfig)
+	}
+	s.push.configs[taskID] = config
+}
+
+// getPushNotification handles tasks/pushNotificationConfig/get
+func (s *A2AServer) getPushNotification(ctx context.Context, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	query, rpcErr := decodeParams[TaskIDParams](s, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if s.push.config == nil {
+		return nil, &JSONRPCError{Code: CodePushNotificationNotSupported, Message: "Push Notification is not supported"}
+	}
+	if current, err := s.store.Get(query.ID); err == nil {
+		if rpcErr := s.checkOwner(ctx, current); rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+	s.push.mu.Lock()
+	config, ok := s.push.configs[query.ID]
+	s.push.mu.Unlock()
+	if !ok {
+		return nil, taskNotFound(query.ID)
+	}
+	return s.marshalResult(TaskPushNotificationConfig{TaskID: query.ID, PushNotificationConfig: config})
+}
+
+// notifyPush delivers a task that finished or needs input to its push
+// notification URL, if it has one, in the background
+func (s *A2AServer) notifyPush(result *TaskResult) {
+	if s.push.config == nil || !(result.Status.Terminal() || result.Status == TaskStateInputRequired) {
+		return
+	}
+	s.push.mu.Lock()
+	config, ok := s.push.configs[result.TaskID]
+	s.push.mu.Unlock()
+	if !ok {
+		return
+	}
+	body, err := s.codec.Marshal(result.Task(nil))
+	if err != nil {
+		s.logger.Error("encoding push notification", "taskId", result.TaskID, "error", err)
+		return
+	}
+	go s.deliverPush(result.TaskID, config, body)
+}
+
+func (s *A2AServer) deliverPush(taskID string, config PushNotificationConfig, body []byte) {
+	var err error
+	for attempt := 1; attempt <= s.push.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(time.Duration(1<<(attempt-2)) * time.Second):
+			case <-s.done:
+				return
+			}
+		}
+		var retry bool
+		retry, err = s.postPush(config, body)
+		if err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		s.logger.Warn("push notification failed", "taskId", taskID, "url", config.URL, "error", err)
+	}
+}
+
+// postPush makes one delivery attempt, reporting whether a failure is
+// worth retrying
+func (s *A2AServer) postPush(config PushNotificationConfig, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.push.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", s.codec.ContentType())
+	if config.Token != "" {
+		req.Header.Set(PushNotificationTokenHeader, config.Token)
+	}
+	if auth := config.Authentication; auth != nil && auth.Credentials != "" {
+		for _, scheme := range auth.Schemes {
+			if strings.EqualFold(scheme, "bearer") {
+				req.Header.Set("Authorization", "Bearer "+auth.Credentials)
+			}
+		}
+	}
+	resp, err := s.push.config.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// SetTaskPushNotification asks another agent to POST the task to
+// config's URL when it finishes or needs input
+func (a *A2AAgent) SetTaskPushNotification(targetAgentID, taskID string, config PushNotificationConfig, directoryURL string) (*TaskPushNotificationConfig, error) {
+	return a.pushNotificationRequest(targetAgentID, "tasks/pushNotificationConfig/set",
+		TaskPushNotificationConfig{TaskID: taskID, PushNotificationConfig: config}, directoryURL)
+}
+
+// GetTaskPushNotification returns the push notification config set on
+// another agent's task
+func (a *A2AAgent) GetTaskPushNotification(targetAgentID, taskID, directoryURL string) (*TaskPushNotificationConfig, error) {
+	return a.pushNotificationRequest(targetAgentID, "tasks/pushNotificationConfig/get", TaskIDParams{ID: taskID}, directoryURL)
+}
+
+func (a *A2AAgent) pushNotificationRequest(targetAgentID, method string, params interface{}, directoryURL string) (*TaskPushNotificationConfig, error) {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.doRequest(context.Background(), agentInfo.Endpoint, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("push notification config failed: %w", err)
+	}
+	var config TaskPushNotificationConfig
+	if err := a.codec().Unmarshal(result, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
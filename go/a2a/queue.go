@@ -0,0 +1,97 @@
+package a2a
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// queuedTask is a task waiting to be executed by a server worker
+type queuedTask struct {
+	params  TaskParams
+	attempt int
+	seq     uint64
+}
+
+// taskHeap orders tasks by descending priority, then by arrival
+type taskHeap []*queuedTask
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].params.Priority != h[j].params.Priority {
+		return h[i].params.Priority > h[j].params.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(*queuedTask)) }
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return t
+}
+
+// taskQueue is a blocking priority queue of tasks awaiting execution.
+// Tasks with equal priority run in arrival order.
+type taskQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  taskHeap
+	seq    uint64
+	closed bool
+}
+
+func newTaskQueue() *taskQueue {
+	q := &taskQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds a task to the queue. It returns false if the queue is closed.
+func (q *taskQueue) push(t *queuedTask) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	q.seq++
+	t.seq = q.seq
+	heap.Push(&q.items, t)
+	q.cond.Signal()
+	return true
+}
+
+// pop blocks until a task is available or the queue is closed, and returns
+// the highest-priority task
+func (q *taskQueue) pop() (*queuedTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	return heap.Pop(&q.items).(*queuedTask), true
+}
+
+// len returns the number of tasks waiting in the queue
+func (q *taskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// close wakes all waiting workers; queued tasks are still drained
+func (q *taskQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,182 @@
+package a2a
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxReplyBytes bounds the replies a ReplyReceiver accepts
+const maxReplyBytes = 10 << 20
+
+// ReplyReceiver receives the results of tasks sent WithReplyTo, which the
+// target POSTs back when they finish or need input instead of answering
+// the request, so slow agents can reply minutes later without a
+// connection held open. Mount it where URL points:
+//
+//	receiver := a2a.NewReplyReceiver("https://me.example.com/replies")
+//	http.Handle("/replies", receiver)
+//	submitted, err := agent.SendTask("slow-agent", "render", input, directoryURL, a2a.WithReplyTo(receiver))
+//	result, err := receiver.Wait(ctx, submitted.TaskID)
+//
+// Each task gets its own token, derived from the task ID and a secret of
+// the receiver's, so the receiver only accepts replies from the agent it
+// sent the task to and keeps no state for tasks until they reply. Targets
+// need WithPushNotifications.
+type ReplyReceiver struct {
+	URL   string
+	Codec Codec // nil means DefaultCodec
+	// OnReply, if set, is called with each reply as it arrives, to handle
+	// replies without waiting for them. Replies nobody waits for are then
+	// not kept.
+	OnReply func(*TaskResult)
+
+	secretOnce sync.Once
+	secret     []byte
+	mu         sync.Mutex
+	pending    map[string]chan *TaskResult // the last reply not yet waited for, by task ID
+}
+
+// NewReplyReceiver returns a receiver that targets reach at url
+func NewReplyReceiver(url string) *ReplyReceiver {
+	return &ReplyReceiver{URL: url}
+}
+
+// WithReplyTo makes the target queue the task and POST its result to
+// receiver once it finishes or needs input. The task is answered at once
+// with its submitted state.
+func WithReplyTo(receiver *ReplyReceiver) TaskOption {
+	return func(p *TaskParams) {
+		p.ReplyTo = &PushNotificationConfig{URL: receiver.URL, Token: receiver.token(p.TaskID)}
+	}
+}
+
+// token returns the token replies for taskID must carry
+func (rr *ReplyReceiver) token(taskID string) string {
+	rr.secretOnce.Do(func() {
+		rr.secret = make([]byte, 32)
+		rand.Read(rr.secret)
+	})
+	mac := hmac.New(sha256.New, rr.secret)
+	mac.Write([]byte(taskID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// replies returns the channel holding taskID's last reply
+func (rr *ReplyReceiver) replies(taskID string) chan *TaskResult {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.pending == nil {
+		rr.pending = make(map[string]chan *TaskResult)
+	}
+	latest, ok := rr.pending[taskID]
+	if !ok {
+		latest = make(chan *TaskResult, 1)
+		rr.pending[taskID] = latest
+	}
+	return latest
+}
+
+// Wait returns the next reply for a task sent WithReplyTo, or ctx's error
+// if it is done first. A reply that arrived before Wait was called is
+// returned at once; a task waiting for input can be waited for again
+// after it is resumed.
+func (rr *ReplyReceiver) Wait(ctx context.Context, taskID string) (*TaskResult, error) {
+	latest := rr.replies(taskID)
+	select {
+	case result := <-latest:
+		if result.Status.Terminal() {
+			rr.forget(taskID, latest)
+		}
+		return result, nil
+	case <-ctx.Done():
+		rr.forget(taskID, latest)
+		return nil, ctx.Err()
+	}
+}
+
+// forget drops taskID's channel unless a reply is waiting in it
+func (rr *ReplyReceiver) forget(taskID string, latest chan *TaskResult) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.pending[taskID] == latest && len(latest) == 0 {
+		delete(rr.pending, taskID)
+	}
+}
+
+// ServeHTTP accepts a reply POSTed by a target, as a TaskResult or, from
+// targets that only speak message/send, a Task
+func (rr *ReplyReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxReplyBytes))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	result, err := rr.decode(body)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	token := r.Header.Get(PushNotificationTokenHeader)
+	if !hmac.Equal([]byte(token), []byte(rr.token(result.TaskID))) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if rr.OnReply != nil {
+		rr.OnReply(result)
+		rr.mu.Lock()
+		_, waiting := rr.pending[result.TaskID]
+		rr.mu.Unlock()
+		if !waiting {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	// Keep only the latest reply: a task that asked for input and
+	// finished since supersedes its question
+	latest := rr.replies(result.TaskID)
+	rr.mu.Lock()
+	select {
+	case <-latest:
+	default:
+	}
+	latest <- result
+	rr.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rr *ReplyReceiver) decode(body []byte) (*TaskResult, error) {
+	codec := rr.Codec
+	if codec == nil {
+		codec = DefaultCodec
+	}
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := codec.Unmarshal(body, &kind); err != nil {
+		return nil, err
+	}
+	if kind.Kind == "task" {
+		var task Task
+		if err := codec.Unmarshal(body, &task); err != nil {
+			return nil, err
+		}
+		return task.Result(), nil
+	}
+	var result TaskResult
+	if err := codec.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
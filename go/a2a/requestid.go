@@ -0,0 +1,66 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// RequestID is a JSON-RPC request ID: a string, a number or null. It keeps
+// the ID as it was sent, so responses echo integer IDs from Python and
+// JavaScript SDKs exactly.
+type RequestID struct {
+	raw json.RawMessage
+}
+
+// StringID returns a string request ID
+func StringID(id string) RequestID {
+	raw, _ := json.Marshal(id)
+	return RequestID{raw: raw}
+}
+
+// NumberID returns a numeric request ID
+func NumberID(id int64) RequestID {
+	return RequestID{raw: strconv.AppendInt(nil, id, 10)}
+}
+
+// String returns a string ID's value or a number's digits. Null and
+// missing IDs are "".
+func (id RequestID) String() string {
+	if id.IsNull() {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(id.raw, &s) == nil {
+		return s
+	}
+	return string(id.raw)
+}
+
+// IsNull reports whether the ID is null or missing, as in a notification
+func (id RequestID) IsNull() bool {
+	return len(id.raw) == 0 || id.kind() == "null"
+}
+
+// kind names the JSON type of the ID
+func (id RequestID) kind() string {
+	if len(id.raw) == 0 {
+		return "null"
+	}
+	return jsonKind(id.raw)
+}
+
+// MarshalJSON writes the ID as it was sent, or null
+func (id RequestID) MarshalJSON() ([]byte, error) {
+	if len(id.raw) == 0 {
+		return []byte("null"), nil
+	}
+	return id.raw, nil
+}
+
+// UnmarshalJSON keeps the ID as sent. IDs of any type are accepted here;
+// the server checks them according to its ParseMode.
+func (id *RequestID) UnmarshalJSON(data []byte) error {
+	id.raw = append(json.RawMessage(nil), bytes.TrimSpace(data)...)
+	return nil
+}
@@ -0,0 +1,207 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// TasksPath is where WithREST serves tasks, under the server's endpoint
+// and each hosted agent's path
+const TasksPath = "/tasks"
+
+// WithREST serves a RESTful surface over the task methods beside JSON-RPC,
+// for integrators whose tooling can't easily speak JSON-RPC:
+//
+//	POST   /tasks       run a task, as a2a/task; with Prefer: respond-async, queue it, as a2a/task/submit
+//	GET    /tasks/{id}  get a task's state, as a2a/task/get
+//	DELETE /tasks/{id}  cancel a task, as tasks/cancel
+//
+// Bodies are a TaskParams and TaskResults in the server's codec. Errors
+// are {"error": JSONRPCError} with an HTTP status matching the code.
+func WithREST() ServerOption {
+	return func(s *A2AServer) {
+		s.rest = true
+	}
+}
+
+// handleREST registers the REST routes on mux
+func (s *A2AServer) handleREST(mux *http.ServeMux) {
+	for _, prefix := range []string{"", agentPathPrefix + "{agent}"} {
+		mux.HandleFunc("POST "+prefix+TasksPath, s.restCreateTask)
+		mux.HandleFunc("GET "+prefix+TasksPath+"/{id}", s.restGetTask)
+		mux.HandleFunc("DELETE "+prefix+TasksPath+"/{id}", s.restCancelTask)
+	}
+}
+
+// restAgent returns the hosted agent a REST request is addressed to,
+// answering 404 if it isn't served here
+func (s *A2AServer) restAgent(w http.ResponseWriter, r *http.Request) (string, bool) {
+	agentID := r.PathValue("agent")
+	if agentID == "" {
+		return "", true
+	}
+	if _, ok := s.agentInfo(agentID); !ok {
+		http.NotFound(w, r)
+		return "", false
+	}
+	return agentID, true
+}
+
+func (s *A2AServer) restCreateTask(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.restAgent(w, r)
+	if !ok {
+		return
+	}
+	body := io.Reader(r.Body)
+	if s.limits.MaxRequestBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.limits.MaxRequestBytes)
+	}
+	params, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			s.restError(w, r, &JSONRPCError{Code: CodeInvalidRequest, Message: "Request too large"})
+			return
+		}
+		s.restError(w, r, &JSONRPCError{Code: CodeParseError, Message: "Parse error"})
+		return
+	}
+	ctx, params, rpcErr := s.openJWS(requestCorrelation(w, r), "a2a/task", params)
+	if rpcErr == nil {
+		ctx, params, rpcErr = s.openJWT(ctx, bearerJWT(r.Header.Get("Authorization")), agentID, "a2a/task", params)
+	}
+	if rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	task, rpcErr := s.decodeTask(agentID, params)
+	if rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	if task.TaskID == "" {
+		task.TaskID = generateID()
+	}
+
+	if preferAsync(r) {
+		result, rpcErr := s.enqueueTask(ctx, task)
+		if rpcErr != nil {
+			s.restError(w, r, rpcErr)
+			return
+		}
+		w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+result.TaskID)
+		w.Header().Set("Preference-Applied", "respond-async")
+		s.restWrite(w, r, http.StatusAccepted, result)
+		return
+	}
+	result, rpcErr := s.runTask(ctx, task)
+	if rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	s.restWrite(w, r, http.StatusOK, result)
+}
+
+func (s *A2AServer) restGetTask(w http.ResponseWriter, r *http.Request) {
+	s.restTask(w, r, s.ownedTask)
+}
+
+func (s *A2AServer) restCancelTask(w http.ResponseWriter, r *http.Request) {
+	s.restTask(w, r, func(ctx context.Context, taskID string) (*TaskResult, *JSONRPCError) {
+		if _, rpcErr := s.ownedTask(ctx, taskID); rpcErr != nil {
+			return nil, rpcErr
+		}
+		return s.cancelTask(taskID)
+	})
+}
+
+// restTask answers a request for the task named by the path with what
+// method returns, once its JWT, if any, verifies
+func (s *A2AServer) restTask(w http.ResponseWriter, r *http.Request, method func(ctx context.Context, taskID string) (*TaskResult, *JSONRPCError)) {
+	agentID, ok := s.restAgent(w, r)
+	if !ok {
+		return
+	}
+	ctx, _, rpcErr := s.openJWT(requestCorrelation(w, r), bearerJWT(r.Header.Get("Authorization")), agentID, "tasks/get", nil)
+	if rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	result, rpcErr := method(ctx, r.PathValue("id"))
+	if rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	s.restWrite(w, r, http.StatusOK, result)
+}
+
+// preferAsync reports whether the request prefers an asynchronous
+// response, as RFC 7240 puts it
+func preferAsync(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(strings.TrimSpace(preference), ";")
+			if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// restWrite writes v as a response with status
+func (s *A2AServer) restWrite(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	s.writeJSON(&statusWriter{ResponseWriter: w, status: status}, r, v)
+}
+
+// statusWriter sends status with the first write, so headers set before
+// it still count
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(w.status)
+	return w.ResponseWriter.Write(b)
+}
+
+func (s *A2AServer) restError(w http.ResponseWriter, r *http.Request, rpcErr *JSONRPCError) {
+	s.restWrite(w, r, restStatus(rpcErr.Code), map[string]*JSONRPCError{"error": rpcErr})
+}
+
+// restStatus returns the HTTP status of a JSON-RPC error code
+func restStatus(code int) int {
+	switch code {
+	case CodeParseError, CodeInvalidRequest, CodeInvalidParams:
+		return http.StatusBadRequest
+	case CodeTaskNotFound:
+		return http.StatusNotFound
+	case CodeTaskNotCancelable:
+		return http.StatusConflict
+	case CodeContentTypeNotSupported:
+		return http.StatusUnsupportedMediaType
+	case CodeMethodNotFound, CodeUnsupportedOperation, CodePushNotificationNotSupported:
+		return http.StatusNotImplemented
+	case CodeQueueFull, CodeServerUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeHopLimitExceeded:
+		return http.StatusLoopDetected
+	case CodeInvalidSignature, CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,48 @@
+package a2a
+
+import "time"
+
+// RetryPolicy controls how failed asynchronous tasks are re-executed.
+// Only errors marked with Retryable are retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first; 0 or 1 disables retries
+	InitialBackoff time.Duration // delay before the first retry
+	MaxBackoff     time.Duration // upper bound on the delay; 0 means no bound
+	Multiplier     float64       // growth factor between retries; values below 1 are treated as 1
+}
+
+// backoff returns the delay before the retry that follows the given attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if p.MaxBackoff > 0 && delay >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// SetRetryPolicy sets the retry policy for an action. An empty action sets
+// the default policy used by actions without their own.
+func (s *A2AServer) SetRetryPolicy(action string, policy RetryPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retryPolicies[action] = policy
+}
+
+func (s *A2AServer) retryPolicy(action string) RetryPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if policy, ok := s.retryPolicies[action]; ok {
+		return policy
+	}
+	return s.retryPolicies[""]
+}
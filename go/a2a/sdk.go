@@ -43,8 +43,11 @@
 package a2a
 
 import (
-	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -53,7 +56,7 @@ import (
 // JSONRPCRequest represents a JSON-RPC 2.0 request
 type JSONRPCRequest struct {
 	JSONRPC string      `json:"jsonrpc"`
-	ID      string      `json:"id"`
+	ID      RequestID   `json:"id"`
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params,omitempty"`
 }
@@ -61,7 +64,7 @@ type JSONRPCRequest struct {
 // JSONRPCResponse represents a JSON-RPC 2.0 response
 type JSONRPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
-	ID      string          `json:"id"`
+	ID      RequestID       `json:"id"`
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *JSONRPCError   `json:"error,omitempty"`
 }
@@ -73,32 +76,178 @@ type JSONRPCError struct {
 	Data    json.RawMessage `json:"data,omitempty"`
 }
 
+// Error implements the error interface, so clients can recover the code of
+// a failed call with errors.As
+func (e *JSONRPCError) Error() string {
+	return "RPC error: " + e.Message
+}
+
 // AgentInfo represents registered agent information
 type AgentInfo struct {
-	AgentID       string    `json:"agentId"`
-	Name          string    `json:"name"`
-	Capabilities  []string  `json:"capabilities"`
-	Endpoint      string    `json:"endpoint"`
-	RegisteredAt time.Time `json:"registeredAt,omitempty"`
+	AgentID      string            `json:"agentId"`
+	Name         string            `json:"name"`
+	Capabilities []string          `json:"capabilities"`
+	Endpoint     string            `json:"endpoint"`
+	Description  string            `json:"description,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"` // e.g. team, model, cost tier
+	Region       string            `json:"region,omitempty"`   // e.g. "eu-west-1"
+	Zone         string            `json:"zone,omitempty"`     // e.g. "eu-west-1a"
+	Load         *Load             `json:"load,omitempty"`     // as of the last heartbeat that reported one
+	Reputation   *Reputation       `json:"reputation,omitempty"`
+	PublicKey    string            `json:"publicKey,omitempty"` // ed25519 key the registration was signed with
+	RegisteredAt time.Time         `json:"registeredAt,omitempty"`
+	// Schemas holds the JSON Schemas of capabilities' input and output, by
+	// capability
+	Schemas map[string]CapabilitySchema `json:"schemas,omitempty"`
+	// Status and LastSeen are as of the agent's last registration or
+	// heartbeat
+	Status   AgentStatus `json:"status,omitempty"`
+	LastSeen time.Time   `json:"lastSeen,omitempty"`
+}
+
+// CapabilitySchema holds the JSON Schemas a capability's task input and
+// output conform to, for consumers generating typed clients or validating
+// payloads
+type CapabilitySchema struct {
+	Input  json.RawMessage `json:"input,omitempty"`
+	Output json.RawMessage `json:"output,omitempty"`
 }
 
 // RegisterParams represents registration parameters
 type RegisterParams struct {
-	AgentID      string   `json:"agentId"`
-	Name         string   `json:"name"`
-	Capabilities []string `json:"capabilities"`
-	Endpoint     string   `json:"endpoint"`
+	AgentID      string            `json:"agentId"`
+	Name         string            `json:"name"`
+	Capabilities []string          `json:"capabilities"`
+	Endpoint     string            `json:"endpoint"`
+	Description  string            `json:"description,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Region       string            `json:"region,omitempty"`
+	Zone         string            `json:"zone,omitempty"`
+	TTL          int               `json:"ttl,omitempty"` // seconds to keep the registration without a heartbeat; 0 uses the directory default
+	// PublicKey and Signature prove endpoint ownership: an ed25519 key
+	// (EncodePublicKey) and its base64 signature over RegistrationPayload
+	PublicKey string `json:"publicKey,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	// Schemas holds the JSON Schemas of registered capabilities' input and
+	// output, by capability
+	Schemas map[string]CapabilitySchema `json:"schemas,omitempty"`
 }
 
 // RegisterResult represents registration result
 type RegisterResult struct {
-	Status   string `json:"status"`
-	AgentID  string `json:"agentId"`
+	Status  string `json:"status"`
+	AgentID string `json:"agentId"`
+	TTL     int    `json:"ttl,omitempty"` // seconds granted by the directory; 0 means the registration never expires
+}
+
+// DeregisterParams represents deregistration parameters
+type DeregisterParams struct {
+	AgentID string `json:"agentId"`
+}
+
+// HeartbeatParams represents heartbeat parameters
+type HeartbeatParams struct {
+	AgentID string      `json:"agentId"`
+	Load    *Load       `json:"load,omitempty"`
+	Status  AgentStatus `json:"status,omitempty"` // empty keeps the last one reported
+}
+
+// WebhookParams subscribes a URL to a directory's registry events
+type WebhookParams struct {
+	URL      string   `json:"url"`
+	AgentIDs []string `json:"agentIds,omitempty"` // agents to watch; empty means every agent
+	Events   []string `json:"events,omitempty"`   // event types; empty means deregistered, expired and removed
+	Secret   string   `json:"secret,omitempty"`   // signs each delivery's body with HMAC-SHA256
+}
+
+// WebhookResult identifies a webhook subscription
+type WebhookResult struct {
+	ID string `json:"id"`
+}
+
+// ReportParams describes the outcome of a task, as reported to a directory
+// by the agent that sent it
+type ReportParams struct {
+	AgentID   string `json:"agentId"`  // agent that ran the task
+	Reporter  string `json:"reporter"` // agent that sent the task
+	TaskID    string `json:"taskId,omitempty"`
+	Success   bool   `json:"success"`
+	LatencyMs int64  `json:"latencyMs,omitempty"`
+}
+
+// Reputation summarizes the outcomes other agents have reported for an
+// agent, with older reports counting progressively less
+type Reputation struct {
+	Score        float64 `json:"score"` // estimated success rate, 0 to 1
+	Successes    float64 `json:"successes"`
+	Failures     float64 `json:"failures"`
+	AvgLatencyMs float64 `json:"avgLatencyMs,omitempty"`
+}
+
+// Load is the work an agent reports having with its heartbeats
+type Load struct {
+	InFlight   int `json:"inFlight"`   // tasks being executed
+	QueueDepth int `json:"queueDepth"` // tasks accepted but not yet started
+}
+
+// Total is the number of tasks ahead of a newly sent one
+func (l Load) Total() int {
+	return l.InFlight + l.QueueDepth
+}
+
+// AgentStatus is an agent's availability, as it last told a directory
+type AgentStatus string
+
+// Agent statuses
+const (
+	AgentOnline   AgentStatus = "online"   // taking tasks
+	AgentBusy     AgentStatus = "busy"     // taking tasks, but they queue
+	AgentDraining AgentStatus = "draining" // finishing its tasks and taking no new ones
+	AgentOffline  AgentStatus = "offline"  // shut down
+)
+
+// Available reports whether an agent in status s takes new tasks. Agents
+// whose status is not known, such as those registered with older
+// directories, are assumed to.
+func (s AgentStatus) Available() bool {
+	return s != AgentDraining && s != AgentOffline
+}
+
+// Valid reports whether s is one of the agent statuses
+func (s AgentStatus) Valid() bool {
+	switch s {
+	case AgentOnline, AgentBusy, AgentDraining, AgentOffline:
+		return true
+	}
+	return false
 }
 
-// DiscoverParams represents discovery parameters
+// DiscoverParams represents discovery parameters. Agents must offer one of
+// the Capabilities, if any are given, and carry all of the Tags and
+// Metadata values. With a Query, matching agents are ranked by how well
+// their name, description and capabilities match it.
 type DiscoverParams struct {
-	Capabilities []string `json:"capabilities"`
+	Capabilities []string          `json:"capabilities"`
+	Tags         []string          `json:"tags,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Query        string            `json:"query,omitempty"`
+	Limit        int               `json:"limit,omitempty"` // maximum agents returned for a Query; 0 means no limit
+	// LeastLoaded returns only the matching agent with the lowest reported
+	// load, picking at random among ties
+	LeastLoaded bool `json:"leastLoaded,omitempty"`
+	// MinReputation drops agents whose reputation score is below it
+	MinReputation float64 `json:"minReputation,omitempty"`
+	// ByReputation orders matches by reputation score, best first
+	ByReputation bool `json:"byReputation,omitempty"`
+	// Region and Zone are the caller's location. Matches in the same zone,
+	// then the same region, come first; RequireZone and RequireRegion drop
+	// the others.
+	Region        string `json:"region,omitempty"`
+	Zone          string `json:"zone,omitempty"`
+	RequireRegion bool   `json:"requireRegion,omitempty"`
+	RequireZone   bool   `json:"requireZone,omitempty"`
 }
 
 // DiscoverResult represents discovery result
@@ -108,17 +257,72 @@ type DiscoverResult struct {
 
 // TaskParams represents task parameters
 type TaskParams struct {
-	TaskID  string                 `json:"taskId"`
-	Action  string                 `json:"action"`
-	Sender  string                 `json:"sender"`
-	Input   map[string]interface{} `json:"input"`
+	TaskID         string                 `json:"taskId"`
+	AgentID        string                 `json:"agentId,omitempty"` // target agent when a server hosts several
+	Action         string                 `json:"action"`
+	Sender         string                 `json:"sender"`
+	Input          map[string]interface{} `json:"input"`
+	Message        *Message               `json:"message,omitempty"`        // mixed text, file and data content, merged into Input for handlers
+	Priority       int                    `json:"priority,omitempty"`       // higher runs first when queued
+	CorrelationID  string                 `json:"correlationId,omitempty"`  // shared by all tasks serving one request
+	IdempotencyKey string                 `json:"idempotencyKey,omitempty"` // resends with the same key return the first result
+	// InputMode is the media type of Input, checked with the modes of
+	// Message's parts against the skill's InputModes
+	InputMode           string   `json:"inputMode,omitempty"`
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"` // media types the sender can use as output
+	// Metadata carries custom fields, such as a tenant or billing code,
+	// from the sender to the handler and back in the result
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Resume continues the task with this ID, which must be waiting for
+	// input; set by ResumeTask
+	Resume bool `json:"resume,omitempty"`
+	// ParentTaskID, RootTaskID and Hop place a sub-task in the tree of
+	// tasks serving one request: the task whose handler sent it, the first
+	// task of the tree and how deep it is, 0 for the root. They are set by
+	// TaskClient and Propagate.
+	ParentTaskID string `json:"parentTaskId,omitempty"`
+	RootTaskID   string `json:"rootTaskId,omitempty"`
+	Hop          int    `json:"hop,omitempty"`
+	// ReplyTo is where to POST the task once it finishes or needs input,
+	// instead of answering the request. The task is queued and answered
+	// with its submitted state. Set by WithReplyTo.
+	ReplyTo *PushNotificationConfig `json:"replyTo,omitempty"`
+
+	history    []Message               // earlier turns of a resumed task
+	pushConfig *PushNotificationConfig // from message/send's configuration, set once the task is admitted
+}
+
+// Common task priorities. Any int is accepted; queued tasks with a higher
+// priority are executed first.
+const (
+	PriorityBulk        = -10
+	PriorityNormal      = 0
+	PriorityInteractive = 10
+)
+
+// TaskOption customizes a task before it is sent
+type TaskOption func(*TaskParams)
+
+// WithPriority sets the task's queue priority
+func WithPriority(priority int) TaskOption {
+	return func(p *TaskParams) {
+		p.Priority = priority
+	}
 }
 
 // TaskResult represents task result
 type TaskResult struct {
-	TaskID  string                 `json:"taskId"`
-	Status  string                 `json:"status"` // completed, failed, cancelled, timeout
-	Output  map[string]interface{} `json:"output,omitempty"`
+	TaskID        string                 `json:"taskId"`
+	Status        TaskState              `json:"status"`
+	StatusMessage *Message               `json:"statusMessage,omitempty"` // from the agent about the status, e.g. what input it needs
+	UpdatedAt     time.Time              `json:"updatedAt,omitempty"`     // when Status last changed
+	Output        map[string]interface{} `json:"output,omitempty"`
+	Artifacts     []Artifact             `json:"artifacts,omitempty"` // added by the handler with AddArtifact
+	Attempts      int                    `json:"attempts,omitempty"`
+	Error         *JSONRPCError          `json:"error,omitempty"` // set when status is failed
+	CorrelationID string                 `json:"correlationId,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"` // the task's metadata and what its handler set
+	Owner         *TaskOwner             `json:"owner,omitempty"`    // who may read and change the stored task; nil if anyone may
 }
 
 // A2AAgent represents an A2A-enabled agent
@@ -127,6 +331,66 @@ type A2AAgent struct {
 	Name         string
 	Capabilities []string
 	Endpoint     string
+	HTTPClient   *http.Client      // client used for all requests; nil means http.DefaultClient
+	Codec        Codec             // message encoding; nil means DefaultCodec
+	Description  string            // registered with the directory to help query-based discovery
+	Tags         []string          // registered with the directory for filtering discovery
+	Metadata     map[string]string // registered with the directory for filtering discovery
+	// Schemas describes capabilities' input and output, by capability. It
+	// is registered with the directory and returned by discovery.
+	Schemas map[string]CapabilitySchema
+	// Region and Zone are where the agent runs. They are registered with
+	// the directory and sent with discovery so nearby agents are preferred.
+	Region string
+	Zone   string
+	// DirectoryToken is presented as a bearer token when registering,
+	// deregistering and heartbeating, for directories that require it
+	DirectoryToken string
+	// TTL asks the directory to drop the registration if no heartbeat
+	// arrives for this long; zero uses the directory's default. After
+	// Register it holds the TTL the directory granted.
+	TTL time.Duration
+	// Directory is used by calls given an empty directoryURL, in place of
+	// a directory server, e.g. an mDNS, Consul or static file provider
+	Directory Directory
+	// SigningKey signs registrations to prove the agent owns its endpoint;
+	// the endpoint must answer a2a/verify with the same key
+	SigningKey ed25519.PrivateKey
+	// JWS signs the params of task and message requests, so servers in
+	// other organizations can verify them with the key published in this
+	// agent's Agent Card
+	JWS *JWSSigner
+	// JWT identifies the agent to the servers it calls with a token it
+	// signs for each request, so they can trust the sender of its tasks
+	JWT *JWTSigner
+	// Credentials provides the bearer token or API key sent with every
+	// request to other agents. A JWT from JWT is sent in place of the
+	// token. For client certificates, use NewCredentialHTTPClient.
+	Credentials CredentialProvider
+	// CardVerification refuses Agent Cards FetchAgentCard fetches unless
+	// a trusted organization key signed them
+	CardVerification *CardVerification
+	// EncryptionKey makes SendTask and SubmitTask encrypt task input to the
+	// encryption key in the target's Agent Card, which they fetch first,
+	// and have the output encrypted to this key, which they and GetTask
+	// decrypt it with
+	EncryptionKey *ecdh.PrivateKey
+	// PreferMessages sends SendTask, SubmitTask and GetTask with the spec's
+	// message/send and tasks/get rather than the legacy a2a/task methods.
+	// Either way, peers answering CodeMethodNotFound are retried with the
+	// other, so agents can migrate without a flag day.
+	PreferMessages bool
+	// Transports carries requests to endpoints by URL scheme, e.g.
+	// "loopback" or "grpc"; endpoints with other schemes are sent over
+	// HTTP with HTTPClient
+	Transports map[string]Transport
+	// LongPoll makes StreamMessage send a non-blocking message/send and
+	// follow the task with tasks/poll rather than an event stream, for
+	// networks whose proxies block or buffer them. Targets need
+	// WithLongPolling.
+	LongPoll bool
+
+	parent *TaskParams // the task whose handler sends with this agent, for TaskClient
 }
 
 // NewAgent creates a new A2A agent
@@ -138,7 +402,23 @@ func NewAgent(agentID, name string, capabilities []string) *A2AAgent {
 	}
 }
 
-// Register registers the agent with a directory
+// registeredSchemas returns the schemas of the capabilities being
+// registered, which directories require
+func registeredSchemas(schemas map[string]CapabilitySchema, capabilities []string) map[string]CapabilitySchema {
+	var registered map[string]CapabilitySchema
+	for _, capability := range capabilities {
+		if schema, ok := schemas[capability]; ok {
+			if registered == nil {
+				registered = make(map[string]CapabilitySchema)
+			}
+			registered[capability] = schema
+		}
+	}
+	return registered
+}
+
+// Register registers the agent with a directory. An empty directoryURL
+// means a.Directory, here and in every other method taking one.
 func (a *A2AAgent) Register(endpoint, directoryURL string) error {
 	a.Endpoint = endpoint
 
@@ -147,15 +427,86 @@ func (a *A2AAgent) Register(endpoint, directoryURL string) error {
 		Name:         a.Name,
 		Capabilities: a.Capabilities,
 		Endpoint:     endpoint,
+		Description:  a.Description,
+		Tags:         a.Tags,
+		Metadata:     a.Metadata,
+		Region:       a.Region,
+		Zone:         a.Zone,
+		TTL:          int((a.TTL + time.Second - 1) / time.Second),
+		Schemas:      registeredSchemas(a.Schemas, a.Capabilities),
+	}
+	if a.SigningKey != nil {
+		params.PublicKey = EncodePublicKey(a.SigningKey.Public().(ed25519.PublicKey))
+		params.Signature = sign(a.SigningKey, RegistrationPayload(a.AgentID, endpoint))
 	}
 
-	result, err := a.doRequest(directoryURL+"/a2a/register", params)
+	registered, err := a.directory(directoryURL).Register(params)
 	if err != nil {
 		return fmt.Errorf("registration failed: %w", err)
 	}
+	a.TTL = time.Duration(registered.TTL) * time.Second
 
 	fmt.Printf("✅ Registered: %s\n", a.AgentID)
-	_ = result // Result parsed successfully
+	return nil
+}
+
+// Deregister removes the agent from a directory
+func (a *A2AAgent) Deregister(directoryURL string) error {
+	if err := a.directory(directoryURL).Deregister(a.AgentID); err != nil {
+		return fmt.Errorf("deregistration failed: %w", err)
+	}
+	return nil
+}
+
+// Heartbeat tells a directory the agent is still alive
+func (a *A2AAgent) Heartbeat(directoryURL string) error {
+	return a.heartbeat(HeartbeatParams{AgentID: a.AgentID}, directoryURL)
+}
+
+// ReportLoad sends a heartbeat carrying the agent's current load, for
+// directories to route to the least-loaded agent
+func (a *A2AAgent) ReportLoad(load Load, directoryURL string) error {
+	return a.heartbeat(HeartbeatParams{AgentID: a.AgentID, Load: &load}, directoryURL)
+}
+
+// ReportStatus sends a heartbeat carrying the agent's status, so discovery
+// stops returning it while it is draining or offline. Servers report their
+// status themselves; see A2AServer.Status.
+func (a *A2AAgent) ReportStatus(status AgentStatus, directoryURL string) error {
+	return a.heartbeat(HeartbeatParams{AgentID: a.AgentID, Status: status}, directoryURL)
+}
+
+// ReportOutcome tells a directory how a task sent to agentID went, feeding
+// that agent's reputation. It does nothing for directories that are not
+// Reporters.
+func (a *A2AAgent) ReportOutcome(agentID, taskID string, success bool, latency time.Duration, directoryURL string) error {
+	params := ReportParams{
+		AgentID:   agentID,
+		Reporter:  a.AgentID,
+		TaskID:    taskID,
+		Success:   success,
+		LatencyMs: latency.Milliseconds(),
+	}
+	reporter, ok := a.directory(directoryURL).(Reporter)
+	if !ok {
+		return nil
+	}
+	if err := reporter.Report(params); err != nil {
+		return fmt.Errorf("report failed: %w", err)
+	}
+	return nil
+}
+
+// heartbeat renews the agent's registration. Directories that are not
+// Heartbeaters keep registrations without one.
+func (a *A2AAgent) heartbeat(params HeartbeatParams, directoryURL string) error {
+	heartbeater, ok := a.directory(directoryURL).(Heartbeater)
+	if !ok {
+		return nil
+	}
+	if err := heartbeater.Heartbeat(params); err != nil {
+		return fmt.Errorf("heartbeat failed: %w", err)
+	}
 	return nil
 }
 
@@ -163,97 +514,239 @@ func (a *A2AAgent) Register(endpoint, directoryURL string) error {
 func (a *A2AAgent) Discover(wantedCapabilities []string, directoryURL string) (*AgentInfo, error) {
 	params := DiscoverParams{
 		Capabilities: wantedCapabilities,
+		Region:       a.Region,
+		Zone:         a.Zone,
 	}
 
-	result, err := a.doRequest(directoryURL+"/a2a/discover", params)
+	agents, err := a.directory(directoryURL).Discover(params)
 	if err != nil {
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
 
-	var discoverResult DiscoverResult
-	if err := json.Unmarshal(result, &discoverResult); err != nil {
-		return nil, err
+	if len(agents) == 0 {
+		return nil, nil
 	}
 
-	if len(discoverResult.Agents) == 0 {
-		return nil, nil
+	return &agents[0], nil
+}
+
+// Find returns every agent matching params. Unless params names a region
+// or zone, the agent's own are used.
+func (a *A2AAgent) Find(params DiscoverParams, directoryURL string) ([]AgentInfo, error) {
+	if params.Region == "" && params.Zone == "" {
+		params.Region, params.Zone = a.Region, a.Zone
+	}
+	agents, err := a.directory(directoryURL).Discover(params)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
+	return agents, nil
+}
 
-	return &discoverResult.Agents[0], nil
+// Search finds agents matching a free-text query such as "turn PDFs into
+// summaries", best match first, returning at most limit agents (0 for all)
+func (a *A2AAgent) Search(query string, limit int, directoryURL string) ([]AgentInfo, error) {
+	return a.Find(DiscoverParams{Query: query, Limit: limit}, directoryURL)
 }
 
 // SendTask sends a task to another agent
-func (a *A2AAgent) SendTask(targetAgentID, action string, input map[string]interface{}, directoryURL string) (*TaskResult, error) {
-	// Get target agent info from directory
-	resp, err := http.Get(fmt.Sprintf("%s/a2a/agents/%s", directoryURL, targetAgentID))
+func (a *A2AAgent) SendTask(targetAgentID, action string, input map[string]interface{}, directoryURL string, opts ...TaskOption) (*TaskResult, error) {
+	return a.sendTask("a2a/task", targetAgentID, action, input, directoryURL, opts)
+}
+
+// SubmitTask queues a task on another agent for asynchronous execution.
+// The returned result has status "submitted"; poll it with GetTask.
+func (a *A2AAgent) SubmitTask(targetAgentID, action string, input map[string]interface{}, directoryURL string, opts ...TaskOption) (*TaskResult, error) {
+	return a.sendTask("a2a/task/submit", targetAgentID, action, input, directoryURL, opts)
+}
+
+// GetTask fetches the current state of an asynchronous task
+func (a *A2AAgent) GetTask(targetAgentID, taskID, directoryURL string) (*TaskResult, error) {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get agent: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("agent not found: %s", targetAgentID)
+	legacy := func() (*TaskResult, error) {
+		result, err := a.doRequest(context.Background(), agentInfo.Endpoint, "a2a/task/get", TaskQuery{TaskID: taskID})
+		if err != nil {
+			return nil, err
+		}
+		var taskResult TaskResult
+		if err := a.codec().Unmarshal(result, &taskResult); err != nil {
+			return nil, err
+		}
+		return &taskResult, nil
+	}
+	spec := func() (*TaskResult, error) {
+		result, err := a.doRequest(context.Background(), agentInfo.Endpoint, "tasks/get", TaskIDParams{ID: taskID})
+		if err != nil {
+			return nil, err
+		}
+		var task Task
+		if err := a.codec().Unmarshal(result, &task); err != nil {
+			return nil, err
+		}
+		return task.Result(), nil
 	}
 
-	var agentInfo AgentInfo
-	if err := json.NewDecoder(resp.Body).Decode(&agentInfo); err != nil {
+	taskResult, err := a.negotiate(legacy, spec)
+	if err != nil {
+		return nil, fmt.Errorf("task lookup failed: %w", err)
+	}
+	if err := a.openOutput(taskResult); err != nil {
+		return nil, err
+	}
+	return taskResult, nil
+}
+
+// negotiate calls legacy or spec, in the order PreferMessages says, trying
+// the other if the peer does not know the method
+func (a *A2AAgent) negotiate(legacy, spec func() (*TaskResult, error)) (*TaskResult, error) {
+	first, second := legacy, spec
+	if a.PreferMessages {
+		first, second = spec, legacy
+	}
+	result, err := first()
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == CodeMethodNotFound {
+		return second()
+	}
+	return result, err
+}
+
+func (a *A2AAgent) sendTask(method, targetAgentID, action string, input map[string]interface{}, directoryURL string, opts []TaskOption) (*TaskResult, error) {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
 		return nil, err
 	}
 
 	// Send task to target
 	params := TaskParams{
 		TaskID:  generateID(),
+		AgentID: targetAgentID,
 		Action:  action,
 		Sender:  a.AgentID,
 		Input:   input,
 	}
+	if a.parent != nil {
+		subTaskOf(&params, *a.parent)
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	ctx := ContextWithCorrelationID(context.Background(), params.CorrelationID)
+	if a.EncryptionKey != nil {
+		if err := a.sealInput(ctx, agentInfo.Endpoint, &params); err != nil {
+			return nil, err
+		}
+	}
+
+	legacy := func() (*TaskResult, error) {
+		result, err := a.doRequest(ctx, agentInfo.Endpoint, method, params)
+		if err != nil {
+			return nil, err
+		}
+		var taskResult TaskResult
+		if err := a.codec().Unmarshal(result, &taskResult); err != nil {
+			return nil, err
+		}
+		return &taskResult, nil
+	}
+	spec := func() (*TaskResult, error) {
+		send := params.MessageSendParams()
+		if method == "a2a/task/submit" {
+			blocking := false
+			if send.Configuration == nil {
+				send.Configuration = &MessageSendConfiguration{}
+			}
+			send.Configuration.Blocking = &blocking
+		}
+		result, err := a.doRequest(ctx, agentInfo.Endpoint, "message/send", send)
+		if err != nil {
+			return nil, err
+		}
+		event, err := a.decodeEvent(result)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case event.Task != nil:
+			return event.Task.Result(), nil
+		case event.Message != nil:
+			// Peers may answer simple requests with a message alone
+			return &TaskResult{TaskID: params.TaskID, Status: TaskStateCompleted, StatusMessage: event.Message, CorrelationID: event.Message.ContextID}, nil
+		}
+		return nil, fmt.Errorf("unexpected %s result", result)
+	}
 
-	result, err := a.doRequest(agentInfo.Endpoint, params)
+	taskResult, err := a.negotiate(legacy, spec)
 	if err != nil {
 		return nil, fmt.Errorf("task failed: %w", err)
 	}
-
-	var taskResult TaskResult
-	if err := json.Unmarshal(result, &taskResult); err != nil {
+	if err := a.openOutput(taskResult); err != nil {
 		return nil, err
 	}
-
-	return &taskResult, nil
+	return taskResult, nil
 }
 
-func (a *A2AAgent) doRequest(url string, params interface{}) (json.RawMessage, error) {
-	req := JSONRPCRequest{
-		JSONRPC: "2.0",
-		ID:      generateID(),
-		Method:  "a2a/register",
-		Params:  params,
-	}
-
-	body, err := json.Marshal(req)
+// resolveAgent looks up target agent info in the directory
+func (a *A2AAgent) resolveAgent(targetAgentID, directoryURL string) (*AgentInfo, error) {
+	agentInfo, err := a.directory(directoryURL).Resolve(targetAgentID)
 	if err != nil {
 		return nil, err
 	}
+	return &agentInfo, nil
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+// doRequest sends a JSON-RPC request to an agent's endpoint with the
+// endpoint's transport and returns its result
+func (a *A2AAgent) doRequest(ctx context.Context, endpoint, method string, params interface{}) (json.RawMessage, error) {
+	req, err := a.request(method, params)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if ctx, err = a.identify(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	resp, err := a.transport(endpoint).Send(ctx, endpoint, req)
+	return responseResult(resp, err)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+// doRequest sends a JSON-RPC request over HTTP and returns its result
+func doRequest(client *http.Client, codec Codec, url, method string, params interface{}, header http.Header) (json.RawMessage, error) {
+	t := &HTTPTransport{Client: client, Codec: codec}
+	resp, err := t.send(context.Background(), url, newRequest(method, params), header)
+	return responseResult(resp, err)
+}
+
+// newRequest returns a JSON-RPC request with a new ID
+func newRequest(method string, params interface{}) JSONRPCRequest {
+	return JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      StringID(generateID()),
+		Method:  method,
+		Params:  params,
 	}
+}
 
-	var rpcResp JSONRPCResponse
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+// responseResult returns the result of a response, or its error
+func responseResult(resp *JSONRPCResponse, err error) (json.RawMessage, error) {
+	if err != nil {
 		return nil, err
 	}
-
-	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("RPC error: %s", rpcResp.Error.Message)
+	if resp.Error != nil {
+		return nil, resp.Error
 	}
+	return resp.Result, nil
+}
 
-	return rpcResp.Result, nil
+func (a *A2AAgent) httpClient() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return http.DefaultClient
 }
 
 func generateID() string {
@@ -1,15 +1,31 @@
 package a2a
 
 import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
 // TaskHandler is a function that handles incoming tasks
 type TaskHandler func(action string, input map[string]interface{}, sender string) map[string]interface{}
 
+// TaskFunc is a task handler that can report failure by returning an error.
+// Return an error wrapped with Retryable to have asynchronous tasks retried.
+type TaskFunc func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error)
+
+// defaultWorkers is the number of goroutines executing asynchronous tasks
+const defaultWorkers = 4
+
 // A2AServer is an HTTP server for A2A agents
 type A2AServer struct {
 	AgentID      string
@@ -17,47 +33,206 @@ type A2AServer struct {
 	Capabilities []string
 	Port         int
 	Endpoint     string
-	taskHandler  TaskHandler
+	Description  string
+	Tags         []string                    // published to the directory
+	Metadata     map[string]string           // published to the directory
+	Region       string                      // published to the directory for every agent served here
+	Zone         string                      // published to the directory for every agent served here
+	Schemas      map[string]CapabilitySchema // published to the directory, by capability
+	taskFunc     TaskFunc
+	uploadFunc   UploadFunc
+
+	addr                 string
+	tlsConfig            *tls.Config
+	certFile             string
+	keyFile              string
+	logger               *slog.Logger
+	middleware           []Middleware
+	limits               Limits
+	authSchemes          []string
+	card                 cardConfig
+	legacyPolicy         LegacyPolicy
+	parseMode            ParseMode
+	h2c                  bool
+	rest                 bool
+	directoryCredentials func(agentID string) string
+	directoryURL         string
+	directory            Directory
+	signingKey           ed25519.PrivateKey
+	jws                  *jwsVerifier
+	jwt                  *jwtVerifier
+	didResolver          DIDResolver
+	cardVerification     *CardVerification
+	encryptionKeys       map[string]*ecdh.PrivateKey // by key ID
+	authorizer           Authorizer
+	resultCache          ResultCache
+	resultTTL            time.Duration
+	codec                Codec
+
+	compressionThreshold int
+	httpServer           *http.Server
+	shutDown             bool // set by Shutdown, so a later Serve returns at once
+
+	store         TaskStore
+	paused        pausedTasks
+	running       runningTasks
+	push          pushState
+	idempotent    idempotentRuns
+	poll          pollState
+	queue         *taskQueue
+	startWorkers  sync.Once
+	mu            sync.RWMutex
+	retryPolicies map[string]RetryPolicy
+	hosted        map[string]*HostedAgent
+	hooks         hooks
+	workers       sync.WaitGroup
+	drain         drainState
+	heartbeatOnce sync.Once
+	done          chan struct{} // closed by Shutdown
+	closeOnce     sync.Once
 }
 
 // NewServer creates a new A2A server
 func NewServer(agentID, name string, capabilities []string, port int) *A2AServer {
-	return &A2AServer{
-		AgentID:      agentID,
-		Name:         name,
-		Capabilities: capabilities,
-		Port:         port,
-		Endpoint:     fmt.Sprintf("http://localhost:%d", port),
-	}
+	return NewServerWithOptions(agentID,
+		WithName(name),
+		WithCapabilities(capabilities...),
+		WithAddr(fmt.Sprintf(":%d", port)),
+	)
 }
 
 // HandleTask registers a task handler function
 func (s *A2AServer) HandleTask(handler TaskHandler) {
-	s.taskHandler = handler
+	s.taskFunc = legacyTaskFunc(handler)
+}
+
+// HandleTaskFunc registers a task handler that can return an error
+func (s *A2AServer) HandleTaskFunc(handler TaskFunc) {
+	s.taskFunc = handler
+}
+
+// Handler returns the server's HTTP handler with middleware applied, for
+// mounting in an existing HTTP server
+func (s *A2AServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleRequest)
+	mux.HandleFunc(HealthPath, s.handleHealth)
+	mux.HandleFunc(UploadPath, s.handleUpload)
+	if s.rest {
+		s.handleREST(mux)
+	}
+
+	var handler http.Handler = mux
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+	return handler
 }
 
 // Serve starts the A2A server
 func (s *A2AServer) Serve() error {
-	http.HandleFunc("/", s.handleRequest)
-	fmt.Printf("🤖 Agent '%s' running on port %d\n", s.AgentID, s.Port)
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.Port), nil)
+	httpServer := &http.Server{
+		Addr:      s.addr,
+		Handler:   s.Handler(),
+		TLSConfig: s.tlsConfig,
+		Protocols: s.protocols(),
+	}
+	s.mu.Lock()
+	if s.shutDown {
+		// Shut down before serving, as a transport's Listen may have
+		// been cancelled before Serve ran
+		s.mu.Unlock()
+		return nil
+	}
+	s.httpServer = httpServer
+	s.mu.Unlock()
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.logger.Info(fmt.Sprintf("🤖 Agent '%s' running on %s", s.AgentID, listener.Addr()), "endpoint", s.Endpoint)
+	// Register in the background: directories may call the endpoint back
+	// before answering, to challenge ownership, and the listener only
+	// answers once Serve below starts
+	go func() {
+		if err := s.Publish(); err != nil {
+			s.logger.Error("registering with directory", "directory", s.directoryURL, "error", err)
+		}
+	}()
+	s.runHooks(func(h *hooks) []func() { return h.startup })
+
+	if s.tlsConfig != nil {
+		err = httpServer.ServeTLS(listener, s.certFile, s.keyFile)
+	} else {
+		err = httpServer.Serve(listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting requests and tells the directory the server is
+// offline, waits for in-flight requests and queued asynchronous tasks to
+// finish, then runs the OnShutdown hooks. Tasks waiting
+// for a retry are not resumed. If ctx ends first, Shutdown returns its error
+// and unfinished tasks are abandoned.
+func (s *A2AServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	httpServer := s.httpServer
+	s.shutDown = true
+	s.mu.Unlock()
+	var err error
+	if httpServer != nil {
+		err = httpServer.Shutdown(ctx)
+	}
+
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.reportStatus()
+	})
+	s.queue.close()
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	s.runHooks(func(h *hooks) []func() { return h.shutdown })
+	return err
 }
 
 func (s *A2AServer) handleRequest(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, AgentCardPath) {
+		s.handleAgentCard(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, OpenAPIPath) {
+		s.handleOpenAPI(w, r)
+		return
+	}
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		s.sendError(w, -32700, "Parse error")
-		return
+	if s.limits.MaxRequestBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.limits.MaxRequestBytes)
 	}
 
-	var req JSONRPCRequest
-	if err := json.Unmarshal(body, &req); err != nil {
-		s.sendError(w, -32700, "Parse error")
+	// Decode straight from the body; params stay raw until the method
+	// handler decodes them into their concrete type
+	req, rpcErr := s.readRequest(r.Body)
+	if rpcErr != nil {
+		s.sendError(w, rpcErr)
 		return
 	}
 
@@ -65,77 +240,287 @@ func (s *A2AServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 	resp.JSONRPC = "2.0"
 	resp.ID = req.ID
 
+	ctx := requestCorrelation(w, r)
+	agentID := pathAgent(r)
+	if agentID != "" {
+		if _, ok := s.agentInfo(agentID); !ok {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	if resp.Error = s.checkLegacy(w, r, req.Method); resp.Error != nil {
+		s.writeJSON(w, r, resp)
+		return
+	}
+	if ctx, req.Params, resp.Error = s.openJWS(ctx, req.Method, req.Params); resp.Error != nil {
+		s.writeJSON(w, r, resp)
+		return
+	}
+	if ctx, req.Params, resp.Error = s.openJWT(ctx, bearerJWT(r.Header.Get("Authorization")), agentID, req.Method, req.Params); resp.Error != nil {
+		s.writeJSON(w, r, resp)
+		return
+	}
+
+	if req.Method == "message/stream" {
+		s.streamMessage(ctx, w, r, agentID, req)
+		return
+	}
+	resp.Result, resp.Error = s.dispatch(ctx, agentID, req)
+	s.writeJSON(w, r, resp)
+}
+
+// dispatch calls the handler of a JSON-RPC method other than
+// message/stream, whose events each transport writes its own way
+func (s *A2AServer) dispatch(ctx context.Context, agentID string, req serverRequest) (json.RawMessage, *JSONRPCError) {
 	switch req.Method {
 	case "a2a/task":
-		resp.Result = s.handleTask(req.Params)
+		return s.handleTask(ctx, agentID, req.Params)
+	case "a2a/task/submit":
+		return s.submitTask(ctx, agentID, req.Params)
+	case "message/send":
+		return s.sendMessage(ctx, agentID, req.Params)
+	case "a2a/task/get":
+		return s.getTask(ctx, req.Params)
+	case "tasks/get":
+		return s.getSpecTask(ctx, req.Params)
+	case "tasks/cancel":
+		return s.cancelSpecTask(ctx, req.Params)
+	case "tasks/pushNotificationConfig/set":
+		return s.setPushNotification(ctx, req.Params)
+	case "tasks/pushNotificationConfig/get":
+		return s.getPushNotification(ctx, req.Params)
+	case "tasks/poll":
+		return s.pollTask(ctx, req.Params)
+	case "a2a/verify":
+		return s.handleVerify(agentID, req.Params)
 	case "a2a/discover":
-		// For agent-to-agent discovery, return own info
-		agent := AgentInfo{
-			AgentID:      s.AgentID,
-			Name:         s.Name,
-			Capabilities: s.Capabilities,
-			Endpoint:     s.Endpoint,
+		// For agent-to-agent discovery, return the identities served here
+		agents := s.Agents()
+		if agentID != "" {
+			agent, _ := s.agentInfo(agentID)
+			agents = []AgentInfo{agent}
 		}
 		result := map[string]interface{}{
-			"agents": []AgentInfo{agent},
-		}
-		resp.Result, _ = json.Marshal(result)
-	default:
-		resp.Error = &JSONRPCError{
-			Code:    -32601,
-			Message: "Method not found",
+			"agents": agents,
 		}
+		body, _ := s.codec.Marshal(result)
+		return body, nil
+	}
+	return nil, &JSONRPCError{
+		Code:    CodeMethodNotFound,
+		Message: "Method not found",
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+func (s *A2AServer) handleTask(ctx context.Context, agentID string, params json.RawMessage) (json.RawMessage, *JSONRPCError) {
+	taskParams, rpcErr := s.decodeTask(agentID, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	result, rpcErr := s.runTask(ctx, taskParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return s.marshalResult(result)
 }
 
-func (s *A2AServer) handleTask(params interface{}) json.RawMessage {
-	// Convert params to JSON first
-	paramsJSON, err := json.Marshal(params)
-	if err != nil {
-		return s.errorResult(-32602, "Invalid params")
+// admit continues task if it resumes a paused one, once the Authorizer
+// allows it, and returns ctx carrying its correlation ID. It reports
+// whether the task was resumed. Tasks reusing a stored task's ID are
+// refused unless sent by its owner.
+func (s *A2AServer) admit(ctx context.Context, task *TaskParams) (context.Context, bool, *JSONRPCError) {
+	paused, rpcErr := s.resumes(task)
+	if rpcErr != nil {
+		return ctx, false, rpcErr
+	}
+	if task.TaskID != "" {
+		if current, err := s.store.Get(task.TaskID); err == nil {
+			if rpcErr := s.checkOwner(ctx, current); rpcErr != nil {
+				return ctx, false, rpcErr
+			}
+		}
 	}
+	ctx = correlate(ctx, task)
+	// Results are not returned to senders no longer allowed the action
+	if rpcErr := s.authorize(ctx, *task); rpcErr != nil {
+		return ctx, false, rpcErr
+	}
+	if paused == nil {
+		return ctx, false, nil
+	}
+	paused.resume(task)
+	return ctx, true, nil
+}
 
-	var taskParams TaskParams
-	if err := json.Unmarshal(paramsJSON, &taskParams); err != nil {
-		return s.errorResult(-32602, "Invalid params")
+// runTask executes a task synchronously, or returns the cached result of
+// an earlier one with the same idempotency key, once it has run. Tasks with a ReplyTo are
+// queued instead.
+func (s *A2AServer) runTask(ctx context.Context, taskParams TaskParams) (*TaskResult, *JSONRPCError) {
+	if taskParams.ReplyTo != nil {
+		// The caller will be sent the result rather than wait for it
+		return s.enqueueTask(ctx, taskParams)
+	}
+	ctx, resumed, rpcErr := s.admit(ctx, &taskParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	cached, release, rpcErr := s.claimResult(ctx, taskParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if cached != nil {
+		return cached, nil
 	}
+	// Duplicates sent meanwhile wait for this run's result
+	defer release()
 
-	if s.taskHandler == nil {
-		return s.errorResult(-32001, "No handler registered")
+	if _, err := s.taskFuncFor(taskParams.AgentID); err != nil {
+		return nil, &JSONRPCError{Code: CodeUnsupportedOperation, Message: "No handler registered", Data: rpcData(UnsupportedOperationData{Operation: taskParams.Action})}
 	}
+	if rpcErr := s.checkModes(taskParams); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if rpcErr := s.checkHops(taskParams); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if !s.acquireTask() {
+		return nil, &JSONRPCError{Code: CodeServerUnavailable, Message: "Server draining"}
+	}
+	defer s.releaseTask()
 
-	output := s.taskHandler(taskParams.Action, taskParams.Input, taskParams.Sender)
+	if resumed {
+		working := &TaskResult{TaskID: taskParams.TaskID, Status: TaskStateWorking, CorrelationID: taskParams.CorrelationID, Metadata: taskParams.Metadata}
+		if err := s.saveTask(working); err != nil {
+			if canceled, ok := s.canceledTask(taskParams.TaskID); ok {
+				// Canceled while waiting for input, so cancelTask called
+				// the canceled hooks
+				return canceled, nil
+			}
+			s.logger.Error("saving task state", "taskId", working.TaskID, "correlationId", working.CorrelationID, "error", err)
+		}
+	}
+	if taskParams.pushConfig != nil {
+		// Set only once admitted, but before the task starts
+		s.setPushConfig(taskParams.TaskID, *taskParams.pushConfig)
+	}
+	s.taskReceived(ctx, taskParams)
+	result, _ := s.execute(ctx, taskParams)
+	if resumed {
+		if canceled, ok := s.canceledTask(taskParams.TaskID); ok {
+			// Canceled while running; the handler's outcome is dropped
+			s.taskCanceled(ctx, taskParams, canceled)
+			return canceled, nil
+		}
+	}
+	if result.Status == TaskStateCompleted {
+		s.rememberResult(taskParams, result)
+	}
+	// Interactive tasks are stored so clients can look them up while
+	// they wait for input
+	if resumed || result.Status == TaskStateInputRequired {
+		result.Owner = owner(ctx, taskParams)
+		if err := s.saveTask(result); err != nil {
+			s.logger.Error("saving task state", "taskId", result.TaskID, "correlationId", result.CorrelationID, "error", err)
+		}
+	}
+	s.taskFinished(ctx, taskParams, result)
 
-	result := TaskResult{
-		TaskID: taskParams.TaskID,
-		Status: "completed",
-		Output: output,
+	return result, nil
+}
+
+// decodeTask decodes task params, addressing them to the agent named by
+// the request path if any
+func (s *A2AServer) decodeTask(agentID string, params json.RawMessage) (TaskParams, *JSONRPCError) {
+	taskParams, rpcErr := decodeParams[TaskParams](s, params)
+	if rpcErr != nil {
+		return taskParams, rpcErr
+	}
+	if agentID != "" {
+		taskParams.AgentID = agentID
 	}
+	return taskParams, nil
+}
 
-	response, err := json.Marshal(result)
-	if err != nil {
-		return s.errorResult(-32001, "Task failed")
+// execute runs the handler of the agent a task is addressed to once and
+// builds the task's result. The handler's error, if any, is returned as is.
+func (s *A2AServer) execute(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	ctx = ContextWithCorrelationID(ctx, task.CorrelationID)
+	if task.TaskID != "" {
+		var untrack func()
+		ctx, untrack = s.track(ctx, task.TaskID)
+		defer untrack()
 	}
+	s.logger.Debug("running task", "taskId", task.TaskID, "action", task.Action, "sender", task.Sender, "correlationId", task.CorrelationID)
 
-	return response
+	var output map[string]interface{}
+	ctx, artifacts := withArtifacts(ctx)
+	ctx, metadata := withMetadata(ctx, task)
+	handlerCtx, input := withMessage(withHistory(s.withTask(ctx, task), task), task)
+	input, reply, err := s.openInput(input)
+	if err == nil {
+		var taskFunc TaskFunc
+		if taskFunc, err = s.taskFuncFor(task.AgentID); err == nil {
+			output, err = taskFunc(handlerCtx, task.Action, input, task.Sender)
+		}
+	}
+	if err == nil && reply != nil && output != nil {
+		// Answer in kind, to the key the sender named
+		output, err = sealJWE(output, *reply, nil)
+	}
+
+	result := &TaskResult{
+		TaskID:        task.TaskID,
+		Status:        TaskStateCompleted,
+		Output:        output,
+		Artifacts:     artifacts.list(),
+		UpdatedAt:     time.Now().UTC(),
+		CorrelationID: task.CorrelationID,
+		Metadata:      metadata.merged(),
+	}
+	prompt, paused := inputRequired(err)
+	switch {
+	case paused:
+		result.Status = TaskStateInputRequired
+		result.StatusMessage = &prompt
+		result.Output = nil
+		s.pause(task, input, prompt)
+		s.logger.Debug("task waiting for input", "taskId", task.TaskID, "correlationId", task.CorrelationID, "prompt", prompt.Text())
+	case err != nil:
+		result.Status = TaskStateFailed
+		result.Output = nil
+		result.Error = taskFailure(err)
+		s.logger.Debug("task failed", "taskId", task.TaskID, "correlationId", task.CorrelationID, "error", err)
+	}
+	if !paused {
+		s.unpause(task.TaskID)
+	}
+
+	return result, err
 }
 
-func (s *A2AServer) errorResult(code int, message string) json.RawMessage {
-	err := &JSONRPCError{Code: code, Message: message}
-	resp := map[string]interface{}{
-		"error": err,
+// serverRequest is a JSON-RPC request as decoded by the server
+type serverRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      RequestID       `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// marshalResult encodes a method result, reporting encoding failures as
+// internal errors
+func (s *A2AServer) marshalResult(v interface{}) (json.RawMessage, *JSONRPCError) {
+	result, err := s.codec.Marshal(v)
+	if err != nil {
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: "Internal error"}
 	}
-	result, _ := json.Marshal(resp)
-	return result
+	return result, nil
 }
 
-func (s *A2AServer) sendError(w http.ResponseWriter, code int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	err := &JSONRPCError{Code: code, Message: message}
-	json.NewEncoder(w).Encode(err)
+func (s *A2AServer) sendError(w http.ResponseWriter, err *JSONRPCError) {
+	w.Header().Set("Content-Type", s.codec.ContentType())
+	body, _ := s.codec.Marshal(err)
+	w.Write(body)
 }
 
 // RunServer is a convenience function to run a simple agent server
@@ -0,0 +1,10 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/serverless/lambda
+
+go 1.24
+
+require (
+	github.com/aws/aws-lambda-go v1.47.0
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
@@ -0,0 +1,171 @@
+// Package a2alambda runs an A2A server as an AWS Lambda function behind a
+// function URL or API Gateway.
+//
+//	func main() {
+//		server := a2a.NewServerWithOptions("summarizer",
+//			a2a.WithCapabilities("summarize"),
+//			a2a.WithEndpoint(os.Getenv("FUNCTION_URL")))
+//		server.HandleTask(summarize)
+//		a2alambda.Start(server)
+//	}
+//
+// Each invocation is one HTTP request to the server's handler, so the
+// JSON-RPC endpoint, Agent Card and health check are served as
+// A2AServer.Handler serves them. Lambda freezes the function once it
+// returns, so the handlers return only after the server's asynchronous
+// tasks finish, and responses are buffered: a message/stream answer
+// arrives as all of its events at once. The serverless package covers
+// keeping task state and directory registrations across instances.
+package a2alambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Start runs Handler as the function's handler, for function URLs and
+// API Gateway HTTP APIs. It does not return.
+func Start(server *a2a.A2AServer) {
+	lambda.Start(Handler(server))
+}
+
+// Handler returns a Lambda handler for requests in payload format 2.0, as
+// function URLs and API Gateway HTTP APIs send them
+func Handler(server *a2a.A2AServer) func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	handler := server.Handler()
+	return func(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		header := make(http.Header, len(event.Headers))
+		for name, value := range event.Headers {
+			// Payload 2.0 joins repeated headers with commas
+			header.Set(name, value)
+		}
+		if len(event.Cookies) > 0 {
+			header.Set("Cookie", strings.Join(event.Cookies, "; "))
+		}
+		r, err := newRequest(ctx, event.RequestContext.HTTP.Method, event.RequestContext.DomainName, event.RawPath, event.RawQueryString, header, event.Body, event.IsBase64Encoded)
+		if err != nil {
+			return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusBadRequest, Body: "Invalid request body"}, nil
+		}
+		r.RemoteAddr = event.RequestContext.HTTP.SourceIP
+
+		w := serve(server, handler, r)
+		resp := events.APIGatewayV2HTTPResponse{StatusCode: w.status, Headers: make(map[string]string, len(w.header)), Cookies: w.header.Values("Set-Cookie")}
+		w.header.Del("Set-Cookie")
+		for name, values := range w.header {
+			resp.Headers[name] = strings.Join(values, ", ")
+		}
+		resp.Body, resp.IsBase64Encoded = w.encodeBody()
+		return resp, nil
+	}
+}
+
+// ProxyHandler returns a Lambda handler for requests in payload format
+// 1.0, as API Gateway REST APIs send them. Responses that are not text,
+// such as gzipped ones, come back base64-encoded, which the API only
+// decodes when its binary media types include their content type or */*.
+func ProxyHandler(server *a2a.A2AServer) func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	handler := server.Handler()
+	return func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		header := make(http.Header, len(event.MultiValueHeaders))
+		for name, values := range event.MultiValueHeaders {
+			for _, value := range values {
+				header.Add(name, value)
+			}
+		}
+		for name, value := range event.Headers {
+			if header.Get(name) == "" {
+				header.Set(name, value)
+			}
+		}
+		query := url.Values(event.MultiValueQueryStringParameters)
+		for name, value := range event.QueryStringParameters {
+			if !query.Has(name) {
+				query.Set(name, value)
+			}
+		}
+		r, err := newRequest(ctx, event.HTTPMethod, event.RequestContext.DomainName, event.Path, query.Encode(), header, event.Body, event.IsBase64Encoded)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest, Body: "Invalid request body"}, nil
+		}
+		r.RemoteAddr = event.RequestContext.Identity.SourceIP
+
+		w := serve(server, handler, r)
+		resp := events.APIGatewayProxyResponse{StatusCode: w.status, MultiValueHeaders: w.header}
+		resp.Body, resp.IsBase64Encoded = w.encodeBody()
+		return resp, nil
+	}
+}
+
+// newRequest builds the HTTP request an event describes
+func newRequest(ctx context.Context, method, host, path, rawQuery string, header http.Header, body string, base64Encoded bool) (*http.Request, error) {
+	data := []byte(body)
+	if base64Encoded {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(body); err != nil {
+			return nil, err
+		}
+	}
+	target := &url.URL{Path: path, RawQuery: rawQuery}
+	r, err := http.NewRequestWithContext(ctx, method, target.RequestURI(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	r.Header = header
+	r.Host = host
+	r.RequestURI = target.RequestURI()
+	return r, nil
+}
+
+// serve handles r, then waits until the server has no asynchronous tasks
+// left or the invocation's deadline passes
+func serve(server *a2a.A2AServer, handler http.Handler, r *http.Request) *response {
+	w := &response{header: make(http.Header)}
+	handler.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	server.Idle(r.Context())
+	return w
+}
+
+// response buffers a handler's response for returning from the function
+type response struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *response) Header() http.Header { return w.header }
+
+func (w *response) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *response) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// Flush lets streaming handlers run; what they flush is sent when the
+// function returns
+func (w *response) Flush() {}
+
+// encodeBody returns the body as text, or base64-encoded if it is encoded
+// or not UTF-8
+func (w *response) encodeBody() (string, bool) {
+	if w.header.Get("Content-Encoding") == "" && utf8.Valid(w.body.Bytes()) {
+		return w.body.String(), false
+	}
+	return base64.StdEncoding.EncodeToString(w.body.Bytes()), true
+}
@@ -0,0 +1,113 @@
+// Package serverless runs an A2A server on platforms that scale it to zero:
+// Google Cloud Functions and Cloud Run here, and AWS Lambda with the
+// a2alambda module in the lambda directory.
+//
+// A Cloud Functions entrypoint, with the Functions Framework:
+//
+//	var server = a2a.NewServerWithOptions("summarizer",
+//		a2a.WithCapabilities("summarize"),
+//		a2a.WithEndpoint("https://summarizer-abc123-uc.a.run.app"))
+//
+//	func init() {
+//		server.HandleTask(summarize)
+//		functions.HTTP("Agent", serverless.Handler(server).ServeHTTP)
+//	}
+//
+// A Cloud Run service:
+//
+//	log.Fatal(serverless.ListenAndServe(server))
+//
+// The JSON-RPC endpoint, Agent Card and health check are served as
+// A2AServer.Handler serves them. Set the server's endpoint (see
+// a2a.WithEndpoint) to the service's public URL so the Agent Card points at
+// it.
+//
+// These platforms only give an instance CPU while it handles a request, and
+// may stop it once idle, so:
+//   - Handler keeps each request open until the server's asynchronous tasks
+//     finish (see A2AServer.Idle), after sending the response
+//   - task state kept in memory is lost with the instance; give the server
+//     a shared TaskStore so any instance can answer tasks/get
+//   - an instance cannot heartbeat its directory registration; register
+//     the service URL from deploy tooling, without a TTL
+package serverless
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// ShutdownGrace is how long ListenAndServe lets requests and tasks finish
+// once told to stop; Cloud Run kills an instance 10 seconds after SIGTERM
+const ShutdownGrace = 9 * time.Second
+
+// Option configures Handler
+type Option func(*config)
+
+type config struct {
+	wait bool
+}
+
+// WithoutTaskWait answers requests without waiting for asynchronous tasks,
+// for instances that keep their CPU between requests (Cloud Run with CPU
+// always allocated)
+func WithoutTaskWait() Option {
+	return func(c *config) { c.wait = false }
+}
+
+// Handler returns server's HTTP handler for a serverless platform. After
+// each request it flushes the response and waits until the server has no
+// asynchronous tasks left, or the request's context ends.
+func Handler(server *a2a.A2AServer, opts ...Option) http.Handler {
+	c := config{wait: true}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	handler := server.Handler()
+	if !c.wait {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		server.Idle(r.Context())
+	})
+}
+
+// ListenAndServe serves Handler on the port in $PORT, 8080 if unset, until
+// the process gets SIGTERM or SIGINT. It then stops taking requests and
+// shuts the server down, giving it ShutdownGrace to finish.
+func ListenAndServe(server *a2a.A2AServer, opts ...Option) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:    ":" + cmp.Or(os.Getenv("PORT"), "8080"),
+		Handler: Handler(server, opts...),
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- httpServer.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownGrace)
+	defer cancel()
+	err := httpServer.Shutdown(shutdownCtx)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return errors.Join(err, server.Shutdown(shutdownCtx))
+}
@@ -0,0 +1,80 @@
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// ExitTempFail is the exit status (EX_TEMPFAIL) with which a command run
+// by ExecBackend fails a task as retryable
+const ExitTempFail = 75
+
+// ExecBackend hands each task to a new run of a command, with the task's
+// input as JSON on stdin and its action, sender and correlation ID in the
+// A2A_ACTION, A2A_SENDER and A2A_CORRELATION_ID environment variables.
+// What the command writes to stdout is the task's output, read as
+// HTTPBackend reads answers. Exiting with another status than 0 fails the
+// task with the end of stderr as the message, as retryable if the status
+// is ExitTempFail.
+type ExecBackend struct {
+	Command []string // the program and its arguments
+	Dir     string   // the working directory; empty means the sidecar's
+	Env     []string // added to the sidecar's environment, as "KEY=value"
+}
+
+// Handle is an a2a.TaskFunc running the command for the task
+func (b *ExecBackend) Handle(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+	if len(b.Command) == 0 {
+		return nil, errors.New("backend: no command")
+	}
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, b.Command[0], b.Command[1:]...)
+	cmd.Dir = b.Dir
+	cmd.Env = append(os.Environ(), b.Env...)
+	cmd.Env = append(cmd.Env, "A2A_ACTION="+action, "A2A_SENDER="+sender, "A2A_CORRELATION_ID="+a2a.CorrelationID(ctx))
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.As(err, &exitErr):
+		message := excerptTail(stderr.Bytes())
+		if message == "" {
+			message = exitErr.Error()
+		}
+		return nil, &a2a.TaskError{
+			Code:      a2a.CodeTaskFailed,
+			Message:   fmt.Sprintf("backend: %s", message),
+			Data:      map[string]interface{}{"exitCode": exitErr.ExitCode()},
+			Retryable: exitErr.ExitCode() == ExitTempFail,
+		}
+	case err != nil:
+		return nil, fmt.Errorf("backend: %w", err)
+	}
+	return decodeOutput(stdout.Bytes()), nil
+}
+
+// excerptTail returns the end of a command's stderr, where the reason it
+// failed usually is
+func excerptTail(stderr []byte) string {
+	const max = 512
+	stderr = bytes.TrimSpace(stderr)
+	if len(stderr) > max {
+		return "…" + string(stderr[len(stderr)-max:])
+	}
+	return string(stderr)
+}
@@ -0,0 +1,117 @@
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// HTTPBackend hands tasks to a service over HTTP. Each task is a POST of
+// its input as JSON, with its action, sender and correlation ID in
+// headers; a 2xx answer is the task's output, a JSON object as it is,
+// other JSON as "result" and other bodies as "text". Other answers fail
+// the task; connection errors, 502, 503 and 504 fail it as retryable, so
+// the server's retry policy re-runs asynchronous tasks.
+type HTTPBackend struct {
+	// URL is where tasks are posted; "{action}" in it is replaced by the
+	// task's action, e.g. "http://localhost:8000/{action}"
+	URL    string
+	Client *http.Client // nil means http.DefaultClient
+	// Retry re-sends a task that failed with a connection error, 502, 503
+	// or 504 before failing it, so a restarting service is waited for
+	Retry a2a.RetryPolicy
+}
+
+func (b *HTTPBackend) client() *http.Client {
+	if b.Client != nil {
+		return b.Client
+	}
+	return http.DefaultClient
+}
+
+// Handle is an a2a.TaskFunc posting the task to the backend
+func (b *HTTPBackend) Handle(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	target := strings.ReplaceAll(b.URL, "{action}", url.PathEscape(action))
+
+	for attempt := 1; ; attempt++ {
+		output, err := b.post(ctx, target, body, action, sender)
+		if !a2a.IsRetryable(err) || attempt >= b.Retry.MaxAttempts {
+			return output, err
+		}
+		select {
+		case <-time.After(backoff(b.Retry, attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// post sends one attempt at a task. Failures worth retrying are marked
+// with a2a.Retryable.
+func (b *HTTPBackend) post(ctx context.Context, target string, body []byte, action, sender string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(ActionHeader, action)
+	if sender != "" {
+		req.Header.Set(SenderHeader, sender)
+	}
+	if id := a2a.CorrelationID(ctx); id != "" {
+		req.Header.Set(a2a.CorrelationHeader, id)
+	}
+
+	var respBody []byte
+	resp, err := b.client().Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+		respBody, err = io.ReadAll(resp.Body)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, a2a.Retryable(fmt.Errorf("backend: %w", err))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retryable := false
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			retryable = true
+		}
+		return nil, &a2a.TaskError{
+			Code:      a2a.CodeTaskFailed,
+			Message:   fmt.Sprintf("backend: HTTP %d: %s", resp.StatusCode, excerpt(respBody)),
+			Data:      map[string]interface{}{"status": resp.StatusCode},
+			Retryable: retryable,
+		}
+	}
+	return decodeOutput(respBody), nil
+}
+
+// backoff returns how long to wait after the given attempt, as
+// a2a.RetryPolicy describes
+func backoff(policy a2a.RetryPolicy, attempt int) time.Duration {
+	delay := float64(policy.InitialBackoff)
+	for range attempt - 1 {
+		delay *= max(policy.Multiplier, 1)
+	}
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		return policy.MaxBackoff
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,140 @@
+package sidecar
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// MetricsPath is where Metrics.Middleware serves metrics
+const MetricsPath = "/metrics"
+
+// durationBuckets are the upper bounds, in seconds, of the task duration
+// histogram
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics counts the tasks a handler wrapped with Wrap runs, by action and
+// outcome, and how long they take, and serves them in the Prometheus text
+// format. The zero value is ready to use.
+type Metrics struct {
+	mu       sync.Mutex
+	tasks    map[taskKey]uint64
+	duration map[string]*histogram // by action
+}
+
+type taskKey struct {
+	action, outcome string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// Wrap returns handler recording each task it runs. Outcomes are
+// "completed", "input_required", "retryable" and "failed".
+func (m *Metrics) Wrap(handler a2a.TaskFunc) a2a.TaskFunc {
+	return func(ctx context.Context, action string, input map[string]interface{}, sender string) (map[string]interface{}, error) {
+		start := time.Now()
+		output, err := handler(ctx, action, input, sender)
+		var required *a2a.InputRequiredError
+		outcome := "completed"
+		switch {
+		case errors.As(err, &required):
+			outcome = "input_required"
+		case a2a.IsRetryable(err):
+			outcome = "retryable"
+		case err != nil:
+			outcome = "failed"
+		}
+		m.record(action, outcome, time.Since(start))
+		return output, err
+	}
+}
+
+func (m *Metrics) record(action, outcome string, elapsed time.Duration) {
+	seconds := elapsed.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tasks == nil {
+		m.tasks = make(map[taskKey]uint64)
+		m.duration = make(map[string]*histogram)
+	}
+	m.tasks[taskKey{action, outcome}]++
+	h := m.duration[action]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(durationBuckets)+1)}
+		m.duration[action] = h
+	}
+	i, _ := slices.BinarySearch(durationBuckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// Middleware serves the metrics at MetricsPath, passing other requests on.
+// Put it after RequireToken to require a token for them too.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != MetricsPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.write(w)
+	})
+}
+
+func (m *Metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metric(w, "a2a_sidecar_tasks_total", "counter", "Tasks handed to the backend by action and outcome.")
+	keys := make([]taskKey, 0, len(m.tasks))
+	for key := range m.tasks {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b taskKey) int {
+		return cmp.Or(cmp.Compare(a.action, b.action), cmp.Compare(a.outcome, b.outcome))
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "a2a_sidecar_tasks_total{action=%q,outcome=%q} %d\n", key.action, key.outcome, m.tasks[key])
+	}
+
+	metric(w, "a2a_sidecar_task_duration_seconds", "histogram", "Time the backend took to run tasks, by action.")
+	actions := make([]string, 0, len(m.duration))
+	for action := range m.duration {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+	for _, action := range actions {
+		h := m.duration[action]
+		var cumulative uint64
+		for i, bound := range durationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "a2a_sidecar_task_duration_seconds_bucket{action=%q,le=%q} %d\n", action, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "a2a_sidecar_task_duration_seconds_bucket{action=%q,le=\"+Inf\"} %d\n", action, h.count)
+		fmt.Fprintf(w, "a2a_sidecar_task_duration_seconds_sum{action=%q} %g\n", action, h.sum)
+		fmt.Fprintf(w, "a2a_sidecar_task_duration_seconds_count{action=%q} %d\n", action, h.count)
+	}
+}
+
+// metric writes the HELP and TYPE lines of a metric family
+func metric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
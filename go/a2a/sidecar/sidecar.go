@@ -0,0 +1,89 @@
+// Package sidecar lets a service that does not speak A2A take tasks. A
+// sidecar next to it runs an A2A server, which handles authentication,
+// TLS, retries and metrics, and hands each task to the service over
+// localhost HTTP or by running a command:
+//
+//	backend := &sidecar.HTTPBackend{URL: "http://localhost:8000/{action}"}
+//	metrics := new(sidecar.Metrics)
+//	server := a2a.NewServerWithOptions("invoices",
+//	    a2a.WithCapabilities("create", "void"),
+//	    a2a.WithMiddleware(sidecar.RequireToken(token), metrics.Middleware),
+//	)
+//	server.HandleTaskFunc(metrics.Wrap(backend.Handle))
+//
+// cmd/a2a-sidecar does the same from flags, so the service needs neither
+// code changes nor a Go build.
+package sidecar
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Headers carrying a task's details to an HTTP backend, along with
+// a2a.CorrelationHeader
+const (
+	ActionHeader = "A2A-Action"
+	SenderHeader = "A2A-Sender"
+)
+
+// RequireToken returns middleware rejecting requests without one of the
+// bearer tokens with HTTP 401. The health check and Agent Cards stay
+// public, so orchestrators and callers can still find the agent.
+func RequireToken(tokens ...string) a2a.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == a2a.HealthPath || strings.Contains(r.URL.Path, "/.well-known/") || validToken(r, tokens) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
+
+func validToken(r *http.Request, tokens []string) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	for _, want := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeOutput turns what a backend answered into a task's output: a JSON
+// object as it is, any other JSON value as "result", and anything else as
+// "text"
+func decodeOutput(body []byte) map[string]interface{} {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return map[string]interface{}{}
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return map[string]interface{}{"text": string(body)}
+	}
+	if output, ok := value.(map[string]interface{}); ok {
+		return output
+	}
+	return map[string]interface{}{"result": value}
+}
+
+// excerpt returns the start of a backend's error output, for error
+// messages
+func excerpt(body []byte) string {
+	const max = 512
+	s := strings.TrimSpace(string(body))
+	if len(s) > max {
+		s = s[:max] + "…"
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package spiffe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileSource is a Source reading PEM files, as spiffe-helper writes them
+// from a SPIRE agent. The files are read again when they change, so
+// rotated SVIDs are picked up without a restart.
+type FileSource struct {
+	CertFile   string // the SVID, followed by its intermediates
+	KeyFile    string // the SVID's private key
+	BundleFile string // the roots of the trust domains to trust
+
+	mu       sync.Mutex
+	modified [3]time.Time
+	svid     *tls.Certificate
+	bundle   []*x509.Certificate
+}
+
+// X509SVID returns the certificate in CertFile and KeyFile
+func (s *FileSource) X509SVID() (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s.svid, nil
+}
+
+// X509Bundle returns the certificates in BundleFile, for every trust
+// domain
+func (s *FileSource) X509Bundle(string) ([]*x509.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s.bundle, nil
+}
+
+// reload reads the files again if any changed since they were last read.
+// A file rewritten halfway leaves the previous SVID in use until the next
+// handshake.
+func (s *FileSource) reload() error {
+	var modified [3]time.Time
+	for i, name := range []string{s.CertFile, s.KeyFile, s.BundleFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return fmt.Errorf("spiffe: %w", err)
+		}
+		modified[i] = info.ModTime()
+	}
+	if s.svid != nil && modified == s.modified {
+		return nil
+	}
+
+	svid, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return s.stale(fmt.Errorf("spiffe: loading SVID: %w", err))
+	}
+	bundlePEM, err := os.ReadFile(s.BundleFile)
+	if err != nil {
+		return s.stale(fmt.Errorf("spiffe: %w", err))
+	}
+	var bundle []*x509.Certificate
+	for block, rest := pem.Decode(bundlePEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return s.stale(fmt.Errorf("spiffe: parsing %s: %w", s.BundleFile, err))
+		}
+		bundle = append(bundle, cert)
+	}
+	if len(bundle) == 0 {
+		return s.stale(fmt.Errorf("spiffe: no certificates in %s", s.BundleFile))
+	}
+	s.svid, s.bundle, s.modified = &svid, bundle, modified
+	return nil
+}
+
+// stale keeps serving what was last read when reading the files again
+// fails, returning err only if nothing was ever read
+func (s *FileSource) stale(err error) error {
+	if s.svid != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,243 @@
+// Package spiffe makes an agent's identity its SPIFFE workload identity.
+// Servers and clients authenticate each other with X.509 SVIDs over mTLS:
+// each side checks the other's certificate against its trust domain's
+// bundle and its SPIFFE ID against an Authorizer, and both take their
+// certificate from a Source on every handshake, so rotated SVIDs are used
+// as soon as they are issued.
+//
+//	source := &spiffe.FileSource{CertFile: "svid.pem", KeyFile: "svid_key.pem", BundleFile: "bundle.pem"}
+//	billing := spiffe.RequireID("spiffe://example.org/agent/billing")
+//
+//	server := a2a.NewServerWithOptions("ledger",
+//	    a2a.WithTLS(spiffe.ServerTLSConfig(source, billing), "", ""),
+//	    a2a.WithMiddleware(spiffe.Middleware),
+//	)
+//	agent.HTTPClient = spiffe.NewHTTPClient(source, spiffe.RequireMemberOf("example.org"))
+//
+// The a2a/spiffe/workload module provides a Source fed by the SPIFFE
+// Workload API, as a SPIRE agent serves it.
+package spiffe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ID is a SPIFFE ID, e.g. spiffe://example.org/agent/billing
+type ID struct {
+	TrustDomain string // e.g. "example.org"
+	Path        string // e.g. "/agent/billing"; empty for the trust domain itself
+}
+
+// ParseID parses a SPIFFE ID, rejecting those the SPIFFE specification
+// does not allow
+func ParseID(s string) (ID, error) {
+	u, err := url.Parse(s)
+	switch {
+	case err != nil:
+		return ID{}, fmt.Errorf("spiffe: invalid ID %q: %w", s, err)
+	case u.Scheme != "spiffe":
+		return ID{}, fmt.Errorf("spiffe: invalid ID %q: scheme is not spiffe", s)
+	case u.User != nil || u.Port() != "" || u.RawQuery != "" || u.Fragment != "" || u.Opaque != "":
+		return ID{}, fmt.Errorf("spiffe: invalid ID %q: only a trust domain and path are allowed", s)
+	case u.Host == "" || strings.Trim(u.Host, "abcdefghijklmnopqrstuvwxyz0123456789.-_") != "":
+		return ID{}, fmt.Errorf("spiffe: invalid ID %q: bad trust domain", s)
+	}
+	if u.Path != "" {
+		for _, segment := range strings.Split(u.Path[1:], "/") {
+			if segment == "" || segment == "." || segment == ".." ||
+				strings.Trim(segment, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789.-_") != "" {
+				return ID{}, fmt.Errorf("spiffe: invalid ID %q: bad path segment %q", s, segment)
+			}
+		}
+	}
+	return ID{TrustDomain: u.Host, Path: u.Path}, nil
+}
+
+// String returns the ID as a URI
+func (id ID) String() string {
+	return "spiffe://" + id.TrustDomain + id.Path
+}
+
+// IDFromCertificate returns the SPIFFE ID of an X.509 SVID, its one URI
+// SAN
+func IDFromCertificate(cert *x509.Certificate) (ID, error) {
+	if len(cert.URIs) != 1 {
+		return ID{}, fmt.Errorf("spiffe: certificate has %d URI SANs, not one SPIFFE ID", len(cert.URIs))
+	}
+	return ParseID(cert.URIs[0].String())
+}
+
+// Source provides the workload's current SVID and the bundles of the trust
+// domains it trusts. Both may change at any time, e.g. as SVIDs rotate.
+type Source interface {
+	// X509SVID returns the SVID, with its intermediates after it
+	X509SVID() (*tls.Certificate, error)
+	// X509Bundle returns the root certificates of trustDomain
+	X509Bundle(trustDomain string) ([]*x509.Certificate, error)
+}
+
+// ErrUnauthorized is returned by Authorizers refusing a peer
+var ErrUnauthorized = errors.New("spiffe: peer not authorized")
+
+// Authorizer decides whether a peer with a verified SPIFFE ID may talk to
+// this workload
+type Authorizer func(id ID) error
+
+// RequireID authorizes the peers with one of ids
+func RequireID(ids ...string) Authorizer {
+	return func(id ID) error {
+		for _, allowed := range ids {
+			if id.String() == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrUnauthorized, id)
+	}
+}
+
+// RequireMemberOf authorizes the peers in one of trustDomains
+func RequireMemberOf(trustDomains ...string) Authorizer {
+	return func(id ID) error {
+		for _, trustDomain := range trustDomains {
+			if id.TrustDomain == trustDomain {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrUnauthorized, id)
+	}
+}
+
+// AuthorizeAny authorizes every peer with a valid SVID
+func AuthorizeAny() Authorizer {
+	return func(ID) error { return nil }
+}
+
+// ServerTLSConfig returns the TLS configuration of a server presenting
+// source's SVID and requiring clients to present SVIDs that authorize
+// accepts
+func ServerTLSConfig(source Source, authorize Authorizer) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return source.X509SVID()
+		},
+		VerifyPeerCertificate: verifyPeer(source, authorize),
+	}
+}
+
+// ClientTLSConfig returns the TLS configuration of a client presenting
+// source's SVID and requiring servers to present SVIDs that authorize
+// accepts. Servers are identified by their SPIFFE ID rather than their
+// host name.
+func ClientTLSConfig(source Source, authorize Authorizer) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// verifyPeer checks the server's SVID; host names are not in it
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return source.X509SVID()
+		},
+		VerifyPeerCertificate: verifyPeer(source, authorize),
+	}
+}
+
+// NewHTTPClient returns an HTTP client talking mTLS with ClientTLSConfig,
+// e.g. for A2AAgent.HTTPClient
+func NewHTTPClient(source Source, authorize Authorizer) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = ClientTLSConfig(source, authorize)
+	return &http.Client{Transport: transport}
+}
+
+// verifyPeer returns a tls.Config.VerifyPeerCertificate checking that the
+// peer's certificate is an SVID of a trust domain source has the bundle
+// of, and that authorize accepts its ID
+func verifyPeer(source Source, authorize Authorizer) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("spiffe: peer presented no certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("spiffe: peer certificate: %w", err)
+			}
+			certs[i] = cert
+		}
+		id, err := verifySVID(source, certs)
+		if err != nil {
+			return err
+		}
+		return authorize(id)
+	}
+}
+
+// verifySVID verifies an X.509 SVID chain, leaf first, and returns its ID
+func verifySVID(source Source, certs []*x509.Certificate) (ID, error) {
+	leaf := certs[0]
+	id, err := IDFromCertificate(leaf)
+	if err != nil {
+		return ID{}, err
+	}
+	if leaf.IsCA || leaf.KeyUsage&(x509.KeyUsageCertSign|x509.KeyUsageCRLSign) != 0 {
+		return ID{}, errors.New("spiffe: peer certificate is a CA certificate, not an SVID")
+	}
+	bundle, err := source.X509Bundle(id.TrustDomain)
+	if err != nil {
+		return ID{}, fmt.Errorf("spiffe: no bundle for trust domain %s: %w", id.TrustDomain, err)
+	}
+	opts := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, root := range bundle {
+		opts.Roots.AddCert(root)
+	}
+	for _, intermediate := range certs[1:] {
+		opts.Intermediates.AddCert(intermediate)
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return ID{}, fmt.Errorf("spiffe: verifying %s: %w", id, err)
+	}
+	return id, nil
+}
+
+// PeerID returns the SPIFFE ID of the client that sent r over mTLS, as the
+// server's TLS configuration verified it
+func PeerID(r *http.Request) (ID, bool) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return ID{}, false
+	}
+	id, err := IDFromCertificate(r.TLS.PeerCertificates[0])
+	return id, err == nil
+}
+
+type peerKey struct{}
+
+// Middleware makes the client's SPIFFE ID available to handlers of the
+// tasks its requests run, with PeerIDFromContext
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := PeerID(r); ok {
+			r = r.WithContext(context.WithValue(r.Context(), peerKey{}, id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// PeerIDFromContext returns the SPIFFE ID Middleware found for the request
+// running a task. Tasks queued with a2a/task/submit do not have it.
+func PeerIDFromContext(ctx context.Context) (ID, bool) {
+	id, ok := ctx.Value(peerKey{}).(ID)
+	return id, ok
+}
@@ -0,0 +1,23 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/spiffe/workload
+
+go 1.24
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/spiffe/go-spiffe/v2 v2.5.0
+)
+
+require (
+	github.com/Microsoft/go-winio v0.6.2 // indirect
+	github.com/go-jose/go-jose/v4 v4.0.4 // indirect
+	github.com/zeebo/errs v1.4.0 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+	google.golang.org/grpc v1.70.0 // indirect
+	google.golang.org/protobuf v1.36.1 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
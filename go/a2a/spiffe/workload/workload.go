@@ -0,0 +1,75 @@
+// Package a2aworkload provides a spiffe.Source fed by the SPIFFE Workload
+// API, as a SPIRE agent serves it on a local socket. The agent pushes
+// rotated SVIDs and bundles to the source before the old ones expire, and
+// the next handshake uses them.
+//
+//	source, err := a2aworkload.NewSource(ctx, "unix:///run/spire/agent.sock")
+//	defer source.Close()
+//	server := a2a.NewServerWithOptions("ledger",
+//	    a2a.WithTLS(spiffe.ServerTLSConfig(source, spiffe.RequireMemberOf("example.org")), "", ""))
+package a2aworkload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/spiffe"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// Source is a spiffe.Source kept up to date by the Workload API
+type Source struct {
+	x509 *workloadapi.X509Source
+}
+
+var _ spiffe.Source = (*Source)(nil)
+
+// NewSource connects to the Workload API at addr, e.g.
+// "unix:///run/spire/agent.sock", and waits for the workload's first SVID.
+// An empty addr means $SPIFFE_ENDPOINT_SOCKET.
+func NewSource(ctx context.Context, addr string) (*Source, error) {
+	var opts []workloadapi.X509SourceOption
+	if addr != "" {
+		opts = append(opts, workloadapi.WithClientOptions(workloadapi.WithAddr(addr)))
+	}
+	source, err := workloadapi.NewX509Source(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("spiffe: workload API: %w", err)
+	}
+	return &Source{x509: source}, nil
+}
+
+// X509SVID returns the workload's current SVID
+func (s *Source) X509SVID() (*tls.Certificate, error) {
+	svid, err := s.x509.GetX509SVID()
+	if err != nil {
+		return nil, err
+	}
+	cert := &tls.Certificate{PrivateKey: svid.PrivateKey, Leaf: svid.Certificates[0]}
+	for _, c := range svid.Certificates {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	return cert, nil
+}
+
+// X509Bundle returns the current roots of trustDomain, which may be a
+// federated one
+func (s *Source) X509Bundle(trustDomain string) ([]*x509.Certificate, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := s.x509.GetX509BundleForTrustDomain(td)
+	if err != nil {
+		return nil, err
+	}
+	return bundle.X509Authorities(), nil
+}
+
+// Close disconnects from the Workload API
+func (s *Source) Close() error {
+	return s.x509.Close()
+}
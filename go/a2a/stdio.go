@@ -0,0 +1,355 @@
+package a2a
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// ServeStdio serves JSON-RPC requests read from r, one per line, writing
+// each response to w as one line: the framing MCP servers use, so an
+// orchestrator can run agents as subprocesses without any networking.
+//
+//	server.ServeStdio(ctx, os.Stdin, os.Stdout)
+//
+// Requests run concurrently, so responses can come out of order; match
+// them by ID. message/stream answers with one response line per event.
+// HTTP middleware does not apply, and nothing else may write to w, so log
+// to stderr. ServeStdio returns nil when r ends, once every request read
+// has been answered, or the error that stopped it.
+func (s *A2AServer) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	out := &lineWriter{w: w, codec: s.codec}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleJSONRPC(ctx, "", line, func(resp *JSONRPCResponse) { out.write(resp) }, "transport", "stdio")
+			}()
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// lineWriter writes messages one per line, one at a time
+type lineWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	codec Codec
+}
+
+func (w *lineWriter) write(v interface{}) error {
+	body, err := w.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(append(body, '\n'))
+	return err
+}
+
+// errStdioClosed fails calls once the agent's output has ended
+var errStdioClosed = errors.New("stdio agent closed")
+
+// StdioClient sends tasks to an agent served with ServeStdio, over the
+// agent's stdin and stdout. Calls may be made concurrently.
+type StdioClient struct {
+	out   *lineWriter
+	codec Codec
+	w     io.Writer
+	cmd   *exec.Cmd
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[string]*stdioCall
+	err     error // why reading stopped, once it has
+}
+
+// stdioCall receives the responses to one request
+type stdioCall struct {
+	responses chan JSONRPCResponse
+	done      chan struct{} // closed when the caller stops waiting
+}
+
+// NewStdioClient returns a client writing requests to w and reading
+// responses from r
+func NewStdioClient(r io.Reader, w io.Writer) *StdioClient {
+	c := &StdioClient{
+		out:     &lineWriter{w: w, codec: DefaultCodec},
+		codec:   DefaultCodec,
+		w:       w,
+		pending: make(map[string]*stdioCall),
+	}
+	go c.read(r)
+	return c
+}
+
+// StartStdioAgent starts cmd, an agent calling ServeStdio on its stdin and
+// stdout, and returns a client talking to it. The agent's stderr is
+// cmd.Stderr, discarded if nil.
+func StartStdioAgent(cmd *exec.Cmd) (*StdioClient, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c := NewStdioClient(stdout, stdin)
+	c.cmd = cmd
+	return c, nil
+}
+
+// Close closes the agent's input, which ends ServeStdio once the requests
+// in progress are answered, and for a started agent waits for it to exit
+func (c *StdioClient) Close() error {
+	var err error
+	if closer, ok := c.w.(io.Closer); ok {
+		err = closer.Close()
+	}
+	if c.cmd != nil {
+		if waitErr := c.cmd.Wait(); err == nil {
+			err = waitErr
+		}
+	}
+	return err
+}
+
+// read passes responses to the calls waiting for them until r ends, then
+// fails those still waiting. Lines that are not JSON-RPC responses, such
+// as stray output of the agent, are skipped.
+func (c *StdioClient) read(r io.Reader) {
+	reader := bufio.NewReader(r)
+	var err error
+	for err == nil {
+		var line []byte
+		line, err = reader.ReadBytes('\n')
+		var resp JSONRPCResponse
+		if len(bytes.TrimSpace(line)) == 0 || c.codec.Unmarshal(line, &resp) != nil || resp.JSONRPC != "2.0" {
+			continue
+		}
+		c.mu.Lock()
+		call, ok := c.pending[resp.ID.String()]
+		c.mu.Unlock()
+		if !ok {
+			continue
+		}
+		select {
+		case call.responses <- resp:
+		case <-call.done:
+		}
+	}
+
+	if err == io.EOF {
+		err = errStdioClosed
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+	for id, call := range c.pending {
+		close(call.responses)
+		delete(c.pending, id)
+	}
+}
+
+// start sends a request and returns the call receiving its responses and
+// a func to call once done with them
+func (c *StdioClient) start(method string, params interface{}) (*stdioCall, func(), error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, nil, c.err
+	}
+	c.nextID++
+	id := strconv.FormatInt(c.nextID, 10)
+	call := &stdioCall{responses: make(chan JSONRPCResponse, 1), done: make(chan struct{})}
+	c.pending[id] = call
+	c.mu.Unlock()
+
+	finish := func() {
+		close(call.done)
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}
+	if err := c.out.write(JSONRPCRequest{JSONRPC: "2.0", ID: StringID(id), Method: method, Params: params}); err != nil {
+		finish()
+		return nil, nil, err
+	}
+	return call, finish, nil
+}
+
+// receive returns the next response to call
+func (c *StdioClient) receive(ctx context.Context, call *stdioCall) (JSONRPCResponse, error) {
+	select {
+	case resp, ok := <-call.responses:
+		if !ok {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return resp, c.err
+		}
+		if resp.Error != nil {
+			return resp, resp.Error
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return JSONRPCResponse{}, ctx.Err()
+	}
+}
+
+// Call sends a JSON-RPC request and returns its result
+func (c *StdioClient) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	call, finish, err := c.start(method, params)
+	if err != nil {
+		return nil, err
+	}
+	defer finish()
+	resp, err := c.receive(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// Notify sends a JSON-RPC notification, a request without an ID that is
+// not answered
+func (c *StdioClient) Notify(method string, params interface{}) error {
+	c.mu.Lock()
+	err := c.err
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return c.out.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// notification is a JSON-RPC request without an ID
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// SendTask runs a task on the agent and returns its result
+func (c *StdioClient) SendTask(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	result, err := c.taskResult(ctx, "a2a/task", task)
+	if err != nil {
+		return nil, fmt.Errorf("task failed: %w", err)
+	}
+	return result, nil
+}
+
+// SubmitTask queues a task on the agent and returns its submitted state
+func (c *StdioClient) SubmitTask(ctx context.Context, task TaskParams) (*TaskResult, error) {
+	result, err := c.taskResult(ctx, "a2a/task/submit", task)
+	if err != nil {
+		return nil, fmt.Errorf("task submit failed: %w", err)
+	}
+	return result, nil
+}
+
+// GetTask returns the current state of an asynchronous task
+func (c *StdioClient) GetTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	result, err := c.taskResult(ctx, "a2a/task/get", TaskQuery{TaskID: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("task lookup failed: %w", err)
+	}
+	return result, nil
+}
+
+// CancelTask cancels an asynchronous task and returns its canceled state
+func (c *StdioClient) CancelTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	result, err := c.Call(ctx, "tasks/cancel", TaskIDParams{ID: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("task cancel failed: %w", err)
+	}
+	var task Task
+	if err := c.codec.Unmarshal(result, &task); err != nil {
+		return nil, err
+	}
+	return task.Result(), nil
+}
+
+// StreamTask runs a task with message/stream, calling handle with each
+// event until the final status update. If handle returns an error,
+// StreamTask stops reading and returns it.
+func (c *StdioClient) StreamTask(ctx context.Context, task TaskParams, handle func(StreamEvent) error) error {
+	call, finish, err := c.start("message/stream", task.MessageSendParams())
+	if err != nil {
+		return err
+	}
+	defer finish()
+	for {
+		resp, err := c.receive(ctx, call)
+		if err != nil {
+			return fmt.Errorf("task stream failed: %w", err)
+		}
+		event, err := decodeEvent(c.codec, resp.Result)
+		if err != nil {
+			return err
+		}
+		if err := handle(event); err != nil {
+			return err
+		}
+		if event.StatusUpdate != nil && event.StatusUpdate.Final {
+			return nil
+		}
+	}
+}
+
+// taskResult calls a method answering with a TaskResult
+func (c *StdioClient) taskResult(ctx context.Context, method string, params interface{}) (*TaskResult, error) {
+	result, err := c.Call(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	var taskResult TaskResult
+	if err := c.codec.Unmarshal(result, &taskResult); err != nil {
+		return nil, err
+	}
+	return &taskResult, nil
+}
@@ -0,0 +1,166 @@
+package a2a
+
+import (
+	"container/list"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrTaskNotFound is returned by a TaskStore when no task has the given ID
+var ErrTaskNotFound = errors.New("task not found")
+
+// Defaults for how long and how many finished tasks a MemoryTaskStore
+// keeps
+const (
+	defaultTaskRetention    = 24 * time.Hour
+	defaultMaxFinishedTasks = 100000
+)
+
+// TaskStore persists the state of asynchronously executed tasks
+type TaskStore interface {
+	Save(result *TaskResult) error
+	Get(taskID string) (*TaskResult, error)
+}
+
+// MemoryTaskStore is an in-memory TaskStore. It keeps unfinished tasks
+// until they finish, and finished ones, those completed, failed or
+// canceled, for a while, forgetting the oldest first.
+type MemoryTaskStore struct {
+	mu          sync.RWMutex
+	results     map[string]*storedTask
+	finished    *list.List // of finishedTask, oldest first
+	retention   time.Duration
+	maxFinished int
+}
+
+type storedTask struct {
+	result   *TaskResult
+	finished *list.Element // nil while the task is unfinished
+}
+
+type finishedTask struct {
+	taskID string
+	at     time.Time
+}
+
+// NewMemoryTaskStore creates an empty in-memory task store keeping
+// finished tasks for 24 hours, and at most 100000 of them
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return NewMemoryTaskStoreWithRetention(defaultTaskRetention, defaultMaxFinishedTasks)
+}
+
+// NewMemoryTaskStoreWithRetention creates an empty in-memory task store
+// keeping finished tasks for retention, and at most maxFinished of them. A
+// negative retention or maxFinished means no limit.
+func NewMemoryTaskStoreWithRetention(retention time.Duration, maxFinished int) *MemoryTaskStore {
+	return &MemoryTaskStore{
+		results:     make(map[string]*storedTask),
+		finished:    list.New(),
+		retention:   retention,
+		maxFinished: maxFinished,
+	}
+}
+
+// Save stores a copy of result, replacing any previous state for the task,
+// and forgets the finished tasks kept too long or beyond the limit
+func (m *MemoryTaskStore) Save(result *TaskResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if previous, ok := m.results[result.TaskID]; ok && previous.finished != nil {
+		m.finished.Remove(previous.finished)
+	}
+	stored := &storedTask{result: copyResult(result)}
+	if result.Status.Terminal() {
+		stored.finished = m.finished.PushBack(finishedTask{taskID: result.TaskID, at: now})
+	}
+	m.results[result.TaskID] = stored
+
+	for oldest := m.finished.Front(); oldest != nil; oldest = m.finished.Front() {
+		task := oldest.Value.(finishedTask)
+		if !m.expired(task, now) && (m.maxFinished < 0 || m.finished.Len() <= m.maxFinished) {
+			break
+		}
+		m.finished.Remove(oldest)
+		delete(m.results, task.taskID)
+	}
+	return nil
+}
+
+// Get returns a copy of the stored state for a task
+func (m *MemoryTaskStore) Get(taskID string) (*TaskResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.results[taskID]
+	if !ok || stored.finished != nil && m.expired(stored.finished.Value.(finishedTask), time.Now()) {
+		return nil, ErrTaskNotFound
+	}
+	return copyResult(stored.result), nil
+}
+
+// expired reports whether a finished task has been kept long enough
+func (m *MemoryTaskStore) expired(task finishedTask, now time.Time) bool {
+	return m.retention >= 0 && now.Sub(task.at) > m.retention
+}
+
+// copyResult copies result so that the copy can be changed without
+// changing result. Output and metadata are copied down to the maps and
+// slices JSON decodes to; the parts of messages and artifacts are shared.
+func copyResult(result *TaskResult) *TaskResult {
+	copied := *result
+	copied.Output = copyMap(result.Output)
+	copied.Metadata = copyMap(result.Metadata)
+	if result.StatusMessage != nil {
+		message := *result.StatusMessage
+		message.Parts = slices.Clone(message.Parts)
+		message.Metadata = copyMap(message.Metadata)
+		copied.StatusMessage = &message
+	}
+	if result.Artifacts != nil {
+		copied.Artifacts = make([]Artifact, len(result.Artifacts))
+		for i, artifact := range result.Artifacts {
+			artifact.Parts = slices.Clone(artifact.Parts)
+			artifact.Metadata = copyMap(artifact.Metadata)
+			copied.Artifacts[i] = artifact
+		}
+	}
+	if result.Error != nil {
+		rpcErr := *result.Error
+		rpcErr.Data = slices.Clone(rpcErr.Data)
+		copied.Error = &rpcErr
+	}
+	if result.Owner != nil {
+		owner := *result.Owner
+		copied.Owner = &owner
+	}
+	return &copied
+}
+
+// copyMap deeply copies m's nested maps and slices
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	copied := maps.Clone(m)
+	for key, value := range copied {
+		copied[key] = copyValue(value)
+	}
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return copyMap(value)
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, element := range value {
+			copied[i] = copyValue(element)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,66 @@
+package a2a
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryTaskStoreForgetsFinishedTasks(t *testing.T) {
+	store := NewMemoryTaskStoreWithRetention(-1, 2)
+	store.Save(&TaskResult{TaskID: "working", Status: TaskStateWorking})
+	for _, taskID := range []string{"first", "second", "third"} {
+		store.Save(&TaskResult{TaskID: taskID, Status: TaskStateCompleted})
+	}
+	if _, err := store.Get("first"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("oldest finished task: %v, want it forgotten", err)
+	}
+	for _, taskID := range []string{"working", "second", "third"} {
+		if _, err := store.Get(taskID); err != nil {
+			t.Errorf("%s: %v", taskID, err)
+		}
+	}
+
+	store = NewMemoryTaskStoreWithRetention(time.Millisecond, -1)
+	store.Save(&TaskResult{TaskID: "working", Status: TaskStateWorking})
+	store.Save(&TaskResult{TaskID: "failed", Status: TaskStateFailed})
+	time.Sleep(5 * time.Millisecond)
+	if _, err := store.Get("failed"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expired task: %v, want it forgotten", err)
+	}
+	store.Save(&TaskResult{TaskID: "canceled", Status: TaskStateCanceled})
+	if len(store.results) != 2 {
+		t.Errorf("store holds %d tasks, want the unfinished one and the one just canceled", len(store.results))
+	}
+}
+
+func TestMemoryTaskStoreCopies(t *testing.T) {
+	store := NewMemoryTaskStore()
+	result := &TaskResult{
+		TaskID:   "task",
+		Status:   TaskStateCompleted,
+		Output:   map[string]interface{}{"items": []interface{}{map[string]interface{}{"n": 1}}},
+		Metadata: map[string]interface{}{"trace": map[string]interface{}{"id": "a"}},
+		Owner:    &TaskOwner{Principal: "alice"},
+	}
+	store.Save(result)
+	result.Output["items"].([]interface{})[0].(map[string]interface{})["n"] = 2
+	result.Owner.Principal = "bob"
+
+	stored, err := store.Get("task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.Metadata["trace"].(map[string]interface{})["id"] = "b"
+
+	stored, _ = store.Get("task")
+	if n := stored.Output["items"].([]interface{})[0].(map[string]interface{})["n"]; n != 1 {
+		t.Errorf("output changed to %v through the saved result", n)
+	}
+	if id := stored.Metadata["trace"].(map[string]interface{})["id"]; id != "a" {
+		t.Errorf("metadata changed to %v through a result returned by Get", id)
+	}
+	if stored.Owner.Principal != "alice" {
+		t.Errorf("owner changed to %s through the saved result", stored.Owner.Principal)
+	}
+}
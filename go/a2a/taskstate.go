@@ -0,0 +1,129 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TaskState is where a task is in its lifecycle, as defined by the A2A
+// specification
+type TaskState string
+
+// Task states
+const (
+	TaskStateSubmitted     TaskState = "submitted"      // accepted and queued
+	TaskStateWorking       TaskState = "working"        // being executed
+	TaskStateInputRequired TaskState = "input-required" // waiting for the client to send more input
+	TaskStateCompleted     TaskState = "completed"
+	TaskStateCanceled      TaskState = "canceled"
+	TaskStateFailed        TaskState = "failed"
+	TaskStateUnknown       TaskState = "unknown" // reported by peers in a state this SDK doesn't know
+)
+
+// Task status values reported in TaskResult.Status before TaskState.
+//
+// Deprecated: use the TaskState constants; "cancelled" and "timeout" are
+// read as TaskStateCanceled and TaskStateFailed.
+const (
+	StatusSubmitted = TaskStateSubmitted
+	StatusWorking   = TaskStateWorking
+	StatusCompleted = TaskStateCompleted
+	StatusFailed    = TaskStateFailed
+	StatusCancelled = TaskStateCanceled
+	StatusTimeout   = TaskStateFailed
+)
+
+// transitions lists the states each state may move to. Terminal states
+// have none; a task may stay working across retries.
+var transitions = map[TaskState][]TaskState{
+	TaskStateSubmitted:     {TaskStateWorking, TaskStateInputRequired, TaskStateCompleted, TaskStateCanceled, TaskStateFailed},
+	TaskStateWorking:       {TaskStateWorking, TaskStateInputRequired, TaskStateCompleted, TaskStateCanceled, TaskStateFailed},
+	TaskStateInputRequired: {TaskStateWorking, TaskStateCompleted, TaskStateCanceled, TaskStateFailed},
+}
+
+// ParseTaskState returns the state named s, accepting the legacy spellings
+// "cancelled" and "timeout", and TaskStateUnknown for anything else
+func ParseTaskState(s string) TaskState {
+	switch state := TaskState(s); state {
+	case TaskStateSubmitted, TaskStateWorking, TaskStateInputRequired, TaskStateCompleted, TaskStateCanceled, TaskStateFailed:
+		return state
+	case "cancelled":
+		return TaskStateCanceled
+	case "timeout":
+		return TaskStateFailed
+	}
+	return TaskStateUnknown
+}
+
+// UnmarshalJSON parses the state with ParseTaskState
+func (s *TaskState) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	*s = ParseTaskState(name)
+	return nil
+}
+
+// Terminal reports whether a task in state s is finished
+func (s TaskState) Terminal() bool {
+	return s == TaskStateCompleted || s == TaskStateCanceled || s == TaskStateFailed
+}
+
+// CanTransitionTo reports whether a task in state s may move to next. New
+// tasks, with no state yet, and tasks in an unknown state may move to any
+// state.
+func (s TaskState) CanTransitionTo(next TaskState) bool {
+	if s == "" || s == TaskStateUnknown {
+		return true
+	}
+	return slices.Contains(transitions[s], next)
+}
+
+// InvalidTransitionError is returned when a task would move to a state
+// its current state does not allow, such as out of a terminal state
+type InvalidTransitionError struct {
+	TaskID   string
+	From, To TaskState
+}
+
+// Error implements the error interface
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("task %s cannot move from %s to %s", e.TaskID, e.From, e.To)
+}
+
+// TaskStatus is a task's state in the A2A specification's shape
+type TaskStatus struct {
+	State     TaskState `json:"state"`
+	Message   *Message  `json:"message,omitempty"` // e.g. the question when input is required
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// TaskStatus returns the result's state with its message and timestamp
+func (r *TaskResult) TaskStatus() TaskStatus {
+	return TaskStatus{State: r.Status, Message: r.StatusMessage, Timestamp: r.UpdatedAt}
+}
+
+// saveTask stores a new state for an asynchronous task, refusing
+// transitions its current state does not allow, e.g. a cancelled task
+// completing
+func (s *A2AServer) saveTask(result *TaskResult) error {
+	if current, err := s.store.Get(result.TaskID); err == nil {
+		if !current.Status.CanTransitionTo(result.Status) {
+			return &InvalidTransitionError{TaskID: result.TaskID, From: current.Status, To: result.Status}
+		}
+		// A task stays its first owner's
+		result.Owner = current.Owner
+	}
+	if result.UpdatedAt.IsZero() {
+		result.UpdatedAt = time.Now().UTC()
+	}
+	if err := s.store.Save(result); err != nil {
+		return err
+	}
+	s.notifyPush(result)
+	s.recordEvents(result)
+	return nil
+}
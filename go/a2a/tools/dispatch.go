@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Call is a tool call made by a model
+type Call struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage // a JSON object; empty means no arguments
+}
+
+// ParseOpenAI returns the calls in the tool_calls of an OpenAI assistant
+// message, whose arguments are JSON encoded as a string
+func ParseOpenAI(toolCalls json.RawMessage) ([]Call, error) {
+	var raw []struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(toolCalls, &raw); err != nil {
+		return nil, fmt.Errorf("parsing tool calls: %w", err)
+	}
+	calls := make([]Call, 0, len(raw))
+	for _, c := range raw {
+		if c.Type != "" && c.Type != "function" {
+			continue
+		}
+		calls = append(calls, Call{ID: c.ID, Name: c.Function.Name, Arguments: json.RawMessage(c.Function.Arguments)})
+	}
+	return calls, nil
+}
+
+// ParseAnthropic returns the calls in the tool_use blocks of an Anthropic
+// assistant message's content, skipping its other blocks
+func ParseAnthropic(content json.RawMessage) ([]Call, error) {
+	var blocks []struct {
+		Type  string          `json:"type"`
+		ID    string          `json:"id"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	}
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return nil, fmt.Errorf("parsing content: %w", err)
+	}
+	var calls []Call
+	for _, b := range blocks {
+		if b.Type == "tool_use" {
+			calls = append(calls, Call{ID: b.ID, Name: b.Name, Arguments: b.Input})
+		}
+	}
+	return calls, nil
+}
+
+// Result is the outcome of a tool call, to send back to the model
+type Result struct {
+	CallID string
+	// Content is the task's output as JSON or, if IsError, what went wrong
+	Content string
+	IsError bool
+	// Task is the task's result, if the task was sent
+	Task *a2a.TaskResult
+}
+
+// OpenAIToolMessage is a tool result message for OpenAI's Chat
+// Completions API
+type OpenAIToolMessage struct {
+	Role       string `json:"role"`
+	ToolCallID string `json:"tool_call_id"`
+	Content    string `json:"content"`
+}
+
+// OpenAIMessage returns the result as an OpenAI tool message
+func (r Result) OpenAIMessage() OpenAIToolMessage {
+	return OpenAIToolMessage{Role: "tool", ToolCallID: r.CallID, Content: r.Content}
+}
+
+// AnthropicToolResult is a tool_result content block for Anthropic's
+// Messages API, sent in a user message
+type AnthropicToolResult struct {
+	Type      string `json:"type"`
+	ToolUseID string `json:"tool_use_id"`
+	Content   string `json:"content"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// AnthropicBlock returns the result as an Anthropic tool_result block
+func (r Result) AnthropicBlock() AnthropicToolResult {
+	return AnthropicToolResult{Type: "tool_result", ToolUseID: r.CallID, Content: r.Content, IsError: r.IsError}
+}
+
+// Dispatcher sends tools' tasks for the calls a model makes
+type Dispatcher struct {
+	agent        *a2a.A2AAgent
+	directoryURL string
+
+	mu    sync.RWMutex
+	tools map[string]Tool
+}
+
+// NewDispatcher returns a dispatcher sending the tasks of tools with
+// agent, resolving agents with directoryURL
+func NewDispatcher(agent *a2a.A2AAgent, directoryURL string, tools []Tool) *Dispatcher {
+	d := &Dispatcher{agent: agent, directoryURL: directoryURL, tools: make(map[string]Tool, len(tools))}
+	d.Add(tools...)
+	return d
+}
+
+// Add dispatches calls of tools too. A tool whose name is already taken
+// replaces the earlier one.
+func (d *Dispatcher) Add(tools ...Tool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range tools {
+		d.tools[t.Name] = t
+	}
+}
+
+// Dispatch sends the task a call stands for and returns its result. The
+// call's ID is the task's idempotency key, so a call dispatched twice runs
+// once. Unknown tools, invalid arguments and tasks that fail or stop short
+// of completing are error results, which the model can react to.
+func (d *Dispatcher) Dispatch(call Call) Result {
+	result := Result{CallID: call.ID}
+	d.mu.RLock()
+	t, ok := d.tools[call.Name]
+	d.mu.RUnlock()
+	if !ok {
+		return result.fail("unknown tool: " + call.Name)
+	}
+	input := make(map[string]interface{})
+	if len(call.Arguments) > 0 {
+		if err := json.Unmarshal(call.Arguments, &input); err != nil {
+			return result.fail("invalid arguments: " + err.Error())
+		}
+		if input == nil {
+			input = make(map[string]interface{})
+		}
+	}
+
+	var opts []a2a.TaskOption
+	if call.ID != "" {
+		opts = append(opts, a2a.WithIdempotencyKey(call.ID))
+	}
+	task, err := d.agent.SendTask(t.AgentID, t.Capability, input, d.directoryURL, opts...)
+	if err != nil {
+		return result.fail(err.Error())
+	}
+	result.Task = task
+	switch task.Status {
+	case a2a.TaskStateCompleted:
+	case a2a.TaskStateFailed:
+		message := "task failed"
+		if task.Error != nil {
+			message += ": " + task.Error.Message
+		}
+		return result.fail(message)
+	default:
+		message := fmt.Sprintf("task %s is %s", task.TaskID, task.Status)
+		if task.StatusMessage != nil {
+			message += ": " + task.StatusMessage.Text()
+		}
+		return result.fail(message)
+	}
+
+	output := task.Output
+	if output == nil {
+		output = make(map[string]interface{})
+	}
+	content, err := json.Marshal(output)
+	if err != nil {
+		return result.fail(err.Error())
+	}
+	result.Content = string(content)
+	return result
+}
+
+// DispatchAll dispatches calls concurrently, as models making several
+// calls at once expect, and returns their results in the same order
+func (d *Dispatcher) DispatchAll(calls []Call) []Result {
+	results := make([]Result, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = d.Dispatch(call)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (r Result) fail(message string) Result {
+	r.Content = message
+	r.IsError = true
+	return r
+}
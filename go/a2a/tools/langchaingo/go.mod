@@ -0,0 +1,18 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/tools/langchaingo
+
+go 1.24
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/tmc/langchaingo v0.1.13
+)
+
+require (
+	github.com/dlclark/regexp2 v1.10.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pkoukk/tiktoken-go v0.1.6 // indirect
+	go.starlark.net v0.0.0-20230302034142-4b1e35fe2254 // indirect
+	golang.org/x/sys v0.27.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
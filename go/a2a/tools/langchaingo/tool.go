@@ -0,0 +1,96 @@
+// Package a2alangchaingo wraps A2A agents' capabilities as langchaingo
+// tools, so LangChain-Go agents can delegate work to A2A agents.
+//
+// Each tool sends one capability of one agent a task, named and described
+// as the tools package does. The model's input becomes the task's input:
+// a JSON object is used as is, and any other text is sent as
+// {"input": text}. The task's output is the tool's result.
+//
+//	found, _ := a2a.NewHTTPDirectory(directoryURL).Discover(a2a.DiscoverParams{Capabilities: []string{"search"}})
+//	executor := agents.NewExecutor(agents.NewOneShotAgent(llm, a2alangchaingo.FromAgents(agent, directoryURL, found...)))
+//
+// Failed tasks are results describing the failure, not errors, so the
+// LangChain agent can see what went wrong and carry on.
+package a2alangchaingo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	a2atools "github.com/mentessaas/a2a-protocol/go/a2a/tools"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// InputKey is the input field text that isn't a JSON object is sent as
+const InputKey = "input"
+
+// Tool is a langchaingo tool sending tasks to one capability of an agent
+type Tool struct {
+	def        a2atools.Tool
+	schema     bool // whether the capability has an input schema
+	dispatcher *a2atools.Dispatcher
+}
+
+var _ tools.Tool = (*Tool)(nil)
+
+// New returns a tool sending tasks for capability to the agent info
+// describes with agent, resolving it with directoryURL
+func New(agent *a2a.A2AAgent, directoryURL string, info a2a.AgentInfo, capability string) *Tool {
+	def := a2atools.FromCapability(info, capability)
+	return &Tool{
+		def:        def,
+		schema:     len(info.Schemas[capability].Input) > 0,
+		dispatcher: a2atools.NewDispatcher(agent, directoryURL, []a2atools.Tool{def}),
+	}
+}
+
+// FromAgents returns a tool for each capability of agents, in order
+func FromAgents(agent *a2a.A2AAgent, directoryURL string, agents ...a2a.AgentInfo) []tools.Tool {
+	var ts []tools.Tool
+	for _, info := range agents {
+		for _, capability := range info.Capabilities {
+			ts = append(ts, New(agent, directoryURL, info, capability))
+		}
+	}
+	return ts
+}
+
+// Name implements tools.Tool
+func (t *Tool) Name() string {
+	return t.def.Name
+}
+
+// Description implements tools.Tool. It includes the capability's input
+// schema, if it has one, as the model only sees the description.
+func (t *Tool) Description() string {
+	if !t.schema {
+		return t.def.Description
+	}
+	return t.def.Description + " Input is a JSON object matching this schema: " + string(t.def.Parameters)
+}
+
+// Call implements tools.Tool, sending the task and returning its output as
+// JSON, or what went wrong if it failed
+func (t *Tool) Call(ctx context.Context, input string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	result := t.dispatcher.Dispatch(a2atools.Call{Name: t.def.Name, Arguments: arguments(input)})
+	return result.Content, nil
+}
+
+// arguments returns input as a JSON object of task input
+func arguments(input string) json.RawMessage {
+	trimmed := bytes.TrimSpace([]byte(input))
+	var object map[string]interface{}
+	if json.Unmarshal(trimmed, &object) == nil && object != nil {
+		return trimmed
+	}
+	if len(trimmed) == 0 {
+		return nil
+	}
+	wrapped, _ := json.Marshal(map[string]string{InputKey: input})
+	return wrapped
+}
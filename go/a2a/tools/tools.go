@@ -0,0 +1,136 @@
+// Package tools turns A2A agents' capabilities into tool definitions for
+// LLM function calling, and the tool calls a model makes back into tasks,
+// so a model can orchestrate agents found in the directory.
+//
+// Define one tool per agent and capability, with the capability's input
+// schema as the tool's parameters, in the shape OpenAI's or Anthropic's API
+// takes:
+//
+//	agents, _ := a2a.NewHTTPDirectory(directoryURL).Discover(a2a.DiscoverParams{Capabilities: []string{"search"}})
+//	defs := tools.FromAgents(agents...)
+//	request["tools"] = tools.OpenAI(defs) // or tools.Anthropic(defs)
+//
+// Then dispatch the calls in the model's reply and send the results back
+// in the next request:
+//
+//	dispatcher := tools.NewDispatcher(agent, directoryURL, defs)
+//	calls, _ := tools.ParseOpenAI(message.ToolCalls) // or tools.ParseAnthropic(message.Content)
+//	for _, result := range dispatcher.DispatchAll(calls) {
+//	    messages = append(messages, result.OpenAIMessage()) // or result.AnthropicBlock()
+//	}
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// maxName is the longest tool name the OpenAI and Anthropic APIs accept
+const maxName = 64
+
+// Name returns the name of the tool calling capability on agentID: both
+// joined with an underscore, with characters the APIs reject in tool names
+// replaced by underscores and cut to 64 characters
+func Name(agentID, capability string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, agentID+"_"+capability)
+	if len(name) > maxName {
+		name = name[:maxName]
+	}
+	return name
+}
+
+// Tool is a tool calling one capability of one agent
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON Schema of the arguments, the capability's
+	// input schema or any object if it has none
+	Parameters json.RawMessage
+	AgentID    string
+	Capability string
+}
+
+// FromAgents returns a tool for each capability of agents, in order
+func FromAgents(agents ...a2a.AgentInfo) []Tool {
+	var tools []Tool
+	for _, info := range agents {
+		for _, capability := range info.Capabilities {
+			tools = append(tools, FromCapability(info, capability))
+		}
+	}
+	return tools
+}
+
+// FromCapability returns the tool calling capability on the agent info
+// describes
+func FromCapability(info a2a.AgentInfo, capability string) Tool {
+	t := Tool{
+		Name:        Name(info.AgentID, capability),
+		Description: Description(info, capability),
+		Parameters:  json.RawMessage(`{"type":"object"}`),
+		AgentID:     info.AgentID,
+		Capability:  capability,
+	}
+	if schema, ok := info.Schemas[capability]; ok && len(schema.Input) > 0 {
+		t.Parameters = schema.Input
+	}
+	return t
+}
+
+// Description describes the tool calling capability on an agent to a
+// model: what it runs and, if the agent describes itself, the agent's
+// description
+func Description(info a2a.AgentInfo, capability string) string {
+	description := fmt.Sprintf("Runs %s on the A2A agent %s.", capability, info.Name)
+	if info.Description != "" {
+		description += " " + info.Description
+	}
+	return description
+}
+
+// OpenAITool is a tool definition for OpenAI's Chat Completions API
+type OpenAITool struct {
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction is the function an OpenAITool describes
+type OpenAIFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// OpenAI returns tools as OpenAI function definitions
+func OpenAI(tools []Tool) []OpenAITool {
+	defs := make([]OpenAITool, len(tools))
+	for i, t := range tools {
+		defs[i] = OpenAITool{Type: "function", Function: OpenAIFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters}}
+	}
+	return defs
+}
+
+// AnthropicTool is a tool definition for Anthropic's Messages API
+type AnthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// Anthropic returns tools as Anthropic tool definitions
+func Anthropic(tools []Tool) []AnthropicTool {
+	defs := make([]AnthropicTool, len(tools))
+	for i, t := range tools {
+		defs[i] = AnthropicTool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters}
+	}
+	return defs
+}
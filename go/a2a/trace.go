@@ -0,0 +1,70 @@
+package a2a
+
+import "context"
+
+// defaultMaxHops is how deep a tree of sub-tasks may grow when
+// Limits.MaxHops is unset
+const defaultMaxHops = 16
+
+type taskKey struct{}
+type clientKey struct{}
+
+// withTask returns a copy of ctx carrying the task a handler is running and
+// a client for the sub-tasks it sends
+func (s *A2AServer) withTask(ctx context.Context, task TaskParams) context.Context {
+	ctx = context.WithValue(ctx, taskKey{}, task)
+	return context.WithValue(ctx, clientKey{}, s.taskClient(task))
+}
+
+// taskClient returns an agent sending as the identity task was addressed
+// to, through the server's directory, whose tasks are sub-tasks of task
+func (s *A2AServer) taskClient(task TaskParams) *A2AAgent {
+	info, ok := s.agentInfo(task.AgentID)
+	if !ok {
+		info = AgentInfo{AgentID: s.AgentID, Name: s.Name, Capabilities: s.Capabilities}
+	}
+	agent := s.directoryAgent(info)
+	agent.Codec = s.codec
+	if agent.Directory == nil && s.directoryURL != "" {
+		agent.Directory = &HTTPDirectory{URL: s.directoryURL, Token: agent.DirectoryToken, Codec: s.codec}
+	}
+	agent.parent = &task
+	return agent
+}
+
+// TaskClient returns an agent for a task handler to send sub-tasks with. It
+// sends as the agent the task was addressed to, through the server's
+// directory when given an empty directoryURL, and every task it sends is
+// linked to the running one as by Propagate. It returns nil outside a task
+// handler.
+func TaskClient(ctx context.Context) *A2AAgent {
+	agent, _ := ctx.Value(clientKey{}).(*A2AAgent)
+	return agent
+}
+
+// subTaskOf links task to parent, the task whose handler sends it
+func subTaskOf(task *TaskParams, parent TaskParams) {
+	task.ParentTaskID = parent.TaskID
+	task.RootTaskID = parent.RootTaskID
+	if task.RootTaskID == "" {
+		task.RootTaskID = parent.TaskID
+	}
+	task.Hop = parent.Hop + 1
+	if parent.CorrelationID != "" {
+		task.CorrelationID = parent.CorrelationID
+	}
+}
+
+// checkHops refuses a task nested deeper than Limits.MaxHops, which is most
+// likely agents delegating to each other in a loop
+func (s *A2AServer) checkHops(task TaskParams) *JSONRPCError {
+	maxHops := s.limits.MaxHops
+	if maxHops <= 0 {
+		maxHops = defaultMaxHops
+	}
+	if task.Hop <= maxHops {
+		return nil
+	}
+	s.logger.Warn("refusing task over hop limit", "taskId", task.TaskID, "rootTaskId", task.RootTaskID, "hop", task.Hop, "correlationId", task.CorrelationID)
+	return &JSONRPCError{Code: CodeHopLimitExceeded, Message: "Hop limit exceeded", Data: rpcData(HopLimitData{TaskID: task.TaskID, RootTaskID: task.RootTaskID, Hop: task.Hop, MaxHops: maxHops})}
+}
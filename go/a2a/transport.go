@@ -0,0 +1,241 @@
+package a2a
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Transport carries JSON-RPC requests between agents. A2AAgent sends with
+// the transport its Transports field names for an endpoint's URL scheme,
+// and with HTTPTransport otherwise, so agents reached over HTTP, gRPC, a
+// broker or in process are called the same way. Implement it to carry A2A
+// over a network this package does not know; HandleJSONRPC answers the
+// requests on the receiving side.
+type Transport interface {
+	// Send sends req to the agent at endpoint and returns its response,
+	// whose Error is set if the agent refused the request. The correlation
+	// ID of ctx, if any, goes with the request.
+	Send(ctx context.Context, endpoint string, req JSONRPCRequest) (*JSONRPCResponse, error)
+	// Stream sends req to the agent at endpoint and calls handle with each
+	// response until the agent ends the stream or handle returns an error,
+	// which Stream then returns. Agents that cannot stream answer with a
+	// single response.
+	Stream(ctx context.Context, endpoint string, req JSONRPCRequest, handle func(*JSONRPCResponse) error) error
+	// Listen serves server's JSON-RPC methods until ctx is done, then
+	// stops and returns nil, or until serving fails
+	Listen(ctx context.Context, server *A2AServer) error
+}
+
+// HTTPTransport carries JSON-RPC requests as HTTP POSTs, streams as
+// server-sent events, and listens with the server's own HTTP server. The
+// zero value uses http.DefaultClient and DefaultCodec.
+type HTTPTransport struct {
+	Client *http.Client // nil means http.DefaultClient
+	Codec  Codec        // nil means DefaultCodec
+}
+
+func (t *HTTPTransport) client() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return http.DefaultClient
+}
+
+func (t *HTTPTransport) codec() Codec {
+	if t.Codec != nil {
+		return t.Codec
+	}
+	return DefaultCodec
+}
+
+// Send implements Transport
+func (t *HTTPTransport) Send(ctx context.Context, endpoint string, req JSONRPCRequest) (*JSONRPCResponse, error) {
+	return t.send(ctx, endpoint, req, nil)
+}
+
+// send posts req with header added to the request
+func (t *HTTPTransport) send(ctx context.Context, url string, req JSONRPCRequest, header http.Header) (*JSONRPCResponse, error) {
+	resp, err := t.post(ctx, url, req, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var rpcResp JSONRPCResponse
+	if err := t.codec().NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, err
+	}
+	return &rpcResp, nil
+}
+
+// Stream implements Transport, asking for an event stream but accepting
+// a single response
+func (t *HTTPTransport) Stream(ctx context.Context, endpoint string, req JSONRPCRequest, handle func(*JSONRPCResponse) error) error {
+	resp, err := t.post(ctx, endpoint, req, http.Header{"Accept": {"text/event-stream"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var rpcResp JSONRPCResponse
+		if err := t.codec().NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+			return err
+		}
+		if rpcResp.Error == nil {
+			return fmt.Errorf("%s is not an event stream", resp.Header.Get("Content-Type"))
+		}
+		return handle(&rpcResp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 16<<20)
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(payload, []byte(" "))...)
+			continue
+		}
+		if len(line) > 0 || len(data) == 0 {
+			continue
+		}
+
+		var rpcResp JSONRPCResponse
+		err := t.codec().Unmarshal(data, &rpcResp)
+		data = data[:0]
+		if err != nil {
+			return err
+		}
+		if err := handle(&rpcResp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// post sends req, with the correlation ID, JWT and credential of ctx and
+// header, and returns the HTTP response
+func (t *HTTPTransport) post(ctx context.Context, url string, req JSONRPCRequest, header http.Header) (*http.Response, error) {
+	body, err := t.codec().Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Content-Type", t.codec().ContentType())
+	if id := CorrelationID(ctx); id != "" {
+		httpReq.Header.Set(CorrelationHeader, id)
+	}
+	if token := jwtFromContext(ctx); token != "" && httpReq.Header.Get("Authorization") == "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	setCredential(ctx, httpReq.Header)
+	return t.client().Do(httpReq)
+}
+
+// Listen implements Transport with server's Serve, shutting the server
+// down once ctx is done
+func (t *HTTPTransport) Listen(ctx context.Context, server *A2AServer) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Serve()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	err := server.Shutdown(context.Background())
+	if serveErr := <-errc; err == nil {
+		err = serveErr
+	}
+	return err
+}
+
+// transport returns the transport for endpoint
+func (a *A2AAgent) transport(endpoint string) Transport {
+	if scheme, _, ok := strings.Cut(endpoint, ":"); ok {
+		if t, ok := a.Transports[scheme]; ok {
+			return t
+		}
+	}
+	return &HTTPTransport{Client: a.HTTPClient, Codec: a.Codec}
+}
+
+// errStreamEnd stops reading a stream once its final event arrives
+var errStreamEnd = errors.New("stream ended")
+
+// HandleJSONRPC answers a JSON-RPC request, body, for agentID: the server's
+// own agent if empty, or one it hosts. send is called with the response,
+// or for message/stream with one response per event, and not after
+// HandleJSONRPC returns. Transports call it to serve requests that arrive
+// some other way than HTTP; HTTP middleware does not apply.
+func (s *A2AServer) HandleJSONRPC(ctx context.Context, agentID string, body []byte, send func(*JSONRPCResponse)) {
+	s.handleJSONRPC(ctx, agentID, body, send)
+}
+
+// handleJSONRPC is HandleJSONRPC, with caller describing the transport in
+// legacy method warnings
+func (s *A2AServer) handleJSONRPC(ctx context.Context, agentID string, body []byte, send func(*JSONRPCResponse), caller ...interface{}) {
+	if s.limits.MaxRequestBytes > 0 && int64(len(body)) > s.limits.MaxRequestBytes {
+		send(&JSONRPCResponse{JSONRPC: "2.0", Error: &JSONRPCError{Code: CodeInvalidRequest, Message: "Request too large"}})
+		return
+	}
+	req, rpcErr := s.readRequest(bytes.NewReader(body))
+	if rpcErr == nil && agentID != "" {
+		if _, ok := s.agentInfo(agentID); !ok {
+			rpcErr = &JSONRPCError{Code: CodeInvalidRequest, Message: "Agent not found"}
+		}
+	}
+	if rpcErr == nil {
+		_, rpcErr = s.legacyCall(req.Method, caller...)
+	}
+	if rpcErr == nil {
+		ctx, req.Params, rpcErr = s.openJWS(ctx, req.Method, req.Params)
+	}
+	if rpcErr == nil {
+		ctx, req.Params, rpcErr = s.openJWT(ctx, jwtFromContext(ctx), agentID, req.Method, req.Params)
+	}
+	if rpcErr != nil {
+		send(&JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		return
+	}
+
+	if req.Method == "message/stream" {
+		task, _, rpcErr := s.messageTask(agentID, req.Params)
+		if rpcErr == nil {
+			rpcErr = s.streamTask(ctx, task, func(event StreamEvent) {
+				resp := &JSONRPCResponse{JSONRPC: "2.0", ID: req.ID}
+				resp.Result, _ = s.codec.Marshal(event.Result())
+				send(resp)
+			})
+		}
+		if rpcErr != nil {
+			send(&JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		}
+		return
+	}
+
+	resp := &JSONRPCResponse{JSONRPC: "2.0", ID: req.ID}
+	resp.Result, resp.Error = s.dispatch(ctx, agentID, req)
+	send(resp)
+}
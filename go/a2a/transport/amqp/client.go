@@ -0,0 +1,200 @@
+package a2aamqp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// directReplyTo is RabbitMQ's pseudo-queue delivering responses straight
+// to the channel that published the request
+const directReplyTo = "amq.rabbitmq.reply-to"
+
+// errClosed fails requests once the transport's channel has closed
+var errClosed = errors.New("AMQP channel closed")
+
+// Transport is an http.RoundTripper publishing requests for amqp: URLs to
+// the exchange with the routing key the URL names, and all other requests
+// through Fallback. Requests no queue is bound for fail at once; otherwise
+// the client's timeout or the request context bounds how long to wait.
+type Transport struct {
+	// Fallback carries requests for other URLs; nil means
+	// http.DefaultTransport
+	Fallback http.RoundTripper
+
+	ch       *amqp.Channel
+	exchange string
+
+	mu      sync.Mutex
+	closed  bool
+	pending map[string]chan response
+}
+
+type response struct {
+	delivery amqp.Delivery
+	err      error
+}
+
+// NewTransport returns a Transport sending requests on ch, which it
+// consumes responses from and so should not be shared with a Server
+func NewTransport(ch *amqp.Channel, opts ...Option) (*Transport, error) {
+	o := newOptions(opts)
+	deliveries, err := ch.Consume(directReplyTo, "", true, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	t := &Transport{ch: ch, exchange: o.exchange, pending: make(map[string]chan response)}
+	go t.receive(deliveries, returns)
+	return t, nil
+}
+
+// receive passes responses and returned requests to the requests waiting
+// for them until the channel closes, then fails those still waiting
+func (t *Transport) receive(deliveries <-chan amqp.Delivery, returns <-chan amqp.Return) {
+	for deliveries != nil || returns != nil {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				deliveries = nil
+				continue
+			}
+			t.deliver(d.CorrelationId, response{delivery: d})
+		case r, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+			err := fmt.Errorf("no agent is serving %s: %s", r.RoutingKey, r.ReplyText)
+			t.deliver(r.CorrelationId, response{err: err})
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for id, ch := range t.pending {
+		ch <- response{err: errClosed}
+		delete(t.pending, id)
+	}
+}
+
+// deliver passes a response to the request waiting for it. Responses
+// nobody waits for, such as ones arriving after a timeout, are dropped.
+func (t *Transport) deliver(correlationID string, resp response) {
+	t.mu.Lock()
+	ch, ok := t.pending[correlationID]
+	delete(t.pending, correlationID)
+	t.mu.Unlock()
+	if ok {
+		ch <- resp
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != Scheme {
+		fallback := t.Fallback
+		if fallback == nil {
+			fallback = http.DefaultTransport
+		}
+		return fallback.RoundTrip(req)
+	}
+
+	key := req.URL.Opaque
+	if key == "" {
+		return nil, fmt.Errorf("no routing key in %s", req.URL)
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	msg := amqp.Publishing{
+		ContentType:   req.Header.Get("Content-Type"),
+		CorrelationId: hex.EncodeToString(id),
+		ReplyTo:       directReplyTo,
+		DeliveryMode:  amqp.Persistent,
+		Headers:       amqp.Table{},
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		msg.Body = body
+	}
+	for k := range req.Header {
+		if k != "Content-Type" {
+			msg.Headers[k] = req.Header.Get(k)
+		}
+	}
+
+	ch := make(chan response, 1)
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, errClosed
+	}
+	t.pending[msg.CorrelationId] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, msg.CorrelationId)
+		t.mu.Unlock()
+	}()
+
+	ctx := req.Context()
+	if err := t.ch.PublishWithContext(ctx, t.exchange, key, true, false, msg); err != nil {
+		return nil, err
+	}
+
+	var resp response
+	select {
+	case resp = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if resp.err != nil {
+		return nil, resp.err
+	}
+
+	d := resp.delivery
+	status := http.StatusOK
+	header := make(http.Header)
+	if d.ContentType != "" {
+		header.Set("Content-Type", d.ContentType)
+	}
+	for k, value := range d.Headers {
+		v, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if k == StatusHeader {
+			if code, err := strconv.Atoi(v); err == nil {
+				status = code
+			}
+			continue
+		}
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(d.Body)),
+		ContentLength: int64(len(d.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,10 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/amqp
+
+go 1.24
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/rabbitmq/amqp091-go v1.10.0
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
@@ -0,0 +1,180 @@
+package a2agrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/a2apb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client sends tasks to an agent served with Register. Errors the agent
+// refuses a task with are *a2a.JSONRPCError, as with a2a.A2AAgent.
+type Client struct {
+	service a2apb.A2AServiceClient
+}
+
+// NewClient returns a client for the agent at the other end of conn, such
+// as a *grpc.ClientConn
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{service: a2apb.NewA2AServiceClient(conn)}
+}
+
+// SendTask runs a task on the agent and returns its result
+func (c *Client) SendTask(ctx context.Context, task a2a.TaskParams) (*a2a.TaskResult, error) {
+	in, err := a2apb.FromTaskParams(task)
+	if err != nil {
+		return nil, fmt.Errorf("task failed: %w", err)
+	}
+	out, err := c.service.SendTask(outgoing(ctx, task), in)
+	if err != nil {
+		return nil, fmt.Errorf("task failed: %w", fromStatus(err))
+	}
+	return a2apb.ToTaskResult(out), nil
+}
+
+// SubmitTask queues a task on the agent and returns its submitted state,
+// to be polled with GetTask
+func (c *Client) SubmitTask(ctx context.Context, task a2a.TaskParams) (*a2a.TaskResult, error) {
+	in, err := a2apb.FromTaskParams(task)
+	if err != nil {
+		return nil, fmt.Errorf("task submit failed: %w", err)
+	}
+	out, err := c.service.SubmitTask(outgoing(ctx, task), in)
+	if err != nil {
+		return nil, fmt.Errorf("task submit failed: %w", fromStatus(err))
+	}
+	return a2apb.ToTaskResult(out), nil
+}
+
+// GetTask fetches the current state of a submitted task
+func (c *Client) GetTask(ctx context.Context, taskID string) (*a2a.TaskResult, error) {
+	out, err := c.service.GetTask(ctx, &a2apb.GetTaskRequest{TaskId: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("task lookup failed: %w", fromStatus(err))
+	}
+	return a2apb.ToTaskResult(out), nil
+}
+
+// CancelTask cancels a submitted task and returns its canceled state
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*a2a.TaskResult, error) {
+	out, err := c.service.CancelTask(ctx, &a2apb.CancelTaskRequest{TaskId: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("task cancel failed: %w", fromStatus(err))
+	}
+	return a2apb.ToTaskResult(out), nil
+}
+
+// StreamTask runs a task on the agent and calls handle with each event:
+// the submitted task, artifact updates and a final status update. It
+// returns when the stream ends or handle returns an error, which it then
+// returns.
+func (c *Client) StreamTask(ctx context.Context, task a2a.TaskParams, handle func(a2a.StreamEvent) error) error {
+	in, err := a2apb.FromTaskParams(task)
+	if err != nil {
+		return fmt.Errorf("stream failed: %w", err)
+	}
+	ctx, cancel := context.WithCancel(outgoing(ctx, task))
+	defer cancel()
+	stream, err := c.service.StreamTask(ctx, in)
+	if err != nil {
+		return fmt.Errorf("stream failed: %w", fromStatus(err))
+	}
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stream failed: %w", fromStatus(err))
+		}
+		if err := handle(a2apb.ToStreamEvent(event)); err != nil {
+			return err
+		}
+	}
+}
+
+// Conversation is a task run with Converse: its events are read with Recv,
+// and each time it stops at input-required the answer is sent with Reply
+type Conversation struct {
+	stream a2apb.A2AService_ConverseClient
+	cancel context.CancelFunc
+}
+
+// Converse starts a task on the agent that can be continued with answers
+// while it runs, on one stream. It returns once the stream is open; the
+// conversation must be closed with Close.
+func (c *Client) Converse(ctx context.Context, task a2a.TaskParams) (*Conversation, error) {
+	in, err := a2apb.FromTaskParams(task)
+	if err != nil {
+		return nil, fmt.Errorf("conversation failed: %w", err)
+	}
+	ctx, cancel := context.WithCancel(outgoing(ctx, task))
+	stream, err := c.service.Converse(ctx)
+	if err == nil {
+		err = stream.Send(in)
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("conversation failed: %w", fromStatus(err))
+	}
+	return &Conversation{stream: stream, cancel: cancel}, nil
+}
+
+// Recv returns the task's next event: the submitted task, artifact updates
+// and status updates, a final one each time the task stops at
+// input-required and once it finishes. It returns io.EOF after the last.
+func (c *Conversation) Recv() (a2a.StreamEvent, error) {
+	event, err := c.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return a2a.StreamEvent{}, io.EOF
+	}
+	if err != nil {
+		return a2a.StreamEvent{}, fmt.Errorf("conversation failed: %w", fromStatus(err))
+	}
+	return a2apb.ToStreamEvent(event), nil
+}
+
+// Reply continues the task with answer, the input or message answering
+// the prompt it stopped at. Its TaskID, action, agent and correlation ID
+// default to the task's.
+func (c *Conversation) Reply(answer a2a.TaskParams) error {
+	in, err := a2apb.FromTaskParams(answer)
+	if err == nil {
+		err = c.stream.Send(in)
+	}
+	if err != nil {
+		return fmt.Errorf("reply failed: %w", fromStatus(err))
+	}
+	return nil
+}
+
+// Done tells the agent no more answers are coming, so the conversation
+// ends the next time the task stops at input-required, leaving it waiting
+// for input as StreamTask would. Recv returns the rest of the events.
+func (c *Conversation) Done() error {
+	return c.stream.CloseSend()
+}
+
+// Close ends the conversation, canceling the task if it is still running
+func (c *Conversation) Close() error {
+	c.cancel()
+	return nil
+}
+
+// outgoing returns a copy of ctx sending the task's correlation ID, or
+// ctx's, in the request metadata
+func outgoing(ctx context.Context, task a2a.TaskParams) context.Context {
+	id := task.CorrelationID
+	if id == "" {
+		id = a2a.CorrelationID(ctx)
+	}
+	if id == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, correlationKey, id)
+}
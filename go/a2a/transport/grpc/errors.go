@@ -0,0 +1,75 @@
+package a2agrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/a2apb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCodes maps JSON-RPC error codes to the gRPC codes closest in meaning.
+// The JSON-RPC error itself travels as an a2apb.RPCError detail.
+var grpcCodes = map[int]codes.Code{
+	a2a.CodeParseError:                   codes.InvalidArgument,
+	a2a.CodeInvalidRequest:               codes.InvalidArgument,
+	a2a.CodeMethodNotFound:               codes.Unimplemented,
+	a2a.CodeInvalidParams:                codes.InvalidArgument,
+	a2a.CodeInternalError:                codes.Internal,
+	a2a.CodeTaskNotFound:                 codes.NotFound,
+	a2a.CodeTaskNotCancelable:            codes.FailedPrecondition,
+	a2a.CodePushNotificationNotSupported: codes.Unimplemented,
+	a2a.CodeUnsupportedOperation:         codes.Unimplemented,
+	a2a.CodeContentTypeNotSupported:      codes.InvalidArgument,
+	a2a.CodeInvalidAgentResponse:         codes.Internal,
+	a2a.CodeQueueFull:                    codes.ResourceExhausted,
+	a2a.CodeServerUnavailable:            codes.Unavailable,
+	a2a.CodeHopLimitExceeded:             codes.FailedPrecondition,
+}
+
+// toStatus converts an error refusing a task to a gRPC status error
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var rpcErr *a2a.JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code, ok := grpcCodes[rpcErr.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, rpcErr.Message)
+	if detail, err := a2apb.FromRPCError(rpcErr); err == nil {
+		if withDetail, err := st.WithDetails(detail); err == nil {
+			st = withDetail
+		}
+	}
+	return st.Err()
+}
+
+// fromStatus recovers the JSON-RPC error of a gRPC status error, so callers
+// can check its code with errors.As as with the HTTP client. Other errors
+// are returned as they are.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, detail := range st.Details() {
+		if rpcErr, ok := detail.(*a2apb.RPCError); ok {
+			return a2apb.ToRPCError(rpcErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/grpc
+
+go 1.24
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/mentessaas/a2a-protocol/go/a2a/a2apb v0.0.0
+	google.golang.org/grpc v1.59.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.4 // indirect
+	golang.org/x/net v0.23.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230822172742-b8732ec3820d // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+replace (
+	github.com/mentessaas/a2a-protocol/go => ../../..
+	github.com/mentessaas/a2a-protocol/go/a2a/a2apb => ../../a2apb
+)
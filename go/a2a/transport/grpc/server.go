@@ -0,0 +1,169 @@
+// Package a2agrpc serves an A2A server's tasks over gRPC and sends tasks to
+// agents served that way. The service is A2AService of
+// proto/a2a/v1/a2a.proto; it runs tasks through the same code as the
+// server's JSON-RPC methods, so handlers, hooks, limits, retries and the
+// task store are shared by both transports.
+//
+//	gs := grpc.NewServer()
+//	a2agrpc.Register(gs, server)
+//	gs.Serve(lis)
+//
+// Transport implements a2a.Transport on top, so A2AAgent reaches agents
+// registered with grpc:// endpoints through it.
+package a2agrpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/a2apb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// correlationKey is the metadata key carrying correlation IDs, as
+// a2a.CorrelationHeader does over HTTP
+var correlationKey = strings.ToLower(a2a.CorrelationHeader)
+
+// Register serves server's tasks on gs
+func Register(gs grpc.ServiceRegistrar, server *a2a.A2AServer) {
+	a2apb.RegisterA2AServiceServer(gs, NewService(server))
+}
+
+// NewService returns the A2AService implementation serving server's tasks,
+// e.g. to wrap it before registering
+func NewService(server *a2a.A2AServer) a2apb.A2AServiceServer {
+	return &service{server: server}
+}
+
+type service struct {
+	a2apb.UnimplementedA2AServiceServer
+	server *a2a.A2AServer
+}
+
+func (s *service) SendTask(ctx context.Context, in *a2apb.TaskParams) (*a2apb.TaskResult, error) {
+	result, err := s.server.RunTask(correlate(ctx), a2apb.ToTaskParams(in))
+	return resultMessage(result, err)
+}
+
+func (s *service) SubmitTask(ctx context.Context, in *a2apb.TaskParams) (*a2apb.TaskResult, error) {
+	result, err := s.server.SubmitTask(correlate(ctx), a2apb.ToTaskParams(in))
+	return resultMessage(result, err)
+}
+
+func (s *service) GetTask(ctx context.Context, in *a2apb.GetTaskRequest) (*a2apb.TaskResult, error) {
+	result, err := s.server.GetTask(in.GetTaskId())
+	return resultMessage(result, err)
+}
+
+func (s *service) CancelTask(ctx context.Context, in *a2apb.CancelTaskRequest) (*a2apb.TaskResult, error) {
+	result, err := s.server.CancelTask(in.GetTaskId())
+	return resultMessage(result, err)
+}
+
+func (s *service) StreamTask(in *a2apb.TaskParams, stream a2apb.A2AService_StreamTaskServer) error {
+	ctx := correlate(stream.Context())
+	err := s.server.StreamTask(ctx, a2apb.ToTaskParams(in), func(event a2a.StreamEvent) error {
+		msg, err := a2apb.FromStreamEvent(event)
+		if err != nil {
+			return status.Errorf(codes.Internal, "encoding event: %v", err)
+		}
+		return stream.Send(msg)
+	})
+	return toStatus(err)
+}
+
+// Converse runs the task the first TaskParams received starts, streaming
+// its events as StreamTask does. Each time the task stops at
+// input-required it waits for the next TaskParams, the answer, and
+// continues the task with it; later TaskParams default to its ID and
+// correlation ID, and must not name another task.
+func (s *service) Converse(stream a2apb.A2AService_ConverseServer) error {
+	ctx := correlate(stream.Context())
+	var taskID, correlationID string
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil // the caller left the task waiting for input
+		}
+		if err != nil {
+			return err
+		}
+		task := a2apb.ToTaskParams(in)
+		switch {
+		case taskID == "" && task.TaskID == "":
+			task.TaskID = newTaskID()
+		case taskID == "":
+		case task.TaskID == "":
+			task.TaskID = taskID
+			task.Resume = true
+		case task.TaskID != taskID:
+			return status.Errorf(codes.InvalidArgument, "conversation is about task %s, not %s", taskID, task.TaskID)
+		default:
+			task.Resume = true
+		}
+		taskID = task.TaskID
+		if task.CorrelationID == "" {
+			task.CorrelationID = correlationID
+		}
+
+		var state a2a.TaskState
+		err = s.server.StreamTask(ctx, task, func(event a2a.StreamEvent) error {
+			if event.Task != nil && correlationID == "" {
+				correlationID = event.Task.ContextID
+			}
+			if event.StatusUpdate != nil && event.StatusUpdate.Final {
+				state = event.StatusUpdate.Status.State
+			}
+			msg, err := a2apb.FromStreamEvent(event)
+			if err != nil {
+				return status.Errorf(codes.Internal, "encoding event: %v", err)
+			}
+			return stream.Send(msg)
+		})
+		if err != nil {
+			return toStatus(err)
+		}
+		if state != a2a.TaskStateInputRequired {
+			return nil
+		}
+	}
+}
+
+// newTaskID returns an ID for a conversation's task when the caller named
+// none, as message/stream generates one
+func newTaskID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// correlate returns a copy of ctx carrying the request's correlation ID,
+// echoing it in the response header as the HTTP server does
+func correlate(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(correlationKey)
+	if len(ids) == 0 || ids[0] == "" {
+		return ctx
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(correlationKey, ids[0]))
+	return a2a.ContextWithCorrelationID(ctx, ids[0])
+}
+
+func resultMessage(result *a2a.TaskResult, err error) (*a2apb.TaskResult, error) {
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	msg, err := a2apb.FromTaskResult(result)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encoding result: %v", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,255 @@
+package a2agrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"google.golang.org/grpc"
+)
+
+// Scheme is the URL scheme of endpoints served over gRPC, whose host and
+// port are the target dialed, e.g. grpc://billing-agent:9090
+const Scheme = "grpc"
+
+// Transport is an a2a.Transport over A2AService, so an a2a.A2AAgent can
+// send tasks to agents served with Register:
+//
+//	agent.Transports = map[string]a2a.Transport{
+//	    a2agrpc.Scheme: &a2agrpc.Transport{DialOptions: []grpc.DialOption{
+//	        grpc.WithTransportCredentials(insecure.NewCredentials()),
+//	    }},
+//	}
+//
+// The task methods a2a/task, a2a/task/submit, a2a/task/get, tasks/get,
+// tasks/cancel, message/send and message/stream map onto A2AService's
+// calls; other methods are answered with CodeMethodNotFound, which
+// A2AAgent takes as the peer not supporting them. Connections are dialed
+// on first use and kept until Close.
+type Transport struct {
+	// Addr is the address Listen listens on, e.g. ":9090"
+	Addr string
+	// DialOptions are used to dial endpoints and must set the transport
+	// credentials
+	DialOptions []grpc.DialOption
+	// ServerOptions configure the server Listen starts
+	ServerOptions []grpc.ServerOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// Close closes the connections dialed
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var firstErr error
+	for target, conn := range t.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(t.conns, target)
+	}
+	return firstErr
+}
+
+// client returns a client for endpoint, dialing it the first time
+func (t *Transport) client(endpoint string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != Scheme || u.Host == "" {
+		return nil, fmt.Errorf("not a gRPC endpoint: %s", endpoint)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	conn, ok := t.conns[u.Host]
+	if !ok {
+		if conn, err = grpc.Dial(u.Host, t.DialOptions...); err != nil {
+			return nil, err
+		}
+		if t.conns == nil {
+			t.conns = make(map[string]*grpc.ClientConn)
+		}
+		t.conns[u.Host] = conn
+	}
+	return NewClient(conn), nil
+}
+
+// Send implements a2a.Transport
+func (t *Transport) Send(ctx context.Context, endpoint string, req a2a.JSONRPCRequest) (*a2a.JSONRPCResponse, error) {
+	client, err := t.client(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	result, err := call(ctx, client, req)
+	return response(req, result, err)
+}
+
+// Stream implements a2a.Transport. Methods other than message/stream are
+// answered with a single response.
+func (t *Transport) Stream(ctx context.Context, endpoint string, req a2a.JSONRPCRequest, handle func(*a2a.JSONRPCResponse) error) error {
+	if req.Method != "message/stream" {
+		resp, err := t.Send(ctx, endpoint, req)
+		if err != nil {
+			return err
+		}
+		return handle(resp)
+	}
+
+	client, err := t.client(endpoint)
+	if err != nil {
+		return err
+	}
+	send, err := decode[a2a.MessageSendParams](req.Params)
+	if err != nil {
+		resp, err := response(req, nil, err)
+		if err != nil {
+			return err
+		}
+		return handle(resp)
+	}
+
+	var handleErr error // returned as it is
+	err = client.StreamTask(ctx, messageTask(req, send), func(event a2a.StreamEvent) error {
+		resp, err := response(req, event.Result(), nil)
+		if err != nil {
+			return err
+		}
+		handleErr = handle(resp)
+		return handleErr
+	})
+	if handleErr != nil {
+		return handleErr
+	}
+	var rpcErr *a2a.JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return handle(&a2a.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+	}
+	return err
+}
+
+// Listen implements a2a.Transport, serving server with a gRPC server on
+// Addr and stopping it gracefully once ctx is done
+func (t *Transport) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	lis, err := net.Listen("tcp", t.Addr)
+	if err != nil {
+		return err
+	}
+	gs := grpc.NewServer(t.ServerOptions...)
+	Register(gs, server)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- gs.Serve(lis)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	gs.GracefulStop()
+	return nil
+}
+
+// call makes the A2AService call for req and returns the result to send
+// back, in the shape of the JSON-RPC method's result
+func call(ctx context.Context, client *Client, req a2a.JSONRPCRequest) (interface{}, error) {
+	switch req.Method {
+	case "a2a/task", "a2a/task/submit":
+		task, err := decode[a2a.TaskParams](req.Params)
+		if err != nil {
+			return nil, err
+		}
+		if req.Method == "a2a/task" {
+			return client.SendTask(ctx, task)
+		}
+		return client.SubmitTask(ctx, task)
+	case "message/send":
+		send, err := decode[a2a.MessageSendParams](req.Params)
+		if err != nil {
+			return nil, err
+		}
+		task := messageTask(req, send)
+		run := client.SendTask
+		if send.Configuration != nil && send.Configuration.Blocking != nil && !*send.Configuration.Blocking {
+			run = client.SubmitTask
+		}
+		result, err := run(ctx, task)
+		if err != nil {
+			return nil, err
+		}
+		return result.Task(nil), nil
+	case "a2a/task/get":
+		query, err := decode[a2a.TaskQuery](req.Params)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetTask(ctx, query.TaskID)
+	case "tasks/get", "tasks/cancel":
+		params, err := decode[a2a.TaskIDParams](req.Params)
+		if err != nil {
+			return nil, err
+		}
+		lookup := client.GetTask
+		if req.Method == "tasks/cancel" {
+			lookup = client.CancelTask
+		}
+		result, err := lookup(ctx, params.ID)
+		if err != nil {
+			return nil, err
+		}
+		return result.Task(nil), nil
+	}
+	return nil, &a2a.JSONRPCError{Code: a2a.CodeMethodNotFound, Message: "Method not found"}
+}
+
+// messageTask returns the task a message is sent as, with the request ID
+// as its ID when the message starts a task, as servers of message/send
+// generate one
+func messageTask(req a2a.JSONRPCRequest, send a2a.MessageSendParams) a2a.TaskParams {
+	task := a2a.TaskParamsFromMessage(send)
+	if task.TaskID == "" {
+		task.TaskID = req.ID.String()
+	}
+	return task
+}
+
+// response returns the JSON-RPC response to req carrying result, or err
+// if the agent refused the request. Other errors are returned as they are.
+func response(req a2a.JSONRPCRequest, result interface{}, err error) (*a2a.JSONRPCResponse, error) {
+	resp := &a2a.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		var rpcErr *a2a.JSONRPCError
+		if !errors.As(err, &rpcErr) {
+			return nil, err
+		}
+		resp.Error = rpcErr
+		return resp, nil
+	}
+	if resp.Result, err = json.Marshal(result); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decode converts JSON-RPC params to the type the method takes, failing
+// with CodeInvalidParams
+func decode[T any](params interface{}) (T, error) {
+	var v T
+	data, err := json.Marshal(params)
+	if err == nil {
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return v, &a2a.JSONRPCError{Code: a2a.CodeInvalidParams, Message: "Invalid params"}
+	}
+	return v, nil
+}
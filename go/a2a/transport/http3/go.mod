@@ -0,0 +1,18 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/http3
+
+go 1.24.0
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/quic-go/quic-go v0.59.0
+)
+
+require (
+	github.com/quic-go/qpack v0.6.0 // indirect
+	golang.org/x/crypto v0.46.0 // indirect
+	golang.org/x/net v0.48.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
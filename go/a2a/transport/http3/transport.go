@@ -0,0 +1,129 @@
+package a2ahttp3
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Transport is an a2a.Transport carrying JSON-RPC requests over HTTP/3.
+// QUIC recovers from packet loss per stream and without TCP's head-of-line
+// blocking, which cuts tail latency for agents on lossy links such as
+// cellular. Set it for https endpoints, and serve agents over it with
+// Listen beside the server's own TCP listener:
+//
+//	h3 := &a2ahttp3.Transport{Addr: ":9443", TLSConfig: tlsConfig}
+//	agent.Transports = map[string]a2a.Transport{"https": h3}
+//	go h3.Listen(ctx, server)
+//
+// HTTP/3 always uses TLS. Connections are dialed on first use and kept
+// until Close.
+type Transport struct {
+	// Addr is the UDP address Listen listens on, e.g. ":443"
+	Addr string
+	// TLSConfig configures TLS when sending and, with its certificates,
+	// when listening
+	TLSConfig *tls.Config
+	// QUICConfig tunes QUIC, e.g. its idle timeout and keep-alive period;
+	// nil uses quic-go's defaults
+	QUICConfig *quic.Config
+	Codec      a2a.Codec // nil means a2a.DefaultCodec
+
+	once         sync.Once
+	roundTripper *http3.Transport
+	client       *http.Client
+	mu           sync.Mutex
+	server       *http3.Server // while Listen serves
+}
+
+// Client returns an HTTP client sending requests over the transport's
+// HTTP/3 connections, e.g. for A2AAgent.HTTPClient so directory calls go
+// over HTTP/3 too
+func (t *Transport) Client() *http.Client {
+	t.once.Do(func() {
+		t.roundTripper = &http3.Transport{TLSClientConfig: t.TLSConfig, QUICConfig: t.QUICConfig}
+		t.client = &http.Client{Transport: t.roundTripper}
+	})
+	return t.client
+}
+
+// Close closes the connections dialed
+func (t *Transport) Close() error {
+	t.Client()
+	return t.roundTripper.Close()
+}
+
+func (t *Transport) http() *a2a.HTTPTransport {
+	return &a2a.HTTPTransport{Client: t.Client(), Codec: t.Codec}
+}
+
+// Send implements a2a.Transport
+func (t *Transport) Send(ctx context.Context, endpoint string, req a2a.JSONRPCRequest) (*a2a.JSONRPCResponse, error) {
+	return t.http().Send(ctx, endpoint, req)
+}
+
+// Stream implements a2a.Transport, reading message/stream's events over
+// HTTP/3 as over HTTP/1.1
+func (t *Transport) Stream(ctx context.Context, endpoint string, req a2a.JSONRPCRequest, handle func(*a2a.JSONRPCResponse) error) error {
+	return t.http().Stream(ctx, endpoint, req, handle)
+}
+
+// Listen implements a2a.Transport, serving server's handler over HTTP/3
+// on Addr and shutting down gracefully once ctx is done. Clients without
+// HTTP/3, or whose networks drop UDP, are served by the server's Serve.
+func (t *Transport) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	if t.TLSConfig == nil || (len(t.TLSConfig.Certificates) == 0 && t.TLSConfig.GetCertificate == nil && t.TLSConfig.GetConfigForClient == nil) {
+		return errors.New("a2ahttp3: Listen needs a TLSConfig with a certificate")
+	}
+	h3 := &http3.Server{Addr: t.Addr, Handler: server.Handler(), TLSConfig: t.TLSConfig, QUICConfig: t.QUICConfig}
+	t.mu.Lock()
+	t.server = h3
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.server = nil
+		t.mu.Unlock()
+	}()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- h3.ListenAndServe()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	err := h3.Shutdown(context.Background())
+	if serveErr := <-errc; err == nil && !errors.Is(serveErr, http.ErrServerClosed) && !errors.Is(serveErr, quic.ErrServerClosed) {
+		err = serveErr
+	}
+	return err
+}
+
+// AltSvc returns middleware advertising the HTTP/3 port Listen serves in
+// the Alt-Svc header of responses over TCP, so clients that upgrade, such
+// as browsers and curl, switch to HTTP/3:
+//
+//	server := a2a.NewServerWithOptions("field-agent", a2a.WithMiddleware(h3.AltSvc()))
+func (t *Transport) AltSvc() a2a.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor < 3 {
+				t.mu.Lock()
+				h3 := t.server
+				t.mu.Unlock()
+				if h3 != nil {
+					h3.SetQUICHeaders(w.Header())
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Package a2akafka sends A2A tasks through Kafka topics, for batch
+// workloads whose tasks must survive restarts of the agents running them.
+//
+// A Producer writes each task as JSON TaskParams keyed by its task ID. A
+// Consumer reads them with a consumer group, runs each through the
+// server's RunTask, so limits, hooks, retries and the task store apply as
+// over HTTP, writes the outcome to a results topic and only then commits
+// the task's offset. A task whose agent stopped before committing is
+// delivered again, so delivery is at least once; Producer gives every task
+// an idempotency key, and a server with a result cache answers a
+// redelivered task from the cache instead of running it twice.
+//
+//	consumer := a2akafka.NewConsumer(server,
+//		kafka.NewReader(kafka.ReaderConfig{Brokers: brokers, GroupID: "calculator", Topic: "a2a.tasks.calculator"}),
+//		&kafka.Writer{Addr: kafka.TCP(brokers...), Topic: "a2a.results", RequiredAcks: kafka.RequireAll})
+//	go consumer.Run(ctx)
+//
+// Tasks in a partition run one after another, in order; add partitions and
+// consumers to the group to run more at once.
+package a2akafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/segmentio/kafka-go"
+)
+
+// CorrelationHeader is the message header carrying correlation IDs, as
+// a2a.CorrelationHeader does over HTTP
+const CorrelationHeader = a2a.CorrelationHeader
+
+// Result is the value of a message on the results topic, keyed by task ID:
+// the task's outcome, or the error refusing it
+type Result struct {
+	TaskID string            `json:"taskId"`
+	Result *a2a.TaskResult   `json:"result,omitempty"`
+	Error  *a2a.JSONRPCError `json:"error,omitempty"`
+}
+
+// Consumer runs tasks read from a Kafka topic on an A2A server
+type Consumer struct {
+	server  *a2a.A2AServer
+	reader  *kafka.Reader
+	results *kafka.Writer
+}
+
+// NewConsumer returns a consumer running tasks from reader on server and
+// writing their outcomes with results. reader needs a GroupID, as offsets
+// are committed for the group; results may be nil to not publish outcomes.
+func NewConsumer(server *a2a.A2AServer, reader *kafka.Reader, results *kafka.Writer) *Consumer {
+	return &Consumer{server: server, reader: reader, results: results}
+}
+
+// Run reads and runs tasks until ctx is done, returning ctx's error, or
+// until reading, publishing a result or committing fails. The task being
+// run when ctx is done, or refused because the server is draining, is not
+// committed and is delivered again.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the reader; the results writer belongs to the caller
+func (c *Consumer) Close() error {
+	return c.reader.Close()
+}
+
+// handle runs the task in msg and publishes its result. Tasks that cannot
+// be decoded get an error result, so they are committed instead of
+// blocking the partition.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) error {
+	var task a2a.TaskParams
+	if err := json.Unmarshal(msg.Value, &task); err != nil {
+		return c.publish(ctx, msg, Result{
+			TaskID: string(msg.Key),
+			Error:  &a2a.JSONRPCError{Code: a2a.CodeParseError, Message: "Parse error"},
+		})
+	}
+
+	if id := header(msg, CorrelationHeader); id != "" {
+		ctx = a2a.ContextWithCorrelationID(ctx, id)
+	}
+	result, err := c.server.RunTask(ctx, task)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	out := Result{TaskID: task.TaskID, Result: result}
+	if err != nil {
+		var rpcErr *a2a.JSONRPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &a2a.JSONRPCError{Code: a2a.CodeInternalError, Message: err.Error()}
+		}
+		if rpcErr.Code == a2a.CodeServerUnavailable {
+			// draining: leave the task for another consumer in the group
+			return err
+		}
+		out.Error = rpcErr
+	}
+	return c.publish(ctx, msg, out)
+}
+
+// publish writes result to the results topic with the task's correlation
+// ID
+func (c *Consumer) publish(ctx context.Context, msg kafka.Message, result Result) error {
+	if c.results == nil {
+		return nil
+	}
+	value, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	out := kafka.Message{Key: []byte(result.TaskID), Value: value}
+	if id := header(msg, CorrelationHeader); id != "" {
+		out.Headers = []kafka.Header{{Key: CorrelationHeader, Value: []byte(id)}}
+	}
+	return c.results.WriteMessages(ctx, out)
+}
+
+// header returns the value of a message header, if present
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
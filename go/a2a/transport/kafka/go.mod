@@ -0,0 +1,15 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/kafka
+
+go 1.24
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/segmentio/kafka-go v0.4.47
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
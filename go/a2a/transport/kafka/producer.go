@@ -0,0 +1,82 @@
+package a2akafka
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/segmentio/kafka-go"
+)
+
+// Producer sends tasks to a Kafka topic for a Consumer to run
+type Producer struct {
+	writer *kafka.Writer
+}
+
+// NewProducer returns a producer sending tasks with writer. Use
+// kafka.RequireAll acks for tasks to survive the loss of a broker.
+func NewProducer(writer *kafka.Writer) *Producer {
+	return &Producer{writer: writer}
+}
+
+// Send writes a task keyed by its task ID and returns the ID. Tasks
+// without an ID get one, and tasks without an idempotency key use their
+// ID, so a redelivered task can be answered from the server's result
+// cache. The correlation ID of ctx, or else the task's, travels in the
+// CorrelationHeader header.
+func (p *Producer) Send(ctx context.Context, task a2a.TaskParams) (string, error) {
+	if task.TaskID == "" {
+		id := make([]byte, 16)
+		rand.Read(id)
+		task.TaskID = "task-" + hex.EncodeToString(id)
+	}
+	if task.IdempotencyKey == "" {
+		task.IdempotencyKey = task.TaskID
+	}
+
+	value, err := json.Marshal(task)
+	if err != nil {
+		return "", err
+	}
+	msg := kafka.Message{Key: []byte(task.TaskID), Value: value}
+	correlationID := a2a.CorrelationID(ctx)
+	if correlationID == "" {
+		correlationID = task.CorrelationID
+	}
+	if correlationID != "" {
+		msg.Headers = []kafka.Header{{Key: CorrelationHeader, Value: []byte(correlationID)}}
+	}
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return "", err
+	}
+	return task.TaskID, nil
+}
+
+// Close closes the writer
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
+
+// ConsumeResults reads results with reader, calling handle with each and
+// committing its offset once handle returns nil, until ctx is done or
+// reading, handle or committing fails. Messages that are not results are
+// committed without calling handle.
+func ConsumeResults(ctx context.Context, reader *kafka.Reader, handle func(Result) error) error {
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			return err
+		}
+		var result Result
+		if err := json.Unmarshal(msg.Value, &result); err == nil {
+			if err := handle(result); err != nil {
+				return err
+			}
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package a2amqtt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Transport is an http.RoundTripper publishing requests for mqtt: URLs to
+// the task topic the URL names and waiting for the response on the
+// agent's result topic. All other requests go through Fallback. The
+// client's timeout or the request context bounds how long to wait.
+type Transport struct {
+	// Fallback carries requests for other URLs; nil means
+	// http.DefaultTransport
+	Fallback http.RoundTripper
+
+	client  mqtt.Client
+	qos     byte
+	replyTo string
+
+	mu      sync.Mutex
+	pending map[string]chan Envelope
+}
+
+// NewTransport subscribes to agentID's result topic and returns a
+// Transport sending requests through client. Each agent sending tasks
+// needs its own agent ID, as responses are matched on its result topic.
+func NewTransport(client mqtt.Client, agentID string, opts ...Option) (*Transport, error) {
+	o := newOptions(opts)
+	t := &Transport{
+		client:  client,
+		qos:     o.qos,
+		replyTo: ResultTopic(agentID),
+		pending: make(map[string]chan Envelope),
+	}
+	token := client.Subscribe(t.replyTo, t.qos, t.receive)
+	if token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return t, nil
+}
+
+// Close unsubscribes from the result topic
+func (t *Transport) Close() error {
+	token := t.client.Unsubscribe(t.replyTo)
+	token.Wait()
+	return token.Error()
+}
+
+// receive passes a response to the request waiting for it. Responses
+// nobody waits for, such as duplicates or ones arriving after a timeout,
+// are dropped.
+func (t *Transport) receive(_ mqtt.Client, msg mqtt.Message) {
+	var env Envelope
+	if err := json.Unmarshal(msg.Payload(), &env); err != nil {
+		return
+	}
+	t.mu.Lock()
+	ch, ok := t.pending[env.ID]
+	delete(t.pending, env.ID)
+	t.mu.Unlock()
+	if ok {
+		ch <- env
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != Scheme {
+		fallback := t.Fallback
+		if fallback == nil {
+			fallback = http.DefaultTransport
+		}
+		return fallback.RoundTrip(req)
+	}
+
+	topic := req.URL.Opaque
+	if topic == "" {
+		return nil, fmt.Errorf("no topic in %s", req.URL)
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	env := Envelope{ID: hex.EncodeToString(id), ReplyTo: t.replyTo, Header: make(map[string]string)}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		env.Body = body
+	}
+	for key := range req.Header {
+		env.Header[key] = req.Header.Get(key)
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Envelope, 1)
+	t.mu.Lock()
+	t.pending[env.ID] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, env.ID)
+		t.mu.Unlock()
+	}()
+
+	ctx := req.Context()
+	token := t.client.Publish(topic, t.qos, false, payload)
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var reply Envelope
+	select {
+	case reply = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	status := http.StatusOK
+	if reply.Status != 0 {
+		status = reply.Status
+	}
+	body := []byte(reply.Body)
+	var text string
+	if status != http.StatusOK && json.Unmarshal(body, &text) == nil {
+		body = []byte(text)
+	}
+	header := make(http.Header)
+	for key, value := range reply.Header {
+		header.Set(key, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,16 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/mqtt
+
+go 1.24
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.5.0
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+)
+
+require (
+	github.com/gorilla/websocket v1.5.3 // indirect
+	golang.org/x/net v0.27.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
@@ -0,0 +1,227 @@
+// Package a2amqtt carries A2A JSON-RPC requests through an MQTT broker, so
+// constrained edge devices behind NAT can serve and send tasks with only an
+// outbound connection.
+//
+// An agent takes requests on its task topic, TaskTopic(agentID), and
+// receives responses on its result topic, ResultTopic(agentID). MQTT 3.1.1
+// messages have no headers or reply address, so both travel in an
+// Envelope around the JSON-RPC body. Requests are handed to the server's
+// Handler, so every JSON-RPC method, middleware, limit and the task store
+// behave as they do over HTTP; message/stream needs a connection to write
+// events to and is not available.
+//
+//	client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker("tcp://broker:1883").SetClientID("sensor-agent"))
+//	client.Connect().Wait()
+//	server.Endpoint = a2amqtt.Endpoint(server.AgentID)
+//	sub, _ := a2amqtt.Serve(client, server, a2amqtt.WithQoS(1))
+//	defer sub.Close()
+//
+// Agents send tasks by pointing their HTTP client at a Transport, which
+// sends requests for mqtt: endpoints found in the directory through the
+// broker:
+//
+//	transport, _ := a2amqtt.NewTransport(client, "my-agent")
+//	agent.HTTPClient = &http.Client{Transport: transport, Timeout: 30 * time.Second}
+package a2amqtt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+const (
+	// Scheme is the URL scheme of MQTT endpoints, e.g.
+	// mqtt:a2a/agents/sensor-agent/tasks
+	Scheme = "mqtt"
+	// TopicPrefix begins every topic agents use
+	TopicPrefix = "a2a/agents/"
+)
+
+// TaskTopic returns the topic an agent takes requests on
+func TaskTopic(agentID string) string {
+	return TopicPrefix + topicLevel(agentID) + "/tasks"
+}
+
+// ResultTopic returns the topic an agent receives responses on
+func ResultTopic(agentID string) string {
+	return TopicPrefix + topicLevel(agentID) + "/results"
+}
+
+// Endpoint returns the endpoint to register for an agent served over MQTT
+func Endpoint(agentID string) string {
+	return Scheme + ":" + TaskTopic(agentID)
+}
+
+// topicLevel makes s a single topic level. Slashes and wildcards would
+// split or widen the topic, so they become underscores.
+func topicLevel(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// Envelope is the payload of messages on task and result topics: a
+// JSON-RPC request or response with what HTTP would carry beside it
+type Envelope struct {
+	ID      string            `json:"id"`                // matches a response to its request
+	ReplyTo string            `json:"replyTo,omitempty"` // topic for the response
+	Header  map[string]string `json:"header,omitempty"`  // e.g. X-Request-ID or Authorization
+	Status  int               `json:"status,omitempty"`  // HTTP status of responses, when not 200
+	Body    json.RawMessage   `json:"body"`
+}
+
+// Option configures a Server or Transport
+type Option func(*options)
+
+type options struct {
+	qos byte
+}
+
+// WithQoS sets the QoS level messages are subscribed to and published
+// with, 1 by default. Use 0 on links where an occasional lost task is
+// cheaper than the extra round trip; the sender's timeout catches it.
+func WithQoS(qos byte) Option {
+	return func(o *options) {
+		o.qos = qos
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{qos: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Server serves an A2A server's JSON-RPC methods on MQTT task topics
+type Server struct {
+	client  mqtt.Client
+	handler http.Handler
+	qos     byte
+	topics  []string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Serve subscribes to the task topics of server and its hosted agents.
+// QoS 1 may deliver a request twice; give tasks idempotency keys where
+// running one twice matters.
+func Serve(client mqtt.Client, server *a2a.A2AServer, opts ...Option) (*Server, error) {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{client: client, handler: server.Handler(), qos: o.qos, ctx: ctx, cancel: cancel}
+
+	for i, agent := range server.Agents() {
+		path := "/"
+		if i > 0 {
+			path = a2a.AgentPath(agent.AgentID)
+		}
+		topic := TaskTopic(agent.AgentID)
+		token := client.Subscribe(topic, s.qos, s.serve(path))
+		if token.Wait() && token.Error() != nil {
+			s.Close()
+			return nil, token.Error()
+		}
+		s.topics = append(s.topics, topic)
+	}
+	return s, nil
+}
+
+// Close unsubscribes, cancels requests still being handled and waits for
+// them to return. Drain the A2A server first to let running tasks finish.
+func (s *Server) Close() error {
+	var err error
+	if len(s.topics) > 0 {
+		token := s.client.Unsubscribe(s.topics...)
+		token.Wait()
+		err = token.Error()
+	}
+	s.cancel()
+	s.wg.Wait()
+	return err
+}
+
+// serve returns a handler passing messages to the server at path. The
+// client calls handlers one at a time, so each request is handled on its
+// own goroutine for a long task not to hold up the others.
+func (s *Server) serve(path string) mqtt.MessageHandler {
+	return func(_ mqtt.Client, msg mqtt.Message) {
+		var env Envelope
+		if err := json.Unmarshal(msg.Payload(), &env); err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.handle(env, path)
+	}
+}
+
+func (s *Server) handle(env Envelope, path string) {
+	defer s.wg.Done()
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, path, bytes.NewReader(env.Body))
+	if err != nil {
+		return
+	}
+	for key, value := range env.Header {
+		req.Header.Set(key, value)
+	}
+
+	w := &responseWriter{header: make(http.Header)}
+	s.handler.ServeHTTP(w, req)
+	if env.ReplyTo == "" {
+		return
+	}
+
+	reply := Envelope{ID: env.ID, Header: make(map[string]string), Body: w.body.Bytes()}
+	if !json.Valid(reply.Body) {
+		reply.Body, _ = json.Marshal(w.body.String())
+	}
+	for key := range w.header {
+		reply.Header[key] = w.header.Get(key)
+	}
+	if w.status != 0 && w.status != http.StatusOK {
+		reply.Status = w.status
+	}
+	payload, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+	s.client.Publish(env.ReplyTo, s.qos, false, payload)
+}
+
+// responseWriter buffers a response to publish as a reply
+type responseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,92 @@
+package a2anats
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Transport is an http.RoundTripper sending requests for nats: URLs as NATS
+// requests to the subject the URL names, and all other requests through
+// Fallback. Set it on an A2AAgent's HTTPClient to reach agents served by
+// Serve; the client's timeout or the request context bounds how long to
+// wait for a reply.
+type Transport struct {
+	Conn *nats.Conn
+	// Fallback carries requests for other URLs; nil means
+	// http.DefaultTransport
+	Fallback http.RoundTripper
+}
+
+// NewTransport returns a Transport sending requests over nc
+func NewTransport(nc *nats.Conn) *Transport {
+	return &Transport{Conn: nc}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != Scheme {
+		fallback := t.Fallback
+		if fallback == nil {
+			fallback = http.DefaultTransport
+		}
+		return fallback.RoundTrip(req)
+	}
+
+	subject := req.URL.Opaque
+	if subject == "" {
+		subject = req.URL.Host
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("no subject in %s", req.URL)
+	}
+
+	msg := nats.NewMsg(subject)
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = data
+	}
+	for key, values := range req.Header {
+		msg.Header[key] = values
+	}
+
+	reply, err := t.Conn.RequestMsgWithContext(req.Context(), msg)
+	if errors.Is(err, nats.ErrNoResponders) {
+		return nil, fmt.Errorf("no agent is serving %s", subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := http.StatusOK
+	header := make(http.Header)
+	for key, values := range reply.Header {
+		if key == StatusHeader {
+			if code, err := strconv.Atoi(reply.Header.Get(StatusHeader)); err == nil {
+				status = code
+			}
+			continue
+		}
+		header[key] = values
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(reply.Data)),
+		ContentLength: int64(len(reply.Data)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,18 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/nats
+
+go 1.24
+
+require (
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+	github.com/nats-io/nats.go v1.37.0
+)
+
+require (
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.18.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
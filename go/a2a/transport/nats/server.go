@@ -0,0 +1,196 @@
+// Package a2anats carries A2A JSON-RPC requests over NATS request-reply, so
+// agents can take tasks without exposing an inbound HTTP port.
+//
+// A served agent subscribes to its own subject, AgentSubject(agentID), and
+// joins a queue group on CapabilitySubject(capability) for each of its
+// capabilities, so exactly one of the agents offering a capability takes
+// each task published there. Requests are handed to the server's Handler,
+// so every JSON-RPC method, middleware, limit and the task store behave as
+// they do over HTTP. message/stream needs a connection to write events to
+// and is not available over request-reply.
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	server.Endpoint = a2anats.Endpoint(server.AgentID)
+//	sub, _ := a2anats.Serve(nc, server)
+//	defer sub.Close()
+//
+// Agents send tasks by pointing their HTTP client at a Transport, which
+// sends requests for nats: endpoints found in the directory over nc:
+//
+//	agent.HTTPClient = &http.Client{Transport: a2anats.NewTransport(nc)}
+package a2anats
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// Scheme is the URL scheme of NATS endpoints, e.g.
+	// nats:a2a.agent.search-agent
+	Scheme = "nats"
+	// SubjectPrefix begins every subject agents are served on
+	SubjectPrefix = "a2a"
+	// QueueGroup is the queue group agents join on capability subjects
+	QueueGroup = "a2a"
+	// StatusHeader carries the HTTP status of replies other than 200 OK
+	StatusHeader = "A2A-Status"
+)
+
+// AgentSubject returns the subject an agent takes tasks on
+func AgentSubject(agentID string) string {
+	return SubjectPrefix + ".agent." + subjectToken(agentID)
+}
+
+// CapabilitySubject returns the subject any one agent offering capability
+// takes tasks on
+func CapabilitySubject(capability string) string {
+	return SubjectPrefix + ".capability." + subjectToken(capability)
+}
+
+// Endpoint returns the endpoint to register for an agent served over NATS
+func Endpoint(agentID string) string {
+	return Scheme + ":" + AgentSubject(agentID)
+}
+
+// subjectToken makes s a single subject token. Dots, wildcards and
+// whitespace would split or widen the subject, so they become underscores.
+func subjectToken(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// Server serves an A2A server's JSON-RPC methods on NATS subjects
+type Server struct {
+	handler http.Handler
+	subs    []*nats.Subscription
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Serve subscribes to the subjects of server and its hosted agents and of
+// all their capabilities, as they are when Serve is called. A capability
+// offered by several agents on server goes to the first of them.
+func Serve(nc *nats.Conn, server *a2a.A2AServer) (*Server, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{handler: server.Handler(), ctx: ctx, cancel: cancel}
+
+	capabilities := make(map[string]bool)
+	for i, agent := range server.Agents() {
+		path := "/"
+		if i > 0 {
+			path = a2a.AgentPath(agent.AgentID)
+		}
+		sub, err := nc.Subscribe(AgentSubject(agent.AgentID), s.serve(path))
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.subs = append(s.subs, sub)
+
+		for _, capability := range agent.Capabilities {
+			if capabilities[capability] {
+				continue
+			}
+			capabilities[capability] = true
+			sub, err := nc.QueueSubscribe(CapabilitySubject(capability), QueueGroup, s.serve(path))
+			if err != nil {
+				s.Close()
+				return nil, err
+			}
+			s.subs = append(s.subs, sub)
+		}
+	}
+	return s, nil
+}
+
+// Close unsubscribes, cancels requests still being handled and waits for
+// them to return. Drain the A2A server first to let running tasks finish.
+func (s *Server) Close() error {
+	var firstErr error
+	for _, sub := range s.subs {
+		if err := sub.Unsubscribe(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.cancel()
+	s.wg.Wait()
+	return firstErr
+}
+
+// serve returns a handler passing messages to the server at path. Each
+// message is handled on its own goroutine, as the HTTP server handles
+// each request, so a long task does not hold up the subscription.
+func (s *Server) serve(path string) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		s.wg.Add(1)
+		go s.handle(msg, path)
+	}
+}
+
+func (s *Server) handle(msg *nats.Msg, path string) {
+	defer s.wg.Done()
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, path, bytes.NewReader(msg.Data))
+	if err != nil {
+		return
+	}
+	for key, values := range msg.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	w := &responseWriter{header: make(http.Header)}
+	s.handler.ServeHTTP(w, req)
+	if msg.Reply == "" {
+		return
+	}
+
+	reply := nats.NewMsg(msg.Reply)
+	reply.Data = w.body.Bytes()
+	for key, values := range w.header {
+		reply.Header[key] = values
+	}
+	if w.status != 0 && w.status != http.StatusOK {
+		reply.Header.Set(StatusHeader, strconv.Itoa(w.status))
+	}
+	msg.RespondMsg(reply)
+}
+
+// responseWriter buffers a response to send as a reply
+type responseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,251 @@
+package a2apubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Listen implements a2a.Transport, serving the subscriptions of server's
+// agent and those it hosts, and of the capability topics of all of them
+// as they are when Listen is called, until ctx is done. A capability
+// offered by several agents on server goes to the first of them. A
+// request is acknowledged once its responses are published, and
+// negatively acknowledged, so it is delivered again, if that fails or ctx
+// ends first.
+func (t *Transport) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errc := make(chan error, 1)
+	receive := func(subscription, agentID string) {
+		sub := t.Client.Subscription(subscription)
+		sub.ReceiveSettings.MaxOutstandingMessages = t.maxOutstanding()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+				t.handle(ctx, server, agentID, msg)
+			})
+			if err != nil && ctx.Err() == nil {
+				select {
+				case errc <- fmt.Errorf("pubsub: %s: %w", subscription, err):
+				default:
+				}
+				cancel()
+			}
+		}()
+	}
+
+	for i, agent := range subscriptions(server) {
+		agentID := "" // the server's own agent
+		if i > 0 {
+			agentID = agent.agentID
+		}
+		for _, subscription := range agent.subscriptions {
+			receive(subscription, agentID)
+		}
+	}
+	wg.Wait()
+	select {
+	case err := <-errc:
+		return err
+	default:
+		return nil
+	}
+}
+
+type agentSubscriptions struct {
+	agentID       string
+	subscriptions []string
+}
+
+// subscriptions returns the subscriptions each agent on server takes
+// requests from, the server's own agent first. A capability offered by
+// several agents goes to the first of them.
+func subscriptions(server *a2a.A2AServer) []agentSubscriptions {
+	var agents []agentSubscriptions
+	capabilities := make(map[string]bool)
+	for _, agent := range server.Agents() {
+		subs := []string{AgentTopic(agent.AgentID)}
+		for _, capability := range agent.Capabilities {
+			if !capabilities[capability] {
+				capabilities[capability] = true
+				subs = append(subs, CapabilityTopic(capability))
+			}
+		}
+		agents = append(agents, agentSubscriptions{agentID: agent.AgentID, subscriptions: subs})
+	}
+	return agents
+}
+
+// handle serves a request, publishing each response to its reply topic,
+// and then acknowledges it
+func (t *Transport) handle(ctx context.Context, server *a2a.A2AServer, agentID string, msg *pubsub.Message) {
+	if correlationID := msg.Attributes[CorrelationAttribute]; correlationID != "" {
+		ctx = a2a.ContextWithCorrelationID(ctx, correlationID)
+	}
+	codec := t.codec()
+
+	// Each response is published once the next arrives, so the last can be
+	// marked as such
+	var held *a2a.JSONRPCResponse
+	var sendErr error
+	send := func(resp *a2a.JSONRPCResponse, end bool) {
+		if sendErr != nil {
+			return
+		}
+		body, err := codec.Marshal(resp)
+		if err != nil {
+			sendErr = err
+			return
+		}
+		sendErr = t.reply(ctx, msg, body, end)
+	}
+	server.HandleJSONRPC(ctx, agentID, msg.Data, func(resp *a2a.JSONRPCResponse) {
+		if held != nil {
+			send(held, false)
+		}
+		held = resp
+	})
+	if held != nil {
+		send(held, true)
+	}
+
+	if ctx.Err() != nil || sendErr != nil {
+		msg.Nack()
+		return
+	}
+	msg.Ack()
+}
+
+// reply publishes a response to the reply topic a request names, if any
+func (t *Transport) reply(ctx context.Context, req *pubsub.Message, body []byte, end bool) error {
+	topic, replyTo := req.Attributes[ReplyTopicAttribute], req.Attributes[ReplyToAttribute]
+	if topic == "" {
+		return nil // nobody waits for a response
+	}
+	id := req.Attributes[RequestIDAttribute]
+	msg := &pubsub.Message{
+		Data:        body,
+		OrderingKey: id, // a stream's responses stay in order
+		Attributes:  map[string]string{RequestIDAttribute: id, ReplyToAttribute: replyTo},
+	}
+	if end {
+		msg.Attributes[EndAttribute] = "true"
+	}
+	return t.publish(ctx, topic, msg)
+}
+
+// AnswerDeadLetters receives from subscription, one of a dead-letter
+// topic, until ctx is done, answering the sender of each request Pub/Sub
+// gave up delivering with an error so it stops waiting
+func (t *Transport) AnswerDeadLetters(ctx context.Context, subscription string) error {
+	codec := t.codec()
+	return t.Client.Subscription(subscription).Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		var req struct {
+			ID a2a.RequestID `json:"id"`
+		}
+		codec.Unmarshal(msg.Data, &req)
+		message := "Request dead-lettered"
+		if source := msg.Attributes["CloudPubSubDeadLetterSourceSubscription"]; source != "" {
+			message += " by " + source
+		}
+		body, err := codec.Marshal(a2a.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Error: &a2a.JSONRPCError{Code: a2a.CodeInternalError, Message: message}})
+		if err == nil {
+			err = t.reply(ctx, msg, body, true)
+		}
+		if err != nil {
+			msg.Nack()
+			return
+		}
+		msg.Ack()
+	})
+}
+
+// Provision creates the topics and subscriptions Listen serves server
+// from, with message ordering enabled and the dead-letter policy of
+// DeadLetterTopic, and the dead-letter topic itself. Those that exist are
+// left as they are. Pub/Sub's service account needs to be allowed to
+// publish to the dead-letter topic and subscribe to the others.
+func (t *Transport) Provision(ctx context.Context, server *a2a.A2AServer) error {
+	var deadLetter *pubsub.DeadLetterPolicy
+	if t.DeadLetterTopic != "" {
+		if _, err := t.ensureTopic(ctx, t.DeadLetterTopic); err != nil {
+			return err
+		}
+		deadLetter = &pubsub.DeadLetterPolicy{
+			DeadLetterTopic:     "projects/" + t.Client.Project() + "/topics/" + t.DeadLetterTopic,
+			MaxDeliveryAttempts: t.MaxDeliveryAttempts,
+		}
+		if deadLetter.MaxDeliveryAttempts == 0 {
+			deadLetter.MaxDeliveryAttempts = 5
+		}
+	}
+	for _, agent := range subscriptions(server) {
+		for _, id := range agent.subscriptions {
+			topic, err := t.ensureTopic(ctx, id)
+			if err != nil {
+				return err
+			}
+			if err := t.ensureSubscription(ctx, id, pubsub.SubscriptionConfig{
+				Topic:                 topic,
+				EnableMessageOrdering: true,
+				DeadLetterPolicy:      deadLetter,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ProvisionReplies creates ReplyTopic and ReplySubscription, filtered to
+// the responses sent to this transport and deleted after a day without a
+// receiver, as senders that come and go need
+func (t *Transport) ProvisionReplies(ctx context.Context) error {
+	topic, err := t.ensureTopic(ctx, t.ReplyTopic)
+	if err != nil {
+		return err
+	}
+	return t.ensureSubscription(ctx, t.ReplySubscription, pubsub.SubscriptionConfig{
+		Topic:                 topic,
+		EnableMessageOrdering: true,
+		Filter:                fmt.Sprintf("attributes.%s = %q", ReplyToAttribute, t.ReplySubscription),
+		ExpirationPolicy:      24 * time.Hour,
+	})
+}
+
+func (t *Transport) ensureTopic(ctx context.Context, id string) (*pubsub.Topic, error) {
+	topic := t.Client.Topic(id)
+	exists, err := topic.Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub: topic %s: %w", id, err)
+	}
+	if exists {
+		return topic, nil
+	}
+	if topic, err = t.Client.CreateTopic(ctx, id); err != nil {
+		return nil, fmt.Errorf("pubsub: creating topic %s: %w", id, err)
+	}
+	return topic, nil
+}
+
+func (t *Transport) ensureSubscription(ctx context.Context, id string, config pubsub.SubscriptionConfig) error {
+	exists, err := t.Client.Subscription(id).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("pubsub: subscription %s: %w", id, err)
+	}
+	if exists {
+		return nil
+	}
+	if _, err := t.Client.CreateSubscription(ctx, id, config); err != nil {
+		return fmt.Errorf("pubsub: creating subscription %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+// Package a2apubsub carries A2A JSON-RPC requests through Google Cloud
+// Pub/Sub, for agents deployed on Google Cloud that exchange work through
+// topics rather than calling each other.
+//
+// Each agent takes requests from the subscription of its topic,
+// AgentTopic(agentID), and agents offering a capability share the
+// subscription of its topic, CapabilityTopic(capability), so a capability
+// request goes to whichever is free. Subscriptions are named after their
+// topics. Requests carry the conversation they belong to as their
+// ordering key: on subscriptions with message ordering enabled, Pub/Sub
+// delivers a conversation's turns in order, one at a time, and to the same
+// agent while it keeps up. Requests an agent fails to serve are delivered
+// again until the subscription's dead-letter policy moves them to its
+// dead-letter topic, where AnswerDeadLetters tells their senders. Provision
+// creates the topics and subscriptions of a server.
+//
+//	client, _ := pubsub.NewClient(ctx, "my-project")
+//	ps := &a2apubsub.Transport{Client: client, ReplyTopic: "a2a.replies", ReplySubscription: "a2a.replies.billing-1"}
+//	server.Endpoint = a2apubsub.Endpoint(server.AgentID)
+//	go ps.Listen(ctx, server)
+//
+// Agents sending it tasks set the transport for the scheme:
+//
+//	agent.Transports = map[string]a2a.Transport{a2apubsub.Scheme: ps}
+//
+// Responses are published to the request's reply topic with the
+// attribute ReplyToAttribute set to the sender's ReplySubscription, which
+// should filter on it (see ProvisionReplies) so each sender receives only
+// its own. Each response is one message ordered under its request's ID;
+// the last carries the attribute EndAttribute. Requests are handed to the
+// server's HandleJSONRPC, so every method, message/stream included,
+// behaves as it does over HTTP; HTTP middleware does not apply.
+package a2apubsub
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+const (
+	// Scheme is the URL scheme of Pub/Sub endpoints, whose opaque part is
+	// the topic to publish to, e.g. pubsub:a2a.agent.billing-agent
+	Scheme = "pubsub"
+
+	// The attributes of requests and responses
+	RequestIDAttribute   = "a2aRequestId"
+	ReplyTopicAttribute  = "a2aReplyTopic"
+	ReplyToAttribute     = "a2aReplyTo"
+	CorrelationAttribute = "a2aCorrelationId"
+	EndAttribute         = "a2aEnd" // "true" on a request's last response
+)
+
+// AgentTopic returns the name of the topic, and subscription, of an
+// agent's requests
+func AgentTopic(agentID string) string {
+	return "a2a.agent." + agentID
+}
+
+// CapabilityTopic returns the name of the topic, and subscription, of
+// requests for any agent offering capability
+func CapabilityTopic(capability string) string {
+	return "a2a.capability." + capability
+}
+
+// Endpoint returns the endpoint to register for an agent served over
+// Pub/Sub
+func Endpoint(agentID string) string {
+	return Scheme + ":" + AgentTopic(agentID)
+}
+
+// CapabilityEndpoint returns the endpoint reaching any agent offering
+// capability
+func CapabilityEndpoint(capability string) string {
+	return Scheme + ":" + CapabilityTopic(capability)
+}
+
+type orderingKey struct{}
+
+// WithOrderingKey returns a context whose requests are published with
+// key, so agents take them one at a time and in the order they were sent.
+// Without one, a request is ordered under its contextId or taskId.
+func WithOrderingKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, orderingKey{}, key)
+}
+
+// requestOrderingKey returns the ordering key of a request, empty if it
+// is ordered after nothing
+func requestOrderingKey(ctx context.Context, req a2a.JSONRPCRequest) string {
+	if key, _ := ctx.Value(orderingKey{}).(string); key != "" {
+		return key
+	}
+	var params struct {
+		ContextID string `json:"contextId"`
+		TaskID    string `json:"taskId"`
+		ID        string `json:"id"` // tasks/get, tasks/cancel and the like
+		Message   *struct {
+			ContextID string `json:"contextId"`
+			TaskID    string `json:"taskId"`
+		} `json:"message"`
+	}
+	if raw, err := json.Marshal(req.Params); err == nil {
+		json.Unmarshal(raw, &params)
+	}
+	if params.Message != nil {
+		params.ContextID = cmp.Or(params.ContextID, params.Message.ContextID)
+		params.TaskID = cmp.Or(params.TaskID, params.Message.TaskID)
+	}
+	return cmp.Or(params.ContextID, params.TaskID, params.ID)
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Transport is an a2a.Transport over Pub/Sub. It receives responses from
+// ReplySubscription, starting on the first request; Close stops it.
+type Transport struct {
+	Client *pubsub.Client
+	// ReplyTopic is the topic responses are published to
+	ReplyTopic string
+	// ReplySubscription is the subscription of ReplyTopic this transport
+	// alone receives from, and the value of ReplyToAttribute routing
+	// responses to it
+	ReplySubscription string
+	// MaxOutstanding bounds how many requests Listen serves at once from
+	// each subscription; zero means 10
+	MaxOutstanding int
+	// DeadLetterTopic and MaxDeliveryAttempts set the dead-letter policy of
+	// the subscriptions Provision creates. Without a topic, failed
+	// requests are delivered again until they expire.
+	DeadLetterTopic     string
+	MaxDeliveryAttempts int // zero means 5
+
+	Codec a2a.Codec // nil means a2a.DefaultCodec
+
+	mu      sync.Mutex
+	topics  map[string]*pubsub.Topic
+	replies *replies
+}
+
+func (t *Transport) codec() a2a.Codec {
+	if t.Codec != nil {
+		return t.Codec
+	}
+	return a2a.DefaultCodec
+}
+
+func (t *Transport) maxOutstanding() int {
+	if t.MaxOutstanding > 0 {
+		return t.MaxOutstanding
+	}
+	return 10
+}
+
+// topic returns the publisher for a topic, creating it on first use
+func (t *Transport) topic(id string) *pubsub.Topic {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if topic, ok := t.topics[id]; ok {
+		return topic
+	}
+	topic := t.Client.Topic(id)
+	topic.EnableMessageOrdering = true
+	if t.topics == nil {
+		t.topics = make(map[string]*pubsub.Topic)
+	}
+	t.topics[id] = topic
+	return topic
+}
+
+// publish publishes msg and waits for Pub/Sub to take it. A failure
+// pauses publishing under msg's ordering key, so it is resumed for the
+// next message.
+func (t *Transport) publish(ctx context.Context, topicID string, msg *pubsub.Message) error {
+	topic := t.topic(topicID)
+	if _, err := topic.Publish(ctx, msg).Get(ctx); err != nil {
+		if msg.OrderingKey != "" {
+			topic.ResumePublish(msg.OrderingKey)
+		}
+		return fmt.Errorf("pubsub: %w", err)
+	}
+	return nil
+}
+
+// errAnswered stops Send's stream at the first response
+var errAnswered = errors.New("answered")
+
+// Send implements a2a.Transport
+func (t *Transport) Send(ctx context.Context, endpoint string, req a2a.JSONRPCRequest) (*a2a.JSONRPCResponse, error) {
+	var resp *a2a.JSONRPCResponse
+	err := t.Stream(ctx, endpoint, req, func(r *a2a.JSONRPCResponse) error {
+		resp = r
+		return errAnswered
+	})
+	if err == errAnswered {
+		return resp, nil
+	}
+	if err == nil {
+		err = errors.New("pubsub: request ended without a response")
+	}
+	return nil, err
+}
+
+// Stream implements a2a.Transport
+func (t *Transport) Stream(ctx context.Context, endpoint string, req a2a.JSONRPCRequest, handle func(*a2a.JSONRPCResponse) error) error {
+	topicID, ok := strings.CutPrefix(endpoint, Scheme+":")
+	if !ok || topicID == "" {
+		return fmt.Errorf("pubsub: %q is not a pubsub: endpoint", endpoint)
+	}
+	if t.ReplyTopic == "" || t.ReplySubscription == "" {
+		return errors.New("pubsub: no ReplyTopic and ReplySubscription to take responses from")
+	}
+	codec := t.codec()
+	body, err := codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	replies, err := t.replyReceiver()
+	if err != nil {
+		return err
+	}
+
+	id := newID()
+	msg := &pubsub.Message{
+		Data:        body,
+		OrderingKey: requestOrderingKey(ctx, req),
+		Attributes: map[string]string{
+			RequestIDAttribute:  id,
+			ReplyTopicAttribute: t.ReplyTopic,
+			ReplyToAttribute:    t.ReplySubscription,
+		},
+	}
+	if correlationID := a2a.CorrelationID(ctx); correlationID != "" {
+		msg.Attributes[CorrelationAttribute] = correlationID
+	}
+
+	c := replies.expect(id)
+	defer replies.forget(id)
+	if err := t.publish(ctx, topicID, msg); err != nil {
+		return err
+	}
+	for {
+		data, ok, err := c.next(ctx, replies)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		var resp a2a.JSONRPCResponse
+		if err := codec.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("pubsub: invalid response: %w", err)
+		}
+		if err := handle(&resp); err != nil {
+			return err
+		}
+	}
+}
+
+// replyReceiver returns the receiver of responses, starting one if there
+// is none or the last one failed
+func (t *Transport) replyReceiver() (*replies, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.replies != nil && !t.replies.isClosed() {
+		return t.replies, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &replies{cancel: cancel, calls: make(map[string]*call), closed: make(chan struct{})}
+	sub := t.Client.Subscription(t.ReplySubscription)
+	go func() {
+		err := sub.Receive(ctx, r.receive)
+		if err == nil {
+			err = errClosed
+		}
+		r.close(fmt.Errorf("pubsub: receiving responses: %w", err))
+	}()
+	t.replies = r
+	return r, nil
+}
+
+// Close stops receiving responses, failing requests still waiting for
+// them, and flushes the topics
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	topics, replies := t.topics, t.replies
+	t.topics, t.replies = nil, nil
+	t.mu.Unlock()
+	if replies != nil {
+		replies.close(errClosed)
+	}
+	for _, topic := range topics {
+		topic.Stop()
+	}
+	return nil
+}
+
+// errClosed fails requests once their transport is closed
+var errClosed = errors.New("pubsub: transport closed")
+
+// replies is the receiver of responses and the requests waiting for them
+type replies struct {
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	calls  map[string]*call // by request ID
+	closed chan struct{}
+	err    error
+	once   sync.Once
+}
+
+func (r *replies) expect(id string) *call {
+	c := &call{notify: make(chan struct{}, 1)}
+	r.mu.Lock()
+	r.calls[id] = c
+	r.mu.Unlock()
+	return c
+}
+
+func (r *replies) forget(id string) {
+	r.mu.Lock()
+	delete(r.calls, id)
+	r.mu.Unlock()
+}
+
+func (r *replies) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// close fails the requests waiting for responses and stops receiving
+func (r *replies) close(err error) {
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.err = err
+		close(r.closed)
+		r.mu.Unlock()
+		r.cancel()
+	})
+}
+
+// receive passes a response to the request waiting for it. Responses
+// nobody waits for, such as ones arriving after a timeout, are dropped.
+func (r *replies) receive(_ context.Context, msg *pubsub.Message) {
+	msg.Ack()
+	r.mu.Lock()
+	c := r.calls[msg.Attributes[RequestIDAttribute]]
+	r.mu.Unlock()
+	if c != nil {
+		c.push(msg.Data, msg.Attributes[EndAttribute] == "true")
+	}
+}
+
+// call is a request waiting for its responses. They are queued rather
+// than handed over, so a slow caller holds up neither the receiver nor
+// other requests.
+type call struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	ended  bool
+	notify chan struct{}
+}
+
+func (c *call) push(msg []byte, end bool) {
+	c.mu.Lock()
+	if len(msg) > 0 {
+		c.queue = append(c.queue, msg)
+	}
+	c.ended = c.ended || end
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next response, or false once the request has ended
+func (c *call) next(ctx context.Context, r *replies) ([]byte, bool, error) {
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			msg := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return msg, true, nil
+		}
+		ended := c.ended
+		c.mu.Unlock()
+		if ended {
+			return nil, false, nil
+		}
+		select {
+		case <-c.notify:
+		case <-r.closed:
+			return nil, false, r.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/servicebus
+
+go 1.24
+
+require (
+	github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus v1.8.0
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+)
+
+require (
+	github.com/Azure/azure-sdk-for-go/sdk/azcore v1.17.0 // indirect
+	github.com/Azure/azure-sdk-for-go/sdk/internal v1.10.0 // indirect
+	github.com/Azure/go-amqp v1.3.0 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
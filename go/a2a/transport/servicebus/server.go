@@ -0,0 +1,171 @@
+package a2aservicebus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Listen implements a2a.Transport, serving the queues of server's agent
+// and those it hosts, and the capability topics of all of them as they are
+// when Listen is called, until ctx is done. A capability offered by
+// several agents on server goes to the first of them. Each queue and
+// subscription is served MaxSessions sessions at a time, each session's
+// requests one after another. A request is completed once its responses
+// are sent, and abandoned, so it is delivered again, if ctx ends first.
+func (t *Transport) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errc := make(chan error, 1)
+	serve := func(agentID string, accept func(context.Context) (*azservicebus.SessionReceiver, error)) {
+		for range t.maxSessions() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := t.acceptSessions(ctx, server, agentID, accept); err != nil {
+					select {
+					case errc <- err:
+					default:
+					}
+					cancel()
+				}
+			}()
+		}
+	}
+
+	capabilities := make(map[string]bool)
+	for i, agent := range server.Agents() {
+		agentID := "" // the server's own agent
+		if i > 0 {
+			agentID = agent.AgentID
+		}
+		queue := AgentQueue(agent.AgentID)
+		serve(agentID, func(ctx context.Context) (*azservicebus.SessionReceiver, error) {
+			return t.Client.AcceptNextSessionForQueue(ctx, queue, nil)
+		})
+		for _, capability := range agent.Capabilities {
+			if capabilities[capability] {
+				continue
+			}
+			capabilities[capability] = true
+			topic := CapabilityTopic(capability)
+			serve(agentID, func(ctx context.Context) (*azservicebus.SessionReceiver, error) {
+				return t.Client.AcceptNextSessionForSubscription(ctx, topic, t.subscription(), nil)
+			})
+		}
+	}
+	wg.Wait()
+	select {
+	case err := <-errc:
+		return err
+	default:
+		return nil
+	}
+}
+
+// acceptSessions serves one session after another until ctx is done, or
+// taking a session fails other than by none being waiting
+func (t *Transport) acceptSessions(ctx context.Context, server *a2a.A2AServer, agentID string, accept func(context.Context) (*azservicebus.SessionReceiver, error)) error {
+	for ctx.Err() == nil {
+		session, err := accept(ctx)
+		var sbErr *azservicebus.Error
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.As(err, &sbErr) && sbErr.Code == azservicebus.CodeTimeout:
+			continue // no session has requests waiting
+		case err != nil:
+			return err
+		}
+		t.serveSession(ctx, server, agentID, session)
+	}
+	return nil
+}
+
+// serveSession handles a session's requests in order until none arrives
+// for SessionIdle, then releases it
+func (t *Transport) serveSession(ctx context.Context, server *a2a.A2AServer, agentID string, session *azservicebus.SessionReceiver) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		closeCtx, done := context.WithTimeout(context.Background(), 10*time.Second)
+		defer done()
+		session.Close(closeCtx)
+	}()
+	go renewLock(ctx, session, t.lockRenewal(), func(error) { cancel() })
+
+	for {
+		idleCtx, stop := context.WithTimeout(ctx, t.sessionIdle())
+		msgs, err := session.ReceiveMessages(idleCtx, 1, nil)
+		stop()
+		if err != nil || len(msgs) == 0 {
+			return
+		}
+		t.handle(ctx, server, agentID, session, msgs[0])
+	}
+}
+
+// handle serves a request, sending each response to its reply queue, and
+// then completes it
+func (t *Transport) handle(ctx context.Context, server *a2a.A2AServer, agentID string, session *azservicebus.SessionReceiver, msg *azservicebus.ReceivedMessage) {
+	if correlationID, _ := msg.ApplicationProperties[CorrelationProperty].(string); correlationID != "" {
+		ctx = a2a.ContextWithCorrelationID(ctx, correlationID)
+	}
+	codec := t.codec()
+
+	// Each response is sent once the next arrives, so the last can be
+	// marked as such
+	var held *a2a.JSONRPCResponse
+	var sendErr error
+	send := func(resp *a2a.JSONRPCResponse, end bool) {
+		if sendErr != nil || msg.ReplyTo == nil {
+			return
+		}
+		body, err := codec.Marshal(resp)
+		if err != nil {
+			sendErr = err
+			return
+		}
+		sendErr = t.reply(ctx, msg, body, end)
+	}
+	server.HandleJSONRPC(ctx, agentID, msg.Body, func(resp *a2a.JSONRPCResponse) {
+		if held != nil {
+			send(held, false)
+		}
+		held = resp
+	})
+	if held != nil {
+		send(held, true)
+	}
+
+	settleCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if ctx.Err() != nil || sendErr != nil {
+		session.AbandonMessage(settleCtx, msg, nil)
+		return
+	}
+	session.CompleteMessage(settleCtx, msg, nil)
+}
+
+// reply sends a response to the queue and session a request names
+func (t *Transport) reply(ctx context.Context, req *azservicebus.ReceivedMessage, body []byte, end bool) error {
+	sender, err := t.sender(*req.ReplyTo)
+	if err != nil {
+		return err
+	}
+	contentType := "application/json"
+	msg := &azservicebus.Message{
+		CorrelationID:         &req.MessageID,
+		SessionID:             req.ReplyToSessionID,
+		ContentType:           &contentType,
+		ApplicationProperties: map[string]interface{}{End: end},
+		Body:                  body,
+	}
+	return sender.SendMessage(ctx, msg, nil)
+}
@@ -0,0 +1,481 @@
+// Package a2aservicebus carries A2A JSON-RPC requests through Azure
+// Service Bus queues and topics, for teams whose agents already exchange
+// work over Azure messaging.
+//
+// Each agent takes requests from its queue, AgentQueue(agentID), and
+// agents offering a capability share the subscription Subscription of its
+// topic, CapabilityTopic(capability), so a capability request goes to
+// whichever is free. Every entity must have sessions enabled: requests of
+// one conversation share a session, which one agent serves at a time and
+// in order, so the turns of a multi-turn task never overtake each other
+// while other conversations are served in parallel. Responses come back
+// on a session-enabled ReplyQueue, in a session of the sender's own.
+// Listen creates nothing; create the entities with the Azure CLI, Bicep
+// or the admin client.
+//
+//	client, _ := azservicebus.NewClient("agents.servicebus.windows.net", cred, nil)
+//	sb := &a2aservicebus.Transport{Client: client, ReplyQueue: "a2a.replies"}
+//	server.Endpoint = a2aservicebus.Endpoint(server.AgentID)
+//	go sb.Listen(ctx, server)
+//
+// Agents sending it tasks set the transport for the scheme:
+//
+//	agent.Transports = map[string]a2a.Transport{a2aservicebus.Scheme: sb}
+//
+// Requests are handed to the server's HandleJSONRPC, so every method,
+// message/stream included, behaves as it does over HTTP; HTTP middleware
+// does not apply. Each response is one message correlated with its
+// request's message ID; the last carries the application property End.
+package a2aservicebus
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+const (
+	// Scheme is the URL scheme of Service Bus endpoints, whose opaque part
+	// is the queue or topic to send to, e.g. servicebus:a2a.agent.billing-agent
+	Scheme = "servicebus"
+	// Subscription is the subscription of capability topics that agents
+	// take requests from unless Transport.Subscription is set
+	Subscription = "agents"
+	// End is the application property marking a request's last response
+	End = "a2aEnd"
+	// CorrelationProperty carries the correlation ID of a request
+	CorrelationProperty = "a2aCorrelationId"
+)
+
+// AgentQueue returns the name of the queue of an agent's requests
+func AgentQueue(agentID string) string {
+	return "a2a.agent." + agentID
+}
+
+// CapabilityTopic returns the name of the topic of requests for any agent
+// offering capability
+func CapabilityTopic(capability string) string {
+	return "a2a.capability." + capability
+}
+
+// Endpoint returns the endpoint to register for an agent served over
+// Service Bus
+func Endpoint(agentID string) string {
+	return Scheme + ":" + AgentQueue(agentID)
+}
+
+// CapabilityEndpoint returns the endpoint reaching any agent offering
+// capability
+func CapabilityEndpoint(capability string) string {
+	return Scheme + ":" + CapabilityTopic(capability)
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context whose requests go in the session
+// sessionID, so the agents serving them take them one at a time and in
+// the order they were sent. Without one, a request goes in the session of
+// its contextId or taskId.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// sessionID returns the session a request goes in
+func sessionID(ctx context.Context, req a2a.JSONRPCRequest) string {
+	if id, _ := ctx.Value(sessionKey{}).(string); id != "" {
+		return id
+	}
+	var params struct {
+		ContextID string `json:"contextId"`
+		TaskID    string `json:"taskId"`
+		ID        string `json:"id"` // tasks/get, tasks/cancel and the like
+		Message   *struct {
+			ContextID string `json:"contextId"`
+			TaskID    string `json:"taskId"`
+		} `json:"message"`
+	}
+	if raw, err := json.Marshal(req.Params); err == nil {
+		json.Unmarshal(raw, &params)
+	}
+	if params.Message != nil {
+		params.ContextID = cmp.Or(params.ContextID, params.Message.ContextID)
+		params.TaskID = cmp.Or(params.TaskID, params.Message.TaskID)
+	}
+	if id := cmp.Or(params.ContextID, params.TaskID, params.ID); id != "" {
+		return id
+	}
+	return newID() // nothing to keep in order with
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Transport is an a2a.Transport over Service Bus. It receives responses
+// from a session of ReplyQueue taken on the first request; Close releases
+// it.
+type Transport struct {
+	Client *azservicebus.Client
+	// ReplyQueue is the session-enabled queue responses are sent to
+	ReplyQueue string
+	// Subscription is the subscription of capability topics Listen takes
+	// requests from; empty means the Subscription constant
+	Subscription string
+	// MaxSessions bounds how many sessions, and so conversations, Listen
+	// serves at once from each queue and subscription; zero means 8
+	MaxSessions int
+	// SessionIdle is how long Listen waits for another request in a
+	// session before letting other agents take it; zero means 5 seconds
+	SessionIdle time.Duration
+	// LockRenewal is how often session locks are renewed; zero means every
+	// 20 seconds, a third of Service Bus's default lock duration
+	LockRenewal time.Duration
+	Codec       a2a.Codec // nil means a2a.DefaultCodec
+
+	mu      sync.Mutex
+	senders map[string]*azservicebus.Sender
+	replies *replies
+}
+
+func (t *Transport) codec() a2a.Codec {
+	if t.Codec != nil {
+		return t.Codec
+	}
+	return a2a.DefaultCodec
+}
+
+func (t *Transport) subscription() string {
+	if t.Subscription != "" {
+		return t.Subscription
+	}
+	return Subscription
+}
+
+func (t *Transport) maxSessions() int {
+	if t.MaxSessions > 0 {
+		return t.MaxSessions
+	}
+	return 8
+}
+
+func (t *Transport) sessionIdle() time.Duration {
+	if t.SessionIdle > 0 {
+		return t.SessionIdle
+	}
+	return 5 * time.Second
+}
+
+func (t *Transport) lockRenewal() time.Duration {
+	if t.LockRenewal > 0 {
+		return t.LockRenewal
+	}
+	return 20 * time.Second
+}
+
+// errAnswered stops Send's stream at the first response
+var errAnswered = errors.New("answered")
+
+// Send implements a2a.Transport
+func (t *Transport) Send(ctx context.Context, endpoint string, req a2a.JSONRPCRequest) (*a2a.JSONRPCResponse, error) {
+	var resp *a2a.JSONRPCResponse
+	err := t.Stream(ctx, endpoint, req, func(r *a2a.JSONRPCResponse) error {
+		resp = r
+		return errAnswered
+	})
+	if err == errAnswered {
+		return resp, nil
+	}
+	if err == nil {
+		err = errors.New("servicebus: request ended without a response")
+	}
+	return nil, err
+}
+
+// Stream implements a2a.Transport
+func (t *Transport) Stream(ctx context.Context, endpoint string, req a2a.JSONRPCRequest, handle func(*a2a.JSONRPCResponse) error) error {
+	entity, ok := strings.CutPrefix(endpoint, Scheme+":")
+	if !ok || entity == "" {
+		return fmt.Errorf("servicebus: %q is not a servicebus: endpoint", endpoint)
+	}
+	if t.ReplyQueue == "" {
+		return errors.New("servicebus: no ReplyQueue to take responses from")
+	}
+	codec := t.codec()
+	body, err := codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	sender, err := t.sender(entity)
+	if err != nil {
+		return err
+	}
+	replies, err := t.replySession(ctx)
+	if err != nil {
+		return err
+	}
+
+	id := newID()
+	session := sessionID(ctx, req)
+	contentType := "application/json"
+	msg := &azservicebus.Message{
+		MessageID:             &id,
+		SessionID:             &session,
+		ContentType:           &contentType,
+		ReplyTo:               &t.ReplyQueue,
+		ReplyToSessionID:      &replies.sessionID,
+		ApplicationProperties: map[string]interface{}{},
+		Body:                  body,
+	}
+	if correlationID := a2a.CorrelationID(ctx); correlationID != "" {
+		msg.ApplicationProperties[CorrelationProperty] = correlationID
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		// Requests nobody takes in time expire rather than run late
+		ttl := time.Until(deadline)
+		msg.TimeToLive = &ttl
+	}
+
+	c := replies.expect(id)
+	defer replies.forget(id)
+	if err := sender.SendMessage(ctx, msg, nil); err != nil {
+		return fmt.Errorf("servicebus: %w", err)
+	}
+	for {
+		data, ok, err := c.next(ctx, replies)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		var resp a2a.JSONRPCResponse
+		if err := codec.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("servicebus: invalid response: %w", err)
+		}
+		if err := handle(&resp); err != nil {
+			return err
+		}
+	}
+}
+
+// sender returns the sender for a queue or topic, creating it on first use
+func (t *Transport) sender(entity string) (*azservicebus.Sender, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sender, ok := t.senders[entity]; ok {
+		return sender, nil
+	}
+	sender, err := t.Client.NewSender(entity, nil)
+	if err != nil {
+		return nil, fmt.Errorf("servicebus: %w", err)
+	}
+	if t.senders == nil {
+		t.senders = make(map[string]*azservicebus.Sender)
+	}
+	t.senders[entity] = sender
+	return sender, nil
+}
+
+// replySession returns the session responses arrive in, taking a new one
+// if there is none or the last one failed
+func (t *Transport) replySession(ctx context.Context) (*replies, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.replies != nil && !t.replies.isClosed() {
+		return t.replies, nil
+	}
+	id := newID()
+	receiver, err := t.Client.AcceptSessionForQueue(ctx, t.ReplyQueue, id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("servicebus: %w", err)
+	}
+	r := &replies{sessionID: id, receiver: receiver, calls: make(map[string]*call), closed: make(chan struct{})}
+	go r.receive(t.lockRenewal())
+	t.replies = r
+	return r, nil
+}
+
+// Close releases the reply session, failing requests still waiting for
+// responses, and closes the senders
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	senders, replies := t.senders, t.replies
+	t.senders, t.replies = nil, nil
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var errs []error
+	if replies != nil {
+		replies.close(errClosed)
+	}
+	for _, sender := range senders {
+		errs = append(errs, sender.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// errClosed fails requests once their transport is closed
+var errClosed = errors.New("servicebus: transport closed")
+
+// replies is a session of the reply queue and the requests waiting for
+// responses in it
+type replies struct {
+	sessionID string
+	receiver  *azservicebus.SessionReceiver
+
+	mu     sync.Mutex
+	calls  map[string]*call // by request message ID
+	closed chan struct{}
+	err    error
+	once   sync.Once
+}
+
+func (r *replies) expect(id string) *call {
+	c := &call{notify: make(chan struct{}, 1)}
+	r.mu.Lock()
+	r.calls[id] = c
+	r.mu.Unlock()
+	return c
+}
+
+func (r *replies) forget(id string) {
+	r.mu.Lock()
+	delete(r.calls, id)
+	r.mu.Unlock()
+}
+
+func (r *replies) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// close fails the requests waiting for responses and releases the session
+func (r *replies) close(err error) {
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.err = err
+		close(r.closed)
+		r.mu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		r.receiver.Close(ctx)
+	})
+}
+
+// receive passes responses to the requests waiting for them, renewing
+// the session lock as it goes, until the session fails or is closed.
+// Responses nobody waits for, such as ones arriving after a timeout, are
+// dropped.
+func (r *replies) receive(renewal time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-r.closed
+		cancel()
+	}()
+	go renewLock(ctx, r.receiver, renewal, func(err error) { r.close(fmt.Errorf("servicebus: reply session lost: %w", err)) })
+
+	for {
+		msgs, err := r.receiver.ReceiveMessages(ctx, 32, nil)
+		if err != nil {
+			r.close(fmt.Errorf("servicebus: receiving responses: %w", err))
+			return
+		}
+		for _, msg := range msgs {
+			r.receiver.CompleteMessage(ctx, msg, nil)
+			if msg.CorrelationID == nil {
+				continue
+			}
+			r.mu.Lock()
+			c := r.calls[*msg.CorrelationID]
+			r.mu.Unlock()
+			if c != nil {
+				end, _ := msg.ApplicationProperties[End].(bool)
+				c.push(msg.Body, end)
+			}
+		}
+	}
+}
+
+// renewLock renews a session's lock every interval until ctx is done,
+// calling lost if a renewal fails
+func renewLock(ctx context.Context, session *azservicebus.SessionReceiver, interval time.Duration, lost func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := session.RenewSessionLock(ctx, nil); err != nil && ctx.Err() == nil {
+				lost(err)
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// call is a request waiting for its responses. They are queued rather
+// than handed over, so a slow caller holds up neither the reply session
+// nor other requests.
+type call struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	ended  bool
+	notify chan struct{}
+}
+
+func (c *call) push(msg []byte, end bool) {
+	c.mu.Lock()
+	if len(msg) > 0 {
+		c.queue = append(c.queue, msg)
+	}
+	c.ended = c.ended || end
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next response, or false once the request has ended
+func (c *call) next(ctx context.Context, r *replies) ([]byte, bool, error) {
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			msg := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return msg, true, nil
+		}
+		ended := c.ended
+		c.mu.Unlock()
+		if ended {
+			return nil, false, nil
+		}
+		select {
+		case <-c.notify:
+		case <-r.closed:
+			return nil, false, r.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package a2awebrtc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// Frames, as the package documentation describes them
+const (
+	headerSize = 9
+	flagMore   = 1
+	flagEnd    = 2
+
+	// maxChunk keeps frames within the message size every browser accepts
+	maxChunk = 16 << 10
+	// maxMessageBytes bounds the messages a peer reassembles
+	maxMessageBytes = 32 << 20
+	// bufferLimit is how much may wait in a data channel's send buffer
+	// before senders wait for it to drain
+	bufferLimit = 1 << 20
+)
+
+// errClosed fails requests once their peer connection has closed
+var errClosed = errors.New("webrtc: peer connection closed")
+
+// peer is a peer connection and the data channel requests go over
+type peer struct {
+	pc *webrtc.PeerConnection
+	dc *webrtc.DataChannel
+	// receive is called with each complete message, in order
+	receive func(id uint64, msg []byte, end bool)
+	onClose func()
+
+	sendMu sync.Mutex
+	low    chan struct{} // signaled when the send buffer drains
+
+	mu      sync.Mutex
+	next    uint64
+	calls   map[uint64]*call  // requests sent, by ID
+	partial map[uint64][]byte // messages being reassembled
+	cancels map[uint64]func() // requests being served, by ID
+	closed  chan struct{}
+	err     error
+	once    sync.Once
+}
+
+func newPeer(pc *webrtc.PeerConnection, dc *webrtc.DataChannel) *peer {
+	p := &peer{
+		pc:      pc,
+		dc:      dc,
+		low:     make(chan struct{}, 1),
+		calls:   make(map[uint64]*call),
+		partial: make(map[uint64][]byte),
+		cancels: make(map[uint64]func()),
+		closed:  make(chan struct{}),
+	}
+	dc.SetBufferedAmountLowThreshold(bufferLimit / 2)
+	dc.OnBufferedAmountLow(func() {
+		select {
+		case p.low <- struct{}{}:
+		default:
+		}
+	})
+	dc.OnMessage(p.onMessage)
+	dc.OnClose(func() { p.close(errClosed) })
+	pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		if state == webrtc.PeerConnectionStateFailed || state == webrtc.PeerConnectionStateClosed {
+			p.close(errClosed)
+		}
+	})
+	return p
+}
+
+// close fails the requests waiting on the peer and closes its connection
+func (p *peer) close(err error) {
+	p.once.Do(func() {
+		p.mu.Lock()
+		p.err = err
+		close(p.closed)
+		for _, cancel := range p.cancels {
+			cancel()
+		}
+		p.mu.Unlock()
+		p.pc.Close()
+		if p.onClose != nil {
+			p.onClose()
+		}
+	})
+}
+
+func (p *peer) isClosed() bool {
+	select {
+	case <-p.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *peer) onMessage(msg webrtc.DataChannelMessage) {
+	if msg.IsString || len(msg.Data) < headerSize {
+		return
+	}
+	id := binary.BigEndian.Uint64(msg.Data)
+	flags := msg.Data[8]
+
+	p.mu.Lock()
+	data := append(p.partial[id], msg.Data[headerSize:]...)
+	if len(data) > maxMessageBytes {
+		p.mu.Unlock()
+		p.close(errors.New("webrtc: peer sent a message over 32 MiB"))
+		return
+	}
+	if flags&flagMore != 0 {
+		p.partial[id] = data
+		p.mu.Unlock()
+		return
+	}
+	delete(p.partial, id)
+	p.mu.Unlock()
+	p.receive(id, data, flags&flagEnd != 0)
+}
+
+// send sends msg as the frames of request id, ending the request if end
+// is set. Frames of concurrent messages are not interleaved.
+func (p *peer) send(id uint64, msg []byte, end bool) error {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+	for first := true; first || len(msg) > 0; first = false {
+		chunk := msg[:min(len(msg), maxChunk)]
+		msg = msg[len(chunk):]
+		var flags byte
+		if len(msg) > 0 {
+			flags |= flagMore
+		} else if end {
+			flags |= flagEnd
+		}
+		frame := make([]byte, headerSize, headerSize+len(chunk))
+		binary.BigEndian.PutUint64(frame, id)
+		frame[8] = flags
+		for p.dc.BufferedAmount() > bufferLimit {
+			select {
+			case <-p.low:
+			case <-p.closed:
+				return p.err
+			}
+		}
+		if err := p.dc.Send(append(frame, chunk...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call sends a request and calls handle with each response message until
+// the peer ends the request or handle returns an error, which call then
+// returns
+func (p *peer) call(ctx context.Context, msg []byte, handle func([]byte) error) error {
+	c := &call{notify: make(chan struct{}, 1)}
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		return p.err
+	}
+	p.next++
+	id := p.next
+	p.calls[id] = c
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.calls, id)
+		p.mu.Unlock()
+	}()
+
+	if err := p.send(id, msg, false); err != nil {
+		return err
+	}
+	for {
+		msg, ok, err := c.next(ctx, p)
+		if err == nil && !ok {
+			return nil
+		}
+		if err == nil {
+			err = handle(msg)
+		}
+		if err != nil {
+			if !p.isClosed() {
+				p.send(id, nil, true) // cancel the request
+			}
+			return err
+		}
+	}
+}
+
+// receiveResponse queues a response message for the call waiting for it
+func (p *peer) receiveResponse(id uint64, msg []byte, end bool) {
+	p.mu.Lock()
+	c := p.calls[id]
+	p.mu.Unlock()
+	if c != nil {
+		c.push(msg, end)
+	}
+}
+
+// call is a request waiting for its responses. They are queued rather
+// than handed over, so a slow caller holds up neither the data channel
+// nor other requests.
+type call struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	ended  bool
+	notify chan struct{}
+}
+
+func (c *call) push(msg []byte, end bool) {
+	c.mu.Lock()
+	if len(msg) > 0 {
+		c.queue = append(c.queue, msg)
+	}
+	c.ended = c.ended || end
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next response message, or false once the request has
+// ended
+func (c *call) next(ctx context.Context, p *peer) ([]byte, bool, error) {
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			msg := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return msg, true, nil
+		}
+		ended := c.ended
+		c.mu.Unlock()
+		if ended {
+			return nil, false, nil
+		}
+		select {
+		case <-c.notify:
+		case <-p.closed:
+			return nil, false, p.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package a2awebrtc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// Signaling exchanges the session descriptions that set up peer
+// connections. Peers behind NAT cannot be reached directly, so both sides
+// reach a service they can: SignalingServer, with HTTPSignaling as its
+// client, or one built on something already shared, such as a WebSocket
+// the agents hold open.
+type Signaling interface {
+	// Offer sends offer to agentID and returns its answer
+	Offer(ctx context.Context, agentID string, offer webrtc.SessionDescription) (webrtc.SessionDescription, error)
+	// Accept calls answer with each offer made to agentID, sending back
+	// the answer it returns, until ctx is done; it then returns nil
+	Accept(ctx context.Context, agentID string, answer func(offer webrtc.SessionDescription) (webrtc.SessionDescription, error)) error
+}
+
+// The requests and responses of SignalingServer's routes
+type (
+	offerRequest struct {
+		AgentID string                    `json:"agentId"`
+		Offer   webrtc.SessionDescription `json:"offer"`
+	}
+	offerResponse struct {
+		Answer webrtc.SessionDescription `json:"answer"`
+	}
+	acceptRequest struct {
+		AgentID string `json:"agentId"`
+	}
+	acceptResponse struct {
+		ID    string                    `json:"id"`
+		Offer webrtc.SessionDescription `json:"offer"`
+	}
+	answerRequest struct {
+		ID     string                     `json:"id"`
+		Answer *webrtc.SessionDescription `json:"answer,omitempty"`
+		Error  string                     `json:"error,omitempty"` // why the offer was refused
+	}
+)
+
+// SignalingServer is an http.Handler relaying offers to the agents they
+// are made to and their answers back, for peers that share nothing else.
+// Mount it at a path of its own; it serves three POST routes below it:
+//
+//	offer   {"agentId", "offer"}  waits for the agent's answer: {"answer"}
+//	accept  {"agentId"}           waits for an offer to the agent: {"id", "offer"}, or 204
+//	answer  {"id", "answer"}      answers the offer with that ID, or refuses it with {"id", "error"}
+//
+// It keeps nothing but pending offers, in memory, and checks no one's
+// identity: put authentication in front of it, so only an agent can
+// accept offers made to it.
+type SignalingServer struct {
+	// Timeout bounds how long an offer waits for an answer and an accept
+	// for an offer; zero means 25 seconds, below common proxy timeouts
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	next    int
+	queues  map[string]chan *pendingOffer // offers not yet accepted, by agent ID
+	pending map[string]*pendingOffer      // offers accepted and not yet answered, by ID
+}
+
+type pendingOffer struct {
+	id     string
+	offer  webrtc.SessionDescription
+	answer chan answerRequest
+	done   chan struct{} // closed once the offer stops waiting
+}
+
+// offerQueue holds as many offers to one agent as wait to be accepted
+const offerQueue = 16
+
+func (s *SignalingServer) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return 25 * time.Second
+}
+
+func (s *SignalingServer) queue(agentID string) chan *pendingOffer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queues == nil {
+		s.queues = make(map[string]chan *pendingOffer)
+		s.pending = make(map[string]*pendingOffer)
+	}
+	q, ok := s.queues[agentID]
+	if !ok {
+		q = make(chan *pendingOffer, offerQueue)
+		s.queues[agentID] = q
+	}
+	return q
+}
+
+// ServeHTTP implements http.Handler
+func (s *SignalingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, 1<<20)
+	switch path.Base(r.URL.Path) {
+	case "offer":
+		var req offerRequest
+		if json.NewDecoder(body).Decode(&req) != nil || req.AgentID == "" {
+			http.Error(w, "Invalid offer", http.StatusBadRequest)
+			return
+		}
+		s.offer(w, r, req)
+	case "accept":
+		var req acceptRequest
+		if json.NewDecoder(body).Decode(&req) != nil || req.AgentID == "" {
+			http.Error(w, "Invalid accept", http.StatusBadRequest)
+			return
+		}
+		s.accept(w, r, req)
+	case "answer":
+		var req answerRequest
+		if json.NewDecoder(body).Decode(&req) != nil || (req.Answer == nil && req.Error == "") {
+			http.Error(w, "Invalid answer", http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		pending, ok := s.pending[req.ID]
+		delete(s.pending, req.ID)
+		s.mu.Unlock()
+		if !ok {
+			http.Error(w, "No such offer", http.StatusNotFound)
+			return
+		}
+		pending.answer <- req
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *SignalingServer) offer(w http.ResponseWriter, r *http.Request, req offerRequest) {
+	s.mu.Lock()
+	s.next++
+	pending := &pendingOffer{id: strconv.Itoa(s.next), offer: req.Offer, answer: make(chan answerRequest, 1), done: make(chan struct{})}
+	s.mu.Unlock()
+	defer func() {
+		close(pending.done)
+		s.mu.Lock()
+		delete(s.pending, pending.id)
+		s.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(s.timeout())
+	defer timer.Stop()
+	select {
+	case s.queue(req.AgentID) <- pending:
+	default:
+		http.Error(w, "Too many offers waiting for "+req.AgentID, http.StatusServiceUnavailable)
+		return
+	}
+	select {
+	case answer := <-pending.answer:
+		if answer.Answer == nil {
+			http.Error(w, "Offer refused: "+answer.Error, http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(offerResponse{Answer: *answer.Answer})
+	case <-timer.C:
+		http.Error(w, req.AgentID+" did not answer", http.StatusGatewayTimeout)
+	case <-r.Context().Done():
+	}
+}
+
+func (s *SignalingServer) accept(w http.ResponseWriter, r *http.Request, req acceptRequest) {
+	timer := time.NewTimer(s.timeout())
+	defer timer.Stop()
+	q := s.queue(req.AgentID)
+	for {
+		select {
+		case pending := <-q:
+			s.mu.Lock()
+			select {
+			case <-pending.done: // the offerer gave up
+				s.mu.Unlock()
+				continue
+			default:
+			}
+			s.pending[pending.id] = pending
+			s.mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(acceptResponse{ID: pending.id, Offer: pending.offer})
+			return
+		case <-timer.C:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// HTTPSignaling is the client of a SignalingServer
+type HTTPSignaling struct {
+	URL    string       // where the SignalingServer is mounted
+	Client *http.Client // nil means http.DefaultClient
+	Token  string       // sent as a bearer token, if set
+}
+
+// Offer implements Signaling
+func (h *HTTPSignaling) Offer(ctx context.Context, agentID string, offer webrtc.SessionDescription) (webrtc.SessionDescription, error) {
+	var resp offerResponse
+	if _, err := h.post(ctx, "offer", offerRequest{AgentID: agentID, Offer: offer}, &resp); err != nil {
+		return webrtc.SessionDescription{}, err
+	}
+	return resp.Answer, nil
+}
+
+// Accept implements Signaling, answering each offer on its own goroutine
+// since gathering ICE candidates takes a while
+func (h *HTTPSignaling) Accept(ctx context.Context, agentID string, answer func(offer webrtc.SessionDescription) (webrtc.SessionDescription, error)) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		var offer acceptResponse
+		status, err := h.post(ctx, "accept", acceptRequest{AgentID: agentID}, &offer)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if status == http.StatusNoContent {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply := answerRequest{ID: offer.ID}
+			sdp, err := answer(offer.Offer)
+			if err != nil {
+				reply.Error = err.Error()
+			} else {
+				reply.Answer = &sdp
+			}
+			h.post(ctx, "answer", reply, nil)
+		}()
+	}
+}
+
+// post sends a request to a route of the SignalingServer, decoding a 200
+// response into out
+func (h *HTTPSignaling) post(ctx context.Context, route string, in, out interface{}) (int, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(h.URL, "/")+"/"+route, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("signaling: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK && out != nil:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("signaling: %w", err)
+		}
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, errors.New("signaling: " + strings.TrimSpace(string(msg)))
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,430 @@
+// Package a2awebrtc carries A2A JSON-RPC requests over WebRTC data
+// channels, so agents in browsers and desktop agents behind NAT exchange
+// tasks peer to peer. A signaling service only brokers the offer and
+// answer that set up each peer connection; once ICE has punched through,
+// requests and their responses flow directly between the peers, DTLS
+// encrypted, with no relay in the data path unless the network forces
+// one through a TURN server.
+//
+// The agent taking tasks listens for offers made to its ID and registers
+// a webrtc: endpoint in the directory:
+//
+//	signaling := &a2awebrtc.HTTPSignaling{URL: "https://signal.example.com/webrtc/"}
+//	rtc := &a2awebrtc.Transport{Signaling: signaling, Config: webrtc.Configuration{
+//		ICEServers: []webrtc.ICEServer{{URLs: []string{"stun:stun.l.google.com:19302"}}},
+//	}}
+//	server.Endpoint = a2awebrtc.Endpoint(server.AgentID)
+//	go rtc.Listen(ctx, server)
+//
+// Agents sending it tasks set the same transport for the scheme, and
+// connect on their first request:
+//
+//	agent.Transports = map[string]a2a.Transport{a2awebrtc.Scheme: rtc}
+//
+// Requests are handed to the server's HandleJSONRPC, so every method,
+// message/stream included, behaves as it does over HTTP; HTTP middleware
+// does not apply.
+//
+// Agents in browsers speak the same protocol over an ordered
+// RTCDataChannel labeled Label. Each binary message on it is a frame: the
+// ID the client chose for a request as a big-endian uint64, a byte of
+// flags, then up to 16 KiB of a message; flag 1 means the message
+// continues in the next frame. A request is one message,
+// {"correlationId": ..., "request": <JSON-RPC request>}, and each of its
+// responses one JSON-RPC response, after which the server sends an empty
+// frame with flag 2. A client sends the same empty frame to cancel.
+package a2awebrtc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/pion/webrtc/v4"
+)
+
+const (
+	// Scheme is the URL scheme of WebRTC endpoints, e.g.
+	// webrtc:field-agent
+	Scheme = "webrtc"
+	// Label is the label of the data channel requests go over
+	Label = "a2a"
+)
+
+// Endpoint returns the endpoint to register for an agent listening for
+// offers
+func Endpoint(agentID string) string {
+	return Scheme + ":" + agentID
+}
+
+// envelope is a request as sent over a data channel
+type envelope struct {
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Request       json.RawMessage `json:"request"`
+}
+
+// Transport is an a2a.Transport over WebRTC data channels. Requests to an
+// agent share one peer connection, dialed on the first and again after it
+// fails; Close closes them.
+type Transport struct {
+	// Signaling exchanges offers and answers with peers
+	Signaling Signaling
+	// Config configures peer connections, above all the STUN and TURN
+	// servers ICE finds a path between the peers with
+	Config webrtc.Configuration
+	// API creates peer connections, e.g. one made with a SettingEngine
+	// restricting ports; nil means webrtc's defaults
+	API   *webrtc.API
+	Codec a2a.Codec // nil means a2a.DefaultCodec
+	// DialTimeout bounds setting up a peer connection; zero means 30
+	// seconds
+	DialTimeout time.Duration
+
+	mu    sync.Mutex
+	peers map[string]*dialing // dialed, by agent ID
+}
+
+// dialing is a peer connection being set up, or set up once done is
+// closed
+type dialing struct {
+	done chan struct{}
+	peer *peer
+	err  error
+}
+
+func (t *Transport) codec() a2a.Codec {
+	if t.Codec != nil {
+		return t.Codec
+	}
+	return a2a.DefaultCodec
+}
+
+func (t *Transport) dialTimeout() time.Duration {
+	if t.DialTimeout > 0 {
+		return t.DialTimeout
+	}
+	return 30 * time.Second
+}
+
+func (t *Transport) newPeerConnection() (*webrtc.PeerConnection, error) {
+	if t.API != nil {
+		return t.API.NewPeerConnection(t.Config)
+	}
+	return webrtc.NewPeerConnection(t.Config)
+}
+
+// errAnswered stops Send's stream at the first response
+var errAnswered = errors.New("answered")
+
+// Send implements a2a.Transport
+func (t *Transport) Send(ctx context.Context, endpoint string, req a2a.JSONRPCRequest) (*a2a.JSONRPCResponse, error) {
+	var resp *a2a.JSONRPCResponse
+	err := t.Stream(ctx, endpoint, req, func(r *a2a.JSONRPCResponse) error {
+		resp = r
+		return errAnswered
+	})
+	if err == errAnswered {
+		return resp, nil
+	}
+	if err == nil {
+		err = errors.New("webrtc: request ended without a response")
+	}
+	return nil, err
+}
+
+// Stream implements a2a.Transport
+func (t *Transport) Stream(ctx context.Context, endpoint string, req a2a.JSONRPCRequest, handle func(*a2a.JSONRPCResponse) error) error {
+	agentID, ok := strings.CutPrefix(endpoint, Scheme+":")
+	if !ok || agentID == "" {
+		return fmt.Errorf("webrtc: %q is not a webrtc: endpoint", endpoint)
+	}
+	codec := t.codec()
+	body, err := codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	msg, err := json.Marshal(envelope{CorrelationID: a2a.CorrelationID(ctx), Request: body})
+	if err != nil {
+		return err
+	}
+	p, err := t.dial(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	return p.call(ctx, msg, func(data []byte) error {
+		var resp a2a.JSONRPCResponse
+		if err := codec.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("webrtc: invalid response: %w", err)
+		}
+		return handle(&resp)
+	})
+}
+
+// dial returns the peer connection to agentID, setting it up if there is
+// none. Concurrent requests wait for the same one.
+func (t *Transport) dial(ctx context.Context, agentID string) (*peer, error) {
+	for {
+		t.mu.Lock()
+		if t.peers == nil {
+			t.peers = make(map[string]*dialing)
+		}
+		d := t.peers[agentID]
+		if d == nil {
+			d = &dialing{done: make(chan struct{})}
+			t.peers[agentID] = d
+			t.mu.Unlock()
+			d.peer, d.err = t.connect(agentID, d)
+			if d.err != nil {
+				t.forget(agentID, d)
+			}
+			close(d.done)
+			return d.peer, d.err
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-d.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		if !d.peer.isClosed() {
+			return d.peer, nil
+		}
+		t.forget(agentID, d)
+	}
+}
+
+// forget drops d unless it has already been replaced
+func (t *Transport) forget(agentID string, d *dialing) {
+	t.mu.Lock()
+	if t.peers[agentID] == d {
+		delete(t.peers, agentID)
+	}
+	t.mu.Unlock()
+}
+
+// connect offers agentID a peer connection through the signaling service
+// and waits for its data channel to open. The offer carries every ICE
+// candidate, so one round trip sets the connection up.
+func (t *Transport) connect(agentID string, d *dialing) (*peer, error) {
+	if t.Signaling == nil {
+		return nil, errors.New("webrtc: no Signaling to reach peers with")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), t.dialTimeout())
+	defer cancel()
+
+	pc, err := t.newPeerConnection()
+	if err != nil {
+		return nil, err
+	}
+	ordered := true
+	dc, err := pc.CreateDataChannel(Label, &webrtc.DataChannelInit{Ordered: &ordered})
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	p := newPeer(pc, dc)
+	p.receive = p.receiveResponse
+	p.onClose = func() { t.forget(agentID, d) }
+	opened := make(chan struct{})
+	dc.OnOpen(func() { close(opened) })
+
+	fail := func(err error) (*peer, error) {
+		p.close(err)
+		return nil, fmt.Errorf("webrtc: connecting to %s: %w", agentID, err)
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		return fail(err)
+	}
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(offer); err != nil {
+		return fail(err)
+	}
+	select {
+	case <-gathered:
+	case <-ctx.Done():
+		return fail(ctx.Err())
+	}
+	answer, err := t.Signaling.Offer(ctx, agentID, *pc.LocalDescription())
+	if err != nil {
+		return fail(err)
+	}
+	if err := pc.SetRemoteDescription(answer); err != nil {
+		return fail(err)
+	}
+	select {
+	case <-opened:
+		return p, nil
+	case <-p.closed:
+		return fail(p.err)
+	case <-ctx.Done():
+		return fail(ctx.Err())
+	}
+}
+
+// Close closes the peer connections dialed
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	peers := t.peers
+	t.peers = nil
+	t.mu.Unlock()
+	for _, d := range peers {
+		select {
+		case <-d.done:
+			if d.peer != nil {
+				d.peer.close(errClosed)
+			}
+		default: // still dialing; fails on its own
+		}
+	}
+	return nil
+}
+
+// Listen implements a2a.Transport, answering the offers made to server's
+// agent and those it hosts until ctx is done, then closing their peer
+// connections. It returns early if the signaling service fails.
+func (t *Transport) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	if t.Signaling == nil {
+		return errors.New("webrtc: no Signaling to take offers from")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errc := make(chan error, 1)
+	for i, agent := range server.Agents() {
+		path := "" // the server's own agent
+		if i > 0 {
+			path = agent.AgentID
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := t.Signaling.Accept(ctx, agent.AgentID, func(offer webrtc.SessionDescription) (webrtc.SessionDescription, error) {
+				return t.answer(ctx, server, path, offer)
+			})
+			if err != nil && ctx.Err() == nil {
+				select {
+				case errc <- err:
+				default:
+				}
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errc:
+		return err
+	default:
+		return nil
+	}
+}
+
+// answer accepts an offer, serving the requests that arrive on its data
+// channel to agentID until ctx is done
+func (t *Transport) answer(ctx context.Context, server *a2a.A2AServer, agentID string, offer webrtc.SessionDescription) (webrtc.SessionDescription, error) {
+	pc, err := t.newPeerConnection()
+	if err != nil {
+		return webrtc.SessionDescription{}, err
+	}
+	var opened atomic.Bool
+	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+		if dc.Label() != Label || !opened.CompareAndSwap(false, true) {
+			dc.Close()
+			return
+		}
+		p := newPeer(pc, dc)
+		p.receive = func(id uint64, msg []byte, end bool) {
+			p.serve(ctx, server, agentID, t.codec(), id, msg, end)
+		}
+		stop := context.AfterFunc(ctx, func() { p.close(errClosed) })
+		p.onClose = func() { stop() }
+	})
+	// Peers that never open a data channel are let go
+	time.AfterFunc(t.dialTimeout(), func() {
+		if !opened.Load() {
+			pc.Close()
+		}
+	})
+
+	fail := func(err error) (webrtc.SessionDescription, error) {
+		pc.Close()
+		return webrtc.SessionDescription{}, err
+	}
+	if err := pc.SetRemoteDescription(offer); err != nil {
+		return fail(err)
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		return fail(err)
+	}
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(answer); err != nil {
+		return fail(err)
+	}
+	select {
+	case <-gathered:
+	case <-ctx.Done():
+		return fail(ctx.Err())
+	}
+	return *pc.LocalDescription(), nil
+}
+
+// serve handles a request message, or a client canceling one, sending
+// each response and then ending the request
+func (p *peer) serve(ctx context.Context, server *a2a.A2AServer, agentID string, codec a2a.Codec, id uint64, msg []byte, end bool) {
+	if end {
+		p.mu.Lock()
+		cancel := p.cancels[id]
+		p.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		cancel()
+		return
+	}
+	p.cancels[id] = cancel
+	p.mu.Unlock()
+
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			delete(p.cancels, id)
+			p.mu.Unlock()
+			cancel()
+		}()
+		var env envelope
+		if err := json.Unmarshal(msg, &env); err != nil || len(env.Request) == 0 {
+			body, _ := codec.Marshal(a2a.JSONRPCResponse{JSONRPC: "2.0", Error: &a2a.JSONRPCError{Code: a2a.CodeParseError, Message: "Parse error"}})
+			p.send(id, body, true)
+			return
+		}
+		if env.CorrelationID != "" {
+			ctx = a2a.ContextWithCorrelationID(ctx, env.CorrelationID)
+		}
+		server.HandleJSONRPC(ctx, agentID, env.Request, func(resp *a2a.JSONRPCResponse) {
+			if body, err := codec.Marshal(resp); err == nil {
+				p.send(id, body, false)
+			}
+		})
+		p.send(id, nil, true)
+	}()
+}
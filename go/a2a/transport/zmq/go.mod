@@ -0,0 +1,16 @@
+module github.com/mentessaas/a2a-protocol/go/a2a/transport/zmq
+
+go 1.24
+
+require (
+	github.com/go-zeromq/zmq4 v0.17.0
+	github.com/mentessaas/a2a-protocol/go v0.0.0
+)
+
+require (
+	github.com/go-zeromq/goczmq/v4 v4.2.2 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+)
+
+replace github.com/mentessaas/a2a-protocol/go => ../../..
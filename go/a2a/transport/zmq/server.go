@@ -0,0 +1,112 @@
+package a2azmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-zeromq/zmq4"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Listen implements a2a.Transport, binding a ROUTER socket on Addr and
+// serving server's agent and those it hosts until ctx is done, then
+// canceling the requests still being served
+func (t *Transport) Listen(ctx context.Context, server *a2a.A2AServer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &router{sock: zmq4.NewRouter(ctx), cancels: make(map[string]context.CancelFunc)}
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		r.sock.Close()
+		wg.Wait()
+	}()
+	if err := r.sock.Listen(t.Addr); err != nil {
+		return fmt.Errorf("zmq: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { r.sock.Close() })
+	defer stop()
+
+	for {
+		msg, err := r.sock.Recv()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("zmq: %w", err)
+		}
+		// A ROUTER prefixes each message with the identity of its sender
+		if len(msg.Frames) < 2 || len(msg.Frames[1]) != 8 {
+			continue
+		}
+		peer, id := msg.Frames[0], msg.Frames[1]
+		key := string(peer) + string(id)
+		if len(msg.Frames) == 2 {
+			r.cancel(key)
+			continue
+		}
+		if len(msg.Frames) != 5 {
+			continue
+		}
+		agentID, correlationID, body := string(msg.Frames[2]), string(msg.Frames[3]), msg.Frames[4]
+
+		reqCtx, reqCancel := context.WithCancel(ctx)
+		if correlationID != "" {
+			reqCtx = a2a.ContextWithCorrelationID(reqCtx, correlationID)
+		}
+		r.mu.Lock()
+		r.cancels[key] = reqCancel
+		r.mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.cancel(key)
+			t.serve(reqCtx, server, r, peer, id, agentID, body)
+		}()
+	}
+}
+
+// router is a ROUTER socket and the requests being served on it
+type router struct {
+	sock   zmq4.Socket
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc // by peer identity and request ID
+}
+
+func (r *router) cancel(key string) {
+	r.mu.Lock()
+	cancel := r.cancels[key]
+	delete(r.cancels, key)
+	r.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (r *router) send(frames ...[]byte) error {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+	return r.sock.SendMulti(zmq4.NewMsgFrom(frames...))
+}
+
+// serve handles a request, sending each response back to peer. Each is
+// sent once the next arrives, so the last can be flagged as such.
+func (t *Transport) serve(ctx context.Context, server *a2a.A2AServer, r *router, peer, id []byte, agentID string, body []byte) {
+	codec := t.codec()
+	var held []byte
+	server.HandleJSONRPC(ctx, agentID, body, func(resp *a2a.JSONRPCResponse) {
+		data, err := codec.Marshal(resp)
+		if err != nil {
+			return
+		}
+		if held != nil && ctx.Err() == nil {
+			r.send(peer, id, []byte{flagMore}, held)
+		}
+		held = data
+	})
+	if ctx.Err() == nil {
+		r.send(peer, id, []byte{flagEnd}, held)
+	}
+}
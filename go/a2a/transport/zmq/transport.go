@@ -0,0 +1,315 @@
+// Package a2azmq carries A2A JSON-RPC requests over ZeroMQ, for agent
+// meshes within a datacenter that dispatch tasks in well under a
+// millisecond and cannot afford HTTP's per-request overhead.
+//
+// The agent taking tasks binds a ROUTER socket and registers a zmq:
+// endpoint; agents sending it tasks share one DEALER socket per endpoint,
+// which ZeroMQ reconnects on its own:
+//
+//	zmq := &a2azmq.Transport{Addr: "tcp://*:5555"}
+//	server.Endpoint = a2azmq.Endpoint("10.0.0.5:5555")
+//	go zmq.Listen(ctx, server)
+//
+//	agent.Transports = map[string]a2a.Transport{a2azmq.Scheme: zmq}
+//
+// Requests are handed to the server's HandleJSONRPC, so every method,
+// message/stream included, behaves as it does over HTTP; HTTP middleware
+// does not apply, and neither does TLS: keep the mesh on a trusted
+// network.
+//
+// Every message is multipart. A request is four frames: the ID the
+// client chose for it as a big-endian uint64, the ID of the hosted agent
+// it is for or an empty frame, its correlation ID, and the JSON-RPC
+// request. Each response is three: the request's ID, a byte that is 1 on
+// the request's last response and 0 otherwise, and the JSON-RPC response.
+// A client sends the request's ID alone to cancel it.
+package a2azmq
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-zeromq/zmq4"
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+)
+
+// Scheme is the URL scheme of ZeroMQ endpoints, whose host and port are
+// the TCP address dialed, e.g. zmq://10.0.0.5:5555; a path of
+// a2a.AgentPath(agentID) addresses an agent the server hosts
+const Scheme = "zmq"
+
+// Endpoint returns the endpoint to register for an agent served on addr,
+// a host and port
+func Endpoint(addr string) string {
+	return Scheme + "://" + addr
+}
+
+// Transport is an a2a.Transport over ZeroMQ. Sockets are dialed on first
+// use and kept until Close.
+type Transport struct {
+	// Addr is the ZeroMQ address Listen binds, e.g. "tcp://*:5555"
+	Addr  string
+	Codec a2a.Codec // nil means a2a.DefaultCodec
+
+	mu      sync.Mutex
+	dealers map[string]*dealer // by address dialed
+}
+
+func (t *Transport) codec() a2a.Codec {
+	if t.Codec != nil {
+		return t.Codec
+	}
+	return a2a.DefaultCodec
+}
+
+// errAnswered stops Send's stream at the first response
+var errAnswered = errors.New("answered")
+
+// Send implements a2a.Transport
+func (t *Transport) Send(ctx context.Context, endpoint string, req a2a.JSONRPCRequest) (*a2a.JSONRPCResponse, error) {
+	var resp *a2a.JSONRPCResponse
+	err := t.Stream(ctx, endpoint, req, func(r *a2a.JSONRPCResponse) error {
+		resp = r
+		return errAnswered
+	})
+	if err == errAnswered {
+		return resp, nil
+	}
+	if err == nil {
+		err = errors.New("zmq: request ended without a response")
+	}
+	return nil, err
+}
+
+// Stream implements a2a.Transport
+func (t *Transport) Stream(ctx context.Context, endpoint string, req a2a.JSONRPCRequest, handle func(*a2a.JSONRPCResponse) error) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != Scheme || u.Host == "" {
+		return fmt.Errorf("zmq: %q is not a zmq: endpoint", endpoint)
+	}
+	agentID := strings.TrimPrefix(u.Path, a2a.AgentPath(""))
+
+	codec := t.codec()
+	body, err := codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	d, err := t.dealer(u.Host)
+	if err != nil {
+		return err
+	}
+	return d.call(ctx, [][]byte{[]byte(agentID), []byte(a2a.CorrelationID(ctx)), body}, func(data []byte) error {
+		var resp a2a.JSONRPCResponse
+		if err := codec.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("zmq: invalid response: %w", err)
+		}
+		return handle(&resp)
+	})
+}
+
+// dealer returns the socket dialed to addr, dialing it if there is none
+// or the last one closed
+func (t *Transport) dealer(addr string) (*dealer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if d, ok := t.dealers[addr]; ok && !d.isClosed() {
+		return d, nil
+	}
+	sock := zmq4.NewDealer(context.Background())
+	if err := sock.Dial("tcp://" + addr); err != nil {
+		sock.Close()
+		return nil, fmt.Errorf("zmq: %w", err)
+	}
+	d := &dealer{sock: sock, calls: make(map[uint64]*call), closed: make(chan struct{})}
+	go d.receive()
+	if t.dealers == nil {
+		t.dealers = make(map[string]*dealer)
+	}
+	t.dealers[addr] = d
+	return d, nil
+}
+
+// Close closes the sockets dialed, failing requests still waiting for
+// responses
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	dealers := t.dealers
+	t.dealers = nil
+	t.mu.Unlock()
+	for _, d := range dealers {
+		d.close(errClosed)
+	}
+	return nil
+}
+
+// errClosed fails requests once their socket has closed
+var errClosed = errors.New("zmq: socket closed")
+
+// The flags of responses, as the package documentation describes them
+const (
+	flagMore byte = 0
+	flagEnd  byte = 1
+)
+
+// dealer is a DEALER socket and the requests waiting for responses on it
+type dealer struct {
+	sock   zmq4.Socket
+	sendMu sync.Mutex
+
+	mu     sync.Mutex
+	next   uint64
+	calls  map[uint64]*call
+	closed chan struct{}
+	err    error
+	once   sync.Once
+}
+
+func (d *dealer) isClosed() bool {
+	select {
+	case <-d.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *dealer) close(err error) {
+	d.once.Do(func() {
+		d.mu.Lock()
+		d.err = err
+		close(d.closed)
+		d.mu.Unlock()
+		d.sock.Close()
+	})
+}
+
+func (d *dealer) send(frames ...[]byte) error {
+	d.sendMu.Lock()
+	defer d.sendMu.Unlock()
+	return d.sock.SendMulti(zmq4.NewMsgFrom(frames...))
+}
+
+// receive passes responses to the requests waiting for them until the
+// socket closes. Responses nobody waits for, such as ones arriving after
+// a timeout, are dropped.
+func (d *dealer) receive() {
+	for {
+		msg, err := d.sock.Recv()
+		if err != nil {
+			d.close(errClosed)
+			return
+		}
+		if len(msg.Frames) != 3 || len(msg.Frames[0]) != 8 || len(msg.Frames[1]) != 1 {
+			continue
+		}
+		id := binary.BigEndian.Uint64(msg.Frames[0])
+		d.mu.Lock()
+		c := d.calls[id]
+		d.mu.Unlock()
+		if c != nil {
+			c.push(msg.Frames[2], msg.Frames[1][0] == flagEnd)
+		}
+	}
+}
+
+// call sends a request and calls handle with each response until the
+// server ends the request or handle returns an error, which call then
+// returns
+func (d *dealer) call(ctx context.Context, frames [][]byte, handle func([]byte) error) error {
+	c := &call{notify: make(chan struct{}, 1)}
+	d.mu.Lock()
+	if d.err != nil {
+		d.mu.Unlock()
+		return d.err
+	}
+	d.next++
+	id := d.next
+	d.calls[id] = c
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.calls, id)
+		d.mu.Unlock()
+	}()
+
+	idFrame := binary.BigEndian.AppendUint64(nil, id)
+	if err := d.send(append([][]byte{idFrame}, frames...)...); err != nil {
+		return fmt.Errorf("zmq: %w", err)
+	}
+	for {
+		msg, ok, err := c.next(ctx, d)
+		if err == nil && !ok {
+			return nil
+		}
+		if err == nil {
+			err = handle(msg)
+		}
+		if err != nil {
+			if !d.isClosed() && !c.finished() {
+				d.send(idFrame) // cancel the request
+			}
+			return err
+		}
+	}
+}
+
+// call is a request waiting for its responses. They are queued rather
+// than handed over, so a slow caller holds up neither the socket nor
+// other requests.
+type call struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	ended  bool
+	notify chan struct{}
+}
+
+func (c *call) push(msg []byte, end bool) {
+	c.mu.Lock()
+	if len(msg) > 0 {
+		c.queue = append(c.queue, msg)
+	}
+	c.ended = c.ended || end
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// finished reports whether the request has ended and its responses have
+// all been taken
+func (c *call) finished() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ended && len(c.queue) == 0
+}
+
+// next returns the next response, or false once the request has ended
+func (c *call) next(ctx context.Context, d *dealer) ([]byte, bool, error) {
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			msg := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return msg, true, nil
+		}
+		ended := c.ended
+		c.mu.Unlock()
+		if ended {
+			return nil, false, nil
+		}
+		select {
+		case <-c.notify:
+		case <-d.closed:
+			return nil, false, d.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UploadPath is where servers accept tasks whose input is streamed as the
+// raw request body instead of being embedded in JSON-RPC params
+const UploadPath = "/a2a/upload"
+
+// UploadFunc handles a task whose input is streamed. body yields the
+// request body as it arrives, so large documents never have to fit in
+// memory; it is only valid until the handler returns.
+type UploadFunc func(ctx context.Context, action string, body io.Reader, contentType, sender string) (map[string]interface{}, error)
+
+// HandleUpload registers the handler for streamed task inputs. Uploads are
+// POSTed to UploadPath with the action, sender and optional taskId as query
+// parameters and respond with a TaskResult, or a JSON error as REST
+// requests do if the server's JWT verification or Authorizer refuses them.
+// Only the server's own identity accepts uploads.
+func (s *A2AServer) HandleUpload(handler UploadFunc) {
+	s.uploadFunc = handler
+}
+
+func (s *A2AServer) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.uploadFunc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	task := TaskParams{
+		TaskID:  query.Get("taskId"),
+		AgentID: s.AgentID,
+		Action:  query.Get("action"),
+		Sender:  query.Get("sender"),
+	}
+	if task.TaskID == "" {
+		task.TaskID = generateID()
+	}
+	if task.Action == "" {
+		http.Error(w, "Missing action", http.StatusBadRequest)
+		return
+	}
+	if metadata := query.Get("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &task.Metadata); err != nil {
+			http.Error(w, "Invalid metadata", http.StatusBadRequest)
+			return
+		}
+	}
+	sender, rpcErr := s.jwtSender(r.Context(), bearerJWT(r.Header.Get("Authorization")), task.AgentID, task.Sender)
+	if rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	task.Sender = sender
+	if !s.acquireTask() {
+		http.Error(w, "Server draining", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.releaseTask()
+
+	ctx := correlate(requestCorrelation(w, r), &task)
+	if rpcErr := s.authorize(ctx, task); rpcErr != nil {
+		s.restError(w, r, rpcErr)
+		return
+	}
+	body := io.Reader(r.Body)
+	if s.limits.MaxUploadBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.limits.MaxUploadBytes)
+	}
+
+	s.taskReceived(ctx, task)
+	ctx, metadata := withMetadata(ctx, task)
+	output, err := s.uploadFunc(ctx, task.Action, body, r.Header.Get("Content-Type"), task.Sender)
+
+	result := &TaskResult{
+		TaskID:        task.TaskID,
+		Status:        TaskStateCompleted,
+		Output:        output,
+		UpdatedAt:     time.Now().UTC(),
+		CorrelationID: task.CorrelationID,
+		Metadata:      metadata.merged(),
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = &TaskError{Code: CodeInvalidRequest, Message: "Upload too large", Err: err}
+	}
+	if err != nil {
+		result.Status = TaskStateFailed
+		result.Output = nil
+		result.Error = taskFailure(err)
+	}
+	s.taskFinished(ctx, task, result)
+
+	s.writeJSON(w, r, result)
+}
+
+// UploadTask sends a task whose input is streamed from body, for inputs too
+// large to embed in JSON. The target must have registered an UploadFunc.
+func (a *A2AAgent) UploadTask(targetAgentID, action string, body io.Reader, contentType, directoryURL string, opts ...TaskOption) (*TaskResult, error) {
+	agentInfo, err := a.resolveAgent(targetAgentID, directoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	params := TaskParams{TaskID: generateID(), Action: action, Sender: a.AgentID}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	query := url.Values{}
+	query.Set("taskId", params.TaskID)
+	query.Set("action", params.Action)
+	query.Set("sender", params.Sender)
+	if len(params.Metadata) > 0 {
+		metadata, err := json.Marshal(params.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("metadata", string(metadata))
+	}
+	uploadURL := strings.TrimSuffix(agentInfo.Endpoint, "/") + UploadPath + "?" + query.Encode()
+
+	ctx, err := a.identify(context.Background(), agentInfo.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if params.CorrelationID != "" {
+		req.Header.Set(CorrelationHeader, params.CorrelationID)
+	}
+	if token := jwtFromContext(ctx); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	setCredential(ctx, req.Header)
+
+	resp, err := a.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var refusal struct {
+			Error *JSONRPCError `json:"error"`
+		}
+		if a.codec().NewDecoder(resp.Body).Decode(&refusal) == nil && refusal.Error != nil {
+			return nil, refusal.Error
+		}
+		return nil, fmt.Errorf("upload failed: HTTP %d", resp.StatusCode)
+	}
+
+	var taskResult TaskResult
+	if err := a.codec().NewDecoder(resp.Body).Decode(&taskResult); err != nil {
+		return nil, err
+	}
+
+	return &taskResult, nil
+}
@@ -0,0 +1,75 @@
+// Command a2a-directory runs a standalone A2A directory.
+//
+// Run:
+//
+//	go run ./cmd/a2a-directory -addr :8080
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	ttl := flag.Duration("ttl", 0, "default registration TTL without heartbeats (0 keeps registrations forever)")
+	health := flag.Duration("health-interval", 0, "how often to probe agents' health endpoints (0 disables health checks)")
+	peers := flag.String("peers", "", "comma-separated URLs of peer directories to federate discovery with")
+	id := flag.String("id", "", "unique ID of this directory among federated peers")
+	replicas := flag.String("replicas", "", "comma-separated URLs of the other replicas of this directory")
+	replicationToken := flag.String("replication-token", "", "shared bearer token replicas authenticate with")
+	adminToken := flag.String("admin-token", "", "bearer token enabling the admin API (default $A2A_ADMIN_TOKEN)")
+	dashboard := flag.Bool("dashboard", false, "serve the HTML dashboard at /a2a/dashboard")
+	metrics := flag.Bool("metrics", false, "serve Prometheus metrics at /metrics")
+	registrationSecret := flag.String("registration-secret", "", "secret agent tokens are signed with; requires agents to authenticate (default $A2A_REGISTRATION_SECRET)")
+	endpointProof := flag.Bool("endpoint-proof", false, "require signed registrations and challenge each endpoint to prove ownership")
+	rateLimit := flag.Float64("rate-limit", 0, "requests per second allowed from each client address (0 = unlimited)")
+	maxAgents := flag.Int("max-agents", 0, "maximum number of registered agents (0 = unlimited)")
+	webhooks := flag.Bool("webhooks", false, "let clients subscribe webhooks to deregistrations and pruned agents")
+	webhookToken := flag.String("webhook-token", "", "bearer token required to add or remove webhooks (default $A2A_WEBHOOK_TOKEN)")
+	flag.Parse()
+	if *adminToken == "" {
+		*adminToken = os.Getenv("A2A_ADMIN_TOKEN")
+	}
+	if *registrationSecret == "" {
+		*registrationSecret = os.Getenv("A2A_REGISTRATION_SECRET")
+	}
+
+	opts := []directory.Option{directory.WithTTL(*ttl), directory.WithAdminToken(*adminToken)}
+	if *registrationSecret != "" {
+		opts = append(opts, directory.WithAuthenticator(directory.HMACAuthenticator{Secret: []byte(*registrationSecret)}))
+	}
+	if *rateLimit > 0 || *maxAgents > 0 {
+		opts = append(opts, directory.WithLimits(directory.Limits{RequestsPerIP: *rateLimit, MaxAgents: *maxAgents, ValidateCapabilities: true}))
+	}
+	if *webhooks {
+		if *webhookToken == "" {
+			*webhookToken = os.Getenv("A2A_WEBHOOK_TOKEN")
+		}
+		opts = append(opts, directory.WithWebhooks(directory.Webhooks{Token: *webhookToken}))
+	}
+	if *endpointProof {
+		opts = append(opts, directory.WithEndpointProof(directory.EndpointProof{Required: true, Challenge: true}))
+	}
+	if *health > 0 {
+		opts = append(opts, directory.WithHealthCheck(directory.HealthCheck{Interval: *health}))
+	}
+	if *peers != "" {
+		opts = append(opts, directory.WithFederation(directory.Federation{ID: *id, Peers: strings.Split(*peers, ",")}))
+	}
+	if *replicas != "" {
+		opts = append(opts, directory.WithReplication(directory.Replication{Peers: strings.Split(*replicas, ","), Token: *replicationToken}))
+	}
+	if *dashboard {
+		opts = append(opts, directory.WithDashboard())
+	}
+	if *metrics {
+		opts = append(opts, directory.WithMetrics())
+	}
+	dir := directory.NewServer(opts...)
+	log.Fatal(dir.ListenAndServe(*addr))
+}
@@ -0,0 +1,46 @@
+// Command a2a-gateway runs a standalone A2A gateway, relaying requests to
+// agents connected to it with gateway.Relay.
+//
+// Run:
+//
+//	go run ./cmd/a2a-gateway -addr :8443 -tls-cert cert.pem -tls-key key.pem -relay-secret $SECRET
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/directory"
+	"github.com/mentessaas/a2a-protocol/go/a2a/gateway"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	relaySecret := flag.String("relay-secret", "", "secret relay tokens are signed with, as directory.AgentToken signs them; requires relays to authenticate (default $A2A_RELAY_SECRET)")
+	callerToken := flag.String("caller-token", "", "bearer token callers must present (default $A2A_GATEWAY_TOKEN)")
+	certFile := flag.String("tls-cert", "", "TLS certificate file; serves HTTPS with -tls-key")
+	keyFile := flag.String("tls-key", "", "TLS key file")
+	flag.Parse()
+	if *relaySecret == "" {
+		*relaySecret = os.Getenv("A2A_RELAY_SECRET")
+	}
+	if *callerToken == "" {
+		*callerToken = os.Getenv("A2A_GATEWAY_TOKEN")
+	}
+
+	var opts []gateway.Option
+	if *relaySecret != "" {
+		opts = append(opts, gateway.WithAuthenticator(directory.HMACAuthenticator{Secret: []byte(*relaySecret)}))
+	} else {
+		log.Print("warning: relays are not authenticated; set -relay-secret")
+	}
+	if *callerToken != "" {
+		opts = append(opts, gateway.WithCallerAuthenticator(directory.TokenAuthenticator{Tokens: map[string]string{"*": *callerToken}}))
+	}
+	gw := gateway.NewServer(opts...)
+	if *certFile != "" {
+		log.Fatal(gw.ListenAndServeTLS(*addr, *certFile, *keyFile))
+	}
+	log.Fatal(gw.ListenAndServe(*addr))
+}
@@ -0,0 +1,64 @@
+// Command a2a-interop replays the interop fixtures recorded from other A2A
+// SDKs, and the golden fixtures of this one, against an A2A server.
+//
+// Run:
+//
+//	go run ./cmd/a2a-interop                                   # against the built-in interop agent
+//	go run ./cmd/a2a-interop -url http://localhost:9000/        # against another SDK's interop agent
+//	go run ./cmd/a2a-interop -golden a2a/interop/golden         # write the golden fixtures
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a/interop"
+)
+
+func main() {
+	url := flag.String("url", "", "A2A server to check, serving the interop agent (default: the built-in one)")
+	fixturesDir := flag.String("fixtures", "", "directory to read fixtures from instead of the embedded ones")
+	golden := flag.String("golden", "", "write this SDK's golden fixtures to this directory and exit")
+	flag.Parse()
+
+	goldenFixtures, err := interop.Golden()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *golden != "" {
+		if err := interop.WriteFixtures(*golden, goldenFixtures); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	fixtures := interop.Fixtures()
+	if *fixturesDir != "" {
+		if fixtures, err = interop.ReadFixtures(*fixturesDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fixtures = append(fixtures, goldenFixtures...)
+
+	var results []interop.Result
+	if *url != "" {
+		results = interop.Check(nil, *url, fixtures)
+	} else {
+		results = interop.CheckHandler(interop.NewServer().Handler(), fixtures)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v\n", result.Fixture, result.Err)
+			continue
+		}
+		fmt.Printf("ok   %s\n", result.Fixture)
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d fixtures failed\n", failed, len(results))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+// Command a2a-sidecar runs an A2A agent next to a service that does not
+// speak A2A, forwarding each task to the service over localhost HTTP or
+// by running a command.
+//
+// Run:
+//
+//	go run ./cmd/a2a-sidecar -agent-id invoices -capabilities create,void -backend 'http://localhost:8000/{action}'
+//	go run ./cmd/a2a-sidecar -agent-id resize -capabilities resize -exec './resize.sh'
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mentessaas/a2a-protocol/go/a2a"
+	"github.com/mentessaas/a2a-protocol/go/a2a/sidecar"
+)
+
+func main() {
+	agentID := flag.String("agent-id", "", "ID the agent registers and is called as (required)")
+	name := flag.String("name", "", "agent name (default the agent ID)")
+	capabilities := flag.String("capabilities", "", "comma-separated actions the agent takes")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	endpoint := flag.String("endpoint", "", "URL other agents reach the sidecar at")
+	backendURL := flag.String("backend", "", "URL tasks are posted to; {action} is replaced by the task's action")
+	command := flag.String("exec", "", "command run for each task, split on spaces, instead of -backend")
+	token := flag.String("token", "", "bearer token callers must present (default $A2A_SIDECAR_TOKEN)")
+	certFile := flag.String("tls-cert", "", "TLS certificate file; serves HTTPS with -tls-key")
+	keyFile := flag.String("tls-key", "", "TLS key file")
+	retries := flag.Int("retries", 3, "attempts at a task the backend could not take, including the first")
+	retryBackoff := flag.Duration("retry-backoff", 500*time.Millisecond, "delay before the first retry, doubling after each")
+	metrics := flag.Bool("metrics", false, "serve Prometheus metrics at /metrics")
+	directoryURL := flag.String("directory", "", "directory to register with")
+	directoryToken := flag.String("directory-token", "", "bearer token to register with (default $A2A_DIRECTORY_TOKEN)")
+	flag.Parse()
+	if *agentID == "" || (*backendURL == "") == (*command == "") {
+		log.Fatal("-agent-id and one of -backend and -exec are required")
+	}
+	if *token == "" {
+		*token = os.Getenv("A2A_SIDECAR_TOKEN")
+	}
+	if *directoryToken == "" {
+		*directoryToken = os.Getenv("A2A_DIRECTORY_TOKEN")
+	}
+
+	retry := a2a.RetryPolicy{MaxAttempts: *retries, InitialBackoff: *retryBackoff, Multiplier: 2, MaxBackoff: 30 * *retryBackoff}
+	var handler a2a.TaskFunc
+	if *backendURL != "" {
+		handler = (&sidecar.HTTPBackend{URL: *backendURL, Retry: retry}).Handle
+	} else {
+		handler = (&sidecar.ExecBackend{Command: strings.Fields(*command)}).Handle
+	}
+
+	opts := []a2a.ServerOption{a2a.WithAddr(*addr)}
+	if *name != "" {
+		opts = append(opts, a2a.WithName(*name))
+	}
+	if *capabilities != "" {
+		opts = append(opts, a2a.WithCapabilities(strings.Split(*capabilities, ",")...))
+	}
+	if *endpoint != "" {
+		opts = append(opts, a2a.WithEndpoint(*endpoint))
+	}
+	if *certFile != "" {
+		opts = append(opts, a2a.WithTLS(nil, *certFile, *keyFile))
+	}
+	if *token != "" {
+		opts = append(opts, a2a.WithMiddleware(sidecar.RequireToken(*token)))
+	}
+	if *metrics {
+		m := new(sidecar.Metrics)
+		opts = append(opts, a2a.WithMiddleware(m.Middleware))
+		handler = m.Wrap(handler)
+	}
+	if *directoryURL != "" {
+		opts = append(opts, a2a.WithDirectory(*directoryURL), a2a.WithDirectoryToken(*directoryToken))
+	}
+	server := a2a.NewServerWithOptions(*agentID, opts...)
+	server.HandleTaskFunc(handler)
+	// Asynchronous tasks the backend could not take are retried later too
+	server.SetRetryPolicy("", retry)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := (&a2a.HTTPTransport{}).Listen(ctx, server); err != nil {
+		log.Fatal(err)
+	}
+}
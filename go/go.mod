@@ -1,3 +1,3 @@
 module github.com/mentessaas/a2a-protocol/go
 
-go 1.21
+go 1.24